@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -34,10 +35,89 @@ type ApplicationSpec struct {
 	Path string `json:"path,omitempty"`
 	// Branch is the branch in the repository where the k8s yaml files for this application are stored.
 	Branch string `json:"branch,omitempty"`
+	// Tag is the tag in the repository to sync, instead of the head of the branch
+	Tag string `json:"tag,omitempty"`
+	// SemVer is the semver range of the tags in the repository to sync the latest of, instead of the head of the branch
+	SemVer string `json:"semver,omitempty"`
+	// Commit is the commit on the branch to sync, instead of the head of the branch
+	Commit string `json:"commit,omitempty"`
 	// DeploymentType is the deployment method used to apply the manifests
 	DeploymentType DeploymentType `json:"deployment_type,omitempty"`
 	// SourceType is the type of repository containing the app manifests
 	SourceType SourceType `json:"source_type,omitempty"`
+	// ChartVersion is the version or semver range of the helm chart to deploy
+	ChartVersion string `json:"chart_version,omitempty"`
+	// Values holds the helm values passed to the chart when the deployment type is helm
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Values *apiextensionsv1.JSON `json:"values,omitempty"`
+	// ValuesFrom holds references to ConfigMaps and Secrets containing helm values
+	ValuesFrom []ValuesReference `json:"values_from,omitempty"`
+	// Bucket holds the bucket details when the source type is bucket; URL is then the bucket endpoint
+	Bucket *BucketSource `json:"bucket,omitempty"`
+	// FluxNamespace is the namespace of the app's flux objects and secrets, where the flux controllers reconciling them
+	// run; defaults to the namespace of the application
+	FluxNamespace string `json:"flux_namespace,omitempty"`
+	// TargetNamespace is the namespace the app's resources are deployed into; defaults to the namespace set in the manifests
+	TargetNamespace string `json:"target_namespace,omitempty"`
+	// ServiceAccount is the name of the service account impersonated when deploying the app's resources
+	ServiceAccount string `json:"service_account,omitempty"`
+	// Targets lists the clusters the app is deployed to; defaults to the cluster the app was added from
+	Targets []ApplicationTarget `json:"targets,omitempty"`
+	// SecretEncryption is how generated secrets are encrypted before they are committed to the config repository
+	SecretEncryption SecretEncryptionType `json:"secret_encryption,omitempty"`
+	// DecryptionSecret is the name of the secret holding the keys flux uses to decrypt SOPS encrypted manifests
+	DecryptionSecret string `json:"decryption_secret,omitempty"`
+	// VerificationSecret is the name of the secret holding the public keys used to verify the signature of the synced commit
+	VerificationSecret string `json:"verification_secret,omitempty"`
+	// CommitStatus enables the commit statuses posted to the git provider for each revision applied by the app
+	CommitStatus *CommitStatus `json:"commit_status,omitempty"`
+}
+
+// CommitStatus configures how the result of applying a revision is reported on its commit in the git provider
+type CommitStatus struct {
+	// Reporter posting the statuses: flux uses a notification-controller provider and alert in the app's automation,
+	// wego has the wego controller post them from the application status
+	Reporter CommitStatusReporter `json:"reporter"`
+	// SecretRef is the name of the secret holding the git provider token, in its token key
+	SecretRef string `json:"secret_ref"`
+}
+
+// ApplicationTarget holds the settings used to deploy the app to a single cluster
+type ApplicationTarget struct {
+	// Name of the target, matching the name of the cluster it deploys to
+	Name string `json:"name"`
+	// Branch overrides the application branch for this target
+	Branch string `json:"branch,omitempty"`
+	// Path overrides the application path for this target, e.g. to point at an overlay
+	Path string `json:"path,omitempty"`
+	// Namespace is the namespace the app's resources are deployed into on this target
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// BucketSource describes an S3 compatible bucket containing the app manifests
+type BucketSource struct {
+	// Name of the bucket
+	Name string `json:"name"`
+	// Provider of the bucket; generic for MinIO and other S3 compatible stores
+	// +kubebuilder:validation:Enum=generic;aws
+	Provider string `json:"provider,omitempty"`
+	// Region of the bucket, if required by the provider
+	Region string `json:"region,omitempty"`
+	// SecretRef is the name of the secret holding the accesskey and secretkey for the bucket
+	SecretRef string `json:"secret_ref,omitempty"`
+	// Insecure allows connecting to the endpoint over plain HTTP
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// ValuesReference points to a ConfigMap or Secret holding helm values
+type ValuesReference struct {
+	// Kind of the values referent
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind string `json:"kind"`
+	// Name of the values referent, in the same namespace as the application
+	Name string `json:"name"`
+	// ValuesKey is the data key where the values can be found; defaults to values.yaml
+	ValuesKey string `json:"values_key,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=helm;kustomize
@@ -48,12 +128,29 @@ const (
 	DeploymentTypeKustomize DeploymentType = "kustomize"
 )
 
-// +kubebuilder:validation:Enum=helm;git
+// +kubebuilder:validation:Enum=helm;git;bucket
 type SourceType string
 
 const (
-	SourceTypeGit  SourceType = "git"
-	SourceTypeHelm SourceType = "helm"
+	SourceTypeGit    SourceType = "git"
+	SourceTypeHelm   SourceType = "helm"
+	SourceTypeBucket SourceType = "bucket"
+)
+
+// +kubebuilder:validation:Enum=sops;sealed-secrets
+type SecretEncryptionType string
+
+const (
+	SecretEncryptionSops          SecretEncryptionType = "sops"
+	SecretEncryptionSealedSecrets SecretEncryptionType = "sealed-secrets"
+)
+
+// +kubebuilder:validation:Enum=flux;wego
+type CommitStatusReporter string
+
+const (
+	CommitStatusReporterFlux CommitStatusReporter = "flux"
+	CommitStatusReporterWego CommitStatusReporter = "wego"
 )
 
 // SuspendAction defines the command run to pause/unpause an application
@@ -66,8 +163,14 @@ const (
 
 // ApplicationStatus defines the observed state of Application
 type ApplicationStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// LastAppliedRevision is the source revision last applied by the app's kustomization, e.g. main/<sha>
+	LastAppliedRevision string `json:"last_applied_revision,omitempty"`
+	// LastAttemptedRevision is the source revision the app's kustomization last tried to apply
+	LastAttemptedRevision string `json:"last_attempted_revision,omitempty"`
+	// Conditions holds the conditions of the app's kustomization
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ReportedCommitStatus is the last commit status posted by the wego controller, as <sha>:<state>
+	ReportedCommitStatus string `json:"reported_commit_status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -83,6 +186,15 @@ type Application struct {
 	Status ApplicationStatus `json:"status,omitempty"`
 }
 
+// GetFluxNamespace returns the namespace of the app's flux objects
+func (in *Application) GetFluxNamespace() string {
+	if in.Spec.FluxNamespace != "" {
+		return in.Spec.FluxNamespace
+	}
+
+	return in.Namespace
+}
+
 //+kubebuilder:object:root=true
 
 // ApplicationList contains a list of Application
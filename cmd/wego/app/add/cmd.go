@@ -9,24 +9,25 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/lithammer/dedent"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/wego/gitclient"
 	"github.com/weaveworks/weave-gitops/cmd/wego/version"
 	"github.com/weaveworks/weave-gitops/pkg/flux"
-	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
 	"github.com/weaveworks/weave-gitops/pkg/logger"
 	"github.com/weaveworks/weave-gitops/pkg/osys"
 	"github.com/weaveworks/weave-gitops/pkg/runner"
 	"github.com/weaveworks/weave-gitops/pkg/services/app"
 	"github.com/weaveworks/weave-gitops/pkg/utils"
-	"golang.org/x/term"
 )
 
 var params app.AddParams
 
+var commitFlags gitclient.CommitFlags
+
 var Cmd = &cobra.Command{
 	Use:   "add [--name <name>] [--url <url>] [--branch <branch>] [--path <path within repository>] [--private-key <keyfile>] <repository directory>",
 	Short: "Add a workload repository to a wego cluster",
@@ -40,6 +41,36 @@ var Cmd = &cobra.Command{
   # Add podinfo application to wego control from github repository
   wego app add --url git@github.com:myorg/podinfo
 
+  # Add podinfo application over HTTPS, authenticating with GITHUB_TOKEN instead of an ssh key
+  wego app add --url https://github.com/myorg/podinfo
+
+  # Add a helm chart from a helm repository with custom values
+  wego app add --url https://charts.kube-ops.io --chart loki --chart-version ">=2.0.0" --values ./loki-values.yaml --set persistence.enabled=true
+
+  # Add application to wego control from a MinIO bucket, storing the automation only in the cluster
+  wego app add --url minio.minio.svc:9000 --bucket my-manifests --bucket-secret-ref minio-credentials --bucket-insecure --app-config-url NONE
+
+  # Add podinfo to the staging and prod clusters, with prod deploying an overlay into its own namespace
+  wego app add --url git@github.com:myorg/podinfo --app-config-url git@github.com:myorg/config --target staging --target prod:path=./overlays/prod,namespace=podinfo
+
+  # Add podinfo and commit its deploy keys to the config repository, encrypted with sops for an age key
+  wego app add --url git@github.com:myorg/podinfo --app-config-url git@github.com:myorg/config --encrypt-secrets sops --sops-age-recipients age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p
+
+  # Add podinfo, pushing the automation directly with commits signed by your ssh key
+  wego app add --url git@github.com:myorg/podinfo --auto-merge --commit-author-name "Jane Doe" --commit-author-email jane@example.com --signing-key ~/.ssh/id_ed25519
+
+  # Add podinfo following its 6.x release tags
+  wego app add --url git@github.com:myorg/podinfo --app-config-url git@github.com:myorg/config --semver "^6.0.0"
+
+  # Add podinfo, only syncing commits signed by the release engineers' OpenPGP keys
+  wego app add --url git@github.com:myorg/podinfo --app-config-url git@github.com:myorg/config --verify-signatures --verify-keyring ./release-engineers.asc
+
+  # Add podinfo, posting its sync events to the #deploys slack channel
+  wego app add --url git@github.com:myorg/podinfo --notify slack:deploys --notify-secret-ref slack-webhook
+
+  # Add podinfo, posting a check with the outcome of each applied commit to GitHub
+  wego app add --url git@github.com:myorg/podinfo --commit-status flux --commit-status-secret-ref github-token
+
   # Get status of podinfo application
   wego app status podinfo
 `,
@@ -56,10 +87,43 @@ func init() {
 	Cmd.Flags().StringVar(&params.Url, "url", "", "URL of remote repository")
 	Cmd.Flags().StringVar(&params.Path, "path", "./", "Path of files within git repository")
 	Cmd.Flags().StringVar(&params.Branch, "branch", "main", "Branch to watch within git repository")
+	Cmd.Flags().StringVar(&params.Tag, "tag", "", "Tag to sync from the git repository instead of the head of --branch")
+	Cmd.Flags().StringVar(&params.SemVer, "semver", "", "Semver range of the tags to sync the latest of from the git repository, e.g. \">=1.0.0 <2.0.0\"")
+	Cmd.Flags().StringVar(&params.Commit, "commit", "", "Full SHA of the commit on --branch to sync from the git repository, among the last 1000 commits of the branch")
 	Cmd.Flags().StringVar(&params.DeploymentType, "deployment-type", "kustomize", "deployment type [kustomize, helm]")
 	Cmd.Flags().StringVar(&params.Chart, "chart", "", "Specify chart for helm source")
+	Cmd.Flags().StringVar(&params.ChartVersion, "chart-version", "", "Version or semver range of the helm chart to deploy")
+	Cmd.Flags().StringSliceVar(&params.ValuesFiles, "values", []string{}, "Local path to a helm values file; can be repeated, later files take precedence")
+	Cmd.Flags().StringArrayVar(&params.SetValues, "set", []string{}, "Set helm values on the command line; can be repeated or separated with commas (key1=val1,key2=val2), escaping commas in values with a backslash")
+	Cmd.Flags().StringSliceVar(&params.ValuesFrom, "values-from", []string{}, "ConfigMap or Secret holding helm values, in the form Kind/name[:key]; can be repeated")
+	Cmd.Flags().StringVar(&params.BucketName, "bucket", "", "Name of an S3 compatible bucket holding the manifests; --url is then the bucket endpoint")
+	Cmd.Flags().StringVar(&params.BucketProvider, "bucket-provider", "generic", "Bucket provider [generic, aws]")
+	Cmd.Flags().StringVar(&params.BucketRegion, "bucket-region", "", "Region of the bucket, if required by the provider")
+	Cmd.Flags().StringVar(&params.BucketSecretRef, "bucket-secret-ref", "", "Name of the secret holding the accesskey and secretkey for the bucket")
+	Cmd.Flags().BoolVar(&params.BucketInsecure, "bucket-insecure", false, "Connect to the bucket endpoint over plain HTTP")
+	Cmd.Flags().StringArrayVar(&params.Targets, "target", []string{}, "Cluster to deploy the app to, in the form name[:branch=<branch>,path=<path>,namespace=<namespace>]; can be repeated (defaults to the current cluster). Other clusters sync their target from the config repository they are installed from, which needs --encrypt-secrets")
+	Cmd.Flags().StringVar(&params.FluxNamespace, "flux-namespace", "", "Namespace of the flux install reconciling the app, where its sources, secrets and automation are created (defaults to the wego namespace)")
+	Cmd.Flags().StringVar(&params.TargetNamespace, "target-namespace", "", "Namespace to deploy the app's resources into, overriding the namespace set in the manifests")
+	Cmd.Flags().StringVar(&params.ServiceAccount, "service-account", "", "Service account, in the flux namespace, to impersonate when deploying the app's resources")
 	Cmd.Flags().StringVar(&params.PrivateKey, "private-key", "", "Private key to access git repository over ssh")
+	Cmd.Flags().StringVar(&params.GitAuth, "git-auth", "", "Git authentication method [ssh, https]; https uses GITHUB_TOKEN and is the default for https:// urls")
+	Cmd.Flags().StringVar(&params.SourceToken, "source-token", os.Getenv("WEGO_SOURCE_TOKEN"), "Token stored in the cluster for flux to read https repositories; use a read-only token, e.g. a fine-grained or bot token (env WEGO_SOURCE_TOKEN)")
+	Cmd.Flags().BoolVar(&params.UseProviderToken, "use-provider-token", false, "Store GITHUB_TOKEN in the cluster for flux to read https repositories when --source-token is not set")
+	Cmd.Flags().StringVar(&params.SecretEncryption, "encrypt-secrets", "", "Encrypt generated secrets and commit them to the config repository [sops, sealed-secrets]")
+	Cmd.Flags().StringSliceVar(&params.SopsAgeRecipients, "sops-age-recipients", []string{}, "age public keys to encrypt secrets for with sops")
+	Cmd.Flags().StringSliceVar(&params.SopsPGPFingerprints, "sops-pgp-fingerprints", []string{}, "PGP key fingerprints to encrypt secrets for with sops")
+	Cmd.Flags().StringVar(&params.DecryptionSecret, "decryption-secret", app.DefaultDecryptionSecret, "Secret, in the flux namespace, holding the private keys flux uses to decrypt sops encrypted manifests")
+	Cmd.Flags().StringVar(&params.SealedSecretsCert, "sealed-secrets-cert", "", "Public key certificate of the sealed secrets controller, as a file path or URL")
+	commitFlags.AddFlags(Cmd, &params.CommitAuthorName, &params.CommitAuthorEmail, "the commits pushed with --auto-merge")
+	Cmd.Flags().BoolVar(&params.VerifySignatures, "verify-signatures", false, "Only sync commits signed with one of the keys in --verify-keyring; commits pushed by wego must then be signed with an OpenPGP --signing-key")
+	Cmd.Flags().StringVar(&params.VerificationKeyring, "verify-keyring", "", "Armored OpenPGP keyring holding the public keys trusted to sign the app's commits")
+	Cmd.Flags().StringArrayVar(&params.Notify, "notify", []string{}, "Send the app's events to a chat channel or webhook, in the form <provider>:<channel> for slack, discord or rocket, or generic:<address>; can be repeated")
+	Cmd.Flags().StringVar(&params.NotifySecretRef, "notify-secret-ref", "", "Secret, in the flux namespace, holding the webhook address of the chat providers in its address key")
+	Cmd.Flags().StringVar(&params.NotifySeverity, "notify-severity", app.DefaultNotifySeverity, "Severity of the events to notify [info, error]")
+	Cmd.Flags().StringVar(&params.CommitStatus, "commit-status", "", "Post the status of each applied revision to its commit in GitHub or GitLab, reported by flux or wego [flux, wego]")
+	Cmd.Flags().StringVar(&params.CommitStatusSecretRef, "commit-status-secret-ref", "", "Secret, in the flux namespace, holding the git provider token in its token key")
 	Cmd.Flags().StringVar(&params.AppConfigUrl, "app-config-url", "", "URL of external repository (if any) which will hold automation manifests; NONE to store only in the cluster")
+	Cmd.Flags().BoolVar(&params.InMemoryClone, "in-memory-clone", false, "Clone the repository the automation is written to in memory instead of in a temporary directory")
 	Cmd.Flags().BoolVar(&params.DryRun, "dry-run", false, "If set, 'wego add' will not make any changes to the system; it will just display the actions that would have been taken")
 	Cmd.Flags().BoolVar(&params.AutoMerge, "auto-merge", false, "If set, 'wego add' will merge automatically into the set --branch")
 }
@@ -80,46 +144,45 @@ func runCmd(cmd *cobra.Command, args []string) error {
 		params.Dir = path
 	}
 
-	if strings.HasPrefix(params.PrivateKey, "~/") {
-		dir, err := getHomeDir()
-		if err != nil {
-			return err
-		}
-		params.PrivateKey = filepath.Join(dir, params.PrivateKey[2:])
-	} else if params.PrivateKey == "" {
-		privateKey, err := findPrivateKeyFile()
-		if err != nil {
-			return err
-		}
-		params.PrivateKey = privateKey
-	}
+	var err error
 
-	authMethod, err := ssh.NewPublicKeysFromFile("git", params.PrivateKey, "")
+	params, err = setGitProviderToken(params)
 	if err != nil {
-		fmt.Print("Private Key Password: ")
-		pw, err := term.ReadPassword(int(os.Stdin.Fd()))
-		if err != nil {
-			return errors.Wrap(err, "failed reading ssh key password")
-		}
+		return err
+	}
 
-		authMethod, err = ssh.NewPublicKeysFromFile("git", params.PrivateKey, string(pw))
-		if err != nil {
-			return errors.Wrap(err, "failed reading ssh keys")
-		}
+	// Helm and bucket sources only need git for an external config repo
+	gitUrl := params.Url
+	if params.Chart != "" || params.BucketName != "" {
+		gitUrl = params.AppConfigUrl
 	}
 
-	params, err = setGitProviderToken(params)
+	gitAuth, err := app.ResolveGitAuth(params.GitAuth, gitUrl)
 	if err != nil {
 		return err
 	}
 
+	var authMethod transport.AuthMethod
+	if gitAuth == app.GitAuthHTTPS {
+		authMethod = app.NewHTTPSAuth(params.GitProviderToken)
+	} else {
+		authMethod, err = app.SSHAuthMethod(params.PrivateKey)
+		if err != nil {
+			return err
+		}
+	}
+
 	cliRunner := &runner.CLIRunner{}
 	osysClient := osys.New()
 	fluxClient := flux.New(osysClient, cliRunner)
 	kubeClient := kube.New(cliRunner)
-	gitClient := git.New(authMethod)
 	logger := logger.New(os.Stdout)
 
+	gitClient, err := commitFlags.New(authMethod)
+	if err != nil {
+		return err
+	}
+
 	appService := app.New(logger, gitClient, fluxClient, kubeClient, osysClient)
 
 	utils.SetCommmitMessageFromArgs("wego app add", params.Url, params.Path, params.Name)
@@ -131,33 +194,6 @@ func runCmd(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func getHomeDir() (string, error) {
-	dir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("could not determine user home directory")
-	}
-	return dir, nil
-}
-
-func findPrivateKeyFile() (string, error) {
-	dir, err := getHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	modernFilePath := filepath.Join(dir, ".ssh", "id_ed25519")
-	if utils.Exists(modernFilePath) {
-		return modernFilePath, nil
-	}
-
-	legacyFilePath := filepath.Join(dir, ".ssh", "id_rsa")
-	if utils.Exists(legacyFilePath) {
-		return legacyFilePath, nil
-	}
-
-	return "", fmt.Errorf("could not locate ssh key file; please specify '--private-key'")
-}
-
 func setGitProviderToken(params app.AddParams) (app.AddParams, error) {
 	providerToken, found := os.LookupEnv("GITHUB_TOKEN")
 	if !found {
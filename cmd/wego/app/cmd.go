@@ -3,8 +3,14 @@ package app
 import (
 	"github.com/spf13/cobra"
 	"github.com/weaveworks/weave-gitops/cmd/wego/app/add"
+	"github.com/weaveworks/weave-gitops/cmd/wego/app/diff"
+	"github.com/weaveworks/weave-gitops/cmd/wego/app/imagepolicy"
 	"github.com/weaveworks/weave-gitops/cmd/wego/app/list"
+	"github.com/weaveworks/weave-gitops/cmd/wego/app/logs"
+	"github.com/weaveworks/weave-gitops/cmd/wego/app/notify"
 	"github.com/weaveworks/weave-gitops/cmd/wego/app/pause"
+	"github.com/weaveworks/weave-gitops/cmd/wego/app/resources"
+	"github.com/weaveworks/weave-gitops/cmd/wego/app/rotatekey"
 	"github.com/weaveworks/weave-gitops/cmd/wego/app/status"
 	"github.com/weaveworks/weave-gitops/cmd/wego/app/unpause"
 )
@@ -26,7 +32,25 @@ var ApplicationCmd = &cobra.Command{
   wego app pause <app-name>
 
   # Unpause gitops automation
-  wego app unpause <app-name>`,
+  wego app unpause <app-name>
+
+  # List the Kubernetes objects deployed by an application
+  wego app resources <app-name>
+
+  # Show the events and controller logs of an application
+  wego app logs <app-name> --follow
+
+  # Show the drift between an application source and the cluster
+  wego app diff <app-name>
+
+  # Send the events of an application to a slack channel
+  wego app notify <app-name> --notify slack:<channel> --notify-secret-ref <secret>
+
+  # Update the image of an application to its latest semver release
+  wego app image-policy <app-name> --image <image> --policy semver --select <range>
+
+  # Rotate the deploy keys of an app
+  wego app rotate-key <app-name>`,
 	Args: cobra.MinimumNArgs(1),
 }
 
@@ -36,4 +60,10 @@ func init() {
 	ApplicationCmd.AddCommand(list.Cmd)
 	ApplicationCmd.AddCommand(pause.Cmd)
 	ApplicationCmd.AddCommand(unpause.Cmd)
+	ApplicationCmd.AddCommand(rotatekey.Cmd)
+	ApplicationCmd.AddCommand(diff.Cmd)
+	ApplicationCmd.AddCommand(resources.Cmd)
+	ApplicationCmd.AddCommand(logs.Cmd)
+	ApplicationCmd.AddCommand(notify.Cmd)
+	ApplicationCmd.AddCommand(imagepolicy.Cmd)
 }
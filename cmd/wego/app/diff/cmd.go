@@ -0,0 +1,73 @@
+package diff
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/wego/version"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/osys"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
+)
+
+var params app.DiffParams
+
+var Cmd = &cobra.Command{
+	Use:   "diff <app-name>",
+	Short: "Show the differences between the source of an application and the cluster",
+	Long: `Renders the application manifests with kustomize or helm, from a local checkout of the application repository,
+and shows a unified diff of the fields they set against the objects in the cluster.
+Objects that were removed from the source are not listed.`,
+	Args: cobra.MinimumNArgs(1),
+	Example: `
+  # Show the drift of podinfo, from the current directory
+  wego app diff podinfo
+
+  # Show the drift of podinfo, from another checkout of its repository
+  wego app diff podinfo --dir ~/src/podinfo`,
+	RunE:          runCmd,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PostRun: func(cmd *cobra.Command, args []string) {
+		version.CheckVersion(version.CheckpointParamsWithFlags(version.CheckpointParams(), cmd))
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVar(&params.Dir, "dir", ".", "Local checkout of the application repository")
+}
+
+func runCmd(cmd *cobra.Command, args []string) error {
+	params.Namespace, _ = cmd.Parent().Flags().GetString("namespace")
+	params.Name = args[0]
+
+	cliRunner := &runner.CLIRunner{}
+	osysClient := osys.New()
+	fluxClient := flux.New(osysClient, cliRunner)
+	logger := logger.New(os.Stdout)
+	kubeClient, err := kube.NewKubeHTTPClient()
+	if err != nil {
+		return fmt.Errorf("error initializing kube client: %w", err)
+	}
+
+	appService := app.New(logger, nil, fluxClient, kubeClient, osysClient)
+
+	diff, err := appService.Diff(params)
+	if err != nil {
+		return errors.Wrapf(err, "failed to diff the app %s", params.Name)
+	}
+
+	if diff == "" {
+		logger.Successf("The cluster matches the source of %s", params.Name)
+		return nil
+	}
+
+	fmt.Print(diff)
+
+	return nil
+}
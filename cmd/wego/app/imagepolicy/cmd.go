@@ -0,0 +1,110 @@
+package imagepolicy
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/wego/gitclient"
+	"github.com/weaveworks/weave-gitops/cmd/wego/version"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/osys"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var params app.ImagePolicyParams
+
+var (
+	privateKey  string
+	gitAuth     string
+	commitFlags gitclient.CommitFlags
+)
+
+var Cmd = &cobra.Command{
+	Use:   "image-policy <app-name>",
+	Short: "Update the images of an application to the latest tags selected by a policy",
+	Long: `Generates an image repository scanning the tags of an image, an image policy selecting its latest tag, and an image
+update automation writing that tag to the manifests of the application marked with the policy, pushing them to the app's
+repository and branch. They are written next to the application's automation in each of its targets, or applied to the
+cluster when the automation is only stored there.
+
+The automation runs in the current cluster only, and pushes through a source of its own with a write-enabled deploy key
+of the cluster, generated and uploaded to the app repository the first time. The app's source keeps its read-only key.`,
+	Args: cobra.MinimumNArgs(1),
+	Example: `
+  # Update podinfo to the latest 5.0 patch release
+  wego app image-policy podinfo --image ghcr.io/stefanprodan/podinfo --policy semver --select 5.0.x
+
+  # Update podinfo to the latest build of main, with tags like main-<sha>-<timestamp>
+  wego app image-policy podinfo --image ghcr.io/stefanprodan/podinfo --policy numerical \
+    --filter-regex '^main-[a-f0-9]+-(?P<ts>[0-9]+)' --filter-extract '$ts' --auto-merge`,
+	RunE:          runCmd,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PostRun: func(cmd *cobra.Command, args []string) {
+		version.CheckVersion(version.CheckpointParamsWithFlags(version.CheckpointParams(), cmd))
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVar(&params.Image, "image", "", "Image to update, without a tag, e.g. ghcr.io/stefanprodan/podinfo")
+	Cmd.Flags().StringVar(&params.ImageSecretRef, "image-secret-ref", "", "docker-registry secret, in the flux namespace of the app, used to list the tags of a private image")
+	Cmd.Flags().StringVar(&params.Policy, "policy", app.ImagePolicySemver, "How the latest tag is selected [semver, alphabetical, numerical]")
+	Cmd.Flags().StringVar(&params.Select, "select", "", "Semver range of semver policies, or order of alphabetical and numerical ones [asc, desc] (default asc)")
+	Cmd.Flags().StringVar(&params.FilterRegex, "filter-regex", "", "Only consider the tags matching this regular expression")
+	Cmd.Flags().StringVar(&params.FilterExtract, "filter-extract", "", "Part of the tags matched by --filter-regex the policy selects on, e.g. $ts")
+	Cmd.Flags().StringVar(&params.UpdatePath, "update-path", "", "Path of the manifests to update in the app repository (default the app's path)")
+	Cmd.Flags().StringVar(&privateKey, "private-key", "", "Private key to access the config repository over ssh")
+	Cmd.Flags().StringVar(&gitAuth, "git-auth", "", "Git authentication method [ssh, https]; https uses GITHUB_TOKEN and is the default for https:// urls")
+	commitFlags.AddFlags(Cmd, &params.CommitAuthorName, &params.CommitAuthorEmail, "the commits pushed with --auto-merge and by the image update automation")
+	Cmd.Flags().BoolVar(&params.InMemoryClone, "in-memory-clone", false, "Clone the repository the image update manifests are written to in memory instead of in a temporary directory")
+	Cmd.Flags().BoolVar(&params.DryRun, "dry-run", false, "If set, 'wego app image-policy' will not make any changes to the system; it will just display the manifests that would have been written")
+	Cmd.Flags().BoolVar(&params.AutoMerge, "auto-merge", false, "If set, 'wego app image-policy' will merge automatically into the app's branch")
+}
+
+func runCmd(cmd *cobra.Command, args []string) error {
+	params.Namespace, _ = cmd.Parent().Flags().GetString("namespace")
+	params.Name = args[0]
+
+	providerToken, found := os.LookupEnv("GITHUB_TOKEN")
+	if !found {
+		return fmt.Errorf("GITHUB_TOKEN not set in environment")
+	}
+
+	params.GitProviderToken = providerToken
+
+	cliRunner := &runner.CLIRunner{}
+	osysClient := osys.New()
+	fluxClient := flux.New(osysClient, cliRunner)
+	kubeClient := kube.New(cliRunner)
+	logger := logger.New(os.Stdout)
+
+	application, err := kubeClient.GetApplication(context.Background(), types.NamespacedName{Name: params.Name, Namespace: params.Namespace})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the app %s", params.Name)
+	}
+
+	authMethod, err := app.AutomationRepoAuthMethod(application, gitAuth, privateKey, params.GitProviderToken)
+	if err != nil {
+		return err
+	}
+
+	gitClient, err := commitFlags.New(authMethod)
+	if err != nil {
+		return err
+	}
+
+	appService := app.New(logger, gitClient, fluxClient, kubeClient, osysClient)
+
+	if err := appService.ImagePolicy(params); err != nil {
+		return errors.Wrapf(err, "failed to set the image policy of app %s", params.Name)
+	}
+
+	return nil
+}
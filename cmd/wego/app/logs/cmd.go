@@ -0,0 +1,65 @@
+package logs
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/wego/version"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/osys"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
+)
+
+var params app.LogsParams
+
+var Cmd = &cobra.Command{
+	Use:   "logs <app-name>",
+	Short: "Show the Kubernetes events and controller logs of an application",
+	Long: `Shows the Kubernetes events about the source, kustomization or helm release and deployed objects of an application,
+with the lines of the source, kustomize and helm controller logs about them, oldest first.`,
+	Args: cobra.MinimumNArgs(1),
+	Example: `  # Show the events and controller logs of the last hour
+  wego app logs podinfo --since 1h
+
+  # Keep streaming new events and log lines
+  wego app logs podinfo --follow`,
+	RunE:          runCmd,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PostRun: func(cmd *cobra.Command, args []string) {
+		version.CheckVersion(version.CheckpointParamsWithFlags(version.CheckpointParams(), cmd))
+	},
+}
+
+func init() {
+	Cmd.Flags().BoolVarP(&params.Follow, "follow", "f", false, "Keep streaming new events and log lines")
+	Cmd.Flags().DurationVar(&params.Since, "since", 0, "Only show the entries newer than a relative duration like 5s, 2m, or 3h")
+	Cmd.Flags().StringVar(&params.FluxNamespace, "flux-namespace", "", "Namespace the flux controllers run in, when they are not installed in the wego namespace")
+}
+
+func runCmd(cmd *cobra.Command, args []string) error {
+	params.Namespace, _ = cmd.Parent().Flags().GetString("namespace")
+	params.Name = args[0]
+
+	cliRunner := &runner.CLIRunner{}
+	osysClient := osys.New()
+	fluxClient := flux.New(osysClient, cliRunner)
+	logger := logger.New(os.Stdout)
+	kubeClient, err := kube.NewKubeHTTPClient()
+	if err != nil {
+		return fmt.Errorf("error initializing kube client: %w", err)
+	}
+
+	appService := app.New(logger, nil, fluxClient, kubeClient, osysClient)
+
+	if err := appService.Logs(params); err != nil {
+		return errors.Wrapf(err, "failed to get the logs of app %s", params.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/wego/gitclient"
+	"github.com/weaveworks/weave-gitops/cmd/wego/version"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/osys"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var params app.NotifyParams
+
+var (
+	privateKey  string
+	gitAuth     string
+	commitFlags gitclient.CommitFlags
+)
+
+var Cmd = &cobra.Command{
+	Use:   "notify <app-name>",
+	Short: "Send the events of an application to chat channels or webhooks",
+	Long: `Generates notification-controller providers and alerts for the source and kustomization or helm release of an application.
+They are written next to the application's automation in each of its targets, or applied to the cluster when the automation
+is only stored there.`,
+	Args: cobra.MinimumNArgs(1),
+	Example: `
+  # Post the events of podinfo to the #deploys slack channel, with the webhook address in the slack-webhook secret
+  wego app notify podinfo --notify slack:deploys --notify-secret-ref slack-webhook
+
+  # Post the error events of podinfo to a webhook
+  wego app notify podinfo --notify generic:http://receiver.default:8080 --notify-severity error --auto-merge`,
+	RunE:          runCmd,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PostRun: func(cmd *cobra.Command, args []string) {
+		version.CheckVersion(version.CheckpointParamsWithFlags(version.CheckpointParams(), cmd))
+	},
+}
+
+func init() {
+	Cmd.Flags().StringArrayVar(&params.Notify, "notify", []string{}, "Send the app's events to a chat channel or webhook, in the form <provider>:<channel> for slack, discord or rocket, or generic:<address>; can be repeated")
+	Cmd.Flags().StringVar(&params.NotifySecretRef, "notify-secret-ref", "", "Secret, in the wego namespace, holding the webhook address of the chat providers in its address key")
+	Cmd.Flags().StringVar(&params.NotifySeverity, "notify-severity", app.DefaultNotifySeverity, "Severity of the events to notify [info, error]")
+	Cmd.Flags().StringVar(&privateKey, "private-key", "", "Private key to access the config repository over ssh")
+	Cmd.Flags().StringVar(&gitAuth, "git-auth", "", "Git authentication method [ssh, https]; https uses GITHUB_TOKEN and is the default for https:// urls")
+	commitFlags.AddFlags(Cmd, &params.CommitAuthorName, &params.CommitAuthorEmail, "the commit pushed with --auto-merge")
+	Cmd.Flags().BoolVar(&params.InMemoryClone, "in-memory-clone", false, "Clone the repository the notifications are written to in memory instead of in a temporary directory")
+	Cmd.Flags().BoolVar(&params.DryRun, "dry-run", false, "If set, 'wego app notify' will not make any changes to the system; it will just display the manifests that would have been written")
+	Cmd.Flags().BoolVar(&params.AutoMerge, "auto-merge", false, "If set, 'wego app notify' will merge automatically into the app's branch")
+}
+
+func runCmd(cmd *cobra.Command, args []string) error {
+	params.Namespace, _ = cmd.Parent().Flags().GetString("namespace")
+	params.Name = args[0]
+
+	providerToken, found := os.LookupEnv("GITHUB_TOKEN")
+	if !found {
+		return fmt.Errorf("GITHUB_TOKEN not set in environment")
+	}
+
+	params.GitProviderToken = providerToken
+
+	cliRunner := &runner.CLIRunner{}
+	osysClient := osys.New()
+	fluxClient := flux.New(osysClient, cliRunner)
+	kubeClient := kube.New(cliRunner)
+	logger := logger.New(os.Stdout)
+
+	application, err := kubeClient.GetApplication(context.Background(), types.NamespacedName{Name: params.Name, Namespace: params.Namespace})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the app %s", params.Name)
+	}
+
+	// The notifications are written to the repository holding the app's automation, if it isn't only in the cluster
+	authMethod, err := app.AutomationRepoAuthMethod(application, gitAuth, privateKey, params.GitProviderToken)
+	if err != nil {
+		return err
+	}
+
+	gitClient, err := commitFlags.New(authMethod)
+	if err != nil {
+		return err
+	}
+
+	appService := app.New(logger, gitClient, fluxClient, kubeClient, osysClient)
+
+	if err := appService.Notify(params); err != nil {
+		return errors.Wrapf(err, "failed to set the notifications of app %s", params.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package resources
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/wego/version"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/osys"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
+)
+
+var params app.ResourcesParams
+
+var Cmd = &cobra.Command{
+	Use:   "resources <app-name>",
+	Short: "List the Kubernetes objects deployed by an application",
+	Long: `Lists the objects in the inventory of the application's kustomization, or in the manifest of its helm release,
+with their health: Current, InProgress, Failed, Terminating or NotFound.`,
+	Args:          cobra.MinimumNArgs(1),
+	Example:       "wego app resources podinfo",
+	RunE:          runCmd,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PostRun: func(cmd *cobra.Command, args []string) {
+		version.CheckVersion(version.CheckpointParamsWithFlags(version.CheckpointParams(), cmd))
+	},
+}
+
+func runCmd(cmd *cobra.Command, args []string) error {
+	params.Namespace, _ = cmd.Parent().Flags().GetString("namespace")
+	params.Name = args[0]
+
+	cliRunner := &runner.CLIRunner{}
+	osysClient := osys.New()
+	fluxClient := flux.New(osysClient, cliRunner)
+	logger := logger.New(os.Stdout)
+	kubeClient, err := kube.NewKubeHTTPClient()
+	if err != nil {
+		return fmt.Errorf("error initializing kube client: %w", err)
+	}
+
+	appService := app.New(logger, nil, fluxClient, kubeClient, osysClient)
+
+	resources, err := appService.Resources(params)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list the resources of app %s", params.Name)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tHEALTH\tMESSAGE")
+
+	for _, r := range resources {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Kind, r.Namespace, r.Name, r.Health, r.Message)
+	}
+
+	return w.Flush()
+}
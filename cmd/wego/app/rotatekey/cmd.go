@@ -0,0 +1,139 @@
+package rotatekey
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/wego/gitclient"
+	"github.com/weaveworks/weave-gitops/cmd/wego/version"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/osys"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	params      app.RotateKeyParams
+	privateKey  string
+	gitAuth     string
+	commitFlags gitclient.CommitFlags
+)
+
+var Cmd = &cobra.Command{
+	Use:   "rotate-key [<app-name>]",
+	Short: "Rotate the deploy keys of an application or a repository",
+	Long: `Generates a new deploy key, uploads it next to the old one and updates the cluster secret.
+The old key is deleted once the sources using it have reconciled with the new key.
+Apps committing their secrets encrypted get the new secret committed, encrypted with the given keys.`,
+	Args: cobra.MaximumNArgs(1),
+	Example: `
+  # Rotate the deploy keys used by podinfo
+  wego app rotate-key podinfo
+
+  # Rotate the deploy key of a repository, for every app using it
+  wego app rotate-key --url git@github.com:myorg/gitops-config.git
+
+  # Rotate the deploy keys of an app committing its secrets encrypted with sops
+  wego app rotate-key podinfo --sops-age-recipients age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p`,
+	RunE:          runCmd,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PostRun: func(cmd *cobra.Command, args []string) {
+		version.CheckVersion(version.CheckpointParamsWithFlags(version.CheckpointParams(), cmd))
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVar(&params.Url, "url", "", "URL of a repository to rotate the deploy key of, instead of an app")
+	Cmd.Flags().StringSliceVar(&params.SopsAgeRecipients, "sops-age-recipients", []string{}, "age public keys to encrypt the new secret for, for apps added with --encrypt-secrets sops")
+	Cmd.Flags().StringSliceVar(&params.SopsPGPFingerprints, "sops-pgp-fingerprints", []string{}, "PGP key fingerprints to encrypt the new secret for, for apps added with --encrypt-secrets sops")
+	Cmd.Flags().StringVar(&params.SealedSecretsCert, "sealed-secrets-cert", "", "Public key certificate to seal the new secret with, for apps added with --encrypt-secrets sealed-secrets")
+	Cmd.Flags().StringVar(&privateKey, "private-key", "", "Private key to push the new secret to the automation repository over ssh")
+	commitFlags.AddFlags(Cmd, &params.CommitAuthorName, &params.CommitAuthorEmail, "the commits of the new encrypted secrets")
+	Cmd.Flags().StringVar(&gitAuth, "git-auth", "", "Git authentication method [ssh, https]; https uses GITHUB_TOKEN and is the default for https:// urls")
+}
+
+func runCmd(cmd *cobra.Command, args []string) error {
+	params.Namespace, _ = cmd.Parent().Flags().GetString("namespace")
+
+	if (params.Url == "") == (len(args) == 0) {
+		return fmt.Errorf("you should choose either --url or the app name")
+	}
+
+	if len(args) > 0 {
+		params.Name = args[0]
+	}
+
+	providerToken, found := os.LookupEnv("GITHUB_TOKEN")
+	if !found {
+		return fmt.Errorf("GITHUB_TOKEN not set in environment")
+	}
+
+	params.GitProviderToken = providerToken
+
+	cliRunner := &runner.CLIRunner{}
+	osysClient := osys.New()
+	fluxClient := flux.New(osysClient, cliRunner)
+	logger := logger.New(os.Stdout)
+	kubeClient, err := kube.NewKubeHTTPClient()
+	if err != nil {
+		return fmt.Errorf("error initializing kube client: %w", err)
+	}
+
+	authMethod, err := automationRepoAuth(kubeClient)
+	if err != nil {
+		return err
+	}
+
+	gitClient, err := commitFlags.New(authMethod)
+	if err != nil {
+		return err
+	}
+
+	appService := app.New(logger, gitClient, fluxClient, kubeClient, osysClient)
+
+	if err := appService.RotateKey(params); err != nil {
+		if params.Name != "" {
+			return errors.Wrapf(err, "failed to rotate the deploy keys of app %s", params.Name)
+		}
+
+		return errors.Wrapf(err, "failed to rotate the deploy key of repo %s", params.Url)
+	}
+
+	return nil
+}
+
+// automationRepoAuth returns the auth method the new encrypted secrets are pushed with: to the repository holding the
+// automation of the app, or, for --url, to the rotated repository, as the config repository of the apps using it
+func automationRepoAuth(kubeClient kube.Kube) (transport.AuthMethod, error) {
+	if params.Name == "" {
+		resolved, err := app.ResolveGitAuth(gitAuth, params.Url)
+		if err != nil {
+			return nil, err
+		}
+
+		params.GitAuth = resolved
+
+		return app.RepoAuthMethod(params.Url, gitAuth, privateKey, params.GitProviderToken)
+	}
+
+	application, err := kubeClient.GetApplication(context.Background(), types.NamespacedName{Name: params.Name, Namespace: params.Namespace})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the app %s", params.Name)
+	}
+
+	// The automation repository urls are cloned in the format of the auth method
+	params.GitAuth, err = app.ResolveGitAuth(gitAuth, app.AutomationRepoUrl(application))
+	if err != nil {
+		return nil, err
+	}
+
+	return app.AutomationRepoAuthMethod(application, gitAuth, privateKey, params.GitProviderToken)
+}
@@ -0,0 +1,86 @@
+package gitclient
+
+// Provides the git client of the commands pushing commits to repositories, with the flags setting the author of
+// the commits, the key signing them and how often a push is retried when the remote branch moved.
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
+	"golang.org/x/term"
+)
+
+// pushBackoff is the wait before the first push retry, doubling for each retry
+const pushBackoff = time.Second
+
+// CommitFlags are the flags of a command setting the author and the signing key of the commits it pushes
+type CommitFlags struct {
+	signingKeyFile string
+	signingKeyType string
+	pushRetries    int
+}
+
+// AddFlags adds the commit author and signing key flags to a command. The author defaults to the
+// WEGO_COMMIT_AUTHOR_NAME and WEGO_COMMIT_AUTHOR_EMAIL environment variables, then to the wego author;
+// commits describes the commits it is set on in the help of the author flags.
+func (f *CommitFlags) AddFlags(cmd *cobra.Command, authorName *string, authorEmail *string, commits string) {
+	cmd.Flags().StringVar(authorName, "commit-author-name", envOrDefault("WEGO_COMMIT_AUTHOR_NAME", app.DefaultCommitAuthorName), fmt.Sprintf("Name of the author of %s (env WEGO_COMMIT_AUTHOR_NAME)", commits))
+	cmd.Flags().StringVar(authorEmail, "commit-author-email", envOrDefault("WEGO_COMMIT_AUTHOR_EMAIL", app.DefaultCommitAuthorEmail), fmt.Sprintf("Email of the author of %s (env WEGO_COMMIT_AUTHOR_EMAIL)", commits))
+	cmd.Flags().StringVar(&f.signingKeyFile, "signing-key", os.Getenv("WEGO_SIGNING_KEY"), "Private OpenPGP or ssh key file used to sign the commits wego pushes (env WEGO_SIGNING_KEY); the passphrase is read from WEGO_SIGNING_KEY_PASSPHRASE")
+	cmd.Flags().StringVar(&f.signingKeyType, "signing-key-type", os.Getenv("WEGO_SIGNING_KEY_TYPE"), "Type of the signing key [openpgp, ssh]; detected from the key by default (env WEGO_SIGNING_KEY_TYPE)")
+	cmd.Flags().IntVar(&f.pushRetries, "push-retries", 3, "Number of times to re-apply the commits onto the remote branch and push again, when the branch moved while pushing")
+}
+
+// New returns a git client using the given auth, which signs the commits it creates with the key of --signing-key
+// and re-applies them onto the remote branch up to --push-retries times when a push is rejected
+func (f *CommitFlags) New(auth transport.AuthMethod) (*git.GoGit, error) {
+	gitClient := git.New(auth).WithPushRetries(f.pushRetries, pushBackoff)
+
+	if f.signingKeyFile != "" {
+		signingKey, err := f.loadSigningKey()
+		if err != nil {
+			return nil, err
+		}
+
+		gitClient.WithSigningKey(signingKey)
+	}
+
+	return gitClient, nil
+}
+
+func (f *CommitFlags) loadSigningKey() (git.SigningKey, error) {
+	key, err := ioutil.ReadFile(f.signingKeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading signing key")
+	}
+
+	passphrase, found := os.LookupEnv("WEGO_SIGNING_KEY_PASSPHRASE")
+
+	signingKey, err := git.NewSigningKey(git.SigningKeyType(f.signingKeyType), key, passphrase)
+	if err != nil && !found {
+		fmt.Print("Signing Key Password: ")
+		pw, err := term.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed reading signing key password")
+		}
+
+		return git.NewSigningKey(git.SigningKeyType(f.signingKeyType), key, string(pw))
+	}
+
+	return signingKey, err
+}
+
+func envOrDefault(name string, defaultValue string) string {
+	if value, found := os.LookupEnv(name); found {
+		return value
+	}
+
+	return defaultValue
+}
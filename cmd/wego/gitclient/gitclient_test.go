@@ -0,0 +1,66 @@
+package gitclient
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
+)
+
+func TestAuthorDefaults(t *testing.T) {
+	var name, email string
+
+	flags := CommitFlags{}
+	flags.AddFlags(&cobra.Command{}, &name, &email, "the commits")
+
+	assert.Equal(t, app.DefaultCommitAuthorName, name)
+	assert.Equal(t, app.DefaultCommitAuthorEmail, email)
+}
+
+func TestAuthorFromEnv(t *testing.T) {
+	os.Setenv("WEGO_COMMIT_AUTHOR_NAME", "Jane Doe")
+	os.Setenv("WEGO_COMMIT_AUTHOR_EMAIL", "jane@example.com")
+	defer os.Unsetenv("WEGO_COMMIT_AUTHOR_NAME")
+	defer os.Unsetenv("WEGO_COMMIT_AUTHOR_EMAIL")
+
+	var name, email string
+
+	flags := CommitFlags{}
+	flags.AddFlags(&cobra.Command{}, &name, &email, "the commits")
+
+	assert.Equal(t, "Jane Doe", name)
+	assert.Equal(t, "jane@example.com", email)
+}
+
+func TestSigningKeyFlag(t *testing.T) {
+	var name, email string
+
+	cmd := &cobra.Command{}
+	flags := CommitFlags{}
+	flags.AddFlags(cmd, &name, &email, "the commits")
+
+	_, err := flags.New(nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, cmd.Flags().Set("signing-key", "does-not-exist"))
+
+	_, err = flags.New(nil)
+	assert.EqualError(t, err, "failed reading signing key: open does-not-exist: no such file or directory")
+}
+
+func TestPushRetriesFlag(t *testing.T) {
+	var name, email string
+
+	cmd := &cobra.Command{}
+	flags := CommitFlags{}
+	flags.AddFlags(cmd, &name, &email, "the commits")
+
+	retries, err := cmd.Flags().GetInt("push-retries")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, retries)
+
+	assert.NoError(t, cmd.Flags().Set("push-retries", "5"))
+	assert.Equal(t, 5, flags.pushRetries)
+}
@@ -6,26 +6,47 @@ package gitops
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	_ "embed"
 
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/wego/gitclient"
 	"github.com/weaveworks/weave-gitops/cmd/wego/version"
 	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
 	"github.com/weaveworks/weave-gitops/pkg/logger"
 	"github.com/weaveworks/weave-gitops/pkg/osys"
 	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
 	"github.com/weaveworks/weave-gitops/pkg/services/gitops"
 )
 
 type params struct {
-	Namespace string
-	DryRun    bool
+	Namespace         string
+	FluxNamespace     string
+	ConfigRepo        string
+	Branch            string
+	GitAuth           string
+	PrivateKey        string
+	Registry          string
+	ImagePullSecret   string
+	Components        []string
+	DecryptionSecret  string
+	CommitAuthorName  string
+	CommitAuthorEmail string
+	GitHosts          []string
+	Purge             bool
+	RemoveAutomation  bool
+	DryRun            bool
 }
 
 var (
 	gitopsParams params
+	commitFlags  gitclient.CommitFlags
 )
 
 var Cmd = &cobra.Command{
@@ -38,9 +59,33 @@ var installCmd = &cobra.Command{
 	Use:   "install",
 	Short: "Install or upgrade Wego",
 	Long: `The install command deploys Wego in the specified namespace.
-If a previous version is installed, then an in-place upgrade will be performed.`,
+Next to flux and the App CRD, it installs the wego components selected with --components, none by default: the
+controller reporting the status of apps, the API server, and the UI, which 'wego ui' serves locally. Their images are
+published with each release of wego and pinned to its version.
+When flux v0.13.0 or later is already installed in the cluster, wego is installed alongside it and uses it
+instead of installing its own; set the namespace flux lives in with --flux-namespace.
+With --config-repo, the flux components, the App CRD and a sync of them are committed to targets/<cluster>/<namespace>
+in the config repository and the cluster is synced from it, so later upgrades are git changes; run the install again
+to commit the components of a newer wego. The sync covers all of targets/<cluster>, so the cluster also deploys the
+apps added to it from other clusters, decrypting their secrets with the sops keys of --decryption-secret.
+On clusters without access to ghcr.io, mirror the images listed by 'wego gitops images' to a registry, keeping
+their names and tags, and pull the flux and wego images from it with --registry; --image-pull-secret names a secret holding the
+registry credentials, which must be created in the namespace before installing.
+To move an existing install to the flux version and App CRD of this wego binary, use 'wego gitops upgrade'.`,
 	Example: `  # Install wego in the wego-system namespace
-  wego gitops install`,
+  wego gitops install
+
+  # Install wego with the controller, the API server and the UI
+  wego gitops install --components controller,server,ui
+
+  # Install wego alongside a flux installed in the flux namespace
+  wego gitops install --flux-namespace flux
+
+  # Install wego from a config repository
+  wego gitops install --config-repo git@github.com:myorg/config.git
+
+  # Install wego in an air-gapped cluster, pulling the images from a private registry
+  wego gitops install --registry registry.example.com/fluxcd --image-pull-secret regcred`,
 	RunE:          installRunCmd,
 	SilenceErrors: true,
 	SilenceUsage:  true,
@@ -49,12 +94,73 @@ If a previous version is installed, then an in-place upgrade will be performed.`
 	},
 }
 
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade Wego",
+	Long: `The upgrade command compares the flux controllers, the App CRD and the installed wego components in the cluster
+with the ones of this wego binary, shows the changes, and applies them in place. Applications and their automation are
+left untouched. Wego components that aren't installed are not added, install them with 'wego gitops install --components'.
+Pass the --registry and --image-pull-secret wego was installed with to upgrade an air-gapped install.`,
+	Example: `  # Show what an upgrade of wego in the wego-system namespace would change
+  wego gitops upgrade --dry-run
+
+  # Upgrade wego in the wego-system namespace
+  wego gitops upgrade`,
+	RunE:          upgradeRunCmd,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PostRun: func(cmd *cobra.Command, args []string) {
+		version.CheckVersion(version.CheckpointParamsWithFlags(version.CheckpointParams(), cmd))
+	},
+}
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check a cluster can run Wego",
+	Long: `The check command verifies the Kubernetes version, the RBAC permissions wego needs, the CRDs and controllers
+installed in the cluster, and that the git hosts can be reached. It exits with an error when a check fails.`,
+	Example: `  # Check the cluster wego is installed on, or will be installed on, in the wego-system namespace
+  wego gitops check
+
+  # Check a self-hosted git server can be reached over https
+  wego gitops check --git-host git.example.com:443`,
+	RunE:          checkRunCmd,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PostRun: func(cmd *cobra.Command, args []string) {
+		version.CheckVersion(version.CheckpointParamsWithFlags(version.CheckpointParams(), cmd))
+	},
+}
+
+var imagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "List the images Wego installs",
+	Long: `The images command lists the images an install of wego pulls, one per line, to mirror them to the registry
+of a cluster without access to ghcr.io. Install wego from the registry with 'wego gitops install --registry'.`,
+	Example: `  # Mirror the images to a private registry
+  for image in $(wego gitops images); do
+    crane copy $image registry.example.com/fluxcd/${image##*/}
+  done`,
+	RunE:          imagesRunCmd,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PostRun: func(cmd *cobra.Command, args []string) {
+		version.CheckVersion(version.CheckpointParamsWithFlags(version.CheckpointParams(), cmd))
+	},
+}
+
 var uinstallCmd = &cobra.Command{
 	Use:   "uninstall",
 	Short: "Uninstall Wego",
-	Long:  `The uninstall command removes Wego components from the cluster.`,
+	Long: `The uninstall command removes Wego components from the cluster.
+With --purge, the applications are removed first: the objects wego generated for them, which prunes what they
+deployed, and the weave gitops deploy keys of their repositories. Add --remove-automation to also open a pull
+request removing the automation of each app from its repository.`,
 	Example: `  # Uninstall wego in the wego-system namespace
-  wego uninstall`,
+  wego uninstall
+
+  # Uninstall wego, removing the applications and their automation
+  wego gitops uninstall --purge --remove-automation`,
 	RunE:          uninstallRunCmd,
 	SilenceErrors: true,
 	SilenceUsage:  true,
@@ -67,8 +173,33 @@ func init() {
 	Cmd.PersistentFlags().StringVarP(&gitopsParams.Namespace, "namespace", "n", "wego-system", "the namespace scope for this operation")
 	Cmd.PersistentFlags().BoolVar(&gitopsParams.DryRun, "dry-run", false, "outputs all the manifests that would be installed")
 
+	installCmd.Flags().StringVar(&gitopsParams.FluxNamespace, "flux-namespace", kube.FluxNamespace, "the namespace of an existing flux installation to install wego alongside")
+	installCmd.Flags().StringVar(&gitopsParams.ConfigRepo, "config-repo", "", "URL of a repository to commit the install to and sync the cluster from")
+	installCmd.Flags().StringVar(&gitopsParams.Branch, "branch", "main", "Branch of the config repository")
+	installCmd.Flags().StringVar(&gitopsParams.GitAuth, "git-auth", "", "Git authentication method [ssh, https]; https uses GITHUB_TOKEN and is the default for https:// urls")
+	installCmd.Flags().StringVar(&gitopsParams.PrivateKey, "private-key", "", "Private key to push to the config repository over ssh")
+	commitFlags.AddFlags(installCmd, &gitopsParams.CommitAuthorName, &gitopsParams.CommitAuthorEmail, "the commit of the install to the config repository")
+	installCmd.Flags().StringVar(&gitopsParams.DecryptionSecret, "decryption-secret", app.DefaultDecryptionSecret, "Secret of the namespace holding the sops keys the config repository sync decrypts the secrets of the apps with")
+	installCmd.Flags().StringVar(&gitopsParams.Registry, "registry", "", "Registry to pull the flux and wego images from, mirroring ghcr.io/fluxcd and ghcr.io/weaveworks")
+	installCmd.Flags().StringVar(&gitopsParams.ImagePullSecret, "image-pull-secret", "", "Secret of the namespace holding the credentials of the registry")
+	installCmd.Flags().StringSliceVar(&gitopsParams.Components, "components", []string{}, "Wego components to install, of "+strings.Join(gitops.Components, ", "))
+
+	upgradeCmd.Flags().StringVar(&gitopsParams.Registry, "registry", "", "Registry to pull the flux and wego images from, mirroring ghcr.io/fluxcd and ghcr.io/weaveworks")
+	upgradeCmd.Flags().StringVar(&gitopsParams.ImagePullSecret, "image-pull-secret", "", "Secret of the namespace holding the credentials of the registry")
+
+	checkCmd.Flags().StringVar(&gitopsParams.FluxNamespace, "flux-namespace", kube.FluxNamespace, "the namespace of an existing flux installation wego was installed alongside")
+	checkCmd.Flags().StringSliceVar(&gitopsParams.GitHosts, "git-host", []string{"github.com"}, "Git hosts to check, as host or host:port; ssh and https are checked when no port is given")
+
+	uinstallCmd.Flags().BoolVar(&gitopsParams.Purge, "purge", false, "Remove the applications and their deploy keys before uninstalling")
+	uinstallCmd.Flags().BoolVar(&gitopsParams.RemoveAutomation, "remove-automation", false, "With --purge, open a pull request removing the automation of each application")
+	uinstallCmd.Flags().StringVar(&gitopsParams.GitAuth, "git-auth", "", "Git authentication method the automation of the applications is read with [ssh, https]; https uses GITHUB_TOKEN and is the default")
+	uinstallCmd.Flags().StringVar(&gitopsParams.PrivateKey, "private-key", "", "Private key to read the automation of the applications over ssh")
+
 	Cmd.AddCommand(installCmd)
+	Cmd.AddCommand(checkCmd)
 	Cmd.AddCommand(uinstallCmd)
+	Cmd.AddCommand(upgradeCmd)
+	Cmd.AddCommand(imagesCmd)
 }
 
 func installRunCmd(cmd *cobra.Command, args []string) error {
@@ -79,10 +210,61 @@ func installRunCmd(cmd *cobra.Command, args []string) error {
 
 	gitopsService := gitops.New(logger.New(os.Stdout), fluxClient, kubeClient)
 
-	manifests, err := gitopsService.Install(gitops.InstallParams{
-		Namespace: gitopsParams.Namespace,
-		DryRun:    gitopsParams.DryRun,
-	})
+	installParams := gitops.InstallParams{
+		Namespace:       gitopsParams.Namespace,
+		FluxNamespace:   gitopsParams.FluxNamespace,
+		Branch:          gitopsParams.Branch,
+		Registry:        gitopsParams.Registry,
+		ImagePullSecret: gitopsParams.ImagePullSecret,
+		Components:      gitopsParams.Components,
+		DryRun:          gitopsParams.DryRun,
+	}
+
+	if gitopsParams.ConfigRepo != "" {
+		providerToken, found := os.LookupEnv("GITHUB_TOKEN")
+		if !found {
+			return fmt.Errorf("GITHUB_TOKEN not set in environment")
+		}
+
+		gitAuth, err := app.ResolveGitAuth(gitopsParams.GitAuth, gitopsParams.ConfigRepo)
+		if err != nil {
+			return err
+		}
+
+		installParams.ConfigRepo = app.NormalizeRepoUrl(gitopsParams.ConfigRepo, gitAuth)
+		installParams.GitProviderToken = providerToken
+		installParams.DecryptionSecret = gitopsParams.DecryptionSecret
+		installParams.CommitAuthorName = gitopsParams.CommitAuthorName
+		installParams.CommitAuthorEmail = gitopsParams.CommitAuthorEmail
+
+		authMethod, err := app.RepoAuthMethod(installParams.ConfigRepo, string(gitAuth), gitopsParams.PrivateKey, providerToken)
+		if err != nil {
+			return err
+		}
+
+		repo, err := gitproviders.ParseRepoURL(installParams.ConfigRepo)
+		if err != nil {
+			return err
+		}
+
+		gitProvider, err := gitproviders.New(gitproviders.Config{
+			Provider: repo.Provider,
+			Hostname: repo.Hostname,
+			Token:    providerToken,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed initializing git provider")
+		}
+
+		gitClient, err := commitFlags.New(authMethod)
+		if err != nil {
+			return err
+		}
+
+		gitopsService.WithGit(gitClient, gitProvider)
+	}
+
+	manifests, err := gitopsService.Install(installParams)
 	if err != nil {
 		return err
 	}
@@ -94,7 +276,7 @@ func installRunCmd(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func uninstallRunCmd(cmd *cobra.Command, args []string) error {
+func upgradeRunCmd(cmd *cobra.Command, args []string) error {
 	cliRunner := &runner.CLIRunner{}
 	osysClient := osys.New()
 	fluxClient := flux.New(osysClient, cliRunner)
@@ -102,13 +284,140 @@ func uninstallRunCmd(cmd *cobra.Command, args []string) error {
 
 	gitopsService := gitops.New(logger.New(os.Stdout), fluxClient, kubeClient)
 
-	err := gitopsService.Uninstall(gitops.UinstallParams{
+	_, manifests, err := gitopsService.Upgrade(gitops.UpgradeParams{
+		Namespace:       gitopsParams.Namespace,
+		Registry:        gitopsParams.Registry,
+		ImagePullSecret: gitopsParams.ImagePullSecret,
+		DryRun:          gitopsParams.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(manifests) > 0 {
+		fmt.Println(string(manifests))
+	}
+
+	return nil
+}
+
+func imagesRunCmd(cmd *cobra.Command, args []string) error {
+	cliRunner := &runner.CLIRunner{}
+	osysClient := osys.New()
+	fluxClient := flux.New(osysClient, cliRunner)
+	kubeClient := kube.New(cliRunner)
+
+	gitopsService := gitops.New(logger.New(os.Stdout), fluxClient, kubeClient)
+
+	images, err := gitopsService.Images(gitops.ImagesParams{
 		Namespace: gitopsParams.Namespace,
-		DryRun:    gitopsParams.DryRun,
 	})
 	if err != nil {
 		return err
 	}
 
+	for _, image := range images {
+		fmt.Println(image)
+	}
+
+	return nil
+}
+
+func uninstallRunCmd(cmd *cobra.Command, args []string) error {
+	cliRunner := &runner.CLIRunner{}
+	osysClient := osys.New()
+	fluxClient := flux.New(osysClient, cliRunner)
+	kubeClient := kube.New(cliRunner)
+
+	logger := logger.New(os.Stdout)
+
+	gitopsService := gitops.New(logger, fluxClient, kubeClient)
+
+	uninstallParams := gitops.UinstallParams{
+		Namespace:        gitopsParams.Namespace,
+		Purge:            gitopsParams.Purge,
+		RemoveAutomation: gitopsParams.RemoveAutomation,
+		DryRun:           gitopsParams.DryRun,
+	}
+
+	if gitopsParams.RemoveAutomation && !gitopsParams.Purge {
+		return fmt.Errorf("--remove-automation can only be used with --purge")
+	}
+
+	if gitopsParams.Purge {
+		providerToken, found := os.LookupEnv("GITHUB_TOKEN")
+		if !found {
+			return fmt.Errorf("GITHUB_TOKEN not set in environment")
+		}
+
+		uninstallParams.GitProviderToken = providerToken
+
+		// The automation of the apps may be in several repositories, it is read with the token unless another auth
+		// is requested, and their urls are cloned in its format
+		gitAuth := app.GitAuthHTTPS
+		if gitopsParams.GitAuth != "" {
+			resolved, err := app.ResolveGitAuth(gitopsParams.GitAuth, "")
+			if err != nil {
+				return err
+			}
+
+			gitAuth = resolved
+		}
+
+		uninstallParams.GitAuth = gitAuth
+
+		var authMethod transport.AuthMethod
+		if gitopsParams.RemoveAutomation {
+			method, err := app.RepoAuthMethod("", string(gitAuth), gitopsParams.PrivateKey, providerToken)
+			if err != nil {
+				return err
+			}
+
+			authMethod = method
+		}
+
+		// The automation is removed in pull requests created through the git provider, the client only reads it
+		gitClient, err := commitFlags.New(authMethod)
+		if err != nil {
+			return err
+		}
+
+		appService := app.New(logger, gitClient, fluxClient, kubeClient, osysClient)
+		gitopsService.WithApps(appService)
+	}
+
+	err := gitopsService.Uninstall(uninstallParams)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func checkRunCmd(cmd *cobra.Command, args []string) error {
+	cliRunner := &runner.CLIRunner{}
+	osysClient := osys.New()
+	fluxClient := flux.New(osysClient, cliRunner)
+	kubeClient := kube.New(cliRunner)
+
+	gitopsService := gitops.New(logger.New(os.Stdout), fluxClient, kubeClient)
+
+	results := gitopsService.Check(gitops.CheckParams{
+		Namespace:     gitopsParams.Namespace,
+		FluxNamespace: gitopsParams.FluxNamespace,
+		GitHosts:      gitopsParams.GitHosts,
+	})
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == gitops.CheckFail {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+
 	return nil
 }
@@ -9,4 +9,7 @@ import (
 func TestPostRunDefined(t *testing.T) {
 	assert.NotNil(t, installCmd.PostRun, "PostRun should be defined for install")
 	assert.NotNil(t, uinstallCmd.PostRun, "PostRun should be defined for uninstall")
+	assert.NotNil(t, upgradeCmd.PostRun, "PostRun should be defined for upgrade")
+	assert.NotNil(t, checkCmd.PostRun, "PostRun should be defined for check")
+	assert.NotNil(t, imagesCmd.PostRun, "PostRun should be defined for images")
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/weaveworks/weave-gitops/cmd/wego/app"
 	"github.com/weaveworks/weave-gitops/cmd/wego/flux"
 	"github.com/weaveworks/weave-gitops/cmd/wego/gitops"
+	"github.com/weaveworks/weave-gitops/cmd/wego/ui"
 	"github.com/weaveworks/weave-gitops/cmd/wego/version"
 	fluxBin "github.com/weaveworks/weave-gitops/pkg/flux"
 	"github.com/weaveworks/weave-gitops/pkg/osys"
@@ -57,6 +58,9 @@ var rootCmd = &cobra.Command{
   # Install wego in the wego-system namespace
   wego gitops install
 
+  # Serve the UI installed in the cluster on http://localhost:9001
+  wego ui
+
   # Get the version of wego along with commit, branch, and flux version
   wego version
 `,
@@ -85,6 +89,7 @@ func main() {
 	rootCmd.AddCommand(gitops.Cmd)
 	rootCmd.AddCommand(version.Cmd)
 	rootCmd.AddCommand(flux.Cmd)
+	rootCmd.AddCommand(ui.Cmd)
 
 	rootCmd.AddCommand(app.ApplicationCmd)
 
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/weaveworks/weave-gitops/cmd/wego/version"
+	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/osys"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+	"github.com/weaveworks/weave-gitops/pkg/services/gitops"
+)
+
+var params gitops.UIParams
+
+var Cmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Serve the Weave GitOps UI locally",
+	Long: `The ui command forwards a local port to the UI installed in the cluster by 'wego gitops install', until it
+is interrupted.`,
+	Example: `  # Serve the UI of the wego-system namespace on http://localhost:9001
+  wego ui
+
+  # Serve the UI on another local port
+  wego ui --port 8080`,
+	RunE:          runCmd,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PostRun: func(cmd *cobra.Command, args []string) {
+		version.CheckVersion(version.CheckpointParamsWithFlags(version.CheckpointParams(), cmd))
+	},
+}
+
+func init() {
+	Cmd.Flags().IntVar(&params.Port, "port", 9001, "Local port to serve the UI on")
+}
+
+func runCmd(cmd *cobra.Command, args []string) error {
+	params.Namespace, _ = cmd.Flags().GetString("namespace")
+
+	cliRunner := &runner.CLIRunner{}
+	osysClient := osys.New()
+	fluxClient := flux.New(osysClient, cliRunner)
+	kubeClient := kube.New(cliRunner)
+
+	gitopsService := gitops.New(logger.New(os.Stdout), fluxClient, kubeClient)
+
+	return gitopsService.UI(params)
+}
@@ -0,0 +1,11 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostRunDefined(t *testing.T) {
+	assert.NotNil(t, Cmd.PostRun, "PostRun should be defined")
+}
@@ -0,0 +1,176 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// CommitStatusTokenKey is the key of the git provider token in the secret referenced by an app's commit status
+const CommitStatusTokenKey = "token"
+
+// ApplicationReconciler mirrors the status of each app's kustomization into the app, and posts the
+// outcome of the applied revisions to the git provider for apps reporting their commit status with wego
+type ApplicationReconciler struct {
+	client.Client
+	Scheme             *runtime.Scheme
+	GitProviderFactory func(config gitproviders.Config) (gitproviders.GitProvider, error)
+}
+
+// +kubebuilder:rbac:groups=wego.weave.works,resources=apps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=wego.weave.works,resources=apps/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kustomize.toolkit.fluxcd.io,resources=kustomizations,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	app := &wego.Application{}
+	if err := r.Get(ctx, req.NamespacedName, app); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	kustomization := &kustomizev1.Kustomization{}
+	if err := r.Get(ctx, types.NamespacedName{Name: app.Name, Namespace: app.GetFluxNamespace()}, kustomization); err != nil {
+		// Helm releases, or a kustomization not applied yet
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	app.Status.LastAppliedRevision = kustomization.Status.LastAppliedRevision
+	app.Status.LastAttemptedRevision = kustomization.Status.LastAttemptedRevision
+	app.Status.Conditions = kustomization.Status.Conditions
+
+	if app.Spec.CommitStatus != nil && app.Spec.CommitStatus.Reporter == wego.CommitStatusReporterWego {
+		sha, status := commitStatus(app)
+
+		reported := fmt.Sprintf("%s:%s", sha, status.State)
+		if sha != "" && reported != app.Status.ReportedCommitStatus {
+			if err := r.postCommitStatus(ctx, app, sha, status); err != nil {
+				log.Error(err, "unable to post commit status", "sha", sha)
+				return ctrl.Result{}, err
+			}
+
+			app.Status.ReportedCommitStatus = reported
+		}
+	}
+
+	if err := r.Status().Update(ctx, app); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// commitStatus returns the commit and the status of the revision the app's kustomization last applied or tried to apply
+func commitStatus(app *wego.Application) (string, gitproviders.CommitStatus) {
+	status := gitproviders.CommitStatus{
+		State:   gitproviders.CommitStatusPending,
+		Context: fmt.Sprintf("wego/%s", app.Name),
+	}
+
+	revision := app.Status.LastAttemptedRevision
+
+	ready := apimeta.FindStatusCondition(app.Status.Conditions, meta.ReadyCondition)
+
+	switch {
+	case ready == nil:
+		status.Description = "Waiting for the revision to be applied"
+	case ready.Status == metav1.ConditionTrue:
+		status.State = gitproviders.CommitStatusSuccess
+		status.Description = ready.Message
+		revision = app.Status.LastAppliedRevision
+	case ready.Status == metav1.ConditionFalse:
+		status.State = gitproviders.CommitStatusFailure
+		status.Description = ready.Message
+	default:
+		status.Description = ready.Message
+	}
+
+	// Revisions are <branch>/<sha>
+	return revision[strings.LastIndex(revision, "/")+1:], status
+}
+
+func (r *ApplicationReconciler) postCommitStatus(ctx context.Context, app *wego.Application, sha string, status gitproviders.CommitStatus) error {
+	repo, err := gitproviders.ParseRepoURL(app.Spec.URL)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: app.Spec.CommitStatus.SecretRef, Namespace: app.GetFluxNamespace()}, secret); err != nil {
+		return fmt.Errorf("could not get the git provider token: %w", err)
+	}
+
+	token, ok := secret.Data[CommitStatusTokenKey]
+	if !ok {
+		return fmt.Errorf("secret %s has no %s key", app.Spec.CommitStatus.SecretRef, CommitStatusTokenKey)
+	}
+
+	provider, err := r.GitProviderFactory(gitproviders.Config{
+		Provider: repo.Provider,
+		Hostname: repo.Hostname,
+		Token:    strings.TrimSpace(string(token)),
+	})
+	if err != nil {
+		return err
+	}
+
+	return provider.CreateCommitStatus(repo.Owner, repo.Name, sha, status)
+}
+
+func (r *ApplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&wego.Application{}).
+		Watches(&source.Kind{Type: &kustomizev1.Kustomization{}}, handler.EnqueueRequestsFromMapFunc(r.kustomizationApps)).
+		Complete(r)
+}
+
+// kustomizationApps maps a kustomization to the apps it belongs to, the apps of the same name
+// having their flux objects in the kustomization's namespace
+func (r *ApplicationReconciler) kustomizationApps(obj client.Object) []ctrl.Request {
+	apps := &wego.ApplicationList{}
+	if err := r.List(context.Background(), apps); err != nil {
+		ctrl.Log.Error(err, "unable to list apps", "kustomization", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	var requests []ctrl.Request
+
+	for _, app := range apps.Items {
+		if app.Name == obj.GetName() && app.GetFluxNamespace() == obj.GetNamespace() {
+			requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{Name: app.Name, Namespace: app.Namespace}})
+		}
+	}
+
+	return requests
+}
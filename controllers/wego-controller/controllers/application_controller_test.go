@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders/gitprovidersfakes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ApplicationReconciler", func() {
+	var (
+		app           *wego.Application
+		kustomization *kustomizev1.Kustomization
+		gitProvider   *gitprovidersfakes.FakeGitProvider
+		config        gitproviders.Config
+		reconciler    *ApplicationReconciler
+		name          = types.NamespacedName{Name: "podinfo", Namespace: "wego-system"}
+	)
+
+	var _ = BeforeEach(func() {
+		app = &wego.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace},
+			Spec: wego.ApplicationSpec{
+				URL:            "ssh://git@github.com/foo/podinfo.git",
+				Branch:         "main",
+				SourceType:     wego.SourceTypeGit,
+				DeploymentType: wego.DeploymentTypeKustomize,
+				CommitStatus: &wego.CommitStatus{
+					Reporter:  wego.CommitStatusReporterWego,
+					SecretRef: "github-token",
+				},
+			},
+		}
+
+		kustomization = &kustomizev1.Kustomization{
+			ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace},
+			Status: kustomizev1.KustomizationStatus{
+				LastAppliedRevision:   "main/abc123",
+				LastAttemptedRevision: "main/abc123",
+				Conditions: []metav1.Condition{{
+					Type:    meta.ReadyCondition,
+					Status:  metav1.ConditionTrue,
+					Reason:  meta.ReconciliationSucceededReason,
+					Message: "Applied revision: main/abc123",
+				}},
+			},
+		}
+
+		gitProvider = &gitprovidersfakes.FakeGitProvider{}
+	})
+
+	reconcile := func() (*wego.Application, error) {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(wego.AddToScheme(scheme)).To(Succeed())
+		Expect(kustomizev1.AddToScheme(scheme)).To(Succeed())
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "github-token", Namespace: app.GetFluxNamespace()},
+			Data:       map[string][]byte{CommitStatusTokenKey: []byte("secret-token\n")},
+		}
+
+		reconciler = &ApplicationReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(app, kustomization, secret).Build(),
+			Scheme: scheme,
+			GitProviderFactory: func(c gitproviders.Config) (gitproviders.GitProvider, error) {
+				config = c
+				return gitProvider, nil
+			},
+		}
+
+		if _, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: name}); err != nil {
+			return nil, err
+		}
+
+		result := &wego.Application{}
+		Expect(reconciler.Get(context.Background(), name, result)).To(Succeed())
+
+		return result, nil
+	}
+
+	It("mirrors the kustomization status and posts a success for the applied commit", func() {
+		result, err := reconcile()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(result.Status.LastAppliedRevision).To(Equal("main/abc123"))
+		Expect(result.Status.Conditions).To(HaveLen(1))
+		Expect(result.Status.ReportedCommitStatus).To(Equal("abc123:success"))
+
+		Expect(config).To(Equal(gitproviders.Config{Provider: gitproviders.GitProviderGitHub, Token: "secret-token"}))
+
+		Expect(gitProvider.CreateCommitStatusCallCount()).To(Equal(1))
+		owner, repo, sha, status := gitProvider.CreateCommitStatusArgsForCall(0)
+		Expect(owner).To(Equal("foo"))
+		Expect(repo).To(Equal("podinfo"))
+		Expect(sha).To(Equal("abc123"))
+		Expect(status).To(Equal(gitproviders.CommitStatus{
+			State:       gitproviders.CommitStatusSuccess,
+			Context:     "wego/podinfo",
+			Description: "Applied revision: main/abc123",
+		}))
+	})
+
+	It("posts a failure for the attempted commit", func() {
+		kustomization.Status.LastAttemptedRevision = "main/def456"
+		kustomization.Status.Conditions[0].Status = metav1.ConditionFalse
+		kustomization.Status.Conditions[0].Message = "apply failed"
+
+		result, err := reconcile()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.Status.ReportedCommitStatus).To(Equal("def456:failure"))
+
+		_, _, sha, status := gitProvider.CreateCommitStatusArgsForCall(0)
+		Expect(sha).To(Equal("def456"))
+		Expect(status.State).To(Equal(gitproviders.CommitStatusFailure))
+		Expect(status.Description).To(Equal("apply failed"))
+	})
+
+	It("does not post a status already reported", func() {
+		app.Status.ReportedCommitStatus = "abc123:success"
+
+		_, err := reconcile()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(gitProvider.CreateCommitStatusCallCount()).To(Equal(0))
+	})
+
+	It("leaves the statuses to flux for apps reporting with flux", func() {
+		app.Spec.CommitStatus.Reporter = wego.CommitStatusReporterFlux
+
+		result, err := reconcile()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.Status.LastAppliedRevision).To(Equal("main/abc123"))
+		Expect(gitProvider.CreateCommitStatusCallCount()).To(Equal(0))
+	})
+
+	It("reads the kustomization and the token secret in the app's flux namespace", func() {
+		app.Spec.FluxNamespace = "flux-system"
+		kustomization.Namespace = "flux-system"
+
+		result, err := reconcile()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(result.Status.LastAppliedRevision).To(Equal("main/abc123"))
+		Expect(result.Status.ReportedCommitStatus).To(Equal("abc123:success"))
+	})
+
+	It("maps a kustomization to the app having its flux objects in the kustomization's namespace", func() {
+		app.Spec.FluxNamespace = "flux-system"
+
+		_, err := reconcile()
+		Expect(err).ShouldNot(HaveOccurred())
+
+		other := &kustomizev1.Kustomization{ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: "flux-system"}}
+		Expect(reconciler.kustomizationApps(other)).To(Equal([]ctrl.Request{{NamespacedName: name}}))
+		Expect(reconciler.kustomizationApps(kustomization)).To(BeEmpty())
+	})
+})
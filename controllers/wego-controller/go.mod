@@ -1,9 +1,18 @@
-module github.com/weaveworks/weave-gitops
+module github.com/weaveworks/weave-gitops/controllers/wego-controller
 
-go 1.13
+go 1.16
 
 require (
-	k8s.io/apimachinery v0.17.2
-	k8s.io/client-go v0.17.2
-	sigs.k8s.io/controller-runtime v0.5.0
+	github.com/fluxcd/kustomize-controller/api v0.13.0
+	github.com/fluxcd/pkg/apis/meta v0.10.0
+	github.com/onsi/ginkgo v1.16.4
+	github.com/onsi/gomega v1.13.0
+	github.com/weaveworks/weave-gitops v0.0.0
+	k8s.io/api v0.21.2
+	k8s.io/apimachinery v0.21.2
+	k8s.io/client-go v0.21.2
+	sigs.k8s.io/controller-runtime v0.9.1
 )
+
+// The controller is built from the packages of the repository it is in
+replace github.com/weaveworks/weave-gitops => ../..
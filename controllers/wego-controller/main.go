@@ -20,6 +20,10 @@ import (
 	"flag"
 	"os"
 
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/controllers/wego-controller/controllers"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -35,6 +39,8 @@ var (
 
 func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
+	_ = wego.AddToScheme(scheme)
+	_ = kustomizev1.AddToScheme(scheme)
 
 	// +kubebuilder:scaffold:scheme
 }
@@ -62,6 +68,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&controllers.ApplicationReconciler{
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		GitProviderFactory: gitproviders.New,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Application")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
@@ -6,3 +6,15 @@ import (
 
 //go:embed crds/wego.weave.works_apps.yaml
 var AppCRD []byte
+
+// The manifests of the wego components are templates of the namespace, image and image pull secret
+// they are installed with
+
+//go:embed wego/wego-controller.yaml
+var WegoController []byte
+
+//go:embed wego/wego-server.yaml
+var WegoServer []byte
+
+//go:embed wego/wego-ui.yaml
+var WegoUI []byte
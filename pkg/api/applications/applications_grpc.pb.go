@@ -24,6 +24,12 @@ type ApplicationsClient interface {
 	//
 	// GetApplication returns a given application
 	GetApplication(ctx context.Context, in *GetApplicationRequest, opts ...grpc.CallOption) (*GetApplicationResponse, error)
+	//
+	// GetApplicationResources returns the Kubernetes objects deployed by an application, with their health
+	GetApplicationResources(ctx context.Context, in *GetApplicationResourcesRequest, opts ...grpc.CallOption) (*GetApplicationResourcesResponse, error)
+	//
+	// GetApplicationLogs returns the Kubernetes events and flux controller logs about the objects of an application
+	GetApplicationLogs(ctx context.Context, in *GetApplicationLogsRequest, opts ...grpc.CallOption) (*GetApplicationLogsResponse, error)
 }
 
 type applicationsClient struct {
@@ -52,6 +58,24 @@ func (c *applicationsClient) GetApplication(ctx context.Context, in *GetApplicat
 	return out, nil
 }
 
+func (c *applicationsClient) GetApplicationResources(ctx context.Context, in *GetApplicationResourcesRequest, opts ...grpc.CallOption) (*GetApplicationResourcesResponse, error) {
+	out := new(GetApplicationResourcesResponse)
+	err := c.cc.Invoke(ctx, "/wego_server.v1.Applications/GetApplicationResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *applicationsClient) GetApplicationLogs(ctx context.Context, in *GetApplicationLogsRequest, opts ...grpc.CallOption) (*GetApplicationLogsResponse, error) {
+	out := new(GetApplicationLogsResponse)
+	err := c.cc.Invoke(ctx, "/wego_server.v1.Applications/GetApplicationLogs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ApplicationsServer is the server API for Applications service.
 // All implementations must embed UnimplementedApplicationsServer
 // for forward compatibility
@@ -62,6 +86,12 @@ type ApplicationsServer interface {
 	//
 	// GetApplication returns a given application
 	GetApplication(context.Context, *GetApplicationRequest) (*GetApplicationResponse, error)
+	//
+	// GetApplicationResources returns the Kubernetes objects deployed by an application, with their health
+	GetApplicationResources(context.Context, *GetApplicationResourcesRequest) (*GetApplicationResourcesResponse, error)
+	//
+	// GetApplicationLogs returns the Kubernetes events and flux controller logs about the objects of an application
+	GetApplicationLogs(context.Context, *GetApplicationLogsRequest) (*GetApplicationLogsResponse, error)
 	mustEmbedUnimplementedApplicationsServer()
 }
 
@@ -75,6 +105,12 @@ func (UnimplementedApplicationsServer) ListApplications(context.Context, *ListAp
 func (UnimplementedApplicationsServer) GetApplication(context.Context, *GetApplicationRequest) (*GetApplicationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetApplication not implemented")
 }
+func (UnimplementedApplicationsServer) GetApplicationResources(context.Context, *GetApplicationResourcesRequest) (*GetApplicationResourcesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetApplicationResources not implemented")
+}
+func (UnimplementedApplicationsServer) GetApplicationLogs(context.Context, *GetApplicationLogsRequest) (*GetApplicationLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetApplicationLogs not implemented")
+}
 func (UnimplementedApplicationsServer) mustEmbedUnimplementedApplicationsServer() {}
 
 // UnsafeApplicationsServer may be embedded to opt out of forward compatibility for this service.
@@ -124,6 +160,42 @@ func _Applications_GetApplication_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Applications_GetApplicationResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetApplicationResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApplicationsServer).GetApplicationResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wego_server.v1.Applications/GetApplicationResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApplicationsServer).GetApplicationResources(ctx, req.(*GetApplicationResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Applications_GetApplicationLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetApplicationLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApplicationsServer).GetApplicationLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/wego_server.v1.Applications/GetApplicationLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApplicationsServer).GetApplicationLogs(ctx, req.(*GetApplicationLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Applications_ServiceDesc is the grpc.ServiceDesc for Applications service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -139,6 +211,14 @@ var Applications_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetApplication",
 			Handler:    _Applications_GetApplication_Handler,
 		},
+		{
+			MethodName: "GetApplicationResources",
+			Handler:    _Applications_GetApplicationResources_Handler,
+		},
+		{
+			MethodName: "GetApplicationLogs",
+			Handler:    _Applications_GetApplicationLogs_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/applications/applications.proto",
@@ -0,0 +1,88 @@
+package encryption
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+const (
+	sopsPath     = "sops"
+	kubesealPath = "kubeseal"
+
+	// Only the secret data is encrypted, so the manifest stays readable and can be applied by name
+	sopsEncryptedRegex = "^(data|stringData)$"
+)
+
+// Encryptor encrypts Secret manifests so that they can be stored in git
+//counterfeiter:generate . Encryptor
+type Encryptor interface {
+	// EncryptSops encrypts the data of a Secret manifest with SOPS, for the given age recipients and PGP fingerprints
+	EncryptSops(manifest []byte, ageRecipients []string, pgpFingerprints []string) ([]byte, error)
+	// Seal turns a Secret manifest into a SealedSecret, using the certificate of the sealed secrets controller
+	Seal(manifest []byte, certFile string) ([]byte, error)
+}
+
+type CLIEncryptor struct {
+	runner runner.Runner
+}
+
+func New(cliRunner runner.Runner) *CLIEncryptor {
+	return &CLIEncryptor{
+		runner: cliRunner,
+	}
+}
+
+var _ Encryptor = &CLIEncryptor{}
+
+func (e *CLIEncryptor) EncryptSops(manifest []byte, ageRecipients []string, pgpFingerprints []string) ([]byte, error) {
+	if len(ageRecipients) == 0 && len(pgpFingerprints) == 0 {
+		return nil, fmt.Errorf("at least one age recipient or PGP fingerprint is required to encrypt with sops")
+	}
+
+	args := []string{
+		"--encrypt",
+		"--input-type", "yaml",
+		"--output-type", "yaml",
+		"--encrypted-regex", sopsEncryptedRegex,
+	}
+
+	if len(ageRecipients) > 0 {
+		args = append(args, "--age", strings.Join(ageRecipients, ","))
+	}
+
+	if len(pgpFingerprints) > 0 {
+		args = append(args, "--pgp", strings.Join(pgpFingerprints, ","))
+	}
+
+	args = append(args, "/dev/stdin")
+
+	// Only stdout is the encrypted manifest, warnings printed to stderr must not be committed with it
+	out, stderr, err := e.runner.RunWithStdinSplitOutput(sopsPath, args, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret with sops: %s: %w", string(stderr), err)
+	}
+
+	return out, nil
+}
+
+func (e *CLIEncryptor) Seal(manifest []byte, certFile string) ([]byte, error) {
+	if certFile == "" {
+		return nil, fmt.Errorf("a certificate is required to seal secrets")
+	}
+
+	args := []string{
+		"--format", "yaml",
+		"--cert", certFile,
+	}
+
+	out, stderr, err := e.runner.RunWithStdinSplitOutput(kubesealPath, args, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal secret with kubeseal: %s: %w", string(stderr), err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,80 @@
+package encryption_test
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/encryption"
+	"github.com/weaveworks/weave-gitops/pkg/runner/runnerfakes"
+)
+
+var (
+	runner    *runnerfakes.FakeRunner
+	encryptor *encryption.CLIEncryptor
+)
+
+var _ = BeforeEach(func() {
+	runner = &runnerfakes.FakeRunner{}
+
+	encryptor = encryption.New(runner)
+})
+
+var _ = Describe("EncryptSops", func() {
+	It("encrypts the secret data for age recipients and pgp fingerprints", func() {
+		runner.RunWithStdinSplitOutputStub = func(s1 string, s2 []string, b []byte) ([]byte, []byte, error) {
+			return []byte("encrypted"), []byte("[PGP] WARN[0000] Deprecation Warning\n"), nil
+		}
+
+		out, err := encryptor.EncryptSops([]byte("secret"), []string{"age1abc", "age1def"}, []string{"FINGERPRINT"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("encrypted")))
+
+		Expect(runner.RunWithStdinSplitOutputCallCount()).To(Equal(1))
+
+		cmd, args, input := runner.RunWithStdinSplitOutputArgsForCall(0)
+		Expect(cmd).To(Equal("sops"))
+		Expect(strings.Join(args, " ")).To(Equal("--encrypt --input-type yaml --output-type yaml --encrypted-regex ^(data|stringData)$ --age age1abc,age1def --pgp FINGERPRINT /dev/stdin"))
+		Expect(input).To(Equal([]byte("secret")))
+	})
+
+	It("requires a recipient", func() {
+		_, err := encryptor.EncryptSops([]byte("secret"), nil, nil)
+		Expect(err).Should(HaveOccurred())
+
+		Expect(runner.RunWithStdinSplitOutputCallCount()).To(Equal(0))
+	})
+
+	It("returns the sops output on failure", func() {
+		runner.RunWithStdinSplitOutputStub = func(s1 string, s2 []string, b []byte) ([]byte, []byte, error) {
+			return nil, []byte("could not find key"), errors.New("exit status 1")
+		}
+
+		_, err := encryptor.EncryptSops([]byte("secret"), []string{"age1abc"}, nil)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("could not find key"))
+	})
+})
+
+var _ = Describe("Seal", func() {
+	It("seals the secret with the controller certificate", func() {
+		runner.RunWithStdinSplitOutputStub = func(s1 string, s2 []string, b []byte) ([]byte, []byte, error) {
+			return []byte("sealed"), []byte("warning"), nil
+		}
+
+		out, err := encryptor.Seal([]byte("secret"), "pub-cert.pem")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("sealed")))
+
+		cmd, args, input := runner.RunWithStdinSplitOutputArgsForCall(0)
+		Expect(cmd).To(Equal("kubeseal"))
+		Expect(strings.Join(args, " ")).To(Equal("--format yaml --cert pub-cert.pem"))
+		Expect(input).To(Equal([]byte("secret")))
+	})
+
+	It("requires a certificate", func() {
+		_, err := encryptor.Seal([]byte("secret"), "")
+		Expect(err).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,221 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package encryptionfakes
+
+import (
+	"sync"
+
+	"github.com/weaveworks/weave-gitops/pkg/encryption"
+)
+
+type FakeEncryptor struct {
+	EncryptSopsStub        func([]byte, []string, []string) ([]byte, error)
+	encryptSopsMutex       sync.RWMutex
+	encryptSopsArgsForCall []struct {
+		arg1 []byte
+		arg2 []string
+		arg3 []string
+	}
+	encryptSopsReturns struct {
+		result1 []byte
+		result2 error
+	}
+	encryptSopsReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	SealStub        func([]byte, string) ([]byte, error)
+	sealMutex       sync.RWMutex
+	sealArgsForCall []struct {
+		arg1 []byte
+		arg2 string
+	}
+	sealReturns struct {
+		result1 []byte
+		result2 error
+	}
+	sealReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeEncryptor) EncryptSops(arg1 []byte, arg2 []string, arg3 []string) ([]byte, error) {
+	var arg1Copy []byte
+	if arg1 != nil {
+		arg1Copy = make([]byte, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.encryptSopsMutex.Lock()
+	ret, specificReturn := fake.encryptSopsReturnsOnCall[len(fake.encryptSopsArgsForCall)]
+	fake.encryptSopsArgsForCall = append(fake.encryptSopsArgsForCall, struct {
+		arg1 []byte
+		arg2 []string
+		arg3 []string
+	}{arg1Copy, arg2Copy, arg3Copy})
+	stub := fake.EncryptSopsStub
+	fakeReturns := fake.encryptSopsReturns
+	fake.recordInvocation("EncryptSops", []interface{}{arg1Copy, arg2Copy, arg3Copy})
+	fake.encryptSopsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeEncryptor) EncryptSopsCallCount() int {
+	fake.encryptSopsMutex.RLock()
+	defer fake.encryptSopsMutex.RUnlock()
+	return len(fake.encryptSopsArgsForCall)
+}
+
+func (fake *FakeEncryptor) EncryptSopsCalls(stub func([]byte, []string, []string) ([]byte, error)) {
+	fake.encryptSopsMutex.Lock()
+	defer fake.encryptSopsMutex.Unlock()
+	fake.EncryptSopsStub = stub
+}
+
+func (fake *FakeEncryptor) EncryptSopsArgsForCall(i int) ([]byte, []string, []string) {
+	fake.encryptSopsMutex.RLock()
+	defer fake.encryptSopsMutex.RUnlock()
+	argsForCall := fake.encryptSopsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeEncryptor) EncryptSopsReturns(result1 []byte, result2 error) {
+	fake.encryptSopsMutex.Lock()
+	defer fake.encryptSopsMutex.Unlock()
+	fake.EncryptSopsStub = nil
+	fake.encryptSopsReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeEncryptor) EncryptSopsReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.encryptSopsMutex.Lock()
+	defer fake.encryptSopsMutex.Unlock()
+	fake.EncryptSopsStub = nil
+	if fake.encryptSopsReturnsOnCall == nil {
+		fake.encryptSopsReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.encryptSopsReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeEncryptor) Seal(arg1 []byte, arg2 string) ([]byte, error) {
+	var arg1Copy []byte
+	if arg1 != nil {
+		arg1Copy = make([]byte, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.sealMutex.Lock()
+	ret, specificReturn := fake.sealReturnsOnCall[len(fake.sealArgsForCall)]
+	fake.sealArgsForCall = append(fake.sealArgsForCall, struct {
+		arg1 []byte
+		arg2 string
+	}{arg1Copy, arg2})
+	stub := fake.SealStub
+	fakeReturns := fake.sealReturns
+	fake.recordInvocation("Seal", []interface{}{arg1Copy, arg2})
+	fake.sealMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeEncryptor) SealCallCount() int {
+	fake.sealMutex.RLock()
+	defer fake.sealMutex.RUnlock()
+	return len(fake.sealArgsForCall)
+}
+
+func (fake *FakeEncryptor) SealCalls(stub func([]byte, string) ([]byte, error)) {
+	fake.sealMutex.Lock()
+	defer fake.sealMutex.Unlock()
+	fake.SealStub = stub
+}
+
+func (fake *FakeEncryptor) SealArgsForCall(i int) ([]byte, string) {
+	fake.sealMutex.RLock()
+	defer fake.sealMutex.RUnlock()
+	argsForCall := fake.sealArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeEncryptor) SealReturns(result1 []byte, result2 error) {
+	fake.sealMutex.Lock()
+	defer fake.sealMutex.Unlock()
+	fake.SealStub = nil
+	fake.sealReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeEncryptor) SealReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.sealMutex.Lock()
+	defer fake.sealMutex.Unlock()
+	fake.SealStub = nil
+	if fake.sealReturnsOnCall == nil {
+		fake.sealReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.sealReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeEncryptor) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.encryptSopsMutex.RLock()
+	defer fake.encryptSopsMutex.RUnlock()
+	fake.sealMutex.RLock()
+	defer fake.sealMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeEncryptor) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ encryption.Encryptor = new(FakeEncryptor)
@@ -18,14 +18,22 @@ type Flux interface {
 	SetupBin()
 	GetBinPath() (string, error)
 	GetExePath() (string, error)
-	Install(namespace string, export bool) ([]byte, error)
+	Install(namespace string, registry string, imagePullSecret string, export bool) ([]byte, error)
 	Uninstall(namespace string, export bool) error
-	CreateSourceGit(name string, url string, branch string, secretRef string, namespace string) ([]byte, error)
+	CreateSourceGit(name string, url string, branch string, tag string, semver string, secretRef string, namespace string) ([]byte, error)
 	CreateSourceHelm(name string, url string, namespace string) ([]byte, error)
-	CreateKustomization(name string, source string, path string, namespace string) ([]byte, error)
-	CreateHelmReleaseGitRepository(name string, source string, path string, namespace string) ([]byte, error)
-	CreateHelmReleaseHelmRepository(name string, chart string, namespace string) ([]byte, error)
+	CreateSourceBucket(name string, bucketName string, endpoint string, provider string, region string, secretRef string, insecure bool, namespace string) ([]byte, error)
+	CreateKustomization(name string, source string, path string, targetNamespace string, serviceAccount string, decryptionSecret string, namespace string) ([]byte, error)
+	CreateHelmReleaseGitRepository(name string, source string, path string, chartVersion string, targetNamespace string, serviceAccount string, namespace string) ([]byte, error)
+	CreateHelmReleaseHelmRepository(name string, chart string, chartVersion string, targetNamespace string, serviceAccount string, namespace string) ([]byte, error)
+	CreateHelmReleaseBucket(name string, source string, path string, chartVersion string, targetNamespace string, serviceAccount string, namespace string) ([]byte, error)
 	CreateSecretGit(name string, url string, namespace string) ([]byte, error)
+	CreateAlertProvider(name string, providerType string, channel string, address string, secretRef string, namespace string) ([]byte, error)
+	CreateAlert(name string, providerRef string, eventSources []string, eventSeverity string, namespace string) ([]byte, error)
+	CreateImageRepository(name string, image string, secretRef string, namespace string) ([]byte, error)
+	CreateImagePolicy(name string, imageRef string, policy string, selection string, filterRegex string, filterExtract string, namespace string) ([]byte, error)
+	CreateImageUpdateAutomation(name string, gitRepoRef string, gitRepoPath string, branch string, authorName string, authorEmail string, namespace string) ([]byte, error)
+	ReconcileSource(sourceType string, name string, namespace string) ([]byte, error)
 	GetVersion() (string, error)
 	GetAllResourcesStatus(name string, namespace string) ([]byte, error)
 	SuspendOrResumeApp(pause wego.SuspendActionType, name, namespace, deploymentType string) ([]byte, error)
@@ -46,13 +54,23 @@ func New(osysClient osys.Osys, cliRunner runner.Runner) *FluxClient {
 
 var _ Flux = &FluxClient{}
 
-func (f *FluxClient) Install(namespace string, export bool) ([]byte, error) {
+// Install installs the flux controllers, pulling their images from a registry mirroring ghcr.io/fluxcd when one is
+// given, with an image pull secret of the namespace
+func (f *FluxClient) Install(namespace string, registry string, imagePullSecret string, export bool) ([]byte, error) {
 	args := []string{
 		"install",
 		"--namespace", namespace,
 		"--components-extra", "image-reflector-controller,image-automation-controller",
 	}
 
+	if registry != "" {
+		args = append(args, "--registry", registry)
+	}
+
+	if imagePullSecret != "" {
+		args = append(args, "--image-pull-secret", imagePullSecret)
+	}
+
 	if export {
 		args = append(args, "--export")
 
@@ -88,7 +106,7 @@ func (f *FluxClient) Uninstall(namespace string, dryRun bool) error {
 	return nil
 }
 
-func (f *FluxClient) CreateSourceGit(name string, url string, branch string, secretRef string, namespace string) ([]byte, error) {
+func (f *FluxClient) CreateSourceGit(name string, url string, branch string, tag string, semver string, secretRef string, namespace string) ([]byte, error) {
 	args := []string{
 		"create", "source", "git", name,
 		"--url", url,
@@ -99,6 +117,15 @@ func (f *FluxClient) CreateSourceGit(name string, url string, branch string, sec
 		"--export",
 	}
 
+	// flux tracks the semver range over the tag, and the tag over the branch
+	if tag != "" {
+		args = append(args, "--tag", tag)
+	}
+
+	if semver != "" {
+		args = append(args, "--tag-semver", semver)
+	}
+
 	out, err := f.runFluxCmd(args...)
 	if err != nil {
 		return out, fmt.Errorf("failed to create source git: %w", err)
@@ -124,7 +151,41 @@ func (f *FluxClient) CreateSourceHelm(name string, url string, namespace string)
 	return out, nil
 }
 
-func (f *FluxClient) CreateKustomization(name string, source string, path string, namespace string) ([]byte, error) {
+func (f *FluxClient) CreateSourceBucket(name string, bucketName string, endpoint string, provider string, region string, secretRef string, insecure bool, namespace string) ([]byte, error) {
+	args := []string{
+		"create", "source", "bucket", name,
+		"--bucket-name", bucketName,
+		"--endpoint", endpoint,
+		"--namespace", namespace,
+		"--interval", "30s",
+		"--export",
+	}
+
+	if provider != "" {
+		args = append(args, "--provider", provider)
+	}
+
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+
+	if secretRef != "" {
+		args = append(args, "--secret-ref", secretRef)
+	}
+
+	if insecure {
+		args = append(args, "--insecure")
+	}
+
+	out, err := f.runFluxCmd(args...)
+	if err != nil {
+		return out, fmt.Errorf("failed to create source bucket: %w", err)
+	}
+
+	return out, nil
+}
+
+func (f *FluxClient) CreateKustomization(name string, source string, path string, targetNamespace string, serviceAccount string, decryptionSecret string, namespace string) ([]byte, error) {
 	args := []string{
 		"create", "kustomization", name,
 		"--path", path,
@@ -136,6 +197,18 @@ func (f *FluxClient) CreateKustomization(name string, source string, path string
 		"--export",
 	}
 
+	if targetNamespace != "" {
+		args = append(args, "--target-namespace", targetNamespace)
+	}
+
+	if serviceAccount != "" {
+		args = append(args, "--service-account", serviceAccount)
+	}
+
+	if decryptionSecret != "" {
+		args = append(args, "--decryption-provider", "sops", "--decryption-secret", decryptionSecret)
+	}
+
 	out, err := f.runFluxCmd(args...)
 	if err != nil {
 		return out, fmt.Errorf("failed to create kustomization: %w", err)
@@ -144,7 +217,7 @@ func (f *FluxClient) CreateKustomization(name string, source string, path string
 	return out, nil
 }
 
-func (f *FluxClient) CreateHelmReleaseGitRepository(name string, source string, chartPath string, namespace string) ([]byte, error) {
+func (f *FluxClient) CreateHelmReleaseGitRepository(name string, source string, chartPath string, chartVersion string, targetNamespace string, serviceAccount string, namespace string) ([]byte, error) {
 	args := []string{
 		"create", "helmrelease", name,
 		"--source", "GitRepository/" + source,
@@ -154,6 +227,18 @@ func (f *FluxClient) CreateHelmReleaseGitRepository(name string, source string,
 		"--export",
 	}
 
+	if chartVersion != "" {
+		args = append(args, "--chart-version", chartVersion)
+	}
+
+	if targetNamespace != "" {
+		args = append(args, "--target-namespace", targetNamespace)
+	}
+
+	if serviceAccount != "" {
+		args = append(args, "--service-account", serviceAccount)
+	}
+
 	out, err := f.runFluxCmd(args...)
 	if err != nil {
 		return out, fmt.Errorf("failed to create helm release git repo: %w", err)
@@ -162,7 +247,7 @@ func (f *FluxClient) CreateHelmReleaseGitRepository(name string, source string,
 	return out, nil
 }
 
-func (f *FluxClient) CreateHelmReleaseHelmRepository(name string, chart string, namespace string) ([]byte, error) {
+func (f *FluxClient) CreateHelmReleaseHelmRepository(name string, chart string, chartVersion string, targetNamespace string, serviceAccount string, namespace string) ([]byte, error) {
 	args := []string{
 		"create", "helmrelease", name,
 		"--source", "HelmRepository/" + name,
@@ -172,6 +257,18 @@ func (f *FluxClient) CreateHelmReleaseHelmRepository(name string, chart string,
 		"--export",
 	}
 
+	if chartVersion != "" {
+		args = append(args, "--chart-version", chartVersion)
+	}
+
+	if targetNamespace != "" {
+		args = append(args, "--target-namespace", targetNamespace)
+	}
+
+	if serviceAccount != "" {
+		args = append(args, "--service-account", serviceAccount)
+	}
+
 	out, err := f.runFluxCmd(args...)
 	if err != nil {
 		return out, fmt.Errorf("failed to create helm release helm repo: %w", err)
@@ -180,6 +277,36 @@ func (f *FluxClient) CreateHelmReleaseHelmRepository(name string, chart string,
 	return out, nil
 }
 
+func (f *FluxClient) CreateHelmReleaseBucket(name string, source string, chartPath string, chartVersion string, targetNamespace string, serviceAccount string, namespace string) ([]byte, error) {
+	args := []string{
+		"create", "helmrelease", name,
+		"--source", "Bucket/" + source,
+		"--chart", chartPath,
+		"--namespace", namespace,
+		"--interval", "5m",
+		"--export",
+	}
+
+	if chartVersion != "" {
+		args = append(args, "--chart-version", chartVersion)
+	}
+
+	if targetNamespace != "" {
+		args = append(args, "--target-namespace", targetNamespace)
+	}
+
+	if serviceAccount != "" {
+		args = append(args, "--service-account", serviceAccount)
+	}
+
+	out, err := f.runFluxCmd(args...)
+	if err != nil {
+		return out, fmt.Errorf("failed to create helm release bucket: %w", err)
+	}
+
+	return out, nil
+}
+
 // CreatSecretGit Creates a Git secret returns the deploy key
 func (f *FluxClient) CreateSecretGit(name string, url string, namespace string) ([]byte, error) {
 	args := []string{
@@ -197,6 +324,152 @@ func (f *FluxClient) CreateSecretGit(name string, url string, namespace string)
 	return out, nil
 }
 
+// CreateAlertProvider creates a notification provider, posting to a channel of a chat service or to a webhook address
+func (f *FluxClient) CreateAlertProvider(name string, providerType string, channel string, address string, secretRef string, namespace string) ([]byte, error) {
+	args := []string{
+		"create", "alert-provider", name,
+		"--type", providerType,
+		"--namespace", namespace,
+		"--export",
+	}
+
+	if channel != "" {
+		args = append(args, "--channel", channel)
+	}
+
+	if address != "" {
+		args = append(args, "--address", address)
+	}
+
+	if secretRef != "" {
+		args = append(args, "--secret-ref", secretRef)
+	}
+
+	out, err := f.runFluxCmd(args...)
+	if err != nil {
+		return out, fmt.Errorf("failed to create alert provider: %w", err)
+	}
+
+	return out, nil
+}
+
+// CreateAlert creates an alert sending the events of the given sources, in the form Kind/name, to a provider
+func (f *FluxClient) CreateAlert(name string, providerRef string, eventSources []string, eventSeverity string, namespace string) ([]byte, error) {
+	args := []string{
+		"create", "alert", name,
+		"--provider-ref", providerRef,
+		"--event-source", strings.Join(eventSources, ","),
+		"--event-severity", eventSeverity,
+		"--namespace", namespace,
+		"--export",
+	}
+
+	out, err := f.runFluxCmd(args...)
+	if err != nil {
+		return out, fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	return out, nil
+}
+
+// CreateImageRepository creates an image repository scanning the tags of an image
+func (f *FluxClient) CreateImageRepository(name string, image string, secretRef string, namespace string) ([]byte, error) {
+	args := []string{
+		"create", "image", "repository", name,
+		"--image", image,
+		"--namespace", namespace,
+		"--export",
+	}
+
+	if secretRef != "" {
+		args = append(args, "--secret-ref", secretRef)
+	}
+
+	out, err := f.runFluxCmd(args...)
+	if err != nil {
+		return out, fmt.Errorf("failed to create image repository: %w", err)
+	}
+
+	return out, nil
+}
+
+// imagePolicySelectFlags are the flags selecting the latest tag of each image policy
+var imagePolicySelectFlags = map[string]string{
+	"semver":       "--select-semver",
+	"alphabetical": "--select-alpha",
+	"numerical":    "--select-numeric",
+}
+
+// CreateImagePolicy creates an image policy selecting the latest tag of an image repository. The selection is a
+// semver range for semver policies, or the asc or desc order for alphabetical and numerical ones.
+func (f *FluxClient) CreateImagePolicy(name string, imageRef string, policy string, selection string, filterRegex string, filterExtract string, namespace string) ([]byte, error) {
+	selectFlag, ok := imagePolicySelectFlags[policy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported image policy %q", policy)
+	}
+
+	args := []string{
+		"create", "image", "policy", name,
+		"--image-ref", imageRef,
+		selectFlag, selection,
+		"--namespace", namespace,
+		"--export",
+	}
+
+	if filterRegex != "" {
+		args = append(args, "--filter-regex", filterRegex)
+	}
+
+	if filterExtract != "" {
+		args = append(args, "--filter-extract", filterExtract)
+	}
+
+	out, err := f.runFluxCmd(args...)
+	if err != nil {
+		return out, fmt.Errorf("failed to create image policy: %w", err)
+	}
+
+	return out, nil
+}
+
+// CreateImageUpdateAutomation creates an automation writing the latest images selected by the image policies
+// to the manifests under a path of a git repository, and pushing them to the given branch
+func (f *FluxClient) CreateImageUpdateAutomation(name string, gitRepoRef string, gitRepoPath string, branch string, authorName string, authorEmail string, namespace string) ([]byte, error) {
+	args := []string{
+		"create", "image", "update", name,
+		"--git-repo-ref", gitRepoRef,
+		"--git-repo-path", gitRepoPath,
+		"--checkout-branch", branch,
+		"--push-branch", branch,
+		"--author-name", authorName,
+		"--author-email", authorEmail,
+		"--namespace", namespace,
+		"--export",
+	}
+
+	out, err := f.runFluxCmd(args...)
+	if err != nil {
+		return out, fmt.Errorf("failed to create image update automation: %w", err)
+	}
+
+	return out, nil
+}
+
+// ReconcileSource triggers a reconciliation of a source and waits for it to become ready
+func (f *FluxClient) ReconcileSource(sourceType string, name string, namespace string) ([]byte, error) {
+	args := []string{
+		"reconcile", "source", sourceType, name,
+		"--namespace", namespace,
+	}
+
+	out, err := f.runFluxCmd(args...)
+	if err != nil {
+		return out, fmt.Errorf("failed to reconcile source %s %s: %w", sourceType, name, err)
+	}
+
+	return out, nil
+}
+
 func (f *FluxClient) GetAllResourcesStatus(name string, namespace string) ([]byte, error) {
 	args := []string{
 		"get", "all", "--namespace", namespace, name,
@@ -26,7 +26,7 @@ var _ = BeforeEach(func() {
 
 var _ = Describe("Install", func() {
 	It("installs flux", func() {
-		_, err := fluxClient.Install("wego-system", false)
+		_, err := fluxClient.Install("wego-system", "", "", false)
 		Expect(err).ShouldNot(HaveOccurred())
 
 		Expect(runner.RunWithOutputStreamCallCount()).To(Equal(1))
@@ -41,7 +41,7 @@ var _ = Describe("Install", func() {
 			return []byte("out"), nil
 		}
 
-		out, err := fluxClient.Install("wego-system", true)
+		out, err := fluxClient.Install("wego-system", "", "", true)
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(out).To(Equal([]byte("out")))
 
@@ -51,6 +51,15 @@ var _ = Describe("Install", func() {
 		Expect(cmd).To(Equal(fluxPath()))
 		Expect(strings.Join(args, " ")).To(Equal("install --namespace wego-system --components-extra image-reflector-controller,image-automation-controller --export"))
 	})
+
+	It("pulls the images from a registry", func() {
+		_, err := fluxClient.Install("wego-system", "registry.example.com/fluxcd", "regcred", true)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(Equal("install --namespace wego-system --components-extra image-reflector-controller,image-automation-controller " +
+			"--registry registry.example.com/fluxcd --image-pull-secret regcred --export"))
+	})
 })
 
 var _ = Describe("Uninstall", func() {
@@ -82,7 +91,7 @@ var _ = Describe("CreateSourceGit", func() {
 		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
 			return []byte("out"), nil
 		}
-		out, err := fluxClient.CreateSourceGit("my-name", "https://github.com/foo/my-name", "main", "my-secret", "wego-system")
+		out, err := fluxClient.CreateSourceGit("my-name", "https://github.com/foo/my-name", "main", "", "", "my-secret", "wego-system")
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(out).To(Equal([]byte("out")))
 
@@ -93,6 +102,20 @@ var _ = Describe("CreateSourceGit", func() {
 
 		Expect(strings.Join(args, " ")).To(Equal("create source git my-name --url https://github.com/foo/my-name --branch main --secret-ref my-secret --namespace wego-system --interval 30s --export"))
 	})
+
+	It("creates a source git tracking a tag or semver range", func() {
+		_, err := fluxClient.CreateSourceGit("my-name", "https://github.com/foo/my-name", "main", "v1.0.0", "", "my-secret", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(HaveSuffix("--export --tag v1.0.0"))
+
+		_, err = fluxClient.CreateSourceGit("my-name", "https://github.com/foo/my-name", "main", "", ">=1.0.0 <2.0.0", "my-secret", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args = runner.RunArgsForCall(1)
+		Expect(strings.Join(args, " ")).To(HaveSuffix("--export --tag-semver >=1.0.0 <2.0.0"))
+	})
 })
 
 var _ = Describe("CreateSourceHelm", func() {
@@ -113,12 +136,41 @@ var _ = Describe("CreateSourceHelm", func() {
 	})
 })
 
+var _ = Describe("CreateSourceBucket", func() {
+	It("creates a source bucket", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		out, err := fluxClient.CreateSourceBucket("my-name", "my-bucket", "minio.minio.svc:9000", "", "", "", false, "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("out")))
+
+		Expect(runner.RunCallCount()).To(Equal(1))
+
+		cmd, args := runner.RunArgsForCall(0)
+		Expect(cmd).To(Equal(fluxPath()))
+
+		Expect(strings.Join(args, " ")).To(Equal("create source bucket my-name --bucket-name my-bucket --endpoint minio.minio.svc:9000 --namespace wego-system --interval 30s --export"))
+	})
+
+	It("sets the optional bucket flags when given", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		_, err := fluxClient.CreateSourceBucket("my-name", "my-bucket", "s3.amazonaws.com", "aws", "us-east-1", "my-secret", true, "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(HaveSuffix("--export --provider aws --region us-east-1 --secret-ref my-secret --insecure"))
+	})
+})
+
 var _ = Describe("CreateKustomization", func() {
 	It("creates a kustomization", func() {
 		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
 			return []byte("out"), nil
 		}
-		out, err := fluxClient.CreateKustomization("my-name", "my-source", "./path", "wego-system")
+		out, err := fluxClient.CreateKustomization("my-name", "my-source", "./path", "", "", "", "wego-system")
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(out).To(Equal([]byte("out")))
 
@@ -129,6 +181,39 @@ var _ = Describe("CreateKustomization", func() {
 
 		Expect(strings.Join(args, " ")).To(Equal("create kustomization my-name --path ./path --source my-source --namespace wego-system --prune true --validation client --interval 1m --export"))
 	})
+
+	It("sets the target namespace when given", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		_, err := fluxClient.CreateKustomization("my-name", "my-source", "./path", "staging", "", "", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(HaveSuffix("--export --target-namespace staging"))
+	})
+
+	It("sets the service account when given", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		_, err := fluxClient.CreateKustomization("my-name", "my-source", "./path", "staging", "team-a", "", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(HaveSuffix("--export --target-namespace staging --service-account team-a"))
+	})
+
+	It("sets up sops decryption when a decryption secret is given", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		_, err := fluxClient.CreateKustomization("my-name", "my-source", "./path", "", "", "sops-keys", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(HaveSuffix("--export --decryption-provider sops --decryption-secret sops-keys"))
+	})
 })
 
 var _ = Describe("CreateHelmReleaseGitRepository", func() {
@@ -136,7 +221,7 @@ var _ = Describe("CreateHelmReleaseGitRepository", func() {
 		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
 			return []byte("out"), nil
 		}
-		out, err := fluxClient.CreateHelmReleaseGitRepository("my-name", "my-source", "./chart-path", "wego-system")
+		out, err := fluxClient.CreateHelmReleaseGitRepository("my-name", "my-source", "./chart-path", "", "", "", "wego-system")
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(out).To(Equal([]byte("out")))
 
@@ -149,12 +234,30 @@ var _ = Describe("CreateHelmReleaseGitRepository", func() {
 	})
 })
 
+var _ = Describe("CreateHelmReleaseBucket", func() {
+	It("creates a helm release with a bucket", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		out, err := fluxClient.CreateHelmReleaseBucket("my-name", "my-source", "./chart-path", "", "", "", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("out")))
+
+		Expect(runner.RunCallCount()).To(Equal(1))
+
+		cmd, args := runner.RunArgsForCall(0)
+		Expect(cmd).To(Equal(fluxPath()))
+
+		Expect(strings.Join(args, " ")).To(Equal("create helmrelease my-name --source Bucket/my-source --chart ./chart-path --namespace wego-system --interval 5m --export"))
+	})
+})
+
 var _ = Describe("CreateHelmReleaseHelmRepository", func() {
 	It("creates a helm release with a helm repository", func() {
 		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
 			return []byte("out"), nil
 		}
-		out, err := fluxClient.CreateHelmReleaseHelmRepository("my-name", "my-chart", "wego-system")
+		out, err := fluxClient.CreateHelmReleaseHelmRepository("my-name", "my-chart", "", "", "", "wego-system")
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(out).To(Equal([]byte("out")))
 
@@ -165,6 +268,39 @@ var _ = Describe("CreateHelmReleaseHelmRepository", func() {
 
 		Expect(strings.Join(args, " ")).To(Equal("create helmrelease my-name --source HelmRepository/my-name --chart my-chart --namespace wego-system --interval 5m --export"))
 	})
+
+	It("sets the chart version when given", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		_, err := fluxClient.CreateHelmReleaseHelmRepository("my-name", "my-chart", ">=1.0.0 <2.0.0", "", "", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(args).To(ContainElements("--chart-version", ">=1.0.0 <2.0.0"))
+	})
+
+	It("sets the target namespace when given", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		_, err := fluxClient.CreateHelmReleaseHelmRepository("my-name", "my-chart", "", "staging", "", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(HaveSuffix("--export --target-namespace staging"))
+	})
+
+	It("sets the service account when given", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		_, err := fluxClient.CreateHelmReleaseHelmRepository("my-name", "my-chart", "", "", "team-a", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(HaveSuffix("--export --service-account team-a"))
+	})
 })
 
 var _ = Describe("CreateSecretGit", func() {
@@ -186,6 +322,137 @@ var _ = Describe("CreateSecretGit", func() {
 	})
 })
 
+var _ = Describe("CreateAlertProvider", func() {
+	It("creates a slack provider", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		out, err := fluxClient.CreateAlertProvider("my-app-slack", "slack", "deploys", "", "slack-webhook", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("out")))
+
+		cmd, args := runner.RunArgsForCall(0)
+		Expect(cmd).To(Equal(fluxPath()))
+
+		Expect(strings.Join(args, " ")).To(Equal("create alert-provider my-app-slack --type slack --namespace wego-system --export --channel deploys --secret-ref slack-webhook"))
+	})
+
+	It("creates a generic webhook provider", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		_, err := fluxClient.CreateAlertProvider("my-app-generic", "generic", "", "http://receiver.default:8080", "", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(Equal("create alert-provider my-app-generic --type generic --namespace wego-system --export --address http://receiver.default:8080"))
+	})
+})
+
+var _ = Describe("CreateAlert", func() {
+	It("creates an alert for the given event sources", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		out, err := fluxClient.CreateAlert("my-app-slack", "my-app-slack", []string{"GitRepository/my-app", "Kustomization/my-app"}, "info", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("out")))
+
+		cmd, args := runner.RunArgsForCall(0)
+		Expect(cmd).To(Equal(fluxPath()))
+
+		Expect(strings.Join(args, " ")).To(Equal("create alert my-app-slack --provider-ref my-app-slack --event-source GitRepository/my-app,Kustomization/my-app --event-severity info --namespace wego-system --export"))
+	})
+})
+
+var _ = Describe("CreateImageRepository", func() {
+	It("creates an image repository with pull credentials", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		out, err := fluxClient.CreateImageRepository("my-app-podinfo", "ghcr.io/stefanprodan/podinfo", "regcred", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("out")))
+
+		cmd, args := runner.RunArgsForCall(0)
+		Expect(cmd).To(Equal(fluxPath()))
+
+		Expect(strings.Join(args, " ")).To(Equal("create image repository my-app-podinfo --image ghcr.io/stefanprodan/podinfo --namespace wego-system --export --secret-ref regcred"))
+	})
+})
+
+var _ = Describe("CreateImagePolicy", func() {
+	It("creates a semver policy", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		_, err := fluxClient.CreateImagePolicy("my-app-podinfo", "my-app-podinfo", "semver", "5.0.x", "", "", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(Equal("create image policy my-app-podinfo --image-ref my-app-podinfo --select-semver 5.0.x --namespace wego-system --export"))
+	})
+
+	It("creates a numerical policy over the filtered tags", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		_, err := fluxClient.CreateImagePolicy("my-app-podinfo", "my-app-podinfo", "numerical", "asc", "^main-[a-f0-9]+-(?P<ts>[0-9]+)", "$ts", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(Equal("create image policy my-app-podinfo --image-ref my-app-podinfo --select-numeric asc --namespace wego-system --export --filter-regex ^main-[a-f0-9]+-(?P<ts>[0-9]+) --filter-extract $ts"))
+	})
+
+	It("fails for an unknown policy", func() {
+		_, err := fluxClient.CreateImagePolicy("my-app-podinfo", "my-app-podinfo", "latest", "", "", "", "wego-system")
+		Expect(err).To(MatchError(`unsupported image policy "latest"`))
+		Expect(runner.RunCallCount()).To(Equal(0))
+	})
+})
+
+var _ = Describe("CreateImageUpdateAutomation", func() {
+	It("pushes the updates to the checked out branch", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("out"), nil
+		}
+		out, err := fluxClient.CreateImageUpdateAutomation("my-app", "my-app", "./deploy", "main", "Weave Gitops", "weave-gitops@weave.works", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("out")))
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(args).To(Equal([]string{
+			"create", "image", "update", "my-app",
+			"--git-repo-ref", "my-app",
+			"--git-repo-path", "./deploy",
+			"--checkout-branch", "main",
+			"--push-branch", "main",
+			"--author-name", "Weave Gitops",
+			"--author-email", "weave-gitops@weave.works",
+			"--namespace", "wego-system",
+			"--export",
+		}))
+	})
+})
+
+var _ = Describe("ReconcileSource", func() {
+	It("reconciles a git source", func() {
+		runner.RunStub = func(s1 string, s2 ...string) ([]byte, error) {
+			return []byte("✔ fetched revision main/abc"), nil
+		}
+		out, err := fluxClient.ReconcileSource("git", "my-app", "wego-system")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("✔ fetched revision main/abc")))
+
+		Expect(runner.RunCallCount()).To(Equal(1))
+
+		cmd, args := runner.RunArgsForCall(0)
+		Expect(cmd).To(Equal(fluxPath()))
+
+		Expect(strings.Join(args, " ")).To(Equal("reconcile source git my-app --namespace wego-system"))
+	})
+})
+
 func fluxPath() string {
 	homeDir, err := os.UserHomeDir()
 	Expect(err).ShouldNot(HaveOccurred())
@@ -9,13 +9,70 @@ import (
 )
 
 type FakeFlux struct {
-	CreateHelmReleaseGitRepositoryStub        func(string, string, string, string) ([]byte, error)
+	CreateAlertStub        func(string, string, []string, string, string) ([]byte, error)
+	createAlertMutex       sync.RWMutex
+	createAlertArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 []string
+		arg4 string
+		arg5 string
+	}
+	createAlertReturns struct {
+		result1 []byte
+		result2 error
+	}
+	createAlertReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	CreateAlertProviderStub        func(string, string, string, string, string, string) ([]byte, error)
+	createAlertProviderMutex       sync.RWMutex
+	createAlertProviderArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
+	}
+	createAlertProviderReturns struct {
+		result1 []byte
+		result2 error
+	}
+	createAlertProviderReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	CreateHelmReleaseBucketStub        func(string, string, string, string, string, string, string) ([]byte, error)
+	createHelmReleaseBucketMutex       sync.RWMutex
+	createHelmReleaseBucketArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
+		arg7 string
+	}
+	createHelmReleaseBucketReturns struct {
+		result1 []byte
+		result2 error
+	}
+	createHelmReleaseBucketReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	CreateHelmReleaseGitRepositoryStub        func(string, string, string, string, string, string, string) ([]byte, error)
 	createHelmReleaseGitRepositoryMutex       sync.RWMutex
 	createHelmReleaseGitRepositoryArgsForCall []struct {
 		arg1 string
 		arg2 string
 		arg3 string
 		arg4 string
+		arg5 string
+		arg6 string
+		arg7 string
 	}
 	createHelmReleaseGitRepositoryReturns struct {
 		result1 []byte
@@ -25,12 +82,15 @@ type FakeFlux struct {
 		result1 []byte
 		result2 error
 	}
-	CreateHelmReleaseHelmRepositoryStub        func(string, string, string) ([]byte, error)
+	CreateHelmReleaseHelmRepositoryStub        func(string, string, string, string, string, string) ([]byte, error)
 	createHelmReleaseHelmRepositoryMutex       sync.RWMutex
 	createHelmReleaseHelmRepositoryArgsForCall []struct {
 		arg1 string
 		arg2 string
 		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
 	}
 	createHelmReleaseHelmRepositoryReturns struct {
 		result1 []byte
@@ -40,13 +100,70 @@ type FakeFlux struct {
 		result1 []byte
 		result2 error
 	}
-	CreateKustomizationStub        func(string, string, string, string) ([]byte, error)
+	CreateImagePolicyStub        func(string, string, string, string, string, string, string) ([]byte, error)
+	createImagePolicyMutex       sync.RWMutex
+	createImagePolicyArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
+		arg7 string
+	}
+	createImagePolicyReturns struct {
+		result1 []byte
+		result2 error
+	}
+	createImagePolicyReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	CreateImageRepositoryStub        func(string, string, string, string) ([]byte, error)
+	createImageRepositoryMutex       sync.RWMutex
+	createImageRepositoryArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+	}
+	createImageRepositoryReturns struct {
+		result1 []byte
+		result2 error
+	}
+	createImageRepositoryReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	CreateImageUpdateAutomationStub        func(string, string, string, string, string, string, string) ([]byte, error)
+	createImageUpdateAutomationMutex       sync.RWMutex
+	createImageUpdateAutomationArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
+		arg7 string
+	}
+	createImageUpdateAutomationReturns struct {
+		result1 []byte
+		result2 error
+	}
+	createImageUpdateAutomationReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	CreateKustomizationStub        func(string, string, string, string, string, string, string) ([]byte, error)
 	createKustomizationMutex       sync.RWMutex
 	createKustomizationArgsForCall []struct {
 		arg1 string
 		arg2 string
 		arg3 string
 		arg4 string
+		arg5 string
+		arg6 string
+		arg7 string
 	}
 	createKustomizationReturns struct {
 		result1 []byte
@@ -71,7 +188,27 @@ type FakeFlux struct {
 		result1 []byte
 		result2 error
 	}
-	CreateSourceGitStub        func(string, string, string, string, string) ([]byte, error)
+	CreateSourceBucketStub        func(string, string, string, string, string, string, bool, string) ([]byte, error)
+	createSourceBucketMutex       sync.RWMutex
+	createSourceBucketArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
+		arg7 bool
+		arg8 string
+	}
+	createSourceBucketReturns struct {
+		result1 []byte
+		result2 error
+	}
+	createSourceBucketReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	CreateSourceGitStub        func(string, string, string, string, string, string, string) ([]byte, error)
 	createSourceGitMutex       sync.RWMutex
 	createSourceGitArgsForCall []struct {
 		arg1 string
@@ -79,6 +216,8 @@ type FakeFlux struct {
 		arg3 string
 		arg4 string
 		arg5 string
+		arg6 string
+		arg7 string
 	}
 	createSourceGitReturns struct {
 		result1 []byte
@@ -165,11 +304,13 @@ type FakeFlux struct {
 		result1 string
 		result2 error
 	}
-	InstallStub        func(string, bool) ([]byte, error)
+	InstallStub        func(string, string, string, bool) ([]byte, error)
 	installMutex       sync.RWMutex
 	installArgsForCall []struct {
 		arg1 string
-		arg2 bool
+		arg2 string
+		arg3 string
+		arg4 bool
 	}
 	installReturns struct {
 		result1 []byte
@@ -179,6 +320,21 @@ type FakeFlux struct {
 		result1 []byte
 		result2 error
 	}
+	ReconcileSourceStub        func(string, string, string) ([]byte, error)
+	reconcileSourceMutex       sync.RWMutex
+	reconcileSourceArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	reconcileSourceReturns struct {
+		result1 []byte
+		result2 error
+	}
+	reconcileSourceReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
 	SetupBinStub        func()
 	setupBinMutex       sync.RWMutex
 	setupBinArgsForCall []struct {
@@ -215,7 +371,219 @@ type FakeFlux struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeFlux) CreateHelmReleaseGitRepository(arg1 string, arg2 string, arg3 string, arg4 string) ([]byte, error) {
+func (fake *FakeFlux) CreateAlert(arg1 string, arg2 string, arg3 []string, arg4 string, arg5 string) ([]byte, error) {
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.createAlertMutex.Lock()
+	ret, specificReturn := fake.createAlertReturnsOnCall[len(fake.createAlertArgsForCall)]
+	fake.createAlertArgsForCall = append(fake.createAlertArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 []string
+		arg4 string
+		arg5 string
+	}{arg1, arg2, arg3Copy, arg4, arg5})
+	stub := fake.CreateAlertStub
+	fakeReturns := fake.createAlertReturns
+	fake.recordInvocation("CreateAlert", []interface{}{arg1, arg2, arg3Copy, arg4, arg5})
+	fake.createAlertMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFlux) CreateAlertCallCount() int {
+	fake.createAlertMutex.RLock()
+	defer fake.createAlertMutex.RUnlock()
+	return len(fake.createAlertArgsForCall)
+}
+
+func (fake *FakeFlux) CreateAlertCalls(stub func(string, string, []string, string, string) ([]byte, error)) {
+	fake.createAlertMutex.Lock()
+	defer fake.createAlertMutex.Unlock()
+	fake.CreateAlertStub = stub
+}
+
+func (fake *FakeFlux) CreateAlertArgsForCall(i int) (string, string, []string, string, string) {
+	fake.createAlertMutex.RLock()
+	defer fake.createAlertMutex.RUnlock()
+	argsForCall := fake.createAlertArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeFlux) CreateAlertReturns(result1 []byte, result2 error) {
+	fake.createAlertMutex.Lock()
+	defer fake.createAlertMutex.Unlock()
+	fake.CreateAlertStub = nil
+	fake.createAlertReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateAlertReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.createAlertMutex.Lock()
+	defer fake.createAlertMutex.Unlock()
+	fake.CreateAlertStub = nil
+	if fake.createAlertReturnsOnCall == nil {
+		fake.createAlertReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.createAlertReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateAlertProvider(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string) ([]byte, error) {
+	fake.createAlertProviderMutex.Lock()
+	ret, specificReturn := fake.createAlertProviderReturnsOnCall[len(fake.createAlertProviderArgsForCall)]
+	fake.createAlertProviderArgsForCall = append(fake.createAlertProviderArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
+	}{arg1, arg2, arg3, arg4, arg5, arg6})
+	stub := fake.CreateAlertProviderStub
+	fakeReturns := fake.createAlertProviderReturns
+	fake.recordInvocation("CreateAlertProvider", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6})
+	fake.createAlertProviderMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFlux) CreateAlertProviderCallCount() int {
+	fake.createAlertProviderMutex.RLock()
+	defer fake.createAlertProviderMutex.RUnlock()
+	return len(fake.createAlertProviderArgsForCall)
+}
+
+func (fake *FakeFlux) CreateAlertProviderCalls(stub func(string, string, string, string, string, string) ([]byte, error)) {
+	fake.createAlertProviderMutex.Lock()
+	defer fake.createAlertProviderMutex.Unlock()
+	fake.CreateAlertProviderStub = stub
+}
+
+func (fake *FakeFlux) CreateAlertProviderArgsForCall(i int) (string, string, string, string, string, string) {
+	fake.createAlertProviderMutex.RLock()
+	defer fake.createAlertProviderMutex.RUnlock()
+	argsForCall := fake.createAlertProviderArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
+}
+
+func (fake *FakeFlux) CreateAlertProviderReturns(result1 []byte, result2 error) {
+	fake.createAlertProviderMutex.Lock()
+	defer fake.createAlertProviderMutex.Unlock()
+	fake.CreateAlertProviderStub = nil
+	fake.createAlertProviderReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateAlertProviderReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.createAlertProviderMutex.Lock()
+	defer fake.createAlertProviderMutex.Unlock()
+	fake.CreateAlertProviderStub = nil
+	if fake.createAlertProviderReturnsOnCall == nil {
+		fake.createAlertProviderReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.createAlertProviderReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateHelmReleaseBucket(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string, arg7 string) ([]byte, error) {
+	fake.createHelmReleaseBucketMutex.Lock()
+	ret, specificReturn := fake.createHelmReleaseBucketReturnsOnCall[len(fake.createHelmReleaseBucketArgsForCall)]
+	fake.createHelmReleaseBucketArgsForCall = append(fake.createHelmReleaseBucketArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
+		arg7 string
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	stub := fake.CreateHelmReleaseBucketStub
+	fakeReturns := fake.createHelmReleaseBucketReturns
+	fake.recordInvocation("CreateHelmReleaseBucket", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	fake.createHelmReleaseBucketMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFlux) CreateHelmReleaseBucketCallCount() int {
+	fake.createHelmReleaseBucketMutex.RLock()
+	defer fake.createHelmReleaseBucketMutex.RUnlock()
+	return len(fake.createHelmReleaseBucketArgsForCall)
+}
+
+func (fake *FakeFlux) CreateHelmReleaseBucketCalls(stub func(string, string, string, string, string, string, string) ([]byte, error)) {
+	fake.createHelmReleaseBucketMutex.Lock()
+	defer fake.createHelmReleaseBucketMutex.Unlock()
+	fake.CreateHelmReleaseBucketStub = stub
+}
+
+func (fake *FakeFlux) CreateHelmReleaseBucketArgsForCall(i int) (string, string, string, string, string, string, string) {
+	fake.createHelmReleaseBucketMutex.RLock()
+	defer fake.createHelmReleaseBucketMutex.RUnlock()
+	argsForCall := fake.createHelmReleaseBucketArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
+}
+
+func (fake *FakeFlux) CreateHelmReleaseBucketReturns(result1 []byte, result2 error) {
+	fake.createHelmReleaseBucketMutex.Lock()
+	defer fake.createHelmReleaseBucketMutex.Unlock()
+	fake.CreateHelmReleaseBucketStub = nil
+	fake.createHelmReleaseBucketReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateHelmReleaseBucketReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.createHelmReleaseBucketMutex.Lock()
+	defer fake.createHelmReleaseBucketMutex.Unlock()
+	fake.CreateHelmReleaseBucketStub = nil
+	if fake.createHelmReleaseBucketReturnsOnCall == nil {
+		fake.createHelmReleaseBucketReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.createHelmReleaseBucketReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateHelmReleaseGitRepository(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string, arg7 string) ([]byte, error) {
 	fake.createHelmReleaseGitRepositoryMutex.Lock()
 	ret, specificReturn := fake.createHelmReleaseGitRepositoryReturnsOnCall[len(fake.createHelmReleaseGitRepositoryArgsForCall)]
 	fake.createHelmReleaseGitRepositoryArgsForCall = append(fake.createHelmReleaseGitRepositoryArgsForCall, struct {
@@ -223,13 +591,16 @@ func (fake *FakeFlux) CreateHelmReleaseGitRepository(arg1 string, arg2 string, a
 		arg2 string
 		arg3 string
 		arg4 string
-	}{arg1, arg2, arg3, arg4})
+		arg5 string
+		arg6 string
+		arg7 string
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	stub := fake.CreateHelmReleaseGitRepositoryStub
 	fakeReturns := fake.createHelmReleaseGitRepositoryReturns
-	fake.recordInvocation("CreateHelmReleaseGitRepository", []interface{}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("CreateHelmReleaseGitRepository", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	fake.createHelmReleaseGitRepositoryMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4)
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -243,17 +614,17 @@ func (fake *FakeFlux) CreateHelmReleaseGitRepositoryCallCount() int {
 	return len(fake.createHelmReleaseGitRepositoryArgsForCall)
 }
 
-func (fake *FakeFlux) CreateHelmReleaseGitRepositoryCalls(stub func(string, string, string, string) ([]byte, error)) {
+func (fake *FakeFlux) CreateHelmReleaseGitRepositoryCalls(stub func(string, string, string, string, string, string, string) ([]byte, error)) {
 	fake.createHelmReleaseGitRepositoryMutex.Lock()
 	defer fake.createHelmReleaseGitRepositoryMutex.Unlock()
 	fake.CreateHelmReleaseGitRepositoryStub = stub
 }
 
-func (fake *FakeFlux) CreateHelmReleaseGitRepositoryArgsForCall(i int) (string, string, string, string) {
+func (fake *FakeFlux) CreateHelmReleaseGitRepositoryArgsForCall(i int) (string, string, string, string, string, string, string) {
 	fake.createHelmReleaseGitRepositoryMutex.RLock()
 	defer fake.createHelmReleaseGitRepositoryMutex.RUnlock()
 	argsForCall := fake.createHelmReleaseGitRepositoryArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
 }
 
 func (fake *FakeFlux) CreateHelmReleaseGitRepositoryReturns(result1 []byte, result2 error) {
@@ -282,20 +653,23 @@ func (fake *FakeFlux) CreateHelmReleaseGitRepositoryReturnsOnCall(i int, result1
 	}{result1, result2}
 }
 
-func (fake *FakeFlux) CreateHelmReleaseHelmRepository(arg1 string, arg2 string, arg3 string) ([]byte, error) {
+func (fake *FakeFlux) CreateHelmReleaseHelmRepository(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string) ([]byte, error) {
 	fake.createHelmReleaseHelmRepositoryMutex.Lock()
 	ret, specificReturn := fake.createHelmReleaseHelmRepositoryReturnsOnCall[len(fake.createHelmReleaseHelmRepositoryArgsForCall)]
 	fake.createHelmReleaseHelmRepositoryArgsForCall = append(fake.createHelmReleaseHelmRepositoryArgsForCall, struct {
 		arg1 string
 		arg2 string
 		arg3 string
-	}{arg1, arg2, arg3})
+		arg4 string
+		arg5 string
+		arg6 string
+	}{arg1, arg2, arg3, arg4, arg5, arg6})
 	stub := fake.CreateHelmReleaseHelmRepositoryStub
 	fakeReturns := fake.createHelmReleaseHelmRepositoryReturns
-	fake.recordInvocation("CreateHelmReleaseHelmRepository", []interface{}{arg1, arg2, arg3})
+	fake.recordInvocation("CreateHelmReleaseHelmRepository", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6})
 	fake.createHelmReleaseHelmRepositoryMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -309,17 +683,17 @@ func (fake *FakeFlux) CreateHelmReleaseHelmRepositoryCallCount() int {
 	return len(fake.createHelmReleaseHelmRepositoryArgsForCall)
 }
 
-func (fake *FakeFlux) CreateHelmReleaseHelmRepositoryCalls(stub func(string, string, string) ([]byte, error)) {
+func (fake *FakeFlux) CreateHelmReleaseHelmRepositoryCalls(stub func(string, string, string, string, string, string) ([]byte, error)) {
 	fake.createHelmReleaseHelmRepositoryMutex.Lock()
 	defer fake.createHelmReleaseHelmRepositoryMutex.Unlock()
 	fake.CreateHelmReleaseHelmRepositoryStub = stub
 }
 
-func (fake *FakeFlux) CreateHelmReleaseHelmRepositoryArgsForCall(i int) (string, string, string) {
+func (fake *FakeFlux) CreateHelmReleaseHelmRepositoryArgsForCall(i int) (string, string, string, string, string, string) {
 	fake.createHelmReleaseHelmRepositoryMutex.RLock()
 	defer fake.createHelmReleaseHelmRepositoryMutex.RUnlock()
 	argsForCall := fake.createHelmReleaseHelmRepositoryArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
 }
 
 func (fake *FakeFlux) CreateHelmReleaseHelmRepositoryReturns(result1 []byte, result2 error) {
@@ -348,7 +722,214 @@ func (fake *FakeFlux) CreateHelmReleaseHelmRepositoryReturnsOnCall(i int, result
 	}{result1, result2}
 }
 
-func (fake *FakeFlux) CreateKustomization(arg1 string, arg2 string, arg3 string, arg4 string) ([]byte, error) {
+func (fake *FakeFlux) CreateImagePolicy(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string, arg7 string) ([]byte, error) {
+	fake.createImagePolicyMutex.Lock()
+	ret, specificReturn := fake.createImagePolicyReturnsOnCall[len(fake.createImagePolicyArgsForCall)]
+	fake.createImagePolicyArgsForCall = append(fake.createImagePolicyArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
+		arg7 string
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	stub := fake.CreateImagePolicyStub
+	fakeReturns := fake.createImagePolicyReturns
+	fake.recordInvocation("CreateImagePolicy", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	fake.createImagePolicyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFlux) CreateImagePolicyCallCount() int {
+	fake.createImagePolicyMutex.RLock()
+	defer fake.createImagePolicyMutex.RUnlock()
+	return len(fake.createImagePolicyArgsForCall)
+}
+
+func (fake *FakeFlux) CreateImagePolicyCalls(stub func(string, string, string, string, string, string, string) ([]byte, error)) {
+	fake.createImagePolicyMutex.Lock()
+	defer fake.createImagePolicyMutex.Unlock()
+	fake.CreateImagePolicyStub = stub
+}
+
+func (fake *FakeFlux) CreateImagePolicyArgsForCall(i int) (string, string, string, string, string, string, string) {
+	fake.createImagePolicyMutex.RLock()
+	defer fake.createImagePolicyMutex.RUnlock()
+	argsForCall := fake.createImagePolicyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
+}
+
+func (fake *FakeFlux) CreateImagePolicyReturns(result1 []byte, result2 error) {
+	fake.createImagePolicyMutex.Lock()
+	defer fake.createImagePolicyMutex.Unlock()
+	fake.CreateImagePolicyStub = nil
+	fake.createImagePolicyReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateImagePolicyReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.createImagePolicyMutex.Lock()
+	defer fake.createImagePolicyMutex.Unlock()
+	fake.CreateImagePolicyStub = nil
+	if fake.createImagePolicyReturnsOnCall == nil {
+		fake.createImagePolicyReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.createImagePolicyReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateImageRepository(arg1 string, arg2 string, arg3 string, arg4 string) ([]byte, error) {
+	fake.createImageRepositoryMutex.Lock()
+	ret, specificReturn := fake.createImageRepositoryReturnsOnCall[len(fake.createImageRepositoryArgsForCall)]
+	fake.createImageRepositoryArgsForCall = append(fake.createImageRepositoryArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.CreateImageRepositoryStub
+	fakeReturns := fake.createImageRepositoryReturns
+	fake.recordInvocation("CreateImageRepository", []interface{}{arg1, arg2, arg3, arg4})
+	fake.createImageRepositoryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFlux) CreateImageRepositoryCallCount() int {
+	fake.createImageRepositoryMutex.RLock()
+	defer fake.createImageRepositoryMutex.RUnlock()
+	return len(fake.createImageRepositoryArgsForCall)
+}
+
+func (fake *FakeFlux) CreateImageRepositoryCalls(stub func(string, string, string, string) ([]byte, error)) {
+	fake.createImageRepositoryMutex.Lock()
+	defer fake.createImageRepositoryMutex.Unlock()
+	fake.CreateImageRepositoryStub = stub
+}
+
+func (fake *FakeFlux) CreateImageRepositoryArgsForCall(i int) (string, string, string, string) {
+	fake.createImageRepositoryMutex.RLock()
+	defer fake.createImageRepositoryMutex.RUnlock()
+	argsForCall := fake.createImageRepositoryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeFlux) CreateImageRepositoryReturns(result1 []byte, result2 error) {
+	fake.createImageRepositoryMutex.Lock()
+	defer fake.createImageRepositoryMutex.Unlock()
+	fake.CreateImageRepositoryStub = nil
+	fake.createImageRepositoryReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateImageRepositoryReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.createImageRepositoryMutex.Lock()
+	defer fake.createImageRepositoryMutex.Unlock()
+	fake.CreateImageRepositoryStub = nil
+	if fake.createImageRepositoryReturnsOnCall == nil {
+		fake.createImageRepositoryReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.createImageRepositoryReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateImageUpdateAutomation(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string, arg7 string) ([]byte, error) {
+	fake.createImageUpdateAutomationMutex.Lock()
+	ret, specificReturn := fake.createImageUpdateAutomationReturnsOnCall[len(fake.createImageUpdateAutomationArgsForCall)]
+	fake.createImageUpdateAutomationArgsForCall = append(fake.createImageUpdateAutomationArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
+		arg7 string
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	stub := fake.CreateImageUpdateAutomationStub
+	fakeReturns := fake.createImageUpdateAutomationReturns
+	fake.recordInvocation("CreateImageUpdateAutomation", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
+	fake.createImageUpdateAutomationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFlux) CreateImageUpdateAutomationCallCount() int {
+	fake.createImageUpdateAutomationMutex.RLock()
+	defer fake.createImageUpdateAutomationMutex.RUnlock()
+	return len(fake.createImageUpdateAutomationArgsForCall)
+}
+
+func (fake *FakeFlux) CreateImageUpdateAutomationCalls(stub func(string, string, string, string, string, string, string) ([]byte, error)) {
+	fake.createImageUpdateAutomationMutex.Lock()
+	defer fake.createImageUpdateAutomationMutex.Unlock()
+	fake.CreateImageUpdateAutomationStub = stub
+}
+
+func (fake *FakeFlux) CreateImageUpdateAutomationArgsForCall(i int) (string, string, string, string, string, string, string) {
+	fake.createImageUpdateAutomationMutex.RLock()
+	defer fake.createImageUpdateAutomationMutex.RUnlock()
+	argsForCall := fake.createImageUpdateAutomationArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
+}
+
+func (fake *FakeFlux) CreateImageUpdateAutomationReturns(result1 []byte, result2 error) {
+	fake.createImageUpdateAutomationMutex.Lock()
+	defer fake.createImageUpdateAutomationMutex.Unlock()
+	fake.CreateImageUpdateAutomationStub = nil
+	fake.createImageUpdateAutomationReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateImageUpdateAutomationReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.createImageUpdateAutomationMutex.Lock()
+	defer fake.createImageUpdateAutomationMutex.Unlock()
+	fake.CreateImageUpdateAutomationStub = nil
+	if fake.createImageUpdateAutomationReturnsOnCall == nil {
+		fake.createImageUpdateAutomationReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.createImageUpdateAutomationReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateKustomization(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string, arg7 string) ([]byte, error) {
 	fake.createKustomizationMutex.Lock()
 	ret, specificReturn := fake.createKustomizationReturnsOnCall[len(fake.createKustomizationArgsForCall)]
 	fake.createKustomizationArgsForCall = append(fake.createKustomizationArgsForCall, struct {
@@ -356,13 +937,16 @@ func (fake *FakeFlux) CreateKustomization(arg1 string, arg2 string, arg3 string,
 		arg2 string
 		arg3 string
 		arg4 string
-	}{arg1, arg2, arg3, arg4})
+		arg5 string
+		arg6 string
+		arg7 string
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	stub := fake.CreateKustomizationStub
 	fakeReturns := fake.createKustomizationReturns
-	fake.recordInvocation("CreateKustomization", []interface{}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("CreateKustomization", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	fake.createKustomizationMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4)
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -376,17 +960,17 @@ func (fake *FakeFlux) CreateKustomizationCallCount() int {
 	return len(fake.createKustomizationArgsForCall)
 }
 
-func (fake *FakeFlux) CreateKustomizationCalls(stub func(string, string, string, string) ([]byte, error)) {
+func (fake *FakeFlux) CreateKustomizationCalls(stub func(string, string, string, string, string, string, string) ([]byte, error)) {
 	fake.createKustomizationMutex.Lock()
 	defer fake.createKustomizationMutex.Unlock()
 	fake.CreateKustomizationStub = stub
 }
 
-func (fake *FakeFlux) CreateKustomizationArgsForCall(i int) (string, string, string, string) {
+func (fake *FakeFlux) CreateKustomizationArgsForCall(i int) (string, string, string, string, string, string, string) {
 	fake.createKustomizationMutex.RLock()
 	defer fake.createKustomizationMutex.RUnlock()
 	argsForCall := fake.createKustomizationArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
 }
 
 func (fake *FakeFlux) CreateKustomizationReturns(result1 []byte, result2 error) {
@@ -481,7 +1065,78 @@ func (fake *FakeFlux) CreateSecretGitReturnsOnCall(i int, result1 []byte, result
 	}{result1, result2}
 }
 
-func (fake *FakeFlux) CreateSourceGit(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string) ([]byte, error) {
+func (fake *FakeFlux) CreateSourceBucket(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string, arg7 bool, arg8 string) ([]byte, error) {
+	fake.createSourceBucketMutex.Lock()
+	ret, specificReturn := fake.createSourceBucketReturnsOnCall[len(fake.createSourceBucketArgsForCall)]
+	fake.createSourceBucketArgsForCall = append(fake.createSourceBucketArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 string
+		arg7 bool
+		arg8 string
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8})
+	stub := fake.CreateSourceBucketStub
+	fakeReturns := fake.createSourceBucketReturns
+	fake.recordInvocation("CreateSourceBucket", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8})
+	fake.createSourceBucketMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFlux) CreateSourceBucketCallCount() int {
+	fake.createSourceBucketMutex.RLock()
+	defer fake.createSourceBucketMutex.RUnlock()
+	return len(fake.createSourceBucketArgsForCall)
+}
+
+func (fake *FakeFlux) CreateSourceBucketCalls(stub func(string, string, string, string, string, string, bool, string) ([]byte, error)) {
+	fake.createSourceBucketMutex.Lock()
+	defer fake.createSourceBucketMutex.Unlock()
+	fake.CreateSourceBucketStub = stub
+}
+
+func (fake *FakeFlux) CreateSourceBucketArgsForCall(i int) (string, string, string, string, string, string, bool, string) {
+	fake.createSourceBucketMutex.RLock()
+	defer fake.createSourceBucketMutex.RUnlock()
+	argsForCall := fake.createSourceBucketArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7, argsForCall.arg8
+}
+
+func (fake *FakeFlux) CreateSourceBucketReturns(result1 []byte, result2 error) {
+	fake.createSourceBucketMutex.Lock()
+	defer fake.createSourceBucketMutex.Unlock()
+	fake.CreateSourceBucketStub = nil
+	fake.createSourceBucketReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateSourceBucketReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.createSourceBucketMutex.Lock()
+	defer fake.createSourceBucketMutex.Unlock()
+	fake.CreateSourceBucketStub = nil
+	if fake.createSourceBucketReturnsOnCall == nil {
+		fake.createSourceBucketReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.createSourceBucketReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) CreateSourceGit(arg1 string, arg2 string, arg3 string, arg4 string, arg5 string, arg6 string, arg7 string) ([]byte, error) {
 	fake.createSourceGitMutex.Lock()
 	ret, specificReturn := fake.createSourceGitReturnsOnCall[len(fake.createSourceGitArgsForCall)]
 	fake.createSourceGitArgsForCall = append(fake.createSourceGitArgsForCall, struct {
@@ -490,13 +1145,15 @@ func (fake *FakeFlux) CreateSourceGit(arg1 string, arg2 string, arg3 string, arg
 		arg3 string
 		arg4 string
 		arg5 string
-	}{arg1, arg2, arg3, arg4, arg5})
+		arg6 string
+		arg7 string
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	stub := fake.CreateSourceGitStub
 	fakeReturns := fake.createSourceGitReturns
-	fake.recordInvocation("CreateSourceGit", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.recordInvocation("CreateSourceGit", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	fake.createSourceGitMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4, arg5)
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -510,17 +1167,17 @@ func (fake *FakeFlux) CreateSourceGitCallCount() int {
 	return len(fake.createSourceGitArgsForCall)
 }
 
-func (fake *FakeFlux) CreateSourceGitCalls(stub func(string, string, string, string, string) ([]byte, error)) {
+func (fake *FakeFlux) CreateSourceGitCalls(stub func(string, string, string, string, string, string, string) ([]byte, error)) {
 	fake.createSourceGitMutex.Lock()
 	defer fake.createSourceGitMutex.Unlock()
 	fake.CreateSourceGitStub = stub
 }
 
-func (fake *FakeFlux) CreateSourceGitArgsForCall(i int) (string, string, string, string, string) {
+func (fake *FakeFlux) CreateSourceGitArgsForCall(i int) (string, string, string, string, string, string, string) {
 	fake.createSourceGitMutex.RLock()
 	defer fake.createSourceGitMutex.RUnlock()
 	argsForCall := fake.createSourceGitArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
 }
 
 func (fake *FakeFlux) CreateSourceGitReturns(result1 []byte, result2 error) {
@@ -904,19 +1561,21 @@ func (fake *FakeFlux) GetVersionReturnsOnCall(i int, result1 string, result2 err
 	}{result1, result2}
 }
 
-func (fake *FakeFlux) Install(arg1 string, arg2 bool) ([]byte, error) {
+func (fake *FakeFlux) Install(arg1 string, arg2 string, arg3 string, arg4 bool) ([]byte, error) {
 	fake.installMutex.Lock()
 	ret, specificReturn := fake.installReturnsOnCall[len(fake.installArgsForCall)]
 	fake.installArgsForCall = append(fake.installArgsForCall, struct {
 		arg1 string
-		arg2 bool
-	}{arg1, arg2})
+		arg2 string
+		arg3 string
+		arg4 bool
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.InstallStub
 	fakeReturns := fake.installReturns
-	fake.recordInvocation("Install", []interface{}{arg1, arg2})
+	fake.recordInvocation("Install", []interface{}{arg1, arg2, arg3, arg4})
 	fake.installMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -930,17 +1589,17 @@ func (fake *FakeFlux) InstallCallCount() int {
 	return len(fake.installArgsForCall)
 }
 
-func (fake *FakeFlux) InstallCalls(stub func(string, bool) ([]byte, error)) {
+func (fake *FakeFlux) InstallCalls(stub func(string, string, string, bool) ([]byte, error)) {
 	fake.installMutex.Lock()
 	defer fake.installMutex.Unlock()
 	fake.InstallStub = stub
 }
 
-func (fake *FakeFlux) InstallArgsForCall(i int) (string, bool) {
+func (fake *FakeFlux) InstallArgsForCall(i int) (string, string, string, bool) {
 	fake.installMutex.RLock()
 	defer fake.installMutex.RUnlock()
 	argsForCall := fake.installArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeFlux) InstallReturns(result1 []byte, result2 error) {
@@ -969,6 +1628,72 @@ func (fake *FakeFlux) InstallReturnsOnCall(i int, result1 []byte, result2 error)
 	}{result1, result2}
 }
 
+func (fake *FakeFlux) ReconcileSource(arg1 string, arg2 string, arg3 string) ([]byte, error) {
+	fake.reconcileSourceMutex.Lock()
+	ret, specificReturn := fake.reconcileSourceReturnsOnCall[len(fake.reconcileSourceArgsForCall)]
+	fake.reconcileSourceArgsForCall = append(fake.reconcileSourceArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ReconcileSourceStub
+	fakeReturns := fake.reconcileSourceReturns
+	fake.recordInvocation("ReconcileSource", []interface{}{arg1, arg2, arg3})
+	fake.reconcileSourceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFlux) ReconcileSourceCallCount() int {
+	fake.reconcileSourceMutex.RLock()
+	defer fake.reconcileSourceMutex.RUnlock()
+	return len(fake.reconcileSourceArgsForCall)
+}
+
+func (fake *FakeFlux) ReconcileSourceCalls(stub func(string, string, string) ([]byte, error)) {
+	fake.reconcileSourceMutex.Lock()
+	defer fake.reconcileSourceMutex.Unlock()
+	fake.ReconcileSourceStub = stub
+}
+
+func (fake *FakeFlux) ReconcileSourceArgsForCall(i int) (string, string, string) {
+	fake.reconcileSourceMutex.RLock()
+	defer fake.reconcileSourceMutex.RUnlock()
+	argsForCall := fake.reconcileSourceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeFlux) ReconcileSourceReturns(result1 []byte, result2 error) {
+	fake.reconcileSourceMutex.Lock()
+	defer fake.reconcileSourceMutex.Unlock()
+	fake.ReconcileSourceStub = nil
+	fake.reconcileSourceReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFlux) ReconcileSourceReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.reconcileSourceMutex.Lock()
+	defer fake.reconcileSourceMutex.Unlock()
+	fake.ReconcileSourceStub = nil
+	if fake.reconcileSourceReturnsOnCall == nil {
+		fake.reconcileSourceReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.reconcileSourceReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeFlux) SetupBin() {
 	fake.setupBinMutex.Lock()
 	fake.setupBinArgsForCall = append(fake.setupBinArgsForCall, struct {
@@ -1125,14 +1850,28 @@ func (fake *FakeFlux) UninstallReturnsOnCall(i int, result1 error) {
 func (fake *FakeFlux) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.createAlertMutex.RLock()
+	defer fake.createAlertMutex.RUnlock()
+	fake.createAlertProviderMutex.RLock()
+	defer fake.createAlertProviderMutex.RUnlock()
+	fake.createHelmReleaseBucketMutex.RLock()
+	defer fake.createHelmReleaseBucketMutex.RUnlock()
 	fake.createHelmReleaseGitRepositoryMutex.RLock()
 	defer fake.createHelmReleaseGitRepositoryMutex.RUnlock()
 	fake.createHelmReleaseHelmRepositoryMutex.RLock()
 	defer fake.createHelmReleaseHelmRepositoryMutex.RUnlock()
+	fake.createImagePolicyMutex.RLock()
+	defer fake.createImagePolicyMutex.RUnlock()
+	fake.createImageRepositoryMutex.RLock()
+	defer fake.createImageRepositoryMutex.RUnlock()
+	fake.createImageUpdateAutomationMutex.RLock()
+	defer fake.createImageUpdateAutomationMutex.RUnlock()
 	fake.createKustomizationMutex.RLock()
 	defer fake.createKustomizationMutex.RUnlock()
 	fake.createSecretGitMutex.RLock()
 	defer fake.createSecretGitMutex.RUnlock()
+	fake.createSourceBucketMutex.RLock()
+	defer fake.createSourceBucketMutex.RUnlock()
 	fake.createSourceGitMutex.RLock()
 	defer fake.createSourceGitMutex.RUnlock()
 	fake.createSourceHelmMutex.RLock()
@@ -1149,6 +1888,8 @@ func (fake *FakeFlux) Invocations() map[string][][]interface{} {
 	defer fake.getVersionMutex.RUnlock()
 	fake.installMutex.RLock()
 	defer fake.installMutex.RUnlock()
+	fake.reconcileSourceMutex.RLock()
+	defer fake.reconcileSourceMutex.RUnlock()
 	fake.setupBinMutex.RLock()
 	defer fake.setupBinMutex.RUnlock()
 	fake.suspendOrResumeAppMutex.RLock()
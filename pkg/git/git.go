@@ -21,6 +21,7 @@ import (
 	"errors"
 
 	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -41,6 +42,14 @@ type Commit struct {
 	Message string
 }
 
+// CloneOptions controls how much of a repository is cloned, and where it is stored
+type CloneOptions struct {
+	// Depth limits the history fetched to the given number of commits; the whole history is fetched when 0
+	Depth int
+	// InMemory keeps the repository and its worktree in memory instead of writing them to disk
+	InMemory bool
+}
+
 // Git is an interface for basic Git operations on a single branch of a
 // remote repository.
 //counterfeiter:generate . Git
@@ -48,10 +57,13 @@ type Git interface {
 	Open(path string) (*gogit.Repository, error)
 	Init(path, url, branch string) (bool, error)
 	Clone(ctx context.Context, path, url, branch string) (bool, error)
+	CloneWithOptions(ctx context.Context, path, url, branch string, opts CloneOptions) (bool, error)
 	Write(path string, content []byte) error
 	Remove(path string) error
 	Commit(message Commit, filters ...func(string) bool) (string, error)
 	Push(ctx context.Context) error
 	Status() (bool, error)
 	Head() (string, error)
+	BranchHasCommit(ctx context.Context, url, branch, hash string, depth int) (bool, error)
+	ListRemoteRefs(ctx context.Context, url string) ([]*plumbing.Reference, error)
 }
@@ -6,10 +6,28 @@ import (
 	"sync"
 
 	gita "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/weaveworks/weave-gitops/pkg/git"
 )
 
 type FakeGit struct {
+	BranchHasCommitStub        func(context.Context, string, string, string, int) (bool, error)
+	branchHasCommitMutex       sync.RWMutex
+	branchHasCommitArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 int
+	}
+	branchHasCommitReturns struct {
+		result1 bool
+		result2 error
+	}
+	branchHasCommitReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	CloneStub        func(context.Context, string, string, string) (bool, error)
 	cloneMutex       sync.RWMutex
 	cloneArgsForCall []struct {
@@ -26,6 +44,23 @@ type FakeGit struct {
 		result1 bool
 		result2 error
 	}
+	CloneWithOptionsStub        func(context.Context, string, string, string, git.CloneOptions) (bool, error)
+	cloneWithOptionsMutex       sync.RWMutex
+	cloneWithOptionsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 git.CloneOptions
+	}
+	cloneWithOptionsReturns struct {
+		result1 bool
+		result2 error
+	}
+	cloneWithOptionsReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	CommitStub        func(git.Commit, ...func(string) bool) (string, error)
 	commitMutex       sync.RWMutex
 	commitArgsForCall []struct {
@@ -67,6 +102,20 @@ type FakeGit struct {
 		result1 bool
 		result2 error
 	}
+	ListRemoteRefsStub        func(context.Context, string) ([]*plumbing.Reference, error)
+	listRemoteRefsMutex       sync.RWMutex
+	listRemoteRefsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	listRemoteRefsReturns struct {
+		result1 []*plumbing.Reference
+		result2 error
+	}
+	listRemoteRefsReturnsOnCall map[int]struct {
+		result1 []*plumbing.Reference
+		result2 error
+	}
 	OpenStub        func(string) (*gita.Repository, error)
 	openMutex       sync.RWMutex
 	openArgsForCall []struct {
@@ -130,6 +179,74 @@ type FakeGit struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeGit) BranchHasCommit(arg1 context.Context, arg2 string, arg3 string, arg4 string, arg5 int) (bool, error) {
+	fake.branchHasCommitMutex.Lock()
+	ret, specificReturn := fake.branchHasCommitReturnsOnCall[len(fake.branchHasCommitArgsForCall)]
+	fake.branchHasCommitArgsForCall = append(fake.branchHasCommitArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 int
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.BranchHasCommitStub
+	fakeReturns := fake.branchHasCommitReturns
+	fake.recordInvocation("BranchHasCommit", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.branchHasCommitMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGit) BranchHasCommitCallCount() int {
+	fake.branchHasCommitMutex.RLock()
+	defer fake.branchHasCommitMutex.RUnlock()
+	return len(fake.branchHasCommitArgsForCall)
+}
+
+func (fake *FakeGit) BranchHasCommitCalls(stub func(context.Context, string, string, string, int) (bool, error)) {
+	fake.branchHasCommitMutex.Lock()
+	defer fake.branchHasCommitMutex.Unlock()
+	fake.BranchHasCommitStub = stub
+}
+
+func (fake *FakeGit) BranchHasCommitArgsForCall(i int) (context.Context, string, string, string, int) {
+	fake.branchHasCommitMutex.RLock()
+	defer fake.branchHasCommitMutex.RUnlock()
+	argsForCall := fake.branchHasCommitArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeGit) BranchHasCommitReturns(result1 bool, result2 error) {
+	fake.branchHasCommitMutex.Lock()
+	defer fake.branchHasCommitMutex.Unlock()
+	fake.BranchHasCommitStub = nil
+	fake.branchHasCommitReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGit) BranchHasCommitReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.branchHasCommitMutex.Lock()
+	defer fake.branchHasCommitMutex.Unlock()
+	fake.BranchHasCommitStub = nil
+	if fake.branchHasCommitReturnsOnCall == nil {
+		fake.branchHasCommitReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.branchHasCommitReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGit) Clone(arg1 context.Context, arg2 string, arg3 string, arg4 string) (bool, error) {
 	fake.cloneMutex.Lock()
 	ret, specificReturn := fake.cloneReturnsOnCall[len(fake.cloneArgsForCall)]
@@ -197,6 +314,74 @@ func (fake *FakeGit) CloneReturnsOnCall(i int, result1 bool, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeGit) CloneWithOptions(arg1 context.Context, arg2 string, arg3 string, arg4 string, arg5 git.CloneOptions) (bool, error) {
+	fake.cloneWithOptionsMutex.Lock()
+	ret, specificReturn := fake.cloneWithOptionsReturnsOnCall[len(fake.cloneWithOptionsArgsForCall)]
+	fake.cloneWithOptionsArgsForCall = append(fake.cloneWithOptionsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 git.CloneOptions
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.CloneWithOptionsStub
+	fakeReturns := fake.cloneWithOptionsReturns
+	fake.recordInvocation("CloneWithOptions", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.cloneWithOptionsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGit) CloneWithOptionsCallCount() int {
+	fake.cloneWithOptionsMutex.RLock()
+	defer fake.cloneWithOptionsMutex.RUnlock()
+	return len(fake.cloneWithOptionsArgsForCall)
+}
+
+func (fake *FakeGit) CloneWithOptionsCalls(stub func(context.Context, string, string, string, git.CloneOptions) (bool, error)) {
+	fake.cloneWithOptionsMutex.Lock()
+	defer fake.cloneWithOptionsMutex.Unlock()
+	fake.CloneWithOptionsStub = stub
+}
+
+func (fake *FakeGit) CloneWithOptionsArgsForCall(i int) (context.Context, string, string, string, git.CloneOptions) {
+	fake.cloneWithOptionsMutex.RLock()
+	defer fake.cloneWithOptionsMutex.RUnlock()
+	argsForCall := fake.cloneWithOptionsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeGit) CloneWithOptionsReturns(result1 bool, result2 error) {
+	fake.cloneWithOptionsMutex.Lock()
+	defer fake.cloneWithOptionsMutex.Unlock()
+	fake.CloneWithOptionsStub = nil
+	fake.cloneWithOptionsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGit) CloneWithOptionsReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.cloneWithOptionsMutex.Lock()
+	defer fake.cloneWithOptionsMutex.Unlock()
+	fake.CloneWithOptionsStub = nil
+	if fake.cloneWithOptionsReturnsOnCall == nil {
+		fake.cloneWithOptionsReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.cloneWithOptionsReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGit) Commit(arg1 git.Commit, arg2 ...func(string) bool) (string, error) {
 	fake.commitMutex.Lock()
 	ret, specificReturn := fake.commitReturnsOnCall[len(fake.commitArgsForCall)]
@@ -384,6 +569,71 @@ func (fake *FakeGit) InitReturnsOnCall(i int, result1 bool, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeGit) ListRemoteRefs(arg1 context.Context, arg2 string) ([]*plumbing.Reference, error) {
+	fake.listRemoteRefsMutex.Lock()
+	ret, specificReturn := fake.listRemoteRefsReturnsOnCall[len(fake.listRemoteRefsArgsForCall)]
+	fake.listRemoteRefsArgsForCall = append(fake.listRemoteRefsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ListRemoteRefsStub
+	fakeReturns := fake.listRemoteRefsReturns
+	fake.recordInvocation("ListRemoteRefs", []interface{}{arg1, arg2})
+	fake.listRemoteRefsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGit) ListRemoteRefsCallCount() int {
+	fake.listRemoteRefsMutex.RLock()
+	defer fake.listRemoteRefsMutex.RUnlock()
+	return len(fake.listRemoteRefsArgsForCall)
+}
+
+func (fake *FakeGit) ListRemoteRefsCalls(stub func(context.Context, string) ([]*plumbing.Reference, error)) {
+	fake.listRemoteRefsMutex.Lock()
+	defer fake.listRemoteRefsMutex.Unlock()
+	fake.ListRemoteRefsStub = stub
+}
+
+func (fake *FakeGit) ListRemoteRefsArgsForCall(i int) (context.Context, string) {
+	fake.listRemoteRefsMutex.RLock()
+	defer fake.listRemoteRefsMutex.RUnlock()
+	argsForCall := fake.listRemoteRefsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGit) ListRemoteRefsReturns(result1 []*plumbing.Reference, result2 error) {
+	fake.listRemoteRefsMutex.Lock()
+	defer fake.listRemoteRefsMutex.Unlock()
+	fake.ListRemoteRefsStub = nil
+	fake.listRemoteRefsReturns = struct {
+		result1 []*plumbing.Reference
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGit) ListRemoteRefsReturnsOnCall(i int, result1 []*plumbing.Reference, result2 error) {
+	fake.listRemoteRefsMutex.Lock()
+	defer fake.listRemoteRefsMutex.Unlock()
+	fake.ListRemoteRefsStub = nil
+	if fake.listRemoteRefsReturnsOnCall == nil {
+		fake.listRemoteRefsReturnsOnCall = make(map[int]struct {
+			result1 []*plumbing.Reference
+			result2 error
+		})
+	}
+	fake.listRemoteRefsReturnsOnCall[i] = struct {
+		result1 []*plumbing.Reference
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGit) Open(arg1 string) (*gita.Repository, error) {
 	fake.openMutex.Lock()
 	ret, specificReturn := fake.openReturnsOnCall[len(fake.openArgsForCall)]
@@ -696,14 +946,20 @@ func (fake *FakeGit) WriteReturnsOnCall(i int, result1 error) {
 func (fake *FakeGit) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.branchHasCommitMutex.RLock()
+	defer fake.branchHasCommitMutex.RUnlock()
 	fake.cloneMutex.RLock()
 	defer fake.cloneMutex.RUnlock()
+	fake.cloneWithOptionsMutex.RLock()
+	defer fake.cloneWithOptionsMutex.RUnlock()
 	fake.commitMutex.RLock()
 	defer fake.commitMutex.RUnlock()
 	fake.headMutex.RLock()
 	defer fake.headMutex.RUnlock()
 	fake.initMutex.RLock()
 	defer fake.initMutex.RUnlock()
+	fake.listRemoteRefsMutex.RLock()
+	defer fake.listRemoteRefsMutex.RUnlock()
 	fake.openMutex.RLock()
 	defer fake.openMutex.RUnlock()
 	fake.pushMutex.RLock()
@@ -23,20 +23,25 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"time"
 
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 type GoGit struct {
-	path       string
-	auth       transport.AuthMethod
-	repository *gogit.Repository
+	path        string
+	auth        transport.AuthMethod
+	signingKey  SigningKey
+	pushRetries int
+	pushBackoff time.Duration
+	repository  *gogit.Repository
 }
 
 func New(auth transport.AuthMethod) *GoGit {
@@ -45,6 +50,20 @@ func New(auth transport.AuthMethod) *GoGit {
 	}
 }
 
+// WithSigningKey makes the client sign the commits it creates with the given key
+func (g *GoGit) WithSigningKey(key SigningKey) *GoGit {
+	g.signingKey = key
+	return g
+}
+
+// WithPushRetries makes Push re-apply the local commits onto the remote branch and retry, when the
+// remote branch moved since it was fetched. The wait between retries starts at backoff and doubles.
+func (g *GoGit) WithPushRetries(retries int, backoff time.Duration) *GoGit {
+	g.pushRetries = retries
+	g.pushBackoff = backoff
+	return g
+}
+
 // Open opens a git repository in the provided path, and returns a repository.
 func (g *GoGit) Open(path string) (*gogit.Repository, error) {
 	g.path = path
@@ -73,14 +92,20 @@ func (g *GoGit) Init(path, url, branch string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	if _, err = r.CreateRemote(&config.RemoteConfig{
+
+	return g.initRepository(r, url, branch)
+}
+
+// initRepository sets the remote and branch of a newly initialised repository
+func (g *GoGit) initRepository(r *gogit.Repository, url, branch string) (bool, error) {
+	if _, err := r.CreateRemote(&config.RemoteConfig{
 		Name: gogit.DefaultRemoteName,
 		URLs: []string{url},
 	}); err != nil {
 		return false, err
 	}
 	branchRef := plumbing.NewBranchReferenceName(branch)
-	if err = r.CreateBranch(&config.Branch{
+	if err := r.CreateBranch(&config.Branch{
 		Name:   branch,
 		Remote: gogit.DefaultRemoteName,
 		Merge:  branchRef,
@@ -91,7 +116,7 @@ func (g *GoGit) Init(path, url, branch string) (bool, error) {
 	// overwrite this by setting the reference of the Storer to a new
 	// symbolic reference (as there are no commits yet) that points
 	// the HEAD to our new branch.
-	if err = r.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branchRef)); err != nil {
+	if err := r.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branchRef)); err != nil {
 		return false, err
 	}
 
@@ -105,21 +130,40 @@ func (g *GoGit) Init(path, url, branch string) (bool, error) {
 // If the directory is successfully initialised, it returns true, otherwise it
 // returns false.
 func (g *GoGit) Clone(ctx context.Context, path, url, branch string) (bool, error) {
+	return g.CloneWithOptions(ctx, path, url, branch, CloneOptions{})
+}
+
+// CloneWithOptions clones a starting repository URL like Clone, fetching only
+// the history and storing the repository as set in the options. An in-memory
+// repository ignores the path.
+func (g *GoGit) CloneWithOptions(ctx context.Context, path, url, branch string, opts CloneOptions) (bool, error) {
 	g.path = path
 	branchRef := plumbing.NewBranchReferenceName(branch)
-	r, err := gogit.PlainCloneContext(ctx, path, false, &gogit.CloneOptions{
+	cloneOptions := &gogit.CloneOptions{
 		URL:           url,
 		Auth:          g.auth,
 		RemoteName:    gogit.DefaultRemoteName,
 		ReferenceName: branchRef,
 		SingleBranch:  true,
 		NoCheckout:    false,
+		Depth:         opts.Depth,
 		Progress:      nil,
 		Tags:          gogit.NoTags,
-	})
+	}
+
+	var r *gogit.Repository
+	var err error
+	if opts.InMemory {
+		r, err = gogit.CloneContext(ctx, memory.NewStorage(), memfs.New(), cloneOptions)
+	} else {
+		r, err = gogit.PlainCloneContext(ctx, path, false, cloneOptions)
+	}
 	if err != nil {
 		if errors.Is(err, transport.ErrEmptyRemoteRepository) ||
 			errors.Is(err, gogit.NoMatchingRefSpecError{}) {
+			if opts.InMemory {
+				return g.initInMemory(url, branch)
+			}
 			return g.Init(path, url, branch)
 		}
 		return false, err
@@ -129,6 +173,15 @@ func (g *GoGit) Clone(ctx context.Context, path, url, branch string) (bool, erro
 	return true, nil
 }
 
+func (g *GoGit) initInMemory(url, branch string) (bool, error) {
+	r, err := gogit.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		return false, err
+	}
+
+	return g.initRepository(r, url, branch)
+}
+
 // Write writes the provided content to the path, if the file exists, it will be
 // truncated.
 func (g *GoGit) Write(path string, content []byte) error {
@@ -186,8 +239,7 @@ func (g *GoGit) Commit(message Commit, filters ...func(string) bool) (string, er
 	// change to a broken symlink: so, detect and skip those.
 	var changed bool
 	for file := range status {
-		abspath := filepath.Join(g.path, file)
-		isLink, err := isSymLink(abspath)
+		isLink, err := isSymLink(wt.Filesystem, file)
 		if err != nil {
 			return "", err
 		}
@@ -195,7 +247,7 @@ func (g *GoGit) Commit(message Commit, filters ...func(string) bool) (string, er
 			// symlinks are OK; broken symlinks are probably a result
 			// of the bug mentioned above, but not of interest in any
 			// case.
-			if _, err := os.Stat(abspath); os.IsNotExist(err) {
+			if _, err := wt.Filesystem.Stat(file); os.IsNotExist(err) {
 				continue
 			}
 		}
@@ -219,6 +271,16 @@ func (g *GoGit) Commit(message Commit, filters ...func(string) bool) (string, er
 		return head.Hash().String(), ErrNoStagedFiles
 	}
 
+	commit, err := g.commitStaged(wt, message)
+	if err != nil {
+		return "", err
+	}
+
+	return commit.String(), nil
+}
+
+// commitStaged commits the staged changes of the worktree, signing the commit if a signing key is set
+func (g *GoGit) commitStaged(wt *gogit.Worktree, message Commit) (plumbing.Hash, error) {
 	commit, err := wt.Commit(message.Message, &gogit.CommitOptions{
 		Author: &object.Signature{
 			Name:  message.Name,
@@ -227,16 +289,101 @@ func (g *GoGit) Commit(message Commit, filters ...func(string) bool) (string, er
 		},
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to commit changes: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to commit changes: %w", err)
 	}
-	return commit.String(), nil
+
+	if g.signingKey != nil {
+		return g.signCommit(commit)
+	}
+
+	return commit, nil
 }
 
+// signCommit replaces the commit at HEAD with a signed copy of it. go-git can only sign
+// with openpgp keys, so the signature is added here for both key types.
+func (g *GoGit) signCommit(hash plumbing.Hash) (plumbing.Hash, error) {
+	commit, err := g.repository.CommitObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read commit: %w", err)
+	}
+
+	unsigned := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(unsigned); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	reader, err := unsigned.Reader()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	commit.PGPSignature, err = g.signingKey.Sign(payload)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	signed := g.repository.Storer.NewEncodedObject()
+	if err := commit.Encode(signed); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode signed commit: %w", err)
+	}
+
+	signedHash, err := g.repository.Storer.SetEncodedObject(signed)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store signed commit: %w", err)
+	}
+
+	head, err := g.repository.Head()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get the worktree HEAD reference: %w", err)
+	}
+
+	if err := g.repository.Storer.SetReference(plumbing.NewHashReference(head.Name(), signedHash)); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to update %s: %w", head.Name(), err)
+	}
+
+	return signedHash, nil
+}
+
+// Push pushes the local commits to the remote branch. When push retries are set and the remote
+// branch moved, the local commits are re-applied onto it before pushing again.
 func (g *GoGit) Push(ctx context.Context) error {
 	if g.repository == nil {
 		return ErrNoGitRepository
 	}
 
+	err := g.push(ctx)
+
+	for attempt := 0; err != nil && attempt < g.pushRetries; attempt++ {
+		moved, movedErr := g.remoteBranchMoved(ctx)
+		if movedErr != nil || !moved {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(g.pushBackoff << attempt):
+		}
+
+		if err := g.rebaseOnRemote(ctx); err != nil {
+			return err
+		}
+
+		// The remote branch may already have all the local changes
+		if err = g.push(ctx); errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+			return nil
+		}
+	}
+
+	return err
+}
+
+func (g *GoGit) push(ctx context.Context) error {
 	return g.repository.PushContext(ctx, &gogit.PushOptions{
 		RemoteName: gogit.DefaultRemoteName,
 		Auth:       g.auth,
@@ -271,8 +418,52 @@ func (g *GoGit) Head() (string, error) {
 	return head.Hash().String(), nil
 }
 
-func isSymLink(fname string) (bool, error) {
-	info, err := os.Lstat(fname)
+// BranchHasCommit reports whether a commit is in the last depth commits of a remote branch, or in its whole
+// history when depth is 0. The branch is fetched into a repository of its own, leaving the cloned one as it is.
+func (g *GoGit) BranchHasCommit(ctx context.Context, url, branch, hash string, depth int) (bool, error) {
+	r, err := gogit.CloneContext(ctx, memory.NewStorage(), nil, &gogit.CloneOptions{
+		URL:           url,
+		Auth:          g.auth,
+		RemoteName:    gogit.DefaultRemoteName,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+		NoCheckout:    true,
+		Depth:         depth,
+		Tags:          gogit.NoTags,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch branch %s of %s: %w", branch, url, err)
+	}
+
+	// Only the commits of the fetched branch are stored
+	if _, err := r.CommitObject(plumbing.NewHash(hash)); err != nil {
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ListRemoteRefs lists the branches and tags of a remote repository without cloning it
+func (g *GoGit) ListRemoteRefs(ctx context.Context, url string) ([]*plumbing.Reference, error) {
+	remote := gogit.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: gogit.DefaultRemoteName,
+		URLs: []string{url},
+	})
+
+	refs, err := remote.ListContext(ctx, &gogit.ListOptions{Auth: g.auth})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the refs of %s: %w", url, err)
+	}
+
+	return refs, nil
+}
+
+func isSymLink(fs billy.Filesystem, fname string) (bool, error) {
+	info, err := fs.Lstat(fname)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if %s is a symlink: %w", fname, err)
 	}
@@ -84,6 +84,83 @@ var _ = Describe("Clone", func() {
 	})
 })
 
+var _ = Describe("CloneWithOptions", func() {
+	var remoteDir string
+
+	BeforeEach(func() {
+		sourceDir := dir + "/source"
+		remoteDir = dir + "/remote.git"
+
+		_, err := gitClient.Init(sourceDir, "https://github.com/github/gitignore", "main")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		for _, content := range []string{"one", "two", "three"} {
+			Expect(gitClient.Write("/test.txt", []byte(content))).To(Succeed())
+
+			_, err = gitClient.Commit(git.Commit{
+				Author:  git.Author{Name: "test", Email: "test@example.com"},
+				Message: "commit " + content,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+		}
+
+		executeCommand(dir, "git", "clone", "--bare", sourceDir, remoteDir)
+	})
+
+	It("clones only the last commits", func() {
+		cloneDir := dir + "/clone"
+
+		_, err := git.New(nil).CloneWithOptions(context.Background(), cloneDir, "file://"+remoteDir, "main", git.CloneOptions{Depth: 1})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		out := executeCommand(cloneDir, "git", "rev-list", "--count", "HEAD")
+		Expect(strings.TrimSpace(string(out))).To(Equal("1"))
+
+		content, err := ioutil.ReadFile(cloneDir + "/test.txt")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(content)).To(Equal("three"))
+	})
+
+	It("clones in memory and pushes commits from it", func() {
+		cloneDir := dir + "/clone"
+		client := git.New(nil)
+
+		_, err := client.CloneWithOptions(context.Background(), cloneDir, "file://"+remoteDir, "main", git.CloneOptions{Depth: 1, InMemory: true})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, err = os.Stat(cloneDir)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+
+		Expect(client.Write("/.wego/app.yaml", []byte("app"))).To(Succeed())
+
+		hash, err := client.Commit(git.Commit{
+			Author:  git.Author{Name: "test", Email: "test@example.com"},
+			Message: "add app",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(client.Push(context.Background())).To(Succeed())
+
+		out := executeCommand(remoteDir, "git", "rev-parse", "main")
+		Expect(strings.TrimSpace(string(out))).To(Equal(hash))
+
+		out = executeCommand(remoteDir, "git", "show", "main:test.txt")
+		Expect(string(out)).To(Equal("three"))
+	})
+
+	It("initializes an in-memory repository if the remote branch is not found", func() {
+		client := git.New(nil)
+
+		_, err := client.CloneWithOptions(context.Background(), dir+"/clone", "file://"+remoteDir, "new-branch", git.CloneOptions{InMemory: true})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(client.Write("/test.txt", []byte("testing"))).To(Succeed())
+
+		_, err = os.Stat(dir + "/clone")
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
+
 var _ = Describe("Write", func() {
 	It("writes a file into a given repository", func() {
 		_, err = gitClient.Init(dir, "https://github.com/github/gitignore", "master")
@@ -315,6 +392,99 @@ var _ = Describe("Push", func() {
 	})
 })
 
+var _ = Describe("BranchHasCommit", func() {
+	var hashes []string
+
+	BeforeEach(func() {
+		_, err = gitClient.Init(dir, "https://github.com/github/gitignore", "main")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		hashes = []string{}
+
+		for _, content := range []string{"first", "second"} {
+			Expect(gitClient.Write("/test.txt", []byte(content))).To(Succeed())
+
+			hash, err := gitClient.Commit(git.Commit{
+				Author:  git.Author{Name: "test", Email: "test@example.com"},
+				Message: content,
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			hashes = append(hashes, hash)
+		}
+	})
+
+	It("finds the commits in the history of the branch", func() {
+		client := git.New(nil)
+
+		for _, hash := range hashes {
+			Expect(client.BranchHasCommit(context.Background(), dir, "main", hash, 0)).To(BeTrue())
+		}
+
+		Expect(client.BranchHasCommit(context.Background(), dir, "main", "3f2b5d4e1c0a9b8f7e6d5c4b3a2f1e0d9c8b7a6f", 0)).To(BeFalse())
+	})
+
+	It("only looks at the given number of commits", func() {
+		client := git.New(nil)
+
+		Expect(client.BranchHasCommit(context.Background(), dir, "main", hashes[1], 1)).To(BeTrue())
+		Expect(client.BranchHasCommit(context.Background(), dir, "main", hashes[0], 1)).To(BeFalse())
+	})
+
+	It("leaves the cloned repository as it is", func() {
+		client := git.New(nil)
+		_, err := client.CloneWithOptions(context.Background(), "", dir, "main", git.CloneOptions{InMemory: true})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(client.Write("/local.txt", []byte("local"))).To(Succeed())
+
+		Expect(client.BranchHasCommit(context.Background(), dir, "main", hashes[0], 0)).To(BeTrue())
+
+		// The local change is still in the worktree
+		clean, err := client.Status()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(clean).To(BeFalse())
+	})
+
+	It("fails when the branch does not exist", func() {
+		_, err := git.New(nil).BranchHasCommit(context.Background(), dir, "release", hashes[0], 0)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ListRemoteRefs", func() {
+	It("lists the branches and tags of a remote repository", func() {
+		_, err = gitClient.Init(dir, "https://github.com/github/gitignore", "main")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(gitClient.Write("/test.txt", []byte("testing"))).To(Succeed())
+
+		hash, err := gitClient.Commit(git.Commit{
+			Author:  git.Author{Name: "test", Email: "test@example.com"},
+			Message: "test commit",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		executeCommand(dir, "git", "tag", "v1.0.0")
+
+		refs, err := git.New(nil).ListRemoteRefs(context.Background(), dir)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		names := map[string]string{}
+		for _, ref := range refs {
+			names[ref.Name().String()] = ref.Hash().String()
+		}
+
+		Expect(names).To(HaveKeyWithValue("refs/heads/main", hash))
+		Expect(names).To(HaveKeyWithValue("refs/tags/v1.0.0", hash))
+	})
+
+	It("fails when the remote does not exist", func() {
+		_, err := gitClient.ListRemoteRefs(context.Background(), dir+"/missing")
+		Expect(err).Should(HaveOccurred())
+	})
+})
+
 func executeCommand(workingDir, cmd string, args ...string) []byte {
 	c := exec.Command(cmd, args...)
 	c.Dir = workingDir
@@ -0,0 +1,379 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// PushConflictError is returned by Push when the remote branch changed files that the local commits change too
+type PushConflictError struct {
+	Branch string
+	Files  []string
+}
+
+func (e *PushConflictError) Error() string {
+	return fmt.Sprintf("the remote branch %s changed files that are also changed locally: %s", e.Branch, strings.Join(e.Files, ", "))
+}
+
+// fileChange is the content a commit wrote to a file, or its deletion
+type fileChange struct {
+	path    string
+	content []byte
+	deleted bool
+}
+
+// remoteBranchMoved tells whether the remote branch points to another commit than when it was last fetched
+func (g *GoGit) remoteBranchMoved(ctx context.Context) (bool, error) {
+	head, err := g.repository.Head()
+	if err != nil {
+		return false, fmt.Errorf("failed to get the worktree HEAD reference: %w", err)
+	}
+
+	remote, err := g.repository.Remote(gogit.DefaultRemoteName)
+	if err != nil {
+		return false, err
+	}
+
+	refs, err := remote.ListContext(ctx, &gogit.ListOptions{Auth: g.auth})
+	if err != nil {
+		return false, err
+	}
+
+	tracking, err := g.repository.Reference(remoteTrackingName(head.Name()), true)
+	if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return false, err
+	}
+
+	for _, ref := range refs {
+		if ref.Name() == head.Name() {
+			return tracking == nil || tracking.Hash() != ref.Hash(), nil
+		}
+	}
+
+	return false, nil
+}
+
+// rebaseOnRemote fetches the remote branch and re-applies the files changed by the local commits onto it,
+// keeping their messages and authors. It fails without changing the repository if the remote branch
+// changed any of those files.
+func (g *GoGit) rebaseOnRemote(ctx context.Context) error {
+	wt, err := g.repository.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open the worktree: %w", err)
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get the worktree status: %w", err)
+	}
+
+	for file, s := range status {
+		if (s.Staging != gogit.Unmodified && s.Staging != gogit.Untracked) || (s.Worktree != gogit.Unmodified && s.Worktree != gogit.Untracked) {
+			return fmt.Errorf("failed to re-apply the local commits onto the remote branch: %s has uncommitted changes", file)
+		}
+	}
+
+	head, err := g.repository.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get the worktree HEAD reference: %w", err)
+	}
+
+	trackingName := remoteTrackingName(head.Name())
+
+	base := plumbing.ZeroHash
+	if tracking, err := g.repository.Reference(trackingName, true); err == nil {
+		base = tracking.Hash()
+	}
+
+	commits, err := g.localCommits(head.Hash(), base)
+	if err != nil {
+		return err
+	}
+
+	changes := make([][]fileChange, len(commits))
+	for i, commit := range commits {
+		if changes[i], err = commitChanges(commit); err != nil {
+			return err
+		}
+	}
+
+	err = g.repository.FetchContext(ctx, &gogit.FetchOptions{
+		RemoteName: gogit.DefaultRemoteName,
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", head.Name(), trackingName))},
+		Auth:       g.auth,
+		Tags:       gogit.NoTags,
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to fetch %s: %w", head.Name().Short(), err)
+	}
+
+	tracking, err := g.repository.Reference(trackingName, true)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", trackingName, err)
+	}
+
+	if err := g.checkConflicts(base, tracking.Hash(), head.Name().Short(), changes); err != nil {
+		return err
+	}
+
+	if err := wt.Reset(&gogit.ResetOptions{Commit: tracking.Hash(), Mode: gogit.HardReset}); err != nil {
+		return fmt.Errorf("failed to reset to %s: %w", trackingName, err)
+	}
+
+	for i, commit := range commits {
+		if err := g.applyChanges(wt, commit, changes[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// localCommits returns the commits from base to head, oldest first
+func (g *GoGit) localCommits(head, base plumbing.Hash) ([]*object.Commit, error) {
+	commits := []*object.Commit{}
+
+	for hash := head; hash != base; {
+		commit, err := g.repository.CommitObject(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read local commit %s: %w", hash, err)
+		}
+
+		commits = append([]*object.Commit{commit}, commits...)
+
+		if commit.NumParents() > 1 {
+			return nil, fmt.Errorf("failed to re-apply the local commits onto the remote branch: %s is a merge commit", hash)
+		}
+
+		if commit.NumParents() == 0 {
+			if base != plumbing.ZeroHash {
+				return nil, fmt.Errorf("failed to re-apply the local commits onto the remote branch: they are not based on %s", base)
+			}
+
+			break
+		}
+
+		hash = commit.ParentHashes[0]
+	}
+
+	return commits, nil
+}
+
+// commitChanges returns the files written and deleted by a commit
+func commitChanges(commit *object.Commit) ([]fileChange, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tree of %s: %w", commit.Hash, err)
+	}
+
+	var parentTree *object.Tree
+
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the parent of %s: %w", commit.Hash, err)
+		}
+
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to read the tree of %s: %w", parent.Hash, err)
+		}
+	}
+
+	diff, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s: %w", commit.Hash, err)
+	}
+
+	changes := []fileChange{}
+
+	for _, change := range diff {
+		_, to, err := change.Files()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the changes of %s: %w", commit.Hash, err)
+		}
+
+		if to == nil {
+			changes = append(changes, fileChange{path: change.From.Name, deleted: true})
+			continue
+		}
+
+		content, err := fileContents(to)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, fileChange{path: change.To.Name, content: content})
+	}
+
+	return changes, nil
+}
+
+// checkConflicts fails when the remote branch changed a file since base to something else than the local commits did
+func (g *GoGit) checkConflicts(base, remote plumbing.Hash, branch string, changes [][]fileChange) error {
+	local := map[string]fileChange{}
+
+	for _, commitChanges := range changes {
+		for _, change := range commitChanges {
+			local[change.path] = change
+		}
+	}
+
+	var baseTree *object.Tree
+
+	if base != plumbing.ZeroHash {
+		baseCommit, err := g.repository.CommitObject(base)
+		if err != nil {
+			return fmt.Errorf("failed to read commit %s: %w", base, err)
+		}
+
+		if baseTree, err = baseCommit.Tree(); err != nil {
+			return fmt.Errorf("failed to read the tree of %s: %w", base, err)
+		}
+	}
+
+	remoteCommit, err := g.repository.CommitObject(remote)
+	if err != nil {
+		return fmt.Errorf("failed to read commit %s: %w", remote, err)
+	}
+
+	remoteTree, err := remoteCommit.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to read the tree of %s: %w", remote, err)
+	}
+
+	conflicts := []string{}
+
+	for path, change := range local {
+		baseChange, err := treeFile(baseTree, path)
+		if err != nil {
+			return err
+		}
+
+		remoteChange, err := treeFile(remoteTree, path)
+		if err != nil {
+			return err
+		}
+
+		if !sameChange(baseChange, remoteChange) && !sameChange(remoteChange, change) {
+			conflicts = append(conflicts, path)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return &PushConflictError{Branch: branch, Files: conflicts}
+	}
+
+	return nil
+}
+
+// applyChanges writes the changes of a local commit to the worktree and commits them again
+func (g *GoGit) applyChanges(wt *gogit.Worktree, commit *object.Commit, changes []fileChange) error {
+	for _, change := range changes {
+		if change.deleted {
+			if _, err := wt.Remove(change.path); err != nil && !errors.Is(err, index.ErrEntryNotFound) {
+				return fmt.Errorf("failed to remove %s: %w", change.path, err)
+			}
+
+			continue
+		}
+
+		f, err := wt.Filesystem.Create(change.path)
+		if err != nil {
+			return fmt.Errorf("failed to create file in %s: %w", change.path, err)
+		}
+
+		_, err = f.Write(change.content)
+		f.Close()
+
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", change.path, err)
+		}
+
+		if _, err := wt.Add(change.path); err != nil {
+			return fmt.Errorf("failed to add %s: %w", change.path, err)
+		}
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get the worktree status: %w", err)
+	}
+
+	staged := false
+
+	for _, change := range changes {
+		if s, ok := status[change.path]; ok && s.Staging != gogit.Unmodified && s.Staging != gogit.Untracked {
+			staged = true
+		}
+	}
+
+	// The remote branch already has the same changes
+	if !staged {
+		return nil
+	}
+
+	_, err = g.commitStaged(wt, Commit{
+		Author:  Author{Name: commit.Author.Name, Email: commit.Author.Email},
+		Message: commit.Message,
+	})
+
+	return err
+}
+
+func remoteTrackingName(branch plumbing.ReferenceName) plumbing.ReferenceName {
+	return plumbing.NewRemoteReferenceName(gogit.DefaultRemoteName, branch.Short())
+}
+
+// treeFile returns the content of a file in a tree, or a deletion if the tree doesn't have it
+func treeFile(tree *object.Tree, path string) (fileChange, error) {
+	if tree == nil {
+		return fileChange{path: path, deleted: true}, nil
+	}
+
+	file, err := tree.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return fileChange{path: path, deleted: true}, nil
+	}
+
+	if err != nil {
+		return fileChange{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	content, err := fileContents(file)
+	if err != nil {
+		return fileChange{}, err
+	}
+
+	return fileChange{path: path, content: content}, nil
+}
+
+func fileContents(file *object.File) ([]byte, error) {
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+
+	return content, nil
+}
+
+func sameChange(a, b fileChange) bool {
+	return a.deleted == b.deleted && bytes.Equal(a.content, b.content)
+}
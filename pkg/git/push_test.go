@@ -0,0 +1,150 @@
+package git_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+)
+
+var _ = Describe("Push with retries", func() {
+	var (
+		remoteURL string
+		remoteDir string
+		author    = git.Author{Name: "test", Email: "test@example.com"}
+	)
+
+	commitFile := func(client git.Git, path, content string) {
+		Expect(client.Write(path, []byte(content))).To(Succeed())
+
+		_, err := client.Commit(git.Commit{Author: author, Message: "update " + path})
+		Expect(err).ShouldNot(HaveOccurred())
+	}
+
+	cloneRemote := func(name string, opts git.CloneOptions) *git.GoGit {
+		client := git.New(nil).WithPushRetries(3, time.Millisecond)
+
+		_, err := client.CloneWithOptions(context.Background(), dir+"/"+name, remoteURL, "main", opts)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		return client
+	}
+
+	showRemote := func(path string) string {
+		return string(executeCommand(remoteDir, "git", "show", "main:"+path))
+	}
+
+	BeforeEach(func() {
+		sourceDir := dir + "/source"
+		remoteDir = dir + "/remote.git"
+		remoteURL = "file://" + remoteDir
+
+		_, err := gitClient.Init(sourceDir, "https://github.com/github/gitignore", "main")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		commitFile(gitClient, "/README.md", "readme")
+
+		executeCommand(dir, "git", "clone", "--bare", sourceDir, remoteDir)
+	})
+
+	It("re-applies the local commits when the remote branch moved", func() {
+		client := cloneRemote("clone", git.CloneOptions{})
+		other := cloneRemote("other", git.CloneOptions{})
+
+		commitFile(other, "/.wego/apps/other/app.yaml", "other")
+		Expect(other.Push(context.Background())).To(Succeed())
+
+		commitFile(client, "/.wego/apps/mine/app.yaml", "mine")
+		Expect(client.Push(context.Background())).To(Succeed())
+
+		Expect(showRemote(".wego/apps/other/app.yaml")).To(Equal("other"))
+		Expect(showRemote(".wego/apps/mine/app.yaml")).To(Equal("mine"))
+
+		out := executeCommand(remoteDir, "git", "log", "-1", "--format=%an %s", "main")
+		Expect(strings.TrimSpace(string(out))).To(Equal("test update /.wego/apps/mine/app.yaml"))
+	})
+
+	It("fetches the moved branch into a shallow clone and rebases the local commits onto it", func() {
+		client := cloneRemote("clone", git.CloneOptions{Depth: 1})
+		other := cloneRemote("other", git.CloneOptions{Depth: 1})
+
+		commitFile(other, "/.wego/apps/other/app.yaml", "other")
+		Expect(other.Push(context.Background())).To(Succeed())
+
+		commitFile(client, "/.wego/apps/mine/app.yaml", "mine")
+		Expect(client.Push(context.Background())).To(Succeed())
+
+		Expect(showRemote(".wego/apps/other/app.yaml")).To(Equal("other"))
+		Expect(showRemote(".wego/apps/mine/app.yaml")).To(Equal("mine"))
+
+		out := executeCommand(remoteDir, "git", "log", "--format=%s", "main")
+		Expect(strings.Split(strings.TrimSpace(string(out)), "\n")).To(Equal([]string{
+			"update /.wego/apps/mine/app.yaml",
+			"update /.wego/apps/other/app.yaml",
+			"update /README.md",
+		}))
+
+		local := executeCommand(dir+"/clone", "git", "log", "-1", "--format=%s")
+		Expect(strings.TrimSpace(string(local))).To(Equal("update /.wego/apps/mine/app.yaml"))
+	})
+
+	It("re-applies the local commits of shallow in-memory clones", func() {
+		client := cloneRemote("clone", git.CloneOptions{Depth: 1, InMemory: true})
+		other := cloneRemote("other", git.CloneOptions{Depth: 1, InMemory: true})
+
+		commitFile(other, "/.wego/apps/other/app.yaml", "other")
+		Expect(other.Push(context.Background())).To(Succeed())
+
+		commitFile(client, "/.wego/apps/mine/app.yaml", "mine")
+		Expect(client.Push(context.Background())).To(Succeed())
+
+		Expect(showRemote(".wego/apps/other/app.yaml")).To(Equal("other"))
+		Expect(showRemote(".wego/apps/mine/app.yaml")).To(Equal("mine"))
+	})
+
+	It("reports the files changed on both sides", func() {
+		client := cloneRemote("clone", git.CloneOptions{})
+		other := cloneRemote("other", git.CloneOptions{})
+
+		commitFile(other, "/.wego/apps/app/app.yaml", "other")
+		Expect(other.Push(context.Background())).To(Succeed())
+
+		commitFile(client, "/.wego/apps/app/app.yaml", "mine")
+		err := client.Push(context.Background())
+
+		var conflict *git.PushConflictError
+		Expect(errors.As(err, &conflict)).To(BeTrue())
+		Expect(conflict.Files).To(Equal([]string{".wego/apps/app/app.yaml"}))
+		Expect(err).To(MatchError("the remote branch main changed files that are also changed locally: .wego/apps/app/app.yaml"))
+
+		Expect(showRemote(".wego/apps/app/app.yaml")).To(Equal("other"))
+	})
+
+	It("does not conflict when both sides made the same change", func() {
+		client := cloneRemote("clone", git.CloneOptions{})
+		other := cloneRemote("other", git.CloneOptions{})
+
+		commitFile(other, "/.wego/apps/app/app.yaml", "same")
+		Expect(other.Push(context.Background())).To(Succeed())
+
+		commitFile(client, "/.wego/apps/app/app.yaml", "same")
+		Expect(client.Push(context.Background())).To(Succeed())
+	})
+
+	It("fails without retries", func() {
+		client := git.New(nil)
+		_, err := client.Clone(context.Background(), dir+"/clone", remoteURL, "main")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		other := cloneRemote("other", git.CloneOptions{})
+		commitFile(other, "/other.txt", "other")
+		Expect(other.Push(context.Background())).To(Succeed())
+
+		commitFile(client, "/mine.txt", "mine")
+		Expect(client.Push(context.Background())).To(MatchError("non-fast-forward update: refs/heads/main"))
+	})
+})
@@ -0,0 +1,175 @@
+package git
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/ssh"
+)
+
+type SigningKeyType string
+
+const (
+	SigningKeyOpenPGP SigningKeyType = "openpgp"
+	SigningKeySSH     SigningKeyType = "ssh"
+
+	// Namespace and hash git uses for ssh signatures, see PROTOCOL.sshsig in openssh
+	sshSigNamespace     = "git"
+	sshSigHashAlgorithm = "sha512"
+	sshSigMagic         = "SSHSIG"
+	sshSigVersion       = 1
+	sshSigLineLength    = 70
+)
+
+// SigningKey signs the commits created by GoGit
+type SigningKey interface {
+	// Sign returns the armored signature of a commit, stored in its gpgsig header
+	Sign(payload []byte) (string, error)
+}
+
+// NewSigningKey loads a private key used to sign commits. The key type is detected from the key
+// when keyType is empty.
+func NewSigningKey(keyType SigningKeyType, key []byte, passphrase string) (SigningKey, error) {
+	if keyType == "" {
+		keyType = SigningKeySSH
+		if bytes.Contains(key, []byte("BEGIN PGP PRIVATE KEY BLOCK")) {
+			keyType = SigningKeyOpenPGP
+		}
+	}
+
+	switch keyType {
+	case SigningKeyOpenPGP:
+		return NewOpenPGPSigningKey(key, passphrase)
+	case SigningKeySSH:
+		return NewSSHSigningKey(key, passphrase)
+	default:
+		return nil, fmt.Errorf("invalid signing key type %q, expected openpgp or ssh", keyType)
+	}
+}
+
+type openPGPSigningKey struct {
+	entity *openpgp.Entity
+}
+
+// NewOpenPGPSigningKey loads an armored OpenPGP private key
+func NewOpenPGPSigningKey(armoredKey []byte, passphrase string) (SigningKey, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read openpgp key: %w", err)
+	}
+
+	if len(entities) == 0 || entities[0].PrivateKey == nil {
+		return nil, errors.New("openpgp key does not contain a private key")
+	}
+
+	entity := entities[0]
+
+	if entity.PrivateKey.Encrypted {
+		if err := entity.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt openpgp key: %w", err)
+		}
+	}
+
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+			if err := subkey.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+				return nil, fmt.Errorf("failed to decrypt openpgp subkey: %w", err)
+			}
+		}
+	}
+
+	return &openPGPSigningKey{entity: entity}, nil
+}
+
+func (k *openPGPSigningKey) Sign(payload []byte) (string, error) {
+	var signature bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&signature, k.entity, bytes.NewReader(payload), nil); err != nil {
+		return "", fmt.Errorf("failed to sign commit: %w", err)
+	}
+
+	return signature.String(), nil
+}
+
+type sshSigningKey struct {
+	signer ssh.Signer
+}
+
+// NewSSHSigningKey loads an ssh private key in PEM or OpenSSH format
+func NewSSHSigningKey(key []byte, passphrase string) (SigningKey, error) {
+	var signer ssh.Signer
+
+	var err error
+
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ssh key: %w", err)
+	}
+
+	return &sshSigningKey{signer: signer}, nil
+}
+
+func (k *sshSigningKey) Sign(payload []byte) (string, error) {
+	hash := sha512.Sum512(payload)
+
+	signedData := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sshSigNamespace, "", sshSigHashAlgorithm, hash[:]})...)
+
+	var signature *ssh.Signature
+
+	var err error
+
+	// ssh-rsa signatures use sha1, which git refuses to verify
+	if algorithmSigner, ok := k.signer.(ssh.AlgorithmSigner); ok && k.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, signedData, ssh.SigAlgoRSASHA2512)
+	} else {
+		signature, err = k.signer.Sign(rand.Reader, signedData)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to sign commit: %w", err)
+	}
+
+	blob := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{sshSigVersion, k.signer.PublicKey().Marshal(), sshSigNamespace, "", sshSigHashAlgorithm, ssh.Marshal(signature)})...)
+
+	return armorSSHSignature(blob), nil
+}
+
+func armorSSHSignature(blob []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(blob)
+
+	var armored strings.Builder
+
+	armored.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+
+	for len(encoded) > sshSigLineLength {
+		armored.WriteString(encoded[:sshSigLineLength] + "\n")
+		encoded = encoded[sshSigLineLength:]
+	}
+
+	armored.WriteString(encoded + "\n")
+	armored.WriteString("-----END SSH SIGNATURE-----\n")
+
+	return armored.String()
+}
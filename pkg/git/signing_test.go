@@ -0,0 +1,111 @@
+package git_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	gogit "github.com/go-git/go-git/v5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+)
+
+var _ = Describe("Commit signing", func() {
+	var keyDir string
+
+	BeforeEach(func() {
+		keyDir, err = ioutil.TempDir("", "wego-git-keys-")
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(keyDir)).To(Succeed())
+	})
+
+	commitWithKey := func(key git.SigningKey) string {
+		client := git.New(nil).WithSigningKey(key)
+
+		_, err := client.Init(dir, "https://github.com/github/gitignore", "master")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(client.Write("test.txt", []byte("testing"))).To(Succeed())
+
+		hash, err := client.Commit(git.Commit{
+			Author:  git.Author{Name: "test", Email: "test@example.com"},
+			Message: "signed commit",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		return hash
+	}
+
+	It("signs commits with an openpgp key", func() {
+		entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		var privateKey bytes.Buffer
+		w, err := armor.Encode(&privateKey, openpgp.PrivateKeyType, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(entity.SerializePrivate(w, nil)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+
+		var publicKey bytes.Buffer
+		w, err = armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(entity.Serialize(w)).To(Succeed())
+		Expect(w.Close()).To(Succeed())
+
+		key, err := git.NewSigningKey("", privateKey.Bytes(), "")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		hash := commitWithKey(key)
+
+		repo, err := gogit.PlainOpen(dir)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		head, err := repo.Head()
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(head.Hash().String()).To(Equal(hash))
+
+		commit, err := repo.CommitObject(head.Hash())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(commit.PGPSignature).To(HavePrefix("-----BEGIN PGP SIGNATURE-----"))
+
+		_, err = commit.Verify(publicKey.String())
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("signs commits with an ssh key", func() {
+		keyPath := filepath.Join(keyDir, "id_ed25519")
+		executeCommand(keyDir, "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test@example.com", "-f", keyPath)
+
+		privateKey, err := ioutil.ReadFile(keyPath)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		publicKey, err := ioutil.ReadFile(keyPath + ".pub")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		allowedSigners := filepath.Join(keyDir, "allowed_signers")
+		Expect(ioutil.WriteFile(allowedSigners, []byte("test@example.com "+string(publicKey)), 0600)).To(Succeed())
+
+		key, err := git.NewSigningKey("", privateKey, "")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		commitWithKey(key)
+
+		out := executeCommand(dir, "git", "cat-file", "-p", "HEAD")
+		Expect(string(out)).To(ContainSubstring("gpgsig -----BEGIN SSH SIGNATURE-----"))
+
+		executeCommand(dir, "git", "-c", "gpg.format=ssh", "-c", fmt.Sprintf("gpg.ssh.allowedSignersFile=%s", allowedSigners), "verify-commit", "HEAD")
+	})
+
+	It("fails for an invalid key type", func() {
+		_, err := git.NewSigningKey("x509", []byte("key"), "")
+		Expect(err).Should(MatchError(`invalid signing key type "x509", expected openpgp or ssh`))
+	})
+})
@@ -0,0 +1,88 @@
+package gitproviders
+
+import (
+	"context"
+	"fmt"
+
+	gogithub "github.com/google/go-github/v32/github"
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+type CommitStatusState string
+
+const (
+	CommitStatusPending CommitStatusState = "pending"
+	CommitStatusSuccess CommitStatusState = "success"
+	CommitStatusFailure CommitStatusState = "failure"
+)
+
+// CommitStatus is the check shown next to a commit in the git provider
+type CommitStatus struct {
+	State CommitStatusState
+	// Context identifies the check, so later statuses with the same context replace it
+	Context     string
+	Description string
+	TargetURL   string
+}
+
+func (p defaultGitProvider) CreateCommitStatus(owner, repoName, sha string, status CommitStatus) error {
+	ctx := context.Background()
+
+	switch client := p.provider.Raw().(type) {
+	case *gogithub.Client:
+		return createGitHubCommitStatus(ctx, client, owner, repoName, sha, status)
+	case *gogitlab.Client:
+		return createGitLabCommitStatus(ctx, client, owner, repoName, sha, status)
+	default:
+		return fmt.Errorf("commit statuses are not supported for provider %s", p.provider.ProviderID())
+	}
+}
+
+func createGitHubCommitStatus(ctx context.Context, client *gogithub.Client, owner, repoName, sha string, status CommitStatus) error {
+	repoStatus := &gogithub.RepoStatus{
+		State:       gogithub.String(string(status.State)),
+		Context:     gogithub.String(status.Context),
+		Description: gogithub.String(status.Description),
+	}
+
+	if status.TargetURL != "" {
+		repoStatus.TargetURL = gogithub.String(status.TargetURL)
+	}
+
+	if _, _, err := client.Repositories.CreateStatus(ctx, owner, repoName, sha, repoStatus); err != nil {
+		return fmt.Errorf("error creating commit status for %s in repo %s/%s: %w", sha, owner, repoName, err)
+	}
+
+	return nil
+}
+
+func createGitLabCommitStatus(ctx context.Context, client *gogitlab.Client, owner, repoName, sha string, status CommitStatus) error {
+	opts := &gogitlab.SetCommitStatusOptions{
+		State:       gitLabBuildState(status.State),
+		Name:        gogitlab.String(status.Context),
+		Description: gogitlab.String(status.Description),
+	}
+
+	if status.TargetURL != "" {
+		opts.TargetURL = gogitlab.String(status.TargetURL)
+	}
+
+	project := fmt.Sprintf("%s/%s", owner, repoName)
+	if _, _, err := client.Commits.SetCommitStatus(project, sha, opts, gogitlab.WithContext(ctx)); err != nil {
+		return fmt.Errorf("error creating commit status for %s in project %s: %w", sha, project, err)
+	}
+
+	return nil
+}
+
+// gitLabBuildState maps a commit status to the GitLab pipeline states, which call failures failed
+func gitLabBuildState(state CommitStatusState) gogitlab.BuildStateValue {
+	switch state {
+	case CommitStatusSuccess:
+		return gogitlab.Success
+	case CommitStatusFailure:
+		return gogitlab.Failed
+	}
+
+	return gogitlab.Pending
+}
@@ -0,0 +1,83 @@
+package gitproviders
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	gogithub "github.com/google/go-github/v32/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+var _ = Describe("Commit statuses", func() {
+	Describe("posting statuses", func() {
+		var (
+			server  *httptest.Server
+			path    string
+			request map[string]interface{}
+		)
+
+		var _ = BeforeEach(func() {
+			request = map[string]interface{}{}
+
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The GitLab client reads the rate limits with a first GET request
+				if r.Method == http.MethodPost {
+					path = r.URL.EscapedPath()
+					Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+				}
+
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte("{}"))
+			}))
+		})
+
+		var _ = AfterEach(func() {
+			server.Close()
+		})
+
+		It("creates a GitHub status", func() {
+			client := gogithub.NewClient(nil)
+			client.BaseURL, _ = url.Parse(server.URL + "/")
+
+			err := createGitHubCommitStatus(context.Background(), client, "foo", "bar", "abc123", CommitStatus{
+				State:       CommitStatusSuccess,
+				Context:     "wego/test-cluster/podinfo",
+				Description: "Applied revision main/abc123",
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(path).To(Equal("/repos/foo/bar/statuses/abc123"))
+			Expect(request).To(Equal(map[string]interface{}{
+				"state":       "success",
+				"context":     "wego/test-cluster/podinfo",
+				"description": "Applied revision main/abc123",
+			}))
+		})
+
+		It("creates a GitLab status, calling failures failed", func() {
+			client, err := gogitlab.NewClient("token", gogitlab.WithBaseURL(server.URL))
+			Expect(err).ShouldNot(HaveOccurred())
+
+			err = createGitLabCommitStatus(context.Background(), client, "foo/team", "bar", "abc123", CommitStatus{
+				State:       CommitStatusFailure,
+				Context:     "wego/test-cluster/podinfo",
+				Description: "Apply failed",
+				TargetURL:   "https://example.com",
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(path).To(Equal("/api/v4/projects/foo%2Fteam%2Fbar/statuses/abc123"))
+			Expect(request).To(Equal(map[string]interface{}{
+				"state":       "failed",
+				"name":        "wego/test-cluster/podinfo",
+				"description": "Apply failed",
+				"target_url":  "https://example.com",
+			}))
+		})
+	})
+})
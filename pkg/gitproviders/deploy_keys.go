@@ -0,0 +1,73 @@
+package gitproviders
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Each cluster syncing from a repository has its own deploy key, so the key of one cluster can be rotated or
+// deleted without touching the others. The first key of a cluster is named weave-gitops-<cluster>-deploy-key,
+// and rotated keys are uploaded next to it with a version suffix, e.g. weave-gitops-prod-deploy-key-2.
+const (
+	deployKeyPrefix      = "weave-gitops-"
+	deployKeySuffix      = "-deploy-key"
+	writeDeployKeySuffix = "-write-key"
+)
+
+// LegacyDeployKeyName is the single deploy key uploaded to a repository before each cluster had its own. It is taken
+// as the first key of the cluster looking it up, so that adding, rotating and purging keys replace it.
+const LegacyDeployKeyName = "weave-gitops-deploy-key"
+
+// DeployKeyName returns the name of the first deploy key uploaded for a cluster
+func DeployKeyName(clusterName string) string {
+	return deployKeyPrefix + clusterName + deployKeySuffix
+}
+
+// WriteDeployKeyName returns the name of the write-enabled deploy key a cluster pushes image updates with.
+// It is kept apart from the read-only keys, which are rotated and replaced without it.
+func WriteDeployKeyName(clusterName string) string {
+	return deployKeyPrefix + clusterName + writeDeployKeySuffix
+}
+
+// IsDeployKeyName reports whether a deploy key was uploaded by weave gitops for a cluster
+func IsDeployKeyName(name string, clusterName string) bool {
+	return deployKeyVersion(name, clusterName) > 0
+}
+
+// NextDeployKeyName returns the name for a key that replaces the given keys of a cluster, or the name of its
+// first key when it has none
+func NextDeployKeyName(clusterName string, existing []string) string {
+	latest := 0
+
+	for _, name := range existing {
+		if version := deployKeyVersion(name, clusterName); version > latest {
+			latest = version
+		}
+	}
+
+	if latest == 0 {
+		return DeployKeyName(clusterName)
+	}
+
+	return fmt.Sprintf("%s-%d", DeployKeyName(clusterName), latest+1)
+}
+
+// deployKeyVersion returns the version of a weave gitops deploy key of a cluster, or 0 for any other key
+func deployKeyVersion(name string, clusterName string) int {
+	if name == DeployKeyName(clusterName) || name == LegacyDeployKeyName {
+		return 1
+	}
+
+	suffix := strings.TrimPrefix(name, DeployKeyName(clusterName)+"-")
+	if suffix == name {
+		return 0
+	}
+
+	version, err := strconv.Atoi(suffix)
+	if err != nil || version < 2 {
+		return 0
+	}
+
+	return version
+}
@@ -0,0 +1,39 @@
+package gitproviders
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Deploy key names", func() {
+	It("recognises the original and rotated deploy keys of a cluster", func() {
+		Expect(IsDeployKeyName("weave-gitops-prod-deploy-key", "prod")).To(BeTrue())
+		Expect(IsDeployKeyName("weave-gitops-prod-deploy-key-2", "prod")).To(BeTrue())
+		Expect(IsDeployKeyName("weave-gitops-prod-deploy-key-1", "prod")).To(BeFalse())
+		Expect(IsDeployKeyName("weave-gitops-prod-deploy-key-old", "prod")).To(BeFalse())
+		Expect(IsDeployKeyName("my-deploy-key", "prod")).To(BeFalse())
+	})
+
+	It("does not recognise the keys of other clusters", func() {
+		Expect(IsDeployKeyName("weave-gitops-staging-deploy-key", "prod")).To(BeFalse())
+		Expect(IsDeployKeyName("weave-gitops-prod-eu-deploy-key", "prod")).To(BeFalse())
+		Expect(IsDeployKeyName("weave-gitops-prod-deploy-key-deploy-key", "prod")).To(BeFalse())
+	})
+
+	It("takes the legacy key of a repository as the first key of the cluster", func() {
+		Expect(IsDeployKeyName(LegacyDeployKeyName, "prod")).To(BeTrue())
+		Expect(NextDeployKeyName("prod", []string{LegacyDeployKeyName})).To(Equal("weave-gitops-prod-deploy-key-2"))
+	})
+
+	It("keeps the write key of a cluster apart from its read-only keys", func() {
+		Expect(WriteDeployKeyName("prod")).To(Equal("weave-gitops-prod-write-key"))
+		Expect(IsDeployKeyName(WriteDeployKeyName("prod"), "prod")).To(BeFalse())
+	})
+
+	It("returns the name following the latest key of the cluster", func() {
+		Expect(NextDeployKeyName("prod", []string{})).To(Equal("weave-gitops-prod-deploy-key"))
+		Expect(NextDeployKeyName("prod", []string{"weave-gitops-prod-deploy-key"})).To(Equal("weave-gitops-prod-deploy-key-2"))
+		Expect(NextDeployKeyName("prod", []string{"weave-gitops-prod-deploy-key-3", "weave-gitops-prod-deploy-key-10"})).To(Equal("weave-gitops-prod-deploy-key-11"))
+		Expect(NextDeployKeyName("prod", []string{"weave-gitops-staging-deploy-key-5"})).To(Equal("weave-gitops-prod-deploy-key"))
+	})
+})
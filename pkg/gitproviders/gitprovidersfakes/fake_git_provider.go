@@ -9,6 +9,20 @@ import (
 )
 
 type FakeGitProvider struct {
+	CreateCommitStatusStub        func(string, string, string, gitproviders.CommitStatus) error
+	createCommitStatusMutex       sync.RWMutex
+	createCommitStatusArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 gitproviders.CommitStatus
+	}
+	createCommitStatusReturns struct {
+		result1 error
+	}
+	createCommitStatusReturnsOnCall map[int]struct {
+		result1 error
+	}
 	CreatePullRequestToOrgRepoStub        func(gitprovider.OrgRepositoryRef, string, string, []gitprovider.CommitFile, string, string, string) (gitprovider.PullRequest, error)
 	createPullRequestToOrgRepoMutex       sync.RWMutex
 	createPullRequestToOrgRepoArgsForCall []struct {
@@ -60,11 +74,25 @@ type FakeGitProvider struct {
 	createRepositoryReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DeployKeyExistsStub        func(string, string) (bool, error)
+	DeleteDeployKeyStub        func(string, string, string) error
+	deleteDeployKeyMutex       sync.RWMutex
+	deleteDeployKeyArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	deleteDeployKeyReturns struct {
+		result1 error
+	}
+	deleteDeployKeyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeployKeyExistsStub        func(string, string, string) (bool, error)
 	deployKeyExistsMutex       sync.RWMutex
 	deployKeyExistsArgsForCall []struct {
 		arg1 string
 		arg2 string
+		arg3 string
 	}
 	deployKeyExistsReturns struct {
 		result1 bool
@@ -87,6 +115,21 @@ type FakeGitProvider struct {
 		result1 gitproviders.ProviderAccountType
 		result2 error
 	}
+	ListDeployKeysStub        func(string, string, string) ([]string, error)
+	listDeployKeysMutex       sync.RWMutex
+	listDeployKeysArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	listDeployKeysReturns struct {
+		result1 []string
+		result2 error
+	}
+	listDeployKeysReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	RepositoryExistsStub        func(string, string) (bool, error)
 	repositoryExistsMutex       sync.RWMutex
 	repositoryExistsArgsForCall []struct {
@@ -101,12 +144,13 @@ type FakeGitProvider struct {
 		result1 bool
 		result2 error
 	}
-	UploadDeployKeyStub        func(string, string, []byte) error
+	UploadDeployKeyStub        func(string, string, string, []byte) error
 	uploadDeployKeyMutex       sync.RWMutex
 	uploadDeployKeyArgsForCall []struct {
 		arg1 string
 		arg2 string
-		arg3 []byte
+		arg3 string
+		arg4 []byte
 	}
 	uploadDeployKeyReturns struct {
 		result1 error
@@ -114,10 +158,88 @@ type FakeGitProvider struct {
 	uploadDeployKeyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	UploadWriteDeployKeyStub        func(string, string, string, []byte) error
+	uploadWriteDeployKeyMutex       sync.RWMutex
+	uploadWriteDeployKeyArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []byte
+	}
+	uploadWriteDeployKeyReturns struct {
+		result1 error
+	}
+	uploadWriteDeployKeyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeGitProvider) CreateCommitStatus(arg1 string, arg2 string, arg3 string, arg4 gitproviders.CommitStatus) error {
+	fake.createCommitStatusMutex.Lock()
+	ret, specificReturn := fake.createCommitStatusReturnsOnCall[len(fake.createCommitStatusArgsForCall)]
+	fake.createCommitStatusArgsForCall = append(fake.createCommitStatusArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 gitproviders.CommitStatus
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.CreateCommitStatusStub
+	fakeReturns := fake.createCommitStatusReturns
+	fake.recordInvocation("CreateCommitStatus", []interface{}{arg1, arg2, arg3, arg4})
+	fake.createCommitStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGitProvider) CreateCommitStatusCallCount() int {
+	fake.createCommitStatusMutex.RLock()
+	defer fake.createCommitStatusMutex.RUnlock()
+	return len(fake.createCommitStatusArgsForCall)
+}
+
+func (fake *FakeGitProvider) CreateCommitStatusCalls(stub func(string, string, string, gitproviders.CommitStatus) error) {
+	fake.createCommitStatusMutex.Lock()
+	defer fake.createCommitStatusMutex.Unlock()
+	fake.CreateCommitStatusStub = stub
+}
+
+func (fake *FakeGitProvider) CreateCommitStatusArgsForCall(i int) (string, string, string, gitproviders.CommitStatus) {
+	fake.createCommitStatusMutex.RLock()
+	defer fake.createCommitStatusMutex.RUnlock()
+	argsForCall := fake.createCommitStatusArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeGitProvider) CreateCommitStatusReturns(result1 error) {
+	fake.createCommitStatusMutex.Lock()
+	defer fake.createCommitStatusMutex.Unlock()
+	fake.CreateCommitStatusStub = nil
+	fake.createCommitStatusReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGitProvider) CreateCommitStatusReturnsOnCall(i int, result1 error) {
+	fake.createCommitStatusMutex.Lock()
+	defer fake.createCommitStatusMutex.Unlock()
+	fake.CreateCommitStatusStub = nil
+	if fake.createCommitStatusReturnsOnCall == nil {
+		fake.createCommitStatusReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createCommitStatusReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeGitProvider) CreatePullRequestToOrgRepo(arg1 gitprovider.OrgRepositoryRef, arg2 string, arg3 string, arg4 []gitprovider.CommitFile, arg5 string, arg6 string, arg7 string) (gitprovider.PullRequest, error) {
 	var arg4Copy []gitprovider.CommitFile
 	if arg4 != nil {
@@ -331,19 +453,83 @@ func (fake *FakeGitProvider) CreateRepositoryReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
-func (fake *FakeGitProvider) DeployKeyExists(arg1 string, arg2 string) (bool, error) {
+func (fake *FakeGitProvider) DeleteDeployKey(arg1 string, arg2 string, arg3 string) error {
+	fake.deleteDeployKeyMutex.Lock()
+	ret, specificReturn := fake.deleteDeployKeyReturnsOnCall[len(fake.deleteDeployKeyArgsForCall)]
+	fake.deleteDeployKeyArgsForCall = append(fake.deleteDeployKeyArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.DeleteDeployKeyStub
+	fakeReturns := fake.deleteDeployKeyReturns
+	fake.recordInvocation("DeleteDeployKey", []interface{}{arg1, arg2, arg3})
+	fake.deleteDeployKeyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGitProvider) DeleteDeployKeyCallCount() int {
+	fake.deleteDeployKeyMutex.RLock()
+	defer fake.deleteDeployKeyMutex.RUnlock()
+	return len(fake.deleteDeployKeyArgsForCall)
+}
+
+func (fake *FakeGitProvider) DeleteDeployKeyCalls(stub func(string, string, string) error) {
+	fake.deleteDeployKeyMutex.Lock()
+	defer fake.deleteDeployKeyMutex.Unlock()
+	fake.DeleteDeployKeyStub = stub
+}
+
+func (fake *FakeGitProvider) DeleteDeployKeyArgsForCall(i int) (string, string, string) {
+	fake.deleteDeployKeyMutex.RLock()
+	defer fake.deleteDeployKeyMutex.RUnlock()
+	argsForCall := fake.deleteDeployKeyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGitProvider) DeleteDeployKeyReturns(result1 error) {
+	fake.deleteDeployKeyMutex.Lock()
+	defer fake.deleteDeployKeyMutex.Unlock()
+	fake.DeleteDeployKeyStub = nil
+	fake.deleteDeployKeyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGitProvider) DeleteDeployKeyReturnsOnCall(i int, result1 error) {
+	fake.deleteDeployKeyMutex.Lock()
+	defer fake.deleteDeployKeyMutex.Unlock()
+	fake.DeleteDeployKeyStub = nil
+	if fake.deleteDeployKeyReturnsOnCall == nil {
+		fake.deleteDeployKeyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteDeployKeyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGitProvider) DeployKeyExists(arg1 string, arg2 string, arg3 string) (bool, error) {
 	fake.deployKeyExistsMutex.Lock()
 	ret, specificReturn := fake.deployKeyExistsReturnsOnCall[len(fake.deployKeyExistsArgsForCall)]
 	fake.deployKeyExistsArgsForCall = append(fake.deployKeyExistsArgsForCall, struct {
 		arg1 string
 		arg2 string
-	}{arg1, arg2})
+		arg3 string
+	}{arg1, arg2, arg3})
 	stub := fake.DeployKeyExistsStub
 	fakeReturns := fake.deployKeyExistsReturns
-	fake.recordInvocation("DeployKeyExists", []interface{}{arg1, arg2})
+	fake.recordInvocation("DeployKeyExists", []interface{}{arg1, arg2, arg3})
 	fake.deployKeyExistsMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -357,17 +543,17 @@ func (fake *FakeGitProvider) DeployKeyExistsCallCount() int {
 	return len(fake.deployKeyExistsArgsForCall)
 }
 
-func (fake *FakeGitProvider) DeployKeyExistsCalls(stub func(string, string) (bool, error)) {
+func (fake *FakeGitProvider) DeployKeyExistsCalls(stub func(string, string, string) (bool, error)) {
 	fake.deployKeyExistsMutex.Lock()
 	defer fake.deployKeyExistsMutex.Unlock()
 	fake.DeployKeyExistsStub = stub
 }
 
-func (fake *FakeGitProvider) DeployKeyExistsArgsForCall(i int) (string, string) {
+func (fake *FakeGitProvider) DeployKeyExistsArgsForCall(i int) (string, string, string) {
 	fake.deployKeyExistsMutex.RLock()
 	defer fake.deployKeyExistsMutex.RUnlock()
 	argsForCall := fake.deployKeyExistsArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
 func (fake *FakeGitProvider) DeployKeyExistsReturns(result1 bool, result2 error) {
@@ -460,6 +646,72 @@ func (fake *FakeGitProvider) GetAccountTypeReturnsOnCall(i int, result1 gitprovi
 	}{result1, result2}
 }
 
+func (fake *FakeGitProvider) ListDeployKeys(arg1 string, arg2 string, arg3 string) ([]string, error) {
+	fake.listDeployKeysMutex.Lock()
+	ret, specificReturn := fake.listDeployKeysReturnsOnCall[len(fake.listDeployKeysArgsForCall)]
+	fake.listDeployKeysArgsForCall = append(fake.listDeployKeysArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ListDeployKeysStub
+	fakeReturns := fake.listDeployKeysReturns
+	fake.recordInvocation("ListDeployKeys", []interface{}{arg1, arg2, arg3})
+	fake.listDeployKeysMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGitProvider) ListDeployKeysCallCount() int {
+	fake.listDeployKeysMutex.RLock()
+	defer fake.listDeployKeysMutex.RUnlock()
+	return len(fake.listDeployKeysArgsForCall)
+}
+
+func (fake *FakeGitProvider) ListDeployKeysCalls(stub func(string, string, string) ([]string, error)) {
+	fake.listDeployKeysMutex.Lock()
+	defer fake.listDeployKeysMutex.Unlock()
+	fake.ListDeployKeysStub = stub
+}
+
+func (fake *FakeGitProvider) ListDeployKeysArgsForCall(i int) (string, string, string) {
+	fake.listDeployKeysMutex.RLock()
+	defer fake.listDeployKeysMutex.RUnlock()
+	argsForCall := fake.listDeployKeysArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGitProvider) ListDeployKeysReturns(result1 []string, result2 error) {
+	fake.listDeployKeysMutex.Lock()
+	defer fake.listDeployKeysMutex.Unlock()
+	fake.ListDeployKeysStub = nil
+	fake.listDeployKeysReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGitProvider) ListDeployKeysReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.listDeployKeysMutex.Lock()
+	defer fake.listDeployKeysMutex.Unlock()
+	fake.ListDeployKeysStub = nil
+	if fake.listDeployKeysReturnsOnCall == nil {
+		fake.listDeployKeysReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.listDeployKeysReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGitProvider) RepositoryExists(arg1 string, arg2 string) (bool, error) {
 	fake.repositoryExistsMutex.Lock()
 	ret, specificReturn := fake.repositoryExistsReturnsOnCall[len(fake.repositoryExistsArgsForCall)]
@@ -525,25 +777,26 @@ func (fake *FakeGitProvider) RepositoryExistsReturnsOnCall(i int, result1 bool,
 	}{result1, result2}
 }
 
-func (fake *FakeGitProvider) UploadDeployKey(arg1 string, arg2 string, arg3 []byte) error {
-	var arg3Copy []byte
-	if arg3 != nil {
-		arg3Copy = make([]byte, len(arg3))
-		copy(arg3Copy, arg3)
+func (fake *FakeGitProvider) UploadDeployKey(arg1 string, arg2 string, arg3 string, arg4 []byte) error {
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
 	}
 	fake.uploadDeployKeyMutex.Lock()
 	ret, specificReturn := fake.uploadDeployKeyReturnsOnCall[len(fake.uploadDeployKeyArgsForCall)]
 	fake.uploadDeployKeyArgsForCall = append(fake.uploadDeployKeyArgsForCall, struct {
 		arg1 string
 		arg2 string
-		arg3 []byte
-	}{arg1, arg2, arg3Copy})
+		arg3 string
+		arg4 []byte
+	}{arg1, arg2, arg3, arg4Copy})
 	stub := fake.UploadDeployKeyStub
 	fakeReturns := fake.uploadDeployKeyReturns
-	fake.recordInvocation("UploadDeployKey", []interface{}{arg1, arg2, arg3Copy})
+	fake.recordInvocation("UploadDeployKey", []interface{}{arg1, arg2, arg3, arg4Copy})
 	fake.uploadDeployKeyMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -557,17 +810,17 @@ func (fake *FakeGitProvider) UploadDeployKeyCallCount() int {
 	return len(fake.uploadDeployKeyArgsForCall)
 }
 
-func (fake *FakeGitProvider) UploadDeployKeyCalls(stub func(string, string, []byte) error) {
+func (fake *FakeGitProvider) UploadDeployKeyCalls(stub func(string, string, string, []byte) error) {
 	fake.uploadDeployKeyMutex.Lock()
 	defer fake.uploadDeployKeyMutex.Unlock()
 	fake.UploadDeployKeyStub = stub
 }
 
-func (fake *FakeGitProvider) UploadDeployKeyArgsForCall(i int) (string, string, []byte) {
+func (fake *FakeGitProvider) UploadDeployKeyArgsForCall(i int) (string, string, string, []byte) {
 	fake.uploadDeployKeyMutex.RLock()
 	defer fake.uploadDeployKeyMutex.RUnlock()
 	argsForCall := fake.uploadDeployKeyArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeGitProvider) UploadDeployKeyReturns(result1 error) {
@@ -593,23 +846,100 @@ func (fake *FakeGitProvider) UploadDeployKeyReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeGitProvider) UploadWriteDeployKey(arg1 string, arg2 string, arg3 string, arg4 []byte) error {
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.uploadWriteDeployKeyMutex.Lock()
+	ret, specificReturn := fake.uploadWriteDeployKeyReturnsOnCall[len(fake.uploadWriteDeployKeyArgsForCall)]
+	fake.uploadWriteDeployKeyArgsForCall = append(fake.uploadWriteDeployKeyArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 []byte
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.UploadWriteDeployKeyStub
+	fakeReturns := fake.uploadWriteDeployKeyReturns
+	fake.recordInvocation("UploadWriteDeployKey", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.uploadWriteDeployKeyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGitProvider) UploadWriteDeployKeyCallCount() int {
+	fake.uploadWriteDeployKeyMutex.RLock()
+	defer fake.uploadWriteDeployKeyMutex.RUnlock()
+	return len(fake.uploadWriteDeployKeyArgsForCall)
+}
+
+func (fake *FakeGitProvider) UploadWriteDeployKeyCalls(stub func(string, string, string, []byte) error) {
+	fake.uploadWriteDeployKeyMutex.Lock()
+	defer fake.uploadWriteDeployKeyMutex.Unlock()
+	fake.UploadWriteDeployKeyStub = stub
+}
+
+func (fake *FakeGitProvider) UploadWriteDeployKeyArgsForCall(i int) (string, string, string, []byte) {
+	fake.uploadWriteDeployKeyMutex.RLock()
+	defer fake.uploadWriteDeployKeyMutex.RUnlock()
+	argsForCall := fake.uploadWriteDeployKeyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeGitProvider) UploadWriteDeployKeyReturns(result1 error) {
+	fake.uploadWriteDeployKeyMutex.Lock()
+	defer fake.uploadWriteDeployKeyMutex.Unlock()
+	fake.UploadWriteDeployKeyStub = nil
+	fake.uploadWriteDeployKeyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGitProvider) UploadWriteDeployKeyReturnsOnCall(i int, result1 error) {
+	fake.uploadWriteDeployKeyMutex.Lock()
+	defer fake.uploadWriteDeployKeyMutex.Unlock()
+	fake.UploadWriteDeployKeyStub = nil
+	if fake.uploadWriteDeployKeyReturnsOnCall == nil {
+		fake.uploadWriteDeployKeyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.uploadWriteDeployKeyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeGitProvider) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.createCommitStatusMutex.RLock()
+	defer fake.createCommitStatusMutex.RUnlock()
 	fake.createPullRequestToOrgRepoMutex.RLock()
 	defer fake.createPullRequestToOrgRepoMutex.RUnlock()
 	fake.createPullRequestToUserRepoMutex.RLock()
 	defer fake.createPullRequestToUserRepoMutex.RUnlock()
 	fake.createRepositoryMutex.RLock()
 	defer fake.createRepositoryMutex.RUnlock()
+	fake.deleteDeployKeyMutex.RLock()
+	defer fake.deleteDeployKeyMutex.RUnlock()
 	fake.deployKeyExistsMutex.RLock()
 	defer fake.deployKeyExistsMutex.RUnlock()
 	fake.getAccountTypeMutex.RLock()
 	defer fake.getAccountTypeMutex.RUnlock()
+	fake.listDeployKeysMutex.RLock()
+	defer fake.listDeployKeysMutex.RUnlock()
 	fake.repositoryExistsMutex.RLock()
 	defer fake.repositoryExistsMutex.RUnlock()
 	fake.uploadDeployKeyMutex.RLock()
 	defer fake.uploadDeployKeyMutex.RUnlock()
+	fake.uploadWriteDeployKeyMutex.RLock()
+	defer fake.uploadWriteDeployKeyMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"strings"
+	"sort"
 	"time"
 
 	"github.com/weaveworks/weave-gitops/pkg/utils"
@@ -28,11 +28,15 @@ const (
 type GitProvider interface {
 	CreateRepository(name string, owner string, private bool) error
 	RepositoryExists(name string, owner string) (bool, error)
-	DeployKeyExists(owner, repoName string) (bool, error)
-	UploadDeployKey(owner, repoName string, deployKey []byte) error
+	DeployKeyExists(owner, repoName, clusterName string) (bool, error)
+	UploadDeployKey(owner, repoName, keyName string, deployKey []byte) error
+	UploadWriteDeployKey(owner, repoName, keyName string, deployKey []byte) error
+	ListDeployKeys(owner, repoName, clusterName string) ([]string, error)
+	DeleteDeployKey(owner, repoName, keyName string) error
 	CreatePullRequestToUserRepo(userRepRef gitprovider.UserRepositoryRef, targetBranch string, newBranch string, files []gitprovider.CommitFile, commitMessage string, prTitle string, prDescription string) (gitprovider.PullRequest, error)
 	CreatePullRequestToOrgRepo(orgRepRef gitprovider.OrgRepositoryRef, targetBranch string, newBranch string, files []gitprovider.CommitFile, commitMessage string, prTitle string, prDescription string) (gitprovider.PullRequest, error)
 	GetAccountType(owner string) (ProviderAccountType, error)
+	CreateCommitStatus(owner, repoName, sha string, status CommitStatus) error
 }
 
 // making sure it implements the interface
@@ -116,61 +120,125 @@ func (p defaultGitProvider) CreateRepository(name string, owner string, private
 	return nil
 }
 
-func (p defaultGitProvider) DeployKeyExists(owner, repoName string) (bool, error) {
-	deployKeyName := "weave-gitops-deploy-key"
-
-	ownerType, err := p.GetAccountType(owner)
+// DeployKeyExists reports whether the repository has a deploy key of the cluster, either the original one or a rotated one
+func (p defaultGitProvider) DeployKeyExists(owner, repoName, clusterName string) (bool, error) {
+	names, err := p.ListDeployKeys(owner, repoName, clusterName)
 	if err != nil {
 		return false, err
 	}
 
+	return len(names) > 0, nil
+}
+
+// ListDeployKeys returns the names of the deploy keys of the cluster on a repository, in the order they were created.
+// The keys of other clusters are left out.
+func (p defaultGitProvider) ListDeployKeys(owner, repoName, clusterName string) ([]string, error) {
 	ctx := context.Background()
 	defer ctx.Done()
+
+	deployKeys, err := p.deployKeyClient(ctx, owner, repoName)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := deployKeys.List(ctx)
+	if err != nil {
+		if errors.Is(err, gitprovider.ErrNotFound) {
+			return []string{}, nil
+		}
+
+		return nil, fmt.Errorf("error listing deploy keys for repo %s. %s", repoName, err)
+	}
+
+	names := []string{}
+
+	for _, key := range keys {
+		if name := key.Get().Name; IsDeployKeyName(name, clusterName) {
+			names = append(names, name)
+		}
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+		return deployKeyVersion(names[i], clusterName) < deployKeyVersion(names[j], clusterName)
+	})
+
+	return names, nil
+}
+
+// DeleteDeployKey deletes a deploy key from a repository. Deleting a key that doesn't exist is not an error.
+func (p defaultGitProvider) DeleteDeployKey(owner, repoName, keyName string) error {
+	ctx := context.Background()
+	defer ctx.Done()
+
+	deployKeys, err := p.deployKeyClient(ctx, owner, repoName)
+	if err != nil {
+		return err
+	}
+
+	key, err := deployKeys.Get(ctx, keyName)
+	if err != nil {
+		if errors.Is(err, gitprovider.ErrNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("error getting deploy key %s for repo %s. %s", keyName, repoName, err)
+	}
+
+	if err := key.Delete(ctx); err != nil {
+		return fmt.Errorf("error deleting deploy key %s for repo %s. %s", keyName, repoName, err)
+	}
+
+	return nil
+}
+
+func (p defaultGitProvider) deployKeyClient(ctx context.Context, owner, repoName string) (gitprovider.DeployKeyClient, error) {
+	ownerType, err := p.GetAccountType(owner)
+	if err != nil {
+		return nil, err
+	}
+
 	switch ownerType {
 	case AccountTypeOrg:
 		orgRef := NewOrgRepositoryRef(github.DefaultDomain, owner, repoName)
 		orgRepo, err := p.provider.OrgRepositories().Get(ctx, orgRef)
 		if err != nil {
-			return false, fmt.Errorf("error getting org repo reference for owner %s, repo %s, %s ", owner, repoName, err)
-		}
-		_, err = orgRepo.DeployKeys().Get(ctx, deployKeyName)
-		if err != nil && !strings.Contains(err.Error(), "key is already in use") {
-			if errors.Is(err, gitprovider.ErrNotFound) {
-				return false, nil
-			} else {
-				return false, fmt.Errorf("error getting deploy key %s for repo %s. %s", deployKeyName, repoName, err)
-			}
-		} else {
-			return true, nil
+			return nil, fmt.Errorf("error getting org repo reference for owner %s, repo %s, %s ", owner, repoName, err)
 		}
 
+		return orgRepo.DeployKeys(), nil
 	case AccountTypeUser:
 		userRef := NewUserRepositoryRef(github.DefaultDomain, owner, repoName)
 		userRepo, err := p.provider.UserRepositories().Get(ctx, userRef)
 		if err != nil {
-			return false, fmt.Errorf("error getting user repo reference for owner %s, repo %s, %s ", owner, repoName, err)
-		}
-		_, err = userRepo.DeployKeys().Get(ctx, deployKeyName)
-		if err != nil && !strings.Contains(err.Error(), "key is already in use") {
-			if errors.Is(err, gitprovider.ErrNotFound) {
-				return false, nil
-			} else {
-				return false, fmt.Errorf("error getting deploy key %s for repo %s. %s", deployKeyName, repoName, err)
-			}
-		} else {
-			return true, nil
+			return nil, fmt.Errorf("error getting user repo reference for owner %s, repo %s, %s ", owner, repoName, err)
 		}
+
+		return userRepo.DeployKeys(), nil
 	default:
-		return false, fmt.Errorf("account type not supported %s", ownerType)
+		return nil, fmt.Errorf("account type not supported %s", ownerType)
 	}
 }
 
-func (p defaultGitProvider) UploadDeployKey(owner, repoName string, deployKey []byte) error {
-	deployKeyName := "weave-gitops-deploy-key"
-	deployKeyInfo := gitprovider.DeployKeyInfo{
-		Name: deployKeyName,
-		Key:  deployKey,
-	}
+// UploadDeployKey uploads a read-only deploy key under the given name, see DeployKeyName and NextDeployKeyName
+func (p defaultGitProvider) UploadDeployKey(owner, repoName, deployKeyName string, deployKey []byte) error {
+	return p.uploadDeployKey(owner, repoName, gitprovider.DeployKeyInfo{
+		Name:     deployKeyName,
+		Key:      deployKey,
+		ReadOnly: gitprovider.BoolVar(true),
+	})
+}
+
+// UploadWriteDeployKey uploads a deploy key allowed to push to the repository, see WriteDeployKeyName
+func (p defaultGitProvider) UploadWriteDeployKey(owner, repoName, deployKeyName string, deployKey []byte) error {
+	return p.uploadDeployKey(owner, repoName, gitprovider.DeployKeyInfo{
+		Name:     deployKeyName,
+		Key:      deployKey,
+		ReadOnly: gitprovider.BoolVar(false),
+	})
+}
+
+func (p defaultGitProvider) uploadDeployKey(owner, repoName string, deployKeyInfo gitprovider.DeployKeyInfo) error {
+	deployKeyName := deployKeyInfo.Name
 
 	ownerType, err := p.GetAccountType(owner)
 	if err != nil {
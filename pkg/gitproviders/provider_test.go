@@ -536,22 +536,23 @@ var _ = Describe("Test user deploy keys creation", func() {
 	})
 
 	It("Uploads a new deploy key for a brand new user repo, checks for presence of the key, and shows proper message if trying to re-add it", func() {
-		exists, err := gitProvider.DeployKeyExists(accounts.GithubUserName, repoName)
+		// The legacy key of installs predating per-cluster keys is one of the keys of the cluster
+		exists, err := gitProvider.DeployKeyExists(accounts.GithubUserName, repoName, "test-cluster")
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(exists).To(BeFalse())
 
 		stdout := utils.CaptureStdout(func() {
-			err = gitProvider.UploadDeployKey(accounts.GithubUserName, repoName, []byte(deployKey))
+			err = gitProvider.UploadDeployKey(accounts.GithubUserName, repoName, LegacyDeployKeyName, []byte(deployKey))
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 		Expect(stdout).To(Equal("uploading deploy key\n"))
 
-		exists, err = gitProvider.DeployKeyExists(accounts.GithubUserName, repoName)
+		exists, err = gitProvider.DeployKeyExists(accounts.GithubUserName, repoName, "test-cluster")
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(exists).To(BeTrue())
 
 		stdout = utils.CaptureStdout(func() {
-			err = gitProvider.UploadDeployKey(accounts.GithubUserName, repoName, []byte(deployKey))
+			err = gitProvider.UploadDeployKey(accounts.GithubUserName, repoName, LegacyDeployKeyName, []byte(deployKey))
 			Expect(err).Should(HaveOccurred())
 		})
 		Expect(stdout).To(Equal("uploading deploy key\n"))
@@ -603,22 +604,23 @@ var _ = Describe("Test org deploy keys creation", func() {
 	})
 
 	It("Uploads a new deploy key for a brand new user repo, checks for presence of the key, and shows proper message if trying to re-add it", func() {
-		exists, err := gitProvider.DeployKeyExists(accounts.GithubOrgName, repoName)
+		// The legacy key of installs predating per-cluster keys is one of the keys of the cluster
+		exists, err := gitProvider.DeployKeyExists(accounts.GithubOrgName, repoName, "test-cluster")
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(exists).To(BeFalse())
 
 		stdout := utils.CaptureStdout(func() {
-			err = gitProvider.UploadDeployKey(accounts.GithubOrgName, repoName, []byte(deployKey))
+			err = gitProvider.UploadDeployKey(accounts.GithubOrgName, repoName, LegacyDeployKeyName, []byte(deployKey))
 			Expect(err).ShouldNot(HaveOccurred())
 		})
 		Expect(stdout).To(Equal("uploading deploy key\n"))
 
-		exists, err = gitProvider.DeployKeyExists(accounts.GithubOrgName, repoName)
+		exists, err = gitProvider.DeployKeyExists(accounts.GithubOrgName, repoName, "test-cluster")
 		Expect(err).ShouldNot(HaveOccurred())
 		Expect(exists).To(BeTrue())
 
 		stdout = utils.CaptureStdout(func() {
-			err = gitProvider.UploadDeployKey(accounts.GithubOrgName, repoName, []byte(deployKey))
+			err = gitProvider.UploadDeployKey(accounts.GithubOrgName, repoName, LegacyDeployKeyName, []byte(deployKey))
 			Expect(err).Should(HaveOccurred())
 		})
 		Expect(stdout).To(Equal("uploading deploy key\n"))
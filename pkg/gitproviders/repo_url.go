@@ -0,0 +1,80 @@
+package gitproviders
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fluxcd/go-git-providers/github"
+	"github.com/fluxcd/go-git-providers/gitlab"
+)
+
+// RepoURL is a repository at a git provider, parsed from its clone url
+type RepoURL struct {
+	Provider GitProviderName
+	// Hostname is only set for self hosted providers
+	Hostname string
+	Owner    string
+	Name     string
+}
+
+// ParseRepoURL parses ssh (git@host:owner/repo or ssh://git@host/owner/repo) and https clone urls. The owner
+// keeps the groups of nested repositories, e.g. owner/group. Hosts containing gitlab are GitLab, any other host
+// is considered GitHub.
+func ParseRepoURL(repoUrl string) (RepoURL, error) {
+	normalized := repoUrl
+	if !strings.Contains(normalized, "://") {
+		// scp-like syntax, git@host:owner/repo
+		normalized = "ssh://" + strings.Replace(normalized, ":", "/", 1)
+	}
+
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return RepoURL{}, fmt.Errorf("could not parse repository url %s: %w", repoUrl, err)
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/"), "/")
+	if u.Hostname() == "" || len(parts) < 2 {
+		return RepoURL{}, fmt.Errorf("could not get the owner and name of repository %s", repoUrl)
+	}
+
+	result := RepoURL{
+		Provider: GitProviderGitHub,
+		Owner:    strings.Join(parts[:len(parts)-1], "/"),
+		Name:     parts[len(parts)-1],
+	}
+
+	host := u.Hostname()
+	if strings.Contains(host, "gitlab") {
+		result.Provider = GitProviderGitLab
+	}
+
+	if host != github.DefaultDomain && host != gitlab.DefaultDomain {
+		result.Hostname = host
+	}
+
+	return result, nil
+}
+
+// Host returns the host of the repository, the domain of its provider unless it is self hosted
+func (r RepoURL) Host() string {
+	if r.Hostname != "" {
+		return r.Hostname
+	}
+
+	if r.Provider == GitProviderGitLab {
+		return gitlab.DefaultDomain
+	}
+
+	return github.DefaultDomain
+}
+
+// HTTPSURL returns the web address of the repository
+func (r RepoURL) HTTPSURL() string {
+	return fmt.Sprintf("https://%s/%s/%s", r.Host(), r.Owner, r.Name)
+}
+
+// SSHURL returns the address of the repository over ssh, in the ssh://git@host/owner/repo form flux reads
+func (r RepoURL) SSHURL() string {
+	return fmt.Sprintf("ssh://git@%s/%s/%s", r.Host(), r.Owner, r.Name)
+}
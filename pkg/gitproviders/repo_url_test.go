@@ -0,0 +1,40 @@
+package gitproviders
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Repository urls", func() {
+	table.DescribeTable("parses repository urls",
+		func(repoUrl string, expected RepoURL) {
+			result, err := ParseRepoURL(repoUrl)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result).To(Equal(expected))
+		},
+		table.Entry("scp-like ssh", "git@github.com:foo/bar.git", RepoURL{Provider: GitProviderGitHub, Owner: "foo", Name: "bar"}),
+		table.Entry("ssh", "ssh://git@github.com/foo/bar.git", RepoURL{Provider: GitProviderGitHub, Owner: "foo", Name: "bar"}),
+		table.Entry("https", "https://github.com/foo/bar", RepoURL{Provider: GitProviderGitHub, Owner: "foo", Name: "bar"}),
+		table.Entry("gitlab subgroup", "https://gitlab.com/foo/team/bar.git", RepoURL{Provider: GitProviderGitLab, Owner: "foo/team", Name: "bar"}),
+		table.Entry("self hosted gitlab", "ssh://git@gitlab.example.com/foo/bar.git", RepoURL{Provider: GitProviderGitLab, Hostname: "gitlab.example.com", Owner: "foo", Name: "bar"}),
+		table.Entry("github enterprise", "git@github.example.com:foo/bar", RepoURL{Provider: GitProviderGitHub, Hostname: "github.example.com", Owner: "foo", Name: "bar"}),
+	)
+
+	It("fails for a url without an owner", func() {
+		_, err := ParseRepoURL("https://github.com/bar")
+		Expect(err).To(MatchError("could not get the owner and name of repository https://github.com/bar"))
+	})
+
+	It("returns the web address of a repository", func() {
+		Expect(RepoURL{Provider: GitProviderGitHub, Owner: "foo", Name: "bar"}.HTTPSURL()).To(Equal("https://github.com/foo/bar"))
+		Expect(RepoURL{Provider: GitProviderGitLab, Owner: "foo", Name: "bar"}.HTTPSURL()).To(Equal("https://gitlab.com/foo/bar"))
+		Expect(RepoURL{Provider: GitProviderGitLab, Hostname: "gitlab.example.com", Owner: "foo", Name: "bar"}.HTTPSURL()).To(Equal("https://gitlab.example.com/foo/bar"))
+	})
+
+	It("returns the ssh address of a repository", func() {
+		Expect(RepoURL{Provider: GitProviderGitHub, Owner: "foo", Name: "bar"}.SSHURL()).To(Equal("ssh://git@github.com/foo/bar"))
+		Expect(RepoURL{Provider: GitProviderGitLab, Owner: "foo/team", Name: "bar"}.SSHURL()).To(Equal("ssh://git@gitlab.com/foo/team/bar"))
+		Expect(RepoURL{Provider: GitProviderGitHub, Hostname: "github.example.com", Owner: "foo", Name: "bar"}.SSHURL()).To(Equal("ssh://git@github.example.com/foo/bar"))
+	})
+})
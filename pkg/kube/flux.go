@@ -0,0 +1,75 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	fluxInstanceLabel = "app.kubernetes.io/instance"
+	fluxVersionLabel  = "app.kubernetes.io/version"
+)
+
+var deploymentGVK = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+// FluxController is a flux controller deployment, as installed by flux install
+type FluxController struct {
+	Name    string
+	Version string
+	Image   string
+	// WatchAllNamespaces is false for controllers only reconciling the objects of their own namespace
+	WatchAllNamespaces bool
+	// Ready is true when all the replicas of the controller are ready
+	Ready bool
+}
+
+// GetFluxControllers lists the flux controllers installed in a namespace, sorted by name
+func GetFluxControllers(ctx context.Context, kubeClient Kube, namespace string) ([]FluxController, error) {
+	deployments, err := kubeClient.ListResources(ctx, deploymentGVK, namespace, map[string]string{fluxInstanceLabel: namespace})
+	if err != nil {
+		return nil, fmt.Errorf("could not list the flux controllers: %w", err)
+	}
+
+	controllers := []FluxController{}
+
+	for _, deployment := range deployments {
+		controller := FluxController{
+			Name:               deployment.GetName(),
+			Version:            deployment.GetLabels()[fluxVersionLabel],
+			WatchAllNamespaces: true,
+		}
+
+		containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+		if len(containers) > 0 {
+			container, _ := containers[0].(map[string]interface{})
+			controller.Image, _, _ = unstructured.NestedString(container, "image")
+
+			args, _, _ := unstructured.NestedStringSlice(container, "args")
+			for _, arg := range args {
+				if arg == "--watch-all-namespaces=false" {
+					controller.WatchAllNamespaces = false
+				}
+			}
+		}
+
+		replicas, found, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+		if !found {
+			replicas = 1
+		}
+
+		readyReplicas, _, _ := unstructured.NestedInt64(deployment.Object, "status", "readyReplicas")
+		controller.Ready = readyReplicas >= replicas
+
+		controllers = append(controllers, controller)
+	}
+
+	sort.Slice(controllers, func(i, j int) bool {
+		return controllers[i].Name < controllers[j].Name
+	})
+
+	return controllers, nil
+}
@@ -4,17 +4,25 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"encoding/json"
 
 	"github.com/pkg/errors"
 	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
 	"github.com/weaveworks/weave-gitops/pkg/runner"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/version"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
@@ -60,6 +68,20 @@ type Kube interface {
 	LabelExistsInCluster(ctx context.Context, label string) error
 	GetApplication(ctx context.Context, name types.NamespacedName) (*wego.Application, error)
 	GetResource(ctx context.Context, name types.NamespacedName, resource Resource) error
+	ListResources(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error)
+	GetEvents(ctx context.Context, namespace string) ([]corev1.Event, error)
+	GetPodLogs(ctx context.Context, namespace string, labels map[string]string, opts LogOptions) ([]io.ReadCloser, error)
+	GetServerVersion(ctx context.Context) (string, error)
+	CanI(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error)
+	PortForward(ctx context.Context, namespace string, service string, localPort int, remotePort int) error
+}
+
+// LogOptions selects the log lines streamed by GetPodLogs
+type LogOptions struct {
+	// Since only returns the lines newer than this duration, when set
+	Since time.Duration
+	// Follow keeps the streams open for new lines
+	Follow bool
 }
 
 type KubeClient struct {
@@ -140,9 +162,8 @@ func (k *KubeClient) GetClusterStatus(ctx context.Context) ClusterStatus {
 		return FluxInstalled
 	}
 
-	hostPortError := "was refused - did you specify the right host or port?"
-	if out, err := k.runKubectlCmd([]string{"get", "deployment", "coredns", "-n", "kube-system"}); err == nil ||
-		!strings.Contains(string(out), hostPortError) {
+	// Checking the API server answers
+	if _, err := k.GetServerVersion(ctx); err == nil {
 		return Unmodified
 	}
 
@@ -207,8 +228,144 @@ func (k *KubeClient) GetApplications(ctx context.Context, ns string) ([]wego.App
 	return a.Items, nil
 }
 
+// GetResource gets an object of a kind known to the wego scheme. Like the go-client implementation, the object
+// is left empty when it doesn't exist.
 func (k *KubeClient) GetResource(ctx context.Context, name types.NamespacedName, resource Resource) error {
-	return errors.New("method not implemented, use the go-client implementation of the kube interface")
+	gvks, _, err := CreateScheme().ObjectKinds(resource)
+	if err != nil {
+		return fmt.Errorf("could not get the kind of the resource: %w", err)
+	}
+
+	args := []string{"get", kubectlResourceName(gvks[0]), name.Name, "-o", "json"}
+	if name.Namespace != "" {
+		args = append(args, "-n", name.Namespace)
+	}
+
+	out, err := k.runKubectlCmd(args)
+	if err != nil {
+		if strings.Contains(string(out), "NotFound") {
+			return nil
+		}
+
+		return fmt.Errorf("error getting resource: %w", err)
+	}
+
+	if err := json.Unmarshal(out, resource); err != nil {
+		return fmt.Errorf("could not unmarshal resource json: %w", err)
+	}
+
+	return nil
+}
+
+// ListResources lists the objects of a kind with the given labels; namespace is ignored for cluster scoped kinds
+func (k *KubeClient) ListResources(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+	args := []string{"get", kubectlResourceName(gvk), "-n", namespace, "-o", "json"}
+
+	selectors := []string{}
+	for key, value := range labels {
+		selectors = append(selectors, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	if len(selectors) > 0 {
+		sort.Strings(selectors)
+		args = append(args, "-l", strings.Join(selectors, ","))
+	}
+
+	out, err := k.runKubectlCmd(args)
+	if err != nil {
+		return nil, fmt.Errorf("could not list %s: %w", gvk.Kind, err)
+	}
+
+	list := unstructured.UnstructuredList{}
+	if err := list.UnmarshalJSON(out); err != nil {
+		return nil, fmt.Errorf("could not unmarshal %s list json: %w", gvk.Kind, err)
+	}
+
+	return list.Items, nil
+}
+
+// PortForward forwards a local port to a port of a service, until the command is interrupted
+func (k *KubeClient) PortForward(ctx context.Context, namespace string, service string, localPort int, remotePort int) error {
+	args := []string{
+		"port-forward",
+		"--namespace", namespace,
+		"service/" + service,
+		fmt.Sprintf("%d:%d", localPort, remotePort),
+	}
+
+	if out, err := k.runner.RunWithOutputStream(kubectlPath, args...); err != nil {
+		return fmt.Errorf("failed to forward port %d to service %s: %s: %w", localPort, service, string(out), err)
+	}
+
+	return nil
+}
+
+// kubectlResourceName returns the fully qualified name kubectl resolves a kind with, e.g. deployment.v1.apps
+func kubectlResourceName(gvk schema.GroupVersionKind) string {
+	if gvk.Group == "" {
+		return strings.ToLower(gvk.Kind)
+	}
+
+	return strings.ToLower(fmt.Sprintf("%s.%s.%s", gvk.Kind, gvk.Version, gvk.Group))
+}
+
+func (k *KubeClient) GetEvents(ctx context.Context, namespace string) ([]corev1.Event, error) {
+	return nil, errors.New("method not implemented, use the go-client implementation of the kube interface")
+}
+
+func (k *KubeClient) GetPodLogs(ctx context.Context, namespace string, labels map[string]string, opts LogOptions) ([]io.ReadCloser, error) {
+	return nil, errors.New("method not implemented, use the go-client implementation of the kube interface")
+}
+
+// GetServerVersion returns the git version of the Kubernetes API server, e.g. v1.21.1
+func (k *KubeClient) GetServerVersion(ctx context.Context) (string, error) {
+	out, err := k.runKubectlCmd([]string{"version", "-o", "json"})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get the server version")
+	}
+
+	versions := struct {
+		ServerVersion *version.Info `json:"serverVersion"`
+	}{}
+
+	if err := json.Unmarshal(out, &versions); err != nil {
+		return "", fmt.Errorf("could not unmarshal version json: %w", err)
+	}
+
+	if versions.ServerVersion == nil {
+		return "", errors.New("the server version is missing from the kubectl output")
+	}
+
+	return versions.ServerVersion.GitVersion, nil
+}
+
+// CanI reports whether the current user is allowed an action, through a SelfSubjectAccessReview
+func (k *KubeClient) CanI(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	review := authorizationv1.SelfSubjectAccessReview{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "SelfSubjectAccessReview",
+			APIVersion: authorizationv1.SchemeGroupVersion.String(),
+		},
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+		},
+	}
+
+	manifest, err := json.Marshal(review)
+	if err != nil {
+		return false, fmt.Errorf("could not marshal access review: %w", err)
+	}
+
+	out, err := k.runKubectlCmdWithInput([]string{"create", "-f", "-", "-o", "json"}, manifest)
+	if err != nil {
+		return false, fmt.Errorf("could not review access: %w", err)
+	}
+
+	if err := json.Unmarshal(out, &review); err != nil {
+		return false, fmt.Errorf("could not unmarshal access review json: %w", err)
+	}
+
+	return review.Status.Allowed, nil
 }
 
 func (k *KubeClient) runKubectlCmd(args []string) ([]byte, error) {
@@ -7,6 +7,9 @@ import (
 	"strings"
 
 	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	extensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 
 	. "github.com/onsi/gomega"
@@ -93,8 +96,8 @@ var _ = Describe("GetClusterStatus", func() {
 
 	It("returns unmodified cluster", func() {
 		runner.RunStub = func(cmd string, args ...string) ([]byte, error) {
-			if strings.Join(args, " ") == "get deployment coredns -n kube-system" {
-				return []byte("out"), nil
+			if strings.Join(args, " ") == "version -o json" {
+				return []byte(`{"serverVersion": {"gitVersion": "v1.21.1"}}`), nil
 			}
 
 			return []byte("error"), fmt.Errorf("error")
@@ -243,6 +246,92 @@ var _ = Describe("GetApplication", func() {
 	})
 })
 
+var _ = Describe("GetResource", func() {
+	It("gets a resource of a kind known to the wego scheme", func() {
+		runner.RunStub = func(cmd string, args ...string) ([]byte, error) {
+			return []byte(`{"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition", "metadata": {"name": "apps.wego.weave.works"}, "status": {"storedVersions": ["v1alpha1"]}}`), nil
+		}
+
+		crd := &extensionsv1.CustomResourceDefinition{}
+		err := kubeClient.GetResource(context.Background(), types.NamespacedName{Name: kube.WeGOCRDName}, crd)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(crd.Status.StoredVersions).To(Equal([]string{"v1alpha1"}))
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(Equal("get customresourcedefinition.v1.apiextensions.k8s.io apps.wego.weave.works -o json"))
+	})
+
+	It("leaves the resource empty when it doesn't exist", func() {
+		runner.RunStub = func(cmd string, args ...string) ([]byte, error) {
+			return []byte(`Error from server (NotFound): customresourcedefinitions.apiextensions.k8s.io "apps.wego.weave.works" not found`), fmt.Errorf("exit status 1")
+		}
+
+		crd := &extensionsv1.CustomResourceDefinition{}
+		err := kubeClient.GetResource(context.Background(), types.NamespacedName{Name: kube.WeGOCRDName}, crd)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(crd.Name).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ListResources", func() {
+	It("lists the resources with the given labels", func() {
+		runner.RunStub = func(cmd string, args ...string) ([]byte, error) {
+			return []byte(`{"apiVersion": "v1", "kind": "List", "items": [{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "source-controller"}}]}`), nil
+		}
+
+		items, err := kubeClient.ListResources(context.Background(), schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, "wego-system",
+			map[string]string{"app.kubernetes.io/part-of": "flux", "control-plane": "controller"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(items).To(HaveLen(1))
+		Expect(items[0].GetName()).To(Equal("source-controller"))
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(Equal("get deployment.v1.apps -n wego-system -o json -l app.kubernetes.io/part-of=flux,control-plane=controller"))
+	})
+})
+
+var _ = Describe("GetServerVersion", func() {
+	It("returns the version of the api server", func() {
+		runner.RunStub = func(cmd string, args ...string) ([]byte, error) {
+			return []byte(`{"clientVersion": {"gitVersion": "v1.22.0"}, "serverVersion": {"gitVersion": "v1.21.1"}}`), nil
+		}
+
+		version, err := kubeClient.GetServerVersion(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(version).To(Equal("v1.21.1"))
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(Equal("version -o json"))
+	})
+})
+
+var _ = Describe("CanI", func() {
+	It("creates a self subject access review", func() {
+		runner.RunWithStdinStub = func(cmd string, args []string, input []byte) ([]byte, error) {
+			return []byte(`{"kind": "SelfSubjectAccessReview", "status": {"allowed": true}}`), nil
+		}
+
+		allowed, err := kubeClient.CanI(context.Background(), authorizationv1.ResourceAttributes{Verb: "create", Resource: "secrets", Namespace: "wego-system"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(allowed).To(BeTrue())
+
+		_, args, input := runner.RunWithStdinArgsForCall(0)
+		Expect(strings.Join(args, " ")).To(Equal("create -f - -o json"))
+		Expect(string(input)).To(ContainSubstring(`"resourceAttributes":{"namespace":"wego-system","verb":"create","resource":"secrets"}`))
+	})
+})
+
+var _ = Describe("PortForward", func() {
+	It("forwards a local port to a service", func() {
+		err := kubeClient.PortForward(context.Background(), "wego-system", "wego-ui", 9001, 9001)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		cmd, args := runner.RunWithOutputStreamArgsForCall(0)
+		Expect(cmd).To(Equal("kubectl"))
+		Expect(strings.Join(args, " ")).To(Equal("port-forward --namespace wego-system service/wego-ui 9001:9001"))
+	})
+})
+
 var _ = Describe("LabelExistsInCluster", func() {
 	It("checks if label exists in cluster", func() {
 		ctx := context.Background()
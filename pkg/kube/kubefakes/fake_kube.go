@@ -3,10 +3,15 @@ package kubefakes
 
 import (
 	"context"
+	"io"
 	"sync"
 
 	"github.com/weaveworks/weave-gitops/api/v1alpha1"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
+	v1 "k8s.io/api/authorization/v1"
+	v1a "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -25,6 +30,20 @@ type FakeKube struct {
 		result1 []byte
 		result2 error
 	}
+	CanIStub        func(context.Context, v1.ResourceAttributes) (bool, error)
+	canIMutex       sync.RWMutex
+	canIArgsForCall []struct {
+		arg1 context.Context
+		arg2 v1.ResourceAttributes
+	}
+	canIReturns struct {
+		result1 bool
+		result2 error
+	}
+	canIReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	DeleteStub        func([]byte, string) ([]byte, error)
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
@@ -104,6 +123,36 @@ type FakeKube struct {
 	getClusterStatusReturnsOnCall map[int]struct {
 		result1 kube.ClusterStatus
 	}
+	GetEventsStub        func(context.Context, string) ([]v1a.Event, error)
+	getEventsMutex       sync.RWMutex
+	getEventsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	getEventsReturns struct {
+		result1 []v1a.Event
+		result2 error
+	}
+	getEventsReturnsOnCall map[int]struct {
+		result1 []v1a.Event
+		result2 error
+	}
+	GetPodLogsStub        func(context.Context, string, map[string]string, kube.LogOptions) ([]io.ReadCloser, error)
+	getPodLogsMutex       sync.RWMutex
+	getPodLogsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 map[string]string
+		arg4 kube.LogOptions
+	}
+	getPodLogsReturns struct {
+		result1 []io.ReadCloser
+		result2 error
+	}
+	getPodLogsReturnsOnCall map[int]struct {
+		result1 []io.ReadCloser
+		result2 error
+	}
 	GetResourceStub        func(context.Context, types.NamespacedName, kube.Resource) error
 	getResourceMutex       sync.RWMutex
 	getResourceArgsForCall []struct {
@@ -117,6 +166,19 @@ type FakeKube struct {
 	getResourceReturnsOnCall map[int]struct {
 		result1 error
 	}
+	GetServerVersionStub        func(context.Context) (string, error)
+	getServerVersionMutex       sync.RWMutex
+	getServerVersionArgsForCall []struct {
+		arg1 context.Context
+	}
+	getServerVersionReturns struct {
+		result1 string
+		result2 error
+	}
+	getServerVersionReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	LabelExistsInClusterStub        func(context.Context, string) error
 	labelExistsInClusterMutex       sync.RWMutex
 	labelExistsInClusterArgsForCall []struct {
@@ -129,6 +191,37 @@ type FakeKube struct {
 	labelExistsInClusterReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ListResourcesStub        func(context.Context, schema.GroupVersionKind, string, map[string]string) ([]unstructured.Unstructured, error)
+	listResourcesMutex       sync.RWMutex
+	listResourcesArgsForCall []struct {
+		arg1 context.Context
+		arg2 schema.GroupVersionKind
+		arg3 string
+		arg4 map[string]string
+	}
+	listResourcesReturns struct {
+		result1 []unstructured.Unstructured
+		result2 error
+	}
+	listResourcesReturnsOnCall map[int]struct {
+		result1 []unstructured.Unstructured
+		result2 error
+	}
+	PortForwardStub        func(context.Context, string, string, int, int) error
+	portForwardMutex       sync.RWMutex
+	portForwardArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 int
+		arg5 int
+	}
+	portForwardReturns struct {
+		result1 error
+	}
+	portForwardReturnsOnCall map[int]struct {
+		result1 error
+	}
 	SecretPresentStub        func(context.Context, string, string) (bool, error)
 	secretPresentMutex       sync.RWMutex
 	secretPresentArgsForCall []struct {
@@ -218,6 +311,71 @@ func (fake *FakeKube) ApplyReturnsOnCall(i int, result1 []byte, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeKube) CanI(arg1 context.Context, arg2 v1.ResourceAttributes) (bool, error) {
+	fake.canIMutex.Lock()
+	ret, specificReturn := fake.canIReturnsOnCall[len(fake.canIArgsForCall)]
+	fake.canIArgsForCall = append(fake.canIArgsForCall, struct {
+		arg1 context.Context
+		arg2 v1.ResourceAttributes
+	}{arg1, arg2})
+	stub := fake.CanIStub
+	fakeReturns := fake.canIReturns
+	fake.recordInvocation("CanI", []interface{}{arg1, arg2})
+	fake.canIMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeKube) CanICallCount() int {
+	fake.canIMutex.RLock()
+	defer fake.canIMutex.RUnlock()
+	return len(fake.canIArgsForCall)
+}
+
+func (fake *FakeKube) CanICalls(stub func(context.Context, v1.ResourceAttributes) (bool, error)) {
+	fake.canIMutex.Lock()
+	defer fake.canIMutex.Unlock()
+	fake.CanIStub = stub
+}
+
+func (fake *FakeKube) CanIArgsForCall(i int) (context.Context, v1.ResourceAttributes) {
+	fake.canIMutex.RLock()
+	defer fake.canIMutex.RUnlock()
+	argsForCall := fake.canIArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeKube) CanIReturns(result1 bool, result2 error) {
+	fake.canIMutex.Lock()
+	defer fake.canIMutex.Unlock()
+	fake.CanIStub = nil
+	fake.canIReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeKube) CanIReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.canIMutex.Lock()
+	defer fake.canIMutex.Unlock()
+	fake.CanIStub = nil
+	if fake.canIReturnsOnCall == nil {
+		fake.canIReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.canIReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeKube) Delete(arg1 []byte, arg2 string) ([]byte, error) {
 	var arg1Copy []byte
 	if arg1 != nil {
@@ -607,6 +765,138 @@ func (fake *FakeKube) GetClusterStatusReturnsOnCall(i int, result1 kube.ClusterS
 	}{result1}
 }
 
+func (fake *FakeKube) GetEvents(arg1 context.Context, arg2 string) ([]v1a.Event, error) {
+	fake.getEventsMutex.Lock()
+	ret, specificReturn := fake.getEventsReturnsOnCall[len(fake.getEventsArgsForCall)]
+	fake.getEventsArgsForCall = append(fake.getEventsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetEventsStub
+	fakeReturns := fake.getEventsReturns
+	fake.recordInvocation("GetEvents", []interface{}{arg1, arg2})
+	fake.getEventsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeKube) GetEventsCallCount() int {
+	fake.getEventsMutex.RLock()
+	defer fake.getEventsMutex.RUnlock()
+	return len(fake.getEventsArgsForCall)
+}
+
+func (fake *FakeKube) GetEventsCalls(stub func(context.Context, string) ([]v1a.Event, error)) {
+	fake.getEventsMutex.Lock()
+	defer fake.getEventsMutex.Unlock()
+	fake.GetEventsStub = stub
+}
+
+func (fake *FakeKube) GetEventsArgsForCall(i int) (context.Context, string) {
+	fake.getEventsMutex.RLock()
+	defer fake.getEventsMutex.RUnlock()
+	argsForCall := fake.getEventsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeKube) GetEventsReturns(result1 []v1a.Event, result2 error) {
+	fake.getEventsMutex.Lock()
+	defer fake.getEventsMutex.Unlock()
+	fake.GetEventsStub = nil
+	fake.getEventsReturns = struct {
+		result1 []v1a.Event
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeKube) GetEventsReturnsOnCall(i int, result1 []v1a.Event, result2 error) {
+	fake.getEventsMutex.Lock()
+	defer fake.getEventsMutex.Unlock()
+	fake.GetEventsStub = nil
+	if fake.getEventsReturnsOnCall == nil {
+		fake.getEventsReturnsOnCall = make(map[int]struct {
+			result1 []v1a.Event
+			result2 error
+		})
+	}
+	fake.getEventsReturnsOnCall[i] = struct {
+		result1 []v1a.Event
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeKube) GetPodLogs(arg1 context.Context, arg2 string, arg3 map[string]string, arg4 kube.LogOptions) ([]io.ReadCloser, error) {
+	fake.getPodLogsMutex.Lock()
+	ret, specificReturn := fake.getPodLogsReturnsOnCall[len(fake.getPodLogsArgsForCall)]
+	fake.getPodLogsArgsForCall = append(fake.getPodLogsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 map[string]string
+		arg4 kube.LogOptions
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.GetPodLogsStub
+	fakeReturns := fake.getPodLogsReturns
+	fake.recordInvocation("GetPodLogs", []interface{}{arg1, arg2, arg3, arg4})
+	fake.getPodLogsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeKube) GetPodLogsCallCount() int {
+	fake.getPodLogsMutex.RLock()
+	defer fake.getPodLogsMutex.RUnlock()
+	return len(fake.getPodLogsArgsForCall)
+}
+
+func (fake *FakeKube) GetPodLogsCalls(stub func(context.Context, string, map[string]string, kube.LogOptions) ([]io.ReadCloser, error)) {
+	fake.getPodLogsMutex.Lock()
+	defer fake.getPodLogsMutex.Unlock()
+	fake.GetPodLogsStub = stub
+}
+
+func (fake *FakeKube) GetPodLogsArgsForCall(i int) (context.Context, string, map[string]string, kube.LogOptions) {
+	fake.getPodLogsMutex.RLock()
+	defer fake.getPodLogsMutex.RUnlock()
+	argsForCall := fake.getPodLogsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeKube) GetPodLogsReturns(result1 []io.ReadCloser, result2 error) {
+	fake.getPodLogsMutex.Lock()
+	defer fake.getPodLogsMutex.Unlock()
+	fake.GetPodLogsStub = nil
+	fake.getPodLogsReturns = struct {
+		result1 []io.ReadCloser
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeKube) GetPodLogsReturnsOnCall(i int, result1 []io.ReadCloser, result2 error) {
+	fake.getPodLogsMutex.Lock()
+	defer fake.getPodLogsMutex.Unlock()
+	fake.GetPodLogsStub = nil
+	if fake.getPodLogsReturnsOnCall == nil {
+		fake.getPodLogsReturnsOnCall = make(map[int]struct {
+			result1 []io.ReadCloser
+			result2 error
+		})
+	}
+	fake.getPodLogsReturnsOnCall[i] = struct {
+		result1 []io.ReadCloser
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeKube) GetResource(arg1 context.Context, arg2 types.NamespacedName, arg3 kube.Resource) error {
 	fake.getResourceMutex.Lock()
 	ret, specificReturn := fake.getResourceReturnsOnCall[len(fake.getResourceArgsForCall)]
@@ -670,6 +960,70 @@ func (fake *FakeKube) GetResourceReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeKube) GetServerVersion(arg1 context.Context) (string, error) {
+	fake.getServerVersionMutex.Lock()
+	ret, specificReturn := fake.getServerVersionReturnsOnCall[len(fake.getServerVersionArgsForCall)]
+	fake.getServerVersionArgsForCall = append(fake.getServerVersionArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.GetServerVersionStub
+	fakeReturns := fake.getServerVersionReturns
+	fake.recordInvocation("GetServerVersion", []interface{}{arg1})
+	fake.getServerVersionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeKube) GetServerVersionCallCount() int {
+	fake.getServerVersionMutex.RLock()
+	defer fake.getServerVersionMutex.RUnlock()
+	return len(fake.getServerVersionArgsForCall)
+}
+
+func (fake *FakeKube) GetServerVersionCalls(stub func(context.Context) (string, error)) {
+	fake.getServerVersionMutex.Lock()
+	defer fake.getServerVersionMutex.Unlock()
+	fake.GetServerVersionStub = stub
+}
+
+func (fake *FakeKube) GetServerVersionArgsForCall(i int) context.Context {
+	fake.getServerVersionMutex.RLock()
+	defer fake.getServerVersionMutex.RUnlock()
+	argsForCall := fake.getServerVersionArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeKube) GetServerVersionReturns(result1 string, result2 error) {
+	fake.getServerVersionMutex.Lock()
+	defer fake.getServerVersionMutex.Unlock()
+	fake.GetServerVersionStub = nil
+	fake.getServerVersionReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeKube) GetServerVersionReturnsOnCall(i int, result1 string, result2 error) {
+	fake.getServerVersionMutex.Lock()
+	defer fake.getServerVersionMutex.Unlock()
+	fake.GetServerVersionStub = nil
+	if fake.getServerVersionReturnsOnCall == nil {
+		fake.getServerVersionReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getServerVersionReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeKube) LabelExistsInCluster(arg1 context.Context, arg2 string) error {
 	fake.labelExistsInClusterMutex.Lock()
 	ret, specificReturn := fake.labelExistsInClusterReturnsOnCall[len(fake.labelExistsInClusterArgsForCall)]
@@ -732,6 +1086,138 @@ func (fake *FakeKube) LabelExistsInClusterReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeKube) ListResources(arg1 context.Context, arg2 schema.GroupVersionKind, arg3 string, arg4 map[string]string) ([]unstructured.Unstructured, error) {
+	fake.listResourcesMutex.Lock()
+	ret, specificReturn := fake.listResourcesReturnsOnCall[len(fake.listResourcesArgsForCall)]
+	fake.listResourcesArgsForCall = append(fake.listResourcesArgsForCall, struct {
+		arg1 context.Context
+		arg2 schema.GroupVersionKind
+		arg3 string
+		arg4 map[string]string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ListResourcesStub
+	fakeReturns := fake.listResourcesReturns
+	fake.recordInvocation("ListResources", []interface{}{arg1, arg2, arg3, arg4})
+	fake.listResourcesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeKube) ListResourcesCallCount() int {
+	fake.listResourcesMutex.RLock()
+	defer fake.listResourcesMutex.RUnlock()
+	return len(fake.listResourcesArgsForCall)
+}
+
+func (fake *FakeKube) ListResourcesCalls(stub func(context.Context, schema.GroupVersionKind, string, map[string]string) ([]unstructured.Unstructured, error)) {
+	fake.listResourcesMutex.Lock()
+	defer fake.listResourcesMutex.Unlock()
+	fake.ListResourcesStub = stub
+}
+
+func (fake *FakeKube) ListResourcesArgsForCall(i int) (context.Context, schema.GroupVersionKind, string, map[string]string) {
+	fake.listResourcesMutex.RLock()
+	defer fake.listResourcesMutex.RUnlock()
+	argsForCall := fake.listResourcesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeKube) ListResourcesReturns(result1 []unstructured.Unstructured, result2 error) {
+	fake.listResourcesMutex.Lock()
+	defer fake.listResourcesMutex.Unlock()
+	fake.ListResourcesStub = nil
+	fake.listResourcesReturns = struct {
+		result1 []unstructured.Unstructured
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeKube) ListResourcesReturnsOnCall(i int, result1 []unstructured.Unstructured, result2 error) {
+	fake.listResourcesMutex.Lock()
+	defer fake.listResourcesMutex.Unlock()
+	fake.ListResourcesStub = nil
+	if fake.listResourcesReturnsOnCall == nil {
+		fake.listResourcesReturnsOnCall = make(map[int]struct {
+			result1 []unstructured.Unstructured
+			result2 error
+		})
+	}
+	fake.listResourcesReturnsOnCall[i] = struct {
+		result1 []unstructured.Unstructured
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeKube) PortForward(arg1 context.Context, arg2 string, arg3 string, arg4 int, arg5 int) error {
+	fake.portForwardMutex.Lock()
+	ret, specificReturn := fake.portForwardReturnsOnCall[len(fake.portForwardArgsForCall)]
+	fake.portForwardArgsForCall = append(fake.portForwardArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 int
+		arg5 int
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.PortForwardStub
+	fakeReturns := fake.portForwardReturns
+	fake.recordInvocation("PortForward", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.portForwardMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeKube) PortForwardCallCount() int {
+	fake.portForwardMutex.RLock()
+	defer fake.portForwardMutex.RUnlock()
+	return len(fake.portForwardArgsForCall)
+}
+
+func (fake *FakeKube) PortForwardCalls(stub func(context.Context, string, string, int, int) error) {
+	fake.portForwardMutex.Lock()
+	defer fake.portForwardMutex.Unlock()
+	fake.PortForwardStub = stub
+}
+
+func (fake *FakeKube) PortForwardArgsForCall(i int) (context.Context, string, string, int, int) {
+	fake.portForwardMutex.RLock()
+	defer fake.portForwardMutex.RUnlock()
+	argsForCall := fake.portForwardArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+}
+
+func (fake *FakeKube) PortForwardReturns(result1 error) {
+	fake.portForwardMutex.Lock()
+	defer fake.portForwardMutex.Unlock()
+	fake.PortForwardStub = nil
+	fake.portForwardReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeKube) PortForwardReturnsOnCall(i int, result1 error) {
+	fake.portForwardMutex.Lock()
+	defer fake.portForwardMutex.Unlock()
+	fake.PortForwardStub = nil
+	if fake.portForwardReturnsOnCall == nil {
+		fake.portForwardReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.portForwardReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeKube) SecretPresent(arg1 context.Context, arg2 string, arg3 string) (bool, error) {
 	fake.secretPresentMutex.Lock()
 	ret, specificReturn := fake.secretPresentReturnsOnCall[len(fake.secretPresentArgsForCall)]
@@ -803,6 +1289,8 @@ func (fake *FakeKube) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.applyMutex.RLock()
 	defer fake.applyMutex.RUnlock()
+	fake.canIMutex.RLock()
+	defer fake.canIMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
 	fake.fluxPresentMutex.RLock()
@@ -815,10 +1303,20 @@ func (fake *FakeKube) Invocations() map[string][][]interface{} {
 	defer fake.getClusterNameMutex.RUnlock()
 	fake.getClusterStatusMutex.RLock()
 	defer fake.getClusterStatusMutex.RUnlock()
+	fake.getEventsMutex.RLock()
+	defer fake.getEventsMutex.RUnlock()
+	fake.getPodLogsMutex.RLock()
+	defer fake.getPodLogsMutex.RUnlock()
 	fake.getResourceMutex.RLock()
 	defer fake.getResourceMutex.RUnlock()
+	fake.getServerVersionMutex.RLock()
+	defer fake.getServerVersionMutex.RUnlock()
 	fake.labelExistsInClusterMutex.RLock()
 	defer fake.labelExistsInClusterMutex.RUnlock()
+	fake.listResourcesMutex.RLock()
+	defer fake.listResourcesMutex.RUnlock()
+	fake.portForwardMutex.RLock()
+	defer fake.portForwardMutex.RUnlock()
 	fake.secretPresentMutex.RLock()
 	defer fake.secretPresentMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -3,8 +3,10 @@ package kube
 import (
 	"context"
 	"fmt"
+	"io"
 
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -13,11 +15,14 @@ import (
 	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	"github.com/pkg/errors"
 	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
-	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -66,14 +71,22 @@ func NewKubeHTTPClient() (Kube, error) {
 		return nil, fmt.Errorf("kubernetes client initialization failed: %w", err)
 	}
 
-	return &KubeHTTP{Client: kubeClient, ClusterName: kubeContext}, nil
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes clientset initialization failed: %w", err)
+	}
+
+	return &KubeHTTP{Client: kubeClient, Clientset: clientset, ClusterName: kubeContext}, nil
 }
 
 // This is an alternative implementation of the kube.Kube interface,
 // specifically designed to query the K8s API directly instead of relying on
 // `kubectl` to be present in the PATH.
 type KubeHTTP struct {
-	Client      client.Client
+	Client client.Client
+	// Clientset is used for what the controller-runtime client can't read: pod logs, the server
+	// version and access reviews, and so the status of a cluster without wego or flux
+	Clientset   kubernetes.Interface
 	ClusterName string
 }
 
@@ -96,20 +109,13 @@ func (c *KubeHTTP) GetClusterStatus(ctx context.Context) ClusterStatus {
 		return FluxInstalled
 	}
 
-	dep := appsv1.Deployment{}
-	coreDnsName := types.NamespacedName{
-		Name:      "coredns",
-		Namespace: "kube-system",
-	}
-
-	if err := c.Client.Get(ctx, coreDnsName, &dep); err != nil {
-		// Couldn't find the coredns deployment.
+	if _, err := c.GetServerVersion(ctx); err != nil {
+		// The API server doesn't answer.
 		// We don't know what state the cluster is in.
 		return Unknown
-	} else {
-		// Request for the coredns namespace was successfull.
-		return Unmodified
 	}
+
+	return Unmodified
 }
 
 func (c *KubeHTTP) Apply(manifests []byte, namespace string) ([]byte, error) {
@@ -189,6 +195,64 @@ func (c *KubeHTTP) GetResource(ctx context.Context, name types.NamespacedName, r
 	return nil
 }
 
+// ListResources lists the objects of a kind with the given labels; namespace is ignored for cluster scoped kinds
+func (c *KubeHTTP) ListResources(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+	list := unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+	if err := c.Client.List(ctx, &list, client.InNamespace(namespace), client.MatchingLabels(labels)); err != nil {
+		return nil, fmt.Errorf("could not list %s: %w", gvk.Kind, err)
+	}
+
+	return list.Items, nil
+}
+
+func (c *KubeHTTP) GetEvents(ctx context.Context, namespace string) ([]corev1.Event, error) {
+	list := corev1.EventList{}
+
+	if err := c.Client.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("could not list events: %w", err)
+	}
+
+	return list.Items, nil
+}
+
+// GetPodLogs opens a log stream for each pod with the given labels; the caller closes them
+func (c *KubeHTTP) GetPodLogs(ctx context.Context, namespace string, labels map[string]string, opts LogOptions) ([]io.ReadCloser, error) {
+	if c.Clientset == nil {
+		return nil, errors.New("a clientset is required to get pod logs")
+	}
+
+	pods := corev1.PodList{}
+	if err := c.Client.List(ctx, &pods, client.InNamespace(namespace), client.MatchingLabels(labels)); err != nil {
+		return nil, fmt.Errorf("could not list pods: %w", err)
+	}
+
+	logOptions := &corev1.PodLogOptions{Follow: opts.Follow}
+
+	if opts.Since > 0 {
+		seconds := int64(opts.Since.Seconds())
+		logOptions.SinceSeconds = &seconds
+	}
+
+	streams := []io.ReadCloser{}
+
+	for _, pod := range pods.Items {
+		stream, err := c.Clientset.CoreV1().Pods(namespace).GetLogs(pod.Name, logOptions).Stream(ctx)
+		if err != nil {
+			for _, s := range streams {
+				s.Close()
+			}
+
+			return nil, fmt.Errorf("could not get logs of pod %s: %w", pod.Name, err)
+		}
+
+		streams = append(streams, stream)
+	}
+
+	return streams, nil
+}
+
 func initialContexts(cfgLoadingRules *clientcmd.ClientConfigLoadingRules) (contexts []string, currentCtx string, err error) {
 	rules, err := cfgLoadingRules.Load()
 
@@ -202,3 +266,41 @@ func initialContexts(cfgLoadingRules *clientcmd.ClientConfigLoadingRules) (conte
 
 	return contexts, rules.CurrentContext, nil
 }
+
+// GetServerVersion returns the git version of the Kubernetes API server, e.g. v1.21.1
+func (c *KubeHTTP) GetServerVersion(ctx context.Context) (string, error) {
+	if c.Clientset == nil {
+		return "", errors.New("a clientset is required to get the server version")
+	}
+
+	info, err := c.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the server version: %w", err)
+	}
+
+	return info.GitVersion, nil
+}
+
+// CanI reports whether the current user is allowed an action, through a SelfSubjectAccessReview
+func (c *KubeHTTP) CanI(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+	if c.Clientset == nil {
+		return false, errors.New("a clientset is required to review access")
+	}
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+		},
+	}
+
+	review, err := c.Clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("could not review access: %w", err)
+	}
+
+	return review.Status.Allowed, nil
+}
+
+func (c *KubeHTTP) PortForward(ctx context.Context, namespace string, service string, localPort int, remotePort int) error {
+	return errors.New("method not implemented, use the kubectl implementation of the kube interface")
+}
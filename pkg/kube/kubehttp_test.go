@@ -7,10 +7,12 @@ import (
 	. "github.com/onsi/gomega"
 	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
 )
 
 var _ = Describe("KubeHTTP", func() {
@@ -25,7 +27,7 @@ var _ = Describe("KubeHTTP", func() {
 		err = k8sClient.Create(context.Background(), namespace)
 		Expect(err).NotTo(HaveOccurred(), "failed to create test namespace")
 
-		k = &kube.KubeHTTP{Client: k8sClient, ClusterName: testClustername}
+		k = &kube.KubeHTTP{Client: k8sClient, Clientset: kubernetes.NewForConfigOrDie(cfg), ClusterName: testClustername}
 	})
 	AfterEach(func() {
 		err = k8sClient.Delete(context.Background(), namespace)
@@ -129,4 +131,41 @@ var _ = Describe("KubeHTTP", func() {
 		Expect(list[0].Name).To(Equal(name))
 
 	})
+	It("ListResources", func() {
+		ctx := context.Background()
+
+		for _, name := range []string{"labelled", "other"} {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace.Name,
+					Labels:    map[string]string{"app": name},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, cm)).Should(Succeed())
+		}
+
+		gvk := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+
+		list, err := k.ListResources(ctx, gvk, namespace.Name, map[string]string{"app": "labelled"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list).To(HaveLen(1))
+		Expect(list[0].GetName()).To(Equal("labelled"))
+	})
+	It("GetServerVersion", func() {
+		version, err := k.GetServerVersion(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(HavePrefix("v1."))
+	})
+	It("requires a clientset to query the server", func() {
+		ctx := context.Background()
+		k = &kube.KubeHTTP{Client: k8sClient, ClusterName: testClustername}
+
+		_, err := k.GetServerVersion(ctx)
+		Expect(err).To(MatchError("a clientset is required to get the server version"))
+
+		_, err = k.CanI(ctx, authorizationv1.ResourceAttributes{Verb: "create", Resource: "namespaces"})
+		Expect(err).To(MatchError("a clientset is required to review access"))
+	})
 })
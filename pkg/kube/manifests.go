@@ -0,0 +1,46 @@
+package kube
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// DecodeObjects decodes the kubernetes objects of multi-document manifests, in the order they appear. Empty
+// documents and documents that are not kubernetes objects, like kustomize configurations, are skipped.
+func DecodeObjects(manifests ...[]byte) ([]*unstructured.Unstructured, error) {
+	objects := []*unstructured.Unstructured{}
+
+	for _, manifest := range manifests {
+		reader := k8syaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(manifest)))
+
+		for {
+			doc, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			object := map[string]interface{}{}
+			if err := yaml.Unmarshal(doc, &object); err != nil {
+				return nil, err
+			}
+
+			if len(object) == 0 || object["kind"] == nil || object["apiVersion"] == nil {
+				continue
+			}
+
+			objects = append(objects, &unstructured.Unstructured{Object: object})
+		}
+	}
+
+	return objects, nil
+}
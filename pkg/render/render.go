@@ -0,0 +1,90 @@
+package render
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/weaveworks/weave-gitops/pkg/runner"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+const (
+	kubectlPath = "kubectl"
+	helmPath    = "helm"
+)
+
+// HelmChart describes a chart to template, either from a local path or from a helm repository
+type HelmChart struct {
+	// Chart is the chart name in the repository, or its path when RepoURL is empty
+	Chart   string
+	RepoURL string
+	Version string
+}
+
+// Renderer builds the manifests of an app the way the flux controllers do
+//counterfeiter:generate . Renderer
+type Renderer interface {
+	// Kustomize builds the kustomization in a directory
+	Kustomize(dir string) ([]byte, error)
+	// HelmTemplate renders a chart for a release, with the given values
+	HelmTemplate(releaseName string, namespace string, chart HelmChart, values []byte) ([]byte, error)
+}
+
+type CLIRenderer struct {
+	runner runner.Runner
+}
+
+func New(cliRunner runner.Runner) *CLIRenderer {
+	return &CLIRenderer{
+		runner: cliRunner,
+	}
+}
+
+var _ Renderer = &CLIRenderer{}
+
+func (r *CLIRenderer) Kustomize(dir string) ([]byte, error) {
+	out, err := r.runner.Run(kubectlPath, "kustomize", dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kustomization in %s: %s: %w", dir, string(out), err)
+	}
+
+	return out, nil
+}
+
+func (r *CLIRenderer) HelmTemplate(releaseName string, namespace string, chart HelmChart, values []byte) ([]byte, error) {
+	args := []string{"template", releaseName, chart.Chart, "--namespace", namespace}
+
+	if chart.RepoURL != "" {
+		args = append(args, "--repo", chart.RepoURL)
+	}
+
+	if chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+
+	if len(values) > 0 {
+		valuesFile, err := ioutil.TempFile("", "helm-values-")
+		if err != nil {
+			return nil, fmt.Errorf("failed creating values file: %w", err)
+		}
+		defer os.Remove(valuesFile.Name())
+
+		_, err = valuesFile.Write(values)
+		valuesFile.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed writing values file: %w", err)
+		}
+
+		args = append(args, "--values", valuesFile.Name())
+	}
+
+	out, err := r.runner.Run(helmPath, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to template chart %s: %s: %w", chart.Chart, string(out), err)
+	}
+
+	return out, nil
+}
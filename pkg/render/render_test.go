@@ -0,0 +1,77 @@
+package render_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/render"
+	"github.com/weaveworks/weave-gitops/pkg/runner/runnerfakes"
+)
+
+var (
+	runner   *runnerfakes.FakeRunner
+	renderer *render.CLIRenderer
+)
+
+var _ = BeforeEach(func() {
+	runner = &runnerfakes.FakeRunner{}
+
+	renderer = render.New(runner)
+})
+
+var _ = Describe("Kustomize", func() {
+	It("builds the kustomization with kubectl", func() {
+		runner.RunReturns([]byte("manifests"), nil)
+
+		out, err := renderer.Kustomize("./deploy")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("manifests")))
+
+		cmd, args := runner.RunArgsForCall(0)
+		Expect(cmd).To(Equal("kubectl"))
+		Expect(args).To(Equal([]string{"kustomize", "./deploy"}))
+	})
+
+	It("returns the kubectl output on failure", func() {
+		runner.RunReturns([]byte("missing resource"), errors.New("exit status 1"))
+
+		_, err := renderer.Kustomize("./deploy")
+		Expect(err).To(MatchError("failed to build kustomization in ./deploy: missing resource: exit status 1"))
+	})
+})
+
+var _ = Describe("HelmTemplate", func() {
+	It("templates a chart from a helm repository with values", func() {
+		var values string
+
+		runner.RunStub = func(cmd string, args ...string) ([]byte, error) {
+			file, err := ioutil.ReadFile(args[len(args)-1])
+			Expect(err).ShouldNot(HaveOccurred())
+			values = string(file)
+
+			return []byte("manifests"), nil
+		}
+
+		chart := render.HelmChart{Chart: "podinfo", RepoURL: "https://stefanprodan.github.io/podinfo", Version: "6.0.0"}
+
+		out, err := renderer.HelmTemplate("podinfo", "apps", chart, []byte("replicaCount: 2\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(Equal([]byte("manifests")))
+
+		cmd, args := runner.RunArgsForCall(0)
+		Expect(cmd).To(Equal("helm"))
+		Expect(strings.Join(args[:len(args)-1], " ")).To(Equal("template podinfo podinfo --namespace apps --repo https://stefanprodan.github.io/podinfo --version 6.0.0 --values"))
+		Expect(values).To(Equal("replicaCount: 2\n"))
+	})
+
+	It("templates a local chart without values", func() {
+		_, err := renderer.HelmTemplate("podinfo", "wego-system", render.HelmChart{Chart: "./charts/podinfo"}, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, args := runner.RunArgsForCall(0)
+		Expect(args).To(Equal([]string{"template", "podinfo", "./charts/podinfo", "--namespace", "wego-system"}))
+	})
+})
@@ -0,0 +1,206 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package renderfakes
+
+import (
+	"sync"
+
+	"github.com/weaveworks/weave-gitops/pkg/render"
+)
+
+type FakeRenderer struct {
+	HelmTemplateStub        func(string, string, render.HelmChart, []byte) ([]byte, error)
+	helmTemplateMutex       sync.RWMutex
+	helmTemplateArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 render.HelmChart
+		arg4 []byte
+	}
+	helmTemplateReturns struct {
+		result1 []byte
+		result2 error
+	}
+	helmTemplateReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	KustomizeStub        func(string) ([]byte, error)
+	kustomizeMutex       sync.RWMutex
+	kustomizeArgsForCall []struct {
+		arg1 string
+	}
+	kustomizeReturns struct {
+		result1 []byte
+		result2 error
+	}
+	kustomizeReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRenderer) HelmTemplate(arg1 string, arg2 string, arg3 render.HelmChart, arg4 []byte) ([]byte, error) {
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.helmTemplateMutex.Lock()
+	ret, specificReturn := fake.helmTemplateReturnsOnCall[len(fake.helmTemplateArgsForCall)]
+	fake.helmTemplateArgsForCall = append(fake.helmTemplateArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 render.HelmChart
+		arg4 []byte
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.HelmTemplateStub
+	fakeReturns := fake.helmTemplateReturns
+	fake.recordInvocation("HelmTemplate", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.helmTemplateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRenderer) HelmTemplateCallCount() int {
+	fake.helmTemplateMutex.RLock()
+	defer fake.helmTemplateMutex.RUnlock()
+	return len(fake.helmTemplateArgsForCall)
+}
+
+func (fake *FakeRenderer) HelmTemplateCalls(stub func(string, string, render.HelmChart, []byte) ([]byte, error)) {
+	fake.helmTemplateMutex.Lock()
+	defer fake.helmTemplateMutex.Unlock()
+	fake.HelmTemplateStub = stub
+}
+
+func (fake *FakeRenderer) HelmTemplateArgsForCall(i int) (string, string, render.HelmChart, []byte) {
+	fake.helmTemplateMutex.RLock()
+	defer fake.helmTemplateMutex.RUnlock()
+	argsForCall := fake.helmTemplateArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeRenderer) HelmTemplateReturns(result1 []byte, result2 error) {
+	fake.helmTemplateMutex.Lock()
+	defer fake.helmTemplateMutex.Unlock()
+	fake.HelmTemplateStub = nil
+	fake.helmTemplateReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRenderer) HelmTemplateReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.helmTemplateMutex.Lock()
+	defer fake.helmTemplateMutex.Unlock()
+	fake.HelmTemplateStub = nil
+	if fake.helmTemplateReturnsOnCall == nil {
+		fake.helmTemplateReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.helmTemplateReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRenderer) Kustomize(arg1 string) ([]byte, error) {
+	fake.kustomizeMutex.Lock()
+	ret, specificReturn := fake.kustomizeReturnsOnCall[len(fake.kustomizeArgsForCall)]
+	fake.kustomizeArgsForCall = append(fake.kustomizeArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.KustomizeStub
+	fakeReturns := fake.kustomizeReturns
+	fake.recordInvocation("Kustomize", []interface{}{arg1})
+	fake.kustomizeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeRenderer) KustomizeCallCount() int {
+	fake.kustomizeMutex.RLock()
+	defer fake.kustomizeMutex.RUnlock()
+	return len(fake.kustomizeArgsForCall)
+}
+
+func (fake *FakeRenderer) KustomizeCalls(stub func(string) ([]byte, error)) {
+	fake.kustomizeMutex.Lock()
+	defer fake.kustomizeMutex.Unlock()
+	fake.KustomizeStub = stub
+}
+
+func (fake *FakeRenderer) KustomizeArgsForCall(i int) string {
+	fake.kustomizeMutex.RLock()
+	defer fake.kustomizeMutex.RUnlock()
+	argsForCall := fake.kustomizeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeRenderer) KustomizeReturns(result1 []byte, result2 error) {
+	fake.kustomizeMutex.Lock()
+	defer fake.kustomizeMutex.Unlock()
+	fake.KustomizeStub = nil
+	fake.kustomizeReturns = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRenderer) KustomizeReturnsOnCall(i int, result1 []byte, result2 error) {
+	fake.kustomizeMutex.Lock()
+	defer fake.kustomizeMutex.Unlock()
+	fake.KustomizeStub = nil
+	if fake.kustomizeReturnsOnCall == nil {
+		fake.kustomizeReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 error
+		})
+	}
+	fake.kustomizeReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeRenderer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.helmTemplateMutex.RLock()
+	defer fake.helmTemplateMutex.RUnlock()
+	fake.kustomizeMutex.RLock()
+	defer fake.kustomizeMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRenderer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ render.Renderer = new(FakeRenderer)
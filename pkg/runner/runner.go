@@ -2,6 +2,7 @@ package runner
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os/exec"
@@ -22,6 +23,9 @@ type Runner interface {
 	RunWithOutputStream(command string, args ...string) ([]byte, error)
 	// RunWithStdin take a command name, arguments and passes stdin data to the command
 	RunWithStdin(command string, args []string, stdinData []byte) ([]byte, error)
+	// RunWithStdinSplitOutput takes a command name, arguments and passes stdin data to the command, returning its stdout
+	// and stderr separately, for commands whose output is kept and whose warnings must not end up in it
+	RunWithStdinSplitOutput(command string, args []string, stdinData []byte) ([]byte, []byte, error)
 }
 
 // CLIRunner will use exec.Command as runtime medium.
@@ -98,3 +102,17 @@ func (*CLIRunner) RunWithStdin(c string, args []string, stdinData []byte) ([]byt
 
 	return cmd.CombinedOutput()
 }
+
+func (*CLIRunner) RunWithStdinSplitOutput(c string, args []string, stdinData []byte) ([]byte, []byte, error) {
+	cmd := exec.Command(c, args...)
+
+	var stdout, stderr bytes.Buffer
+
+	cmd.Stdin = bytes.NewReader(stdinData)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	return stdout.Bytes(), stderr.Bytes(), err
+}
@@ -37,6 +37,21 @@ var _ = Describe("RunWithStdin", func() {
 	})
 })
 
+var _ = Describe("RunWithStdinSplitOutput", func() {
+	It("returns stdout and stderr separately", func() {
+		stdout, stderr, err := cliRunner.RunWithStdinSplitOutput("sh", []string{"-c", "cat; echo 1>&2 warning"}, []byte("bla"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(stdout)).To(Equal("bla"))
+		Expect(string(stderr)).To(Equal("warning\n"))
+	})
+
+	It("returns stderr when the command fails", func() {
+		_, stderr, err := cliRunner.RunWithStdinSplitOutput("sh", []string{"-c", "echo 1>&2 failed; exit 1"}, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(string(stderr)).To(Equal("failed\n"))
+	})
+})
+
 var _ = Describe("RunWithOutputStream", func() {
 	It("runs a command outputting data into stdout in real time", func() {
 		output := CaptureStdout(func() {
@@ -51,6 +51,23 @@ type FakeRunner struct {
 		result1 []byte
 		result2 error
 	}
+	RunWithStdinSplitOutputStub        func(string, []string, []byte) ([]byte, []byte, error)
+	runWithStdinSplitOutputMutex       sync.RWMutex
+	runWithStdinSplitOutputArgsForCall []struct {
+		arg1 string
+		arg2 []string
+		arg3 []byte
+	}
+	runWithStdinSplitOutputReturns struct {
+		result1 []byte
+		result2 []byte
+		result3 error
+	}
+	runWithStdinSplitOutputReturnsOnCall map[int]struct {
+		result1 []byte
+		result2 []byte
+		result3 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -261,6 +278,85 @@ func (fake *FakeRunner) RunWithStdinReturnsOnCall(i int, result1 []byte, result2
 	}{result1, result2}
 }
 
+func (fake *FakeRunner) RunWithStdinSplitOutput(arg1 string, arg2 []string, arg3 []byte) ([]byte, []byte, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	var arg3Copy []byte
+	if arg3 != nil {
+		arg3Copy = make([]byte, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.runWithStdinSplitOutputMutex.Lock()
+	ret, specificReturn := fake.runWithStdinSplitOutputReturnsOnCall[len(fake.runWithStdinSplitOutputArgsForCall)]
+	fake.runWithStdinSplitOutputArgsForCall = append(fake.runWithStdinSplitOutputArgsForCall, struct {
+		arg1 string
+		arg2 []string
+		arg3 []byte
+	}{arg1, arg2Copy, arg3Copy})
+	stub := fake.RunWithStdinSplitOutputStub
+	fakeReturns := fake.runWithStdinSplitOutputReturns
+	fake.recordInvocation("RunWithStdinSplitOutput", []interface{}{arg1, arg2Copy, arg3Copy})
+	fake.runWithStdinSplitOutputMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeRunner) RunWithStdinSplitOutputCallCount() int {
+	fake.runWithStdinSplitOutputMutex.RLock()
+	defer fake.runWithStdinSplitOutputMutex.RUnlock()
+	return len(fake.runWithStdinSplitOutputArgsForCall)
+}
+
+func (fake *FakeRunner) RunWithStdinSplitOutputCalls(stub func(string, []string, []byte) ([]byte, []byte, error)) {
+	fake.runWithStdinSplitOutputMutex.Lock()
+	defer fake.runWithStdinSplitOutputMutex.Unlock()
+	fake.RunWithStdinSplitOutputStub = stub
+}
+
+func (fake *FakeRunner) RunWithStdinSplitOutputArgsForCall(i int) (string, []string, []byte) {
+	fake.runWithStdinSplitOutputMutex.RLock()
+	defer fake.runWithStdinSplitOutputMutex.RUnlock()
+	argsForCall := fake.runWithStdinSplitOutputArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeRunner) RunWithStdinSplitOutputReturns(result1 []byte, result2 []byte, result3 error) {
+	fake.runWithStdinSplitOutputMutex.Lock()
+	defer fake.runWithStdinSplitOutputMutex.Unlock()
+	fake.RunWithStdinSplitOutputStub = nil
+	fake.runWithStdinSplitOutputReturns = struct {
+		result1 []byte
+		result2 []byte
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeRunner) RunWithStdinSplitOutputReturnsOnCall(i int, result1 []byte, result2 []byte, result3 error) {
+	fake.runWithStdinSplitOutputMutex.Lock()
+	defer fake.runWithStdinSplitOutputMutex.Unlock()
+	fake.RunWithStdinSplitOutputStub = nil
+	if fake.runWithStdinSplitOutputReturnsOnCall == nil {
+		fake.runWithStdinSplitOutputReturnsOnCall = make(map[int]struct {
+			result1 []byte
+			result2 []byte
+			result3 error
+		})
+	}
+	fake.runWithStdinSplitOutputReturnsOnCall[i] = struct {
+		result1 []byte
+		result2 []byte
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeRunner) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -270,6 +366,8 @@ func (fake *FakeRunner) Invocations() map[string][][]interface{} {
 	defer fake.runWithOutputStreamMutex.RUnlock()
 	fake.runWithStdinMutex.RLock()
 	defer fake.runWithStdinMutex.RUnlock()
+	fake.runWithStdinSplitOutputMutex.RLock()
+	defer fake.runWithStdinSplitOutputMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"time"
 
 	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
@@ -10,6 +11,7 @@ import (
 	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
 	pb "github.com/weaveworks/weave-gitops/pkg/api/applications"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
+	appsvc "github.com/weaveworks/weave-gitops/pkg/services/app"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -60,7 +62,7 @@ func (s *server) GetApplication(ctx context.Context, msg *pb.GetApplicationReque
 		return nil, fmt.Errorf("could not get flux objects for application \"%s\": %w", app.Name, err)
 	}
 
-	name := types.NamespacedName{Name: app.Name, Namespace: app.Namespace}
+	name := types.NamespacedName{Name: app.Name, Namespace: app.GetFluxNamespace()}
 
 	if err := s.kube.GetResource(ctx, name, src); err != nil {
 		if apierrors.IsNotFound(err) {
@@ -88,6 +90,8 @@ func (s *server) GetApplication(ctx context.Context, msg *pb.GetApplicationReque
 			srcK8sConditions = st.Status.Conditions
 		case *sourcev1.HelmRepository:
 			srcK8sConditions = st.Status.Conditions
+		case *sourcev1.Bucket:
+			srcK8sConditions = st.Status.Conditions
 		}
 
 		srcConditions = mapConditions(srcK8sConditions)
@@ -116,6 +120,61 @@ func (s *server) GetApplication(ctx context.Context, msg *pb.GetApplicationReque
 	}}, nil
 }
 
+func (s *server) GetApplicationResources(ctx context.Context, msg *pb.GetApplicationResourcesRequest) (*pb.GetApplicationResourcesResponse, error) {
+	app, err := s.kube.GetApplication(ctx, types.NamespacedName{Name: msg.Name, Namespace: msg.Namespace})
+	if err != nil {
+		return nil, fmt.Errorf("could not get application \"%s\": %w", msg.Name, err)
+	}
+
+	resources, err := appsvc.GetAppResources(ctx, s.kube, *app)
+	if err != nil {
+		return nil, fmt.Errorf("could not get resources for application \"%s\": %w", app.Name, err)
+	}
+
+	list := []*pb.ApplicationResource{}
+	for _, r := range resources {
+		list = append(list, &pb.ApplicationResource{
+			Kind:      r.Kind,
+			Namespace: r.Namespace,
+			Name:      r.Name,
+			Health:    r.Health,
+			Message:   r.Message,
+		})
+	}
+
+	return &pb.GetApplicationResourcesResponse{Resources: list}, nil
+}
+
+func (s *server) GetApplicationLogs(ctx context.Context, msg *pb.GetApplicationLogsRequest) (*pb.GetApplicationLogsResponse, error) {
+	app, err := s.kube.GetApplication(ctx, types.NamespacedName{Name: msg.Name, Namespace: msg.Namespace})
+	if err != nil {
+		return nil, fmt.Errorf("could not get application \"%s\": %w", msg.Name, err)
+	}
+
+	params := appsvc.LogsParams{
+		Name:      app.Name,
+		Namespace: app.Namespace,
+		Since:     time.Duration(msg.SinceSeconds) * time.Second,
+	}
+
+	entries := []*pb.LogEntry{}
+
+	err = appsvc.GetAppLogs(ctx, s.kube, *app, params, func(entry appsvc.LogEntry) {
+		entries = append(entries, &pb.LogEntry{
+			Timestamp: int32(entry.Time.Unix()),
+			Source:    entry.Source,
+			Level:     entry.Level,
+			Object:    entry.Object,
+			Message:   entry.Message,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get logs for application \"%s\": %w", app.Name, err)
+	}
+
+	return &pb.GetApplicationLogsResponse{Entries: entries}, nil
+}
+
 // Returns k8s objects that can be used to find the cluster objects.
 // The first return argument is the source, the second is the deployment
 func findFluxObjects(app *wego.Application) (client.Object, client.Object, error) {
@@ -132,6 +191,8 @@ func findFluxObjects(app *wego.Application) (client.Object, client.Object, error
 		src = &sourcev1.GitRepository{}
 	case wego.SourceTypeHelm:
 		src = &sourcev1.HelmRepository{}
+	case wego.SourceTypeBucket:
+		src = &sourcev1.Bucket{}
 	}
 
 	if src == nil {
@@ -2,12 +2,19 @@ package server_test
 
 import (
 	"context"
+	"time"
 
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
 	"github.com/weaveworks/weave-gitops/pkg/api/applications"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -45,4 +52,133 @@ var _ = Describe("ApplicationsServer", func() {
 
 		Expect(res.Application.Name).To(Equal("my-app"))
 	})
+	It("GetApplication with a bucket source", func() {
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return &wego.Application{
+				ObjectMeta: v1.ObjectMeta{Name: "my-app"},
+				Spec: wego.ApplicationSpec{
+					Path:           "bar",
+					SourceType:     wego.SourceTypeBucket,
+					DeploymentType: wego.DeploymentTypeKustomize,
+				},
+			}, nil
+		}
+
+		kubeClient.GetResourceStub = func(ctx context.Context, name types.NamespacedName, obj kube.Resource) error {
+			if bucket, ok := obj.(*sourcev1.Bucket); ok {
+				bucket.Status.Conditions = []v1.Condition{{Type: "Ready", Status: v1.ConditionTrue}}
+			}
+
+			return nil
+		}
+
+		res, err := client.GetApplication(context.Background(), &applications.GetApplicationRequest{Name: "my-app"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(res.Application.SourceConditions).To(HaveLen(1))
+		Expect(res.Application.SourceConditions[0].Type).To(Equal("Ready"))
+	})
+	It("GetApplication with a failed signature verification", func() {
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return &wego.Application{
+				ObjectMeta: v1.ObjectMeta{Name: "my-app"},
+				Spec: wego.ApplicationSpec{
+					Path:               "bar",
+					SourceType:         wego.SourceTypeGit,
+					VerificationSecret: "weave-gitops-my-app-trusted-keys",
+				},
+			}, nil
+		}
+
+		kubeClient.GetResourceStub = func(ctx context.Context, name types.NamespacedName, obj kube.Resource) error {
+			if repo, ok := obj.(*sourcev1.GitRepository); ok {
+				repo.Status.Conditions = []v1.Condition{{
+					Type:    "Ready",
+					Status:  v1.ConditionFalse,
+					Reason:  sourcev1.VerificationFailedReason,
+					Message: "PGP signature of commit 'abc' could not be verified",
+				}}
+			}
+
+			return nil
+		}
+
+		res, err := client.GetApplication(context.Background(), &applications.GetApplicationRequest{Name: "my-app"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(res.Application.SourceConditions).To(HaveLen(1))
+		Expect(res.Application.SourceConditions[0].Status).To(Equal("False"))
+		Expect(res.Application.SourceConditions[0].Reason).To(Equal(sourcev1.VerificationFailedReason))
+		Expect(res.Application.SourceConditions[0].Message).To(Equal("PGP signature of commit 'abc' could not be verified"))
+	})
+	It("GetApplicationResources", func() {
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return &wego.Application{
+				ObjectMeta: v1.ObjectMeta{Name: "my-app", Namespace: "wego-system"},
+				Spec:       wego.ApplicationSpec{DeploymentType: wego.DeploymentTypeKustomize},
+			}, nil
+		}
+
+		kubeClient.GetResourceStub = func(ctx context.Context, name types.NamespacedName, obj kube.Resource) error {
+			if kustomization, ok := obj.(*kustomizev1.Kustomization); ok {
+				kustomization.Status.Snapshot = &kustomizev1.Snapshot{
+					Entries: []kustomizev1.SnapshotEntry{{Namespace: "apps", Kinds: map[string]string{"/v1, Kind=ConfigMap": "ConfigMap"}}},
+				}
+			}
+
+			return nil
+		}
+
+		kubeClient.ListResourcesStub = func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+			cm := unstructured.Unstructured{}
+			cm.SetGroupVersionKind(gvk)
+			cm.SetName("config")
+			cm.SetNamespace(namespace)
+			cm.SetUID("uid")
+
+			return []unstructured.Unstructured{cm}, nil
+		}
+
+		res, err := client.GetApplicationResources(context.Background(), &applications.GetApplicationResourcesRequest{Name: "my-app"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(res.Resources).To(HaveLen(1))
+		Expect(res.Resources[0].Kind).To(Equal("ConfigMap"))
+		Expect(res.Resources[0].Namespace).To(Equal("apps"))
+		Expect(res.Resources[0].Name).To(Equal("config"))
+		Expect(res.Resources[0].Health).To(Equal("Current"))
+	})
+	It("GetApplicationLogs", func() {
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return &wego.Application{
+				ObjectMeta: v1.ObjectMeta{Name: "my-app", Namespace: "wego-system"},
+				Spec:       wego.ApplicationSpec{DeploymentType: wego.DeploymentTypeKustomize},
+			}, nil
+		}
+
+		kubeClient.GetEventsStub = func(ctx context.Context, namespace string) ([]corev1.Event, error) {
+			return []corev1.Event{
+				{
+					InvolvedObject: corev1.ObjectReference{Kind: "Kustomization", Name: "my-app", Namespace: "wego-system"},
+					Type:           corev1.EventTypeWarning,
+					LastTimestamp:  v1.NewTime(time.Unix(1000, 0)),
+					Message:        "validation failed",
+				},
+				{
+					InvolvedObject: corev1.ObjectReference{Kind: "Kustomization", Name: "other-app", Namespace: "wego-system"},
+					Message:        "another app",
+				},
+			}, nil
+		}
+
+		res, err := client.GetApplicationLogs(context.Background(), &applications.GetApplicationLogsRequest{Name: "my-app"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(res.Entries).To(HaveLen(1))
+		Expect(res.Entries[0].Timestamp).To(Equal(int32(1000)))
+		Expect(res.Entries[0].Source).To(Equal("event"))
+		Expect(res.Entries[0].Level).To(Equal("Warning"))
+		Expect(res.Entries[0].Object).To(Equal("Kustomization/my-app.wego-system"))
+		Expect(res.Entries[0].Message).To(Equal("validation failed"))
+	})
 })
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -33,8 +34,17 @@ type ResourceRef struct {
 
 type AppResourceInfo struct {
 	wego.Application
-	clusterName string
-	targetName  string
+	clusterName     string
+	targetName      string
+	targetNamespace string
+}
+
+// targetAutomation holds the source, automation and notification manifests generated for one of the app's targets
+type targetAutomation struct {
+	info          *AppResourceInfo
+	source        []byte
+	goat          []byte
+	notifications []byte
 }
 
 const (
@@ -42,23 +52,60 @@ const (
 	ConfigTypeNone     ConfigType = "NONE"
 
 	WeGOAppIdentifierLabelKey = "weave-gitops.weave.works/app-identifier"
+
+	DefaultCommitAuthorName  = "Weave Gitops"
+	DefaultCommitAuthorEmail = "weave-gitops@weave.works"
 )
 
 type AddParams struct {
-	Dir              string
-	Name             string
-	Url              string
-	Path             string
-	Branch           string
-	PrivateKey       string
-	DeploymentType   string
-	Chart            string
-	SourceType       string
-	AppConfigUrl     string
-	Namespace        string
-	DryRun           bool
-	AutoMerge        bool
-	GitProviderToken string
+	Dir                   string
+	Name                  string
+	Url                   string
+	Path                  string
+	Branch                string
+	Tag                   string
+	SemVer                string
+	Commit                string
+	PrivateKey            string
+	DeploymentType        string
+	Chart                 string
+	SourceType            string
+	AppConfigUrl          string
+	Namespace             string
+	FluxNamespace         string
+	DryRun                bool
+	AutoMerge             bool
+	GitProviderToken      string
+	ChartVersion          string
+	ValuesFiles           []string
+	SetValues             []string
+	ValuesFrom            []string
+	BucketName            string
+	BucketProvider        string
+	BucketRegion          string
+	BucketSecretRef       string
+	BucketInsecure        bool
+	Targets               []string
+	TargetNamespace       string
+	ServiceAccount        string
+	GitAuth               string
+	SourceToken           string
+	UseProviderToken      bool
+	SecretEncryption      string
+	SopsAgeRecipients     []string
+	SopsPGPFingerprints   []string
+	DecryptionSecret      string
+	SealedSecretsCert     string
+	CommitAuthorName      string
+	CommitAuthorEmail     string
+	VerifySignatures      bool
+	VerificationKeyring   string
+	InMemoryClone         bool
+	Notify                []string
+	NotifySecretRef       string
+	NotifySeverity        string
+	CommitStatus          string
+	CommitStatusSecretRef string
 }
 
 // Three models:
@@ -113,6 +160,8 @@ func (a *App) Add(params AddParams) error {
 	switch clusterStatus {
 	case kube.Unmodified:
 		return fmt.Errorf("Wego not installed... exiting")
+	case kube.FluxInstalled:
+		return fmt.Errorf("Wego not installed alongside flux, run 'wego gitops install'... exiting")
 	case kube.Unknown:
 		return fmt.Errorf("Wego can not determine cluster status... exiting")
 	}
@@ -122,7 +171,49 @@ func (a *App) Add(params AddParams) error {
 		return err
 	}
 
-	info := getAppResourceInfo(makeWegoApplication(params), clusterName)
+	app := makeWegoApplication(params)
+	if err := setHelmValues(&app, params); err != nil {
+		return fmt.Errorf("could not set helm values: %w", err)
+	}
+
+	if err := setTargets(&app, params); err != nil {
+		return fmt.Errorf("could not set targets: %w", err)
+	}
+
+	if err := setSecretEncryption(&app, params); err != nil {
+		return fmt.Errorf("could not set secret encryption: %w", err)
+	}
+
+	if err := setSignatureVerification(&app, params); err != nil {
+		return fmt.Errorf("could not set signature verification: %w", err)
+	}
+
+	if err := setSourceRef(&app, params); err != nil {
+		return fmt.Errorf("could not set source ref: %w", err)
+	}
+
+	if err := setCommitStatus(&app, params); err != nil {
+		return fmt.Errorf("could not set commit status: %w", err)
+	}
+
+	if _, err := parseNotifications(params.Notify, params.NotifySecretRef); err != nil {
+		return fmt.Errorf("could not set notifications: %w", err)
+	}
+
+	info := getAppResourceInfo(app, clusterName)
+
+	if err := validateTargetClusters(info); err != nil {
+		return fmt.Errorf("could not set targets: %w", err)
+	}
+
+	params.SourceToken, err = a.resolveSourceToken(info, params)
+	if err != nil {
+		return err
+	}
+
+	if err := a.validateSourceRef(ctx, info); err != nil {
+		return err
+	}
 
 	gitProvider, err := a.gitProviderFactory(params.GitProviderToken)
 	if err != nil {
@@ -130,11 +221,31 @@ func (a *App) Add(params AddParams) error {
 	}
 
 	var secretRef string
+
+	secrets := []targetSecret{}
+
 	if wego.SourceType(params.SourceType) == wego.SourceTypeGit {
-		secretRef, err = a.createAndUploadDeployKey(info, params.DryRun, info.Spec.URL, gitProvider)
+		var repoSecrets []targetSecret
+
+		secretRef, repoSecrets, err = a.createTargetRepoSecrets(info, params, gitProvider)
 		if err != nil {
 			return fmt.Errorf("could not generate deploy key: %w", err)
 		}
+
+		secrets = append(secrets, repoSecrets...)
+	}
+
+	if info.Spec.VerificationSecret != "" {
+		secret, err := a.createVerificationSecret(info, params)
+		if err != nil {
+			return fmt.Errorf("could not create verification secret: %w", err)
+		}
+
+		if secret != nil {
+			for _, target := range info.targetInfos() {
+				secrets = append(secrets, targetSecret{target: target, manifest: secret})
+			}
+		}
 	}
 
 	appHash, err := getAppHash(info)
@@ -148,11 +259,11 @@ func (a *App) Add(params AddParams) error {
 
 	switch strings.ToUpper(info.Spec.ConfigURL) {
 	case string(ConfigTypeNone):
-		return a.addAppWithNoConfigRepo(info, params.DryRun, secretRef, appHash)
+		return a.addAppWithNoConfigRepo(info, params, secretRef, appHash)
 	case string(ConfigTypeUserRepo):
-		return a.addAppWithConfigInAppRepo(info, params, gitProvider, secretRef, appHash)
+		return a.addAppWithConfigInAppRepo(info, params, gitProvider, secretRef, appHash, secrets)
 	default:
-		return a.addAppWithConfigInExternalRepo(info, params, gitProvider, secretRef, appHash)
+		return a.addAppWithConfigInExternalRepo(info, params, gitProvider, secretRef, appHash, secrets)
 	}
 }
 
@@ -178,6 +289,11 @@ func getAppHash(info *AppResourceInfo) (string, error) {
 		if err != nil {
 			return "", err
 		}
+	} else if info.Spec.SourceType == wego.SourceTypeBucket {
+		appHash, err = getHash(info.Spec.URL, info.Spec.Bucket.Name, info.Spec.Path)
+		if err != nil {
+			return "", err
+		}
 	} else {
 		appHash, err = getHash(info.Spec.URL, info.Spec.Path, info.Spec.Branch)
 		if err != nil {
@@ -193,18 +309,70 @@ func (a *App) printAddSummary(params AddParams) {
 	a.logger.Println("URL: %s", params.Url)
 	a.logger.Println("Path: %s", params.Path)
 	a.logger.Println("Branch: %s", params.Branch)
+
+	if params.Tag != "" {
+		a.logger.Println("Tag: %s", params.Tag)
+	}
+
+	if params.SemVer != "" {
+		a.logger.Println("Semver: %s", params.SemVer)
+	}
+
+	if params.Commit != "" {
+		a.logger.Println("Commit: %s", params.Commit)
+	}
 	a.logger.Println("Type: %s", params.DeploymentType)
 
 	if params.Chart != "" {
 		a.logger.Println("Chart: %s", params.Chart)
 	}
 
+	if params.ChartVersion != "" {
+		a.logger.Println("Chart version: %s", params.ChartVersion)
+	}
+
+	if params.BucketName != "" {
+		a.logger.Println("Bucket: %s", params.BucketName)
+	}
+
+	if len(params.Targets) > 0 {
+		a.logger.Println("Targets: %s", strings.Join(params.Targets, " "))
+	}
+
+	if params.TargetNamespace != "" {
+		a.logger.Println("Target namespace: %s", params.TargetNamespace)
+	}
+
+	if params.ServiceAccount != "" {
+		a.logger.Println("Service account: %s", params.ServiceAccount)
+	}
+
+	if params.SecretEncryption != "" {
+		a.logger.Println("Secret encryption: %s", params.SecretEncryption)
+	}
+
+	if params.VerifySignatures {
+		a.logger.Println("Verify signatures: %s", params.VerificationKeyring)
+	}
+
+	if len(params.Notify) > 0 {
+		a.logger.Println("Notify: %s", strings.Join(params.Notify, " "))
+	}
+
+	if params.CommitStatus != "" {
+		a.logger.Println("Commit status: %s", params.CommitStatus)
+	}
+
 	a.logger.Println("")
 }
 
 func (a *App) updateParametersIfNecessary(params AddParams) (AddParams, error) {
 	params.SourceType = string(wego.SourceTypeGit)
 
+	if params.BucketName != "" {
+		return updateBucketParameters(params)
+	}
+
 	if params.Chart != "" {
 		params.SourceType = string(wego.SourceTypeHelm)
 		params.DeploymentType = string(wego.DeploymentTypeHelm)
@@ -216,6 +384,13 @@ func (a *App) updateParametersIfNecessary(params AddParams) (AddParams, error) {
 		return params, nil
 	}
 
+	gitAuth, err := ResolveGitAuth(params.GitAuth, params.Url)
+	if err != nil {
+		return params, err
+	}
+
+	params.GitAuth = string(gitAuth)
+
 	// Identifying repo url if not set by the user
 	if params.Url == "" {
 		url, err := a.getGitRemoteUrl(params)
@@ -227,7 +402,7 @@ func (a *App) updateParametersIfNecessary(params AddParams) (AddParams, error) {
 		params.Url = url
 	} else {
 		// making sure url is in the correct format
-		params.Url = sanitizeRepoUrl(params.Url)
+		params.Url = NormalizeRepoUrl(params.Url, gitAuth)
 
 		// resetting Dir param since Url has priority over it
 		params.Dir = ""
@@ -236,7 +411,7 @@ func (a *App) updateParametersIfNecessary(params AddParams) (AddParams, error) {
 	// making sure the config url is in good format
 	if strings.ToUpper(params.AppConfigUrl) != string(ConfigTypeNone) &&
 		strings.ToUpper(params.AppConfigUrl) != string(ConfigTypeUserRepo) {
-		params.AppConfigUrl = sanitizeRepoUrl(params.AppConfigUrl)
+		params.AppConfigUrl = NormalizeRepoUrl(params.AppConfigUrl, gitAuth)
 	}
 
 	if params.Name == "" {
@@ -246,6 +421,41 @@ func (a *App) updateParametersIfNecessary(params AddParams) (AddParams, error) {
 	return params, nil
 }
 
+func updateBucketParameters(params AddParams) (AddParams, error) {
+	params.SourceType = string(wego.SourceTypeBucket)
+
+	if params.Url == "" {
+		return params, fmt.Errorf("--url must be set to the bucket endpoint when using a bucket source")
+	}
+
+	if params.Chart != "" {
+		return params, fmt.Errorf("--chart can not be used with a bucket source; use --deployment-type helm and --path instead")
+	}
+
+	// There is no git repository to store the automation in, so it has to go to the cluster or an external repo
+	if strings.ToUpper(params.AppConfigUrl) == string(ConfigTypeUserRepo) {
+		return params, fmt.Errorf("--app-config-url must be set to NONE or an external repository when using a bucket source")
+	}
+
+	if strings.ToUpper(params.AppConfigUrl) != string(ConfigTypeNone) {
+		gitAuth, err := ResolveGitAuth(params.GitAuth, params.AppConfigUrl)
+		if err != nil {
+			return params, err
+		}
+
+		params.GitAuth = string(gitAuth)
+		params.AppConfigUrl = NormalizeRepoUrl(params.AppConfigUrl, gitAuth)
+	}
+
+	params.Dir = ""
+
+	if params.Name == "" {
+		params.Name = params.BucketName
+	}
+
+	return params, nil
+}
+
 func (a *App) getGitRemoteUrl(params AddParams) (string, error) {
 	repo, err := a.git.Open(params.Dir)
 	if err != nil {
@@ -262,27 +472,52 @@ func (a *App) getGitRemoteUrl(params AddParams) (string, error) {
 		return "", fmt.Errorf("remote config in %s does not have an url", params.Dir)
 	}
 
-	return sanitizeRepoUrl(urls[0]), nil
+	return NormalizeRepoUrl(urls[0], GitAuthType(params.GitAuth)), nil
 }
 
-func (a *App) addAppWithNoConfigRepo(info *AppResourceInfo, dryRun bool, secretRef string, appHash string) error {
-	// Returns the source, app spec and kustomization
-	source, appGoat, appSpec, err := a.generateAppManifests(info, secretRef, appHash)
+func (a *App) addAppWithNoConfigRepo(info *AppResourceInfo, params AddParams, secretRef string, appHash string) error {
+	// Returns the source and kustomization of the single target, and the app spec
+	targets, appSpec, err := a.generateAppManifests(info, secretRef, appHash)
 	if err != nil {
 		return fmt.Errorf("could not generate application GitOps Automation manifests: %w", err)
 	}
 
+	if err := a.generateTargetNotifications(targets, params); err != nil {
+		return fmt.Errorf("could not generate notification manifests: %w", err)
+	}
+
 	a.logger.Actionf("Applying manifests to the cluster")
-	return a.applyToCluster(info, dryRun, source, appGoat, appSpec)
+	if err := a.applyToCluster(info.GetFluxNamespace(), params.DryRun, targets[0].source, targets[0].goat); err != nil {
+		return err
+	}
+
+	if err := a.applyToCluster(info.Namespace, params.DryRun, appSpec); err != nil {
+		return err
+	}
+
+	if len(targets[0].notifications) > 0 {
+		return a.applyToCluster(info.GetFluxNamespace(), params.DryRun, targets[0].notifications)
+	}
+
+	return nil
 }
 
-func (a *App) addAppWithConfigInAppRepo(info *AppResourceInfo, params AddParams, gitProvider gitproviders.GitProvider, secretRef string, appHash string) error {
-	// Returns the source, app spec and kustomization
-	source, appGoat, appSpec, err := a.generateAppManifests(info, secretRef, appHash)
+func (a *App) addAppWithConfigInAppRepo(info *AppResourceInfo, params AddParams, gitProvider gitproviders.GitProvider, secretRef string, appHash string, secrets []targetSecret) error {
+	// Returns the source and kustomization for each target, and the app spec
+	targets, appSpec, err := a.generateAppManifests(info, secretRef, appHash)
 	if err != nil {
 		return fmt.Errorf("could not generate application GitOps Automation manifests: %w", err)
 	}
 
+	if err := a.generateTargetNotifications(targets, params); err != nil {
+		return fmt.Errorf("could not generate notification manifests: %w", err)
+	}
+
+	secretFiles, err := a.encryptSecrets(info, params, secrets)
+	if err != nil {
+		return fmt.Errorf("could not encrypt secrets: %w", err)
+	}
+
 	appWegoGoat, err := a.generateAppWegoManifests(info)
 	if err != nil {
 		return fmt.Errorf("could not create GitOps automation for .wego directory: %w", err)
@@ -291,7 +526,7 @@ func (a *App) addAppWithConfigInAppRepo(info *AppResourceInfo, params AddParams,
 	// a local directory has not been passed, so we clone the repo passed in the --url
 	if params.Dir == "" {
 		a.logger.Actionf("Cloning %s", info.Spec.URL)
-		remover, err := a.cloneRepo(info.Spec.URL, info.Spec.Branch, params.DryRun)
+		remover, err := a.cloneRepo(info.Spec.URL, info.Spec.Branch, params)
 		if err != nil {
 			return fmt.Errorf("failed to clone application repo: %w", err)
 		}
@@ -300,7 +535,7 @@ func (a *App) addAppWithConfigInAppRepo(info *AppResourceInfo, params AddParams,
 
 	if !params.DryRun {
 		if !params.AutoMerge {
-			if err := a.createPullRequestToRepo(info, gitProvider, info.Spec.URL, appHash, appSpec, appGoat, source); err != nil {
+			if err := a.createPullRequestToRepo(info, gitProvider, info.Spec.URL, appHash, appSpec, secretFiles, targets...); err != nil {
 				return err
 			}
 		} else {
@@ -310,14 +545,27 @@ func (a *App) addAppWithConfigInAppRepo(info *AppResourceInfo, params AddParams,
 				return fmt.Errorf("failed writing app.yaml to disk: %w", err)
 			}
 
-			if err := a.writeAppGoats(info, source, appGoat); err != nil {
+			if err := a.writeTargetGoats(targets); err != nil {
 				return fmt.Errorf("failed writing app.yaml to disk: %w", err)
 			}
+
+			if err := a.writeSecretFiles(secretFiles); err != nil {
+				return fmt.Errorf("failed writing encrypted secrets to disk: %w", err)
+			}
+		}
+	}
+
+	// The .wego automation is synced from the app repository using this cluster's deploy key
+	source := targets[0].source
+	if len(info.Spec.Targets) > 0 {
+		source, err = a.generateSource(info, secretRef)
+		if err != nil {
+			return fmt.Errorf("could not generate source manifest: %w", err)
 		}
 	}
 
 	a.logger.Actionf("Applying manifests to the cluster")
-	if err := a.applyToCluster(info, params.DryRun, source, appWegoGoat); err != nil {
+	if err := a.applyToCluster(info.GetFluxNamespace(), params.DryRun, source, appWegoGoat); err != nil {
 		return fmt.Errorf("could not apply manifests to the cluster: %w", err)
 	}
 
@@ -326,24 +574,38 @@ func (a *App) addAppWithConfigInAppRepo(info *AppResourceInfo, params AddParams,
 	})
 }
 
-func (a *App) addAppWithConfigInExternalRepo(info *AppResourceInfo, params AddParams, gitProvider gitproviders.GitProvider, appSecretRef string, appHash string) error {
-	appConfigSecretName, err := a.createAndUploadDeployKey(info, params.DryRun, info.Spec.ConfigURL, gitProvider)
+func (a *App) addAppWithConfigInExternalRepo(info *AppResourceInfo, params AddParams, gitProvider gitproviders.GitProvider, appSecretRef string, appHash string, secrets []targetSecret) error {
+	appConfigSecretName, appConfigSecret, err := a.createRepoSecret(info, params, info.Spec.ConfigURL, gitProvider, true)
 	if err != nil {
 		return fmt.Errorf("could not generate deploy key: %w", err)
 	}
 
-	// Returns the source, app spec and kustomization
-	appSource, appGoat, appSpec, err := a.generateAppManifests(info, appSecretRef, appHash)
+	// The other targets read the config repository with the secret of their own install
+	if current := info.currentTarget(); appConfigSecret != nil && current != nil {
+		secrets = append(secrets, targetSecret{target: current, manifest: appConfigSecret})
+	}
+
+	// Returns the source and kustomization for each target, and the app spec
+	targets, appSpec, err := a.generateAppManifests(info, appSecretRef, appHash)
 	if err != nil {
 		return fmt.Errorf("could not generate application GitOps Automation manifests: %w", err)
 	}
 
+	if err := a.generateTargetNotifications(targets, params); err != nil {
+		return fmt.Errorf("could not generate notification manifests: %w", err)
+	}
+
+	secretFiles, err := a.encryptSecrets(info, params, secrets)
+	if err != nil {
+		return fmt.Errorf("could not encrypt secrets: %w", err)
+	}
+
 	targetSource, targetGoats, err := a.generateExternalRepoManifests(info, appConfigSecretName)
 	if err != nil {
 		return fmt.Errorf("could not generate target GitOps Automation manifests: %w", err)
 	}
 
-	remover, err := a.cloneRepo(info.Spec.ConfigURL, info.Spec.Branch, params.DryRun)
+	remover, err := a.cloneRepo(info.Spec.ConfigURL, info.Spec.Branch, params)
 	if err != nil {
 		return fmt.Errorf("failed to clone configuration repo: %w", err)
 	}
@@ -351,7 +613,7 @@ func (a *App) addAppWithConfigInExternalRepo(info *AppResourceInfo, params AddPa
 
 	if !params.DryRun {
 		if !params.AutoMerge {
-			if err := a.createPullRequestToRepo(info, gitProvider, info.Spec.ConfigURL, appHash, appSpec, appGoat, appSource); err != nil {
+			if err := a.createPullRequestToRepo(info, gitProvider, info.Spec.ConfigURL, appHash, appSpec, secretFiles, targets...); err != nil {
 				return err
 			}
 		} else {
@@ -361,42 +623,60 @@ func (a *App) addAppWithConfigInExternalRepo(info *AppResourceInfo, params AddPa
 				return fmt.Errorf("failed writing app.yaml to disk: %w", err)
 			}
 
-			if err := a.writeAppGoats(info, appSource, appGoat); err != nil {
+			if err := a.writeTargetGoats(targets); err != nil {
 				return fmt.Errorf("failed writing application gitops manifests to disk: %w", err)
 			}
+
+			if err := a.writeSecretFiles(secretFiles); err != nil {
+				return fmt.Errorf("failed writing encrypted secrets to disk: %w", err)
+			}
 		}
 	}
 
 	a.logger.Actionf("Applying manifests to the cluster")
-	if err := a.applyToCluster(info, params.DryRun, targetSource, targetGoats); err != nil {
+	if err := a.applyToCluster(info.GetFluxNamespace(), params.DryRun, targetSource, targetGoats); err != nil {
 		return fmt.Errorf("could not apply manifests to the cluster: %w", err)
 	}
 
 	return a.commitAndPush(params)
 }
 
-func (a *App) generateAppManifests(info *AppResourceInfo, secretRef string, appHash string) ([]byte, []byte, []byte, error) {
-	var sourceManifest, appManifest, appGoatManifest []byte
-	var err error
-	a.logger.Generatef("Generating Source manifest")
-	sourceManifest, err = a.generateSource(info, secretRef)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("could not set up GitOps for user repository: %w", err)
-	}
+func (a *App) generateAppManifests(info *AppResourceInfo, secretRef string, appHash string) ([]targetAutomation, []byte, error) {
+	targets := []targetAutomation{}
 
-	a.logger.Generatef("Generating GitOps automation manifests")
-	appGoatManifest, err = a.generateApplicationGoat(info)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("could not create GitOps automation for '%s': %w", info.Name, err)
+	for _, target := range info.targetInfos() {
+		if len(info.Spec.Targets) > 0 {
+			a.logger.Generatef("Generating manifests for target %s", target.targetName)
+		}
+
+		// Each target cluster holds its own deploy key secret
+		targetSecretRef := secretRef
+		if secretRef != "" {
+			targetSecretRef = target.appSecretName(target.Spec.URL)
+		}
+
+		a.logger.Generatef("Generating Source manifest")
+		sourceManifest, err := a.generateSource(target, targetSecretRef)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not set up GitOps for user repository: %w", err)
+		}
+
+		a.logger.Generatef("Generating GitOps automation manifests")
+		appGoatManifest, err := a.generateApplicationGoat(target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not create GitOps automation for '%s': %w", info.Name, err)
+		}
+
+		targets = append(targets, targetAutomation{info: target, source: sourceManifest, goat: appGoatManifest})
 	}
 
 	a.logger.Generatef("Generating Application spec manifest")
-	appManifest, err = generateAppYaml(info, appHash)
+	appManifest, err := generateAppYaml(info, appHash)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("could not create app.yaml for '%s': %w", info.Name, err)
+		return nil, nil, fmt.Errorf("could not create app.yaml for '%s': %w", info.Name, err)
 	}
 
-	return sourceManifest, appGoatManifest, appManifest, nil
+	return targets, appManifest, nil
 }
 
 func (a *App) generateAppWegoManifests(info *AppResourceInfo) ([]byte, error) {
@@ -404,21 +684,33 @@ func (a *App) generateAppWegoManifests(info *AppResourceInfo) ([]byte, error) {
 		info.automationAppsDirKustomizationName(),
 		info.Name,
 		info.appYamlDir(),
-		info.Namespace)
+		"",
+		"",
+		info.decryptionSecret(),
+		info.GetFluxNamespace())
 	if err != nil {
 		return nil, fmt.Errorf("could not create app dir kustomization for '%s': %w", info.Name, err)
 	}
 
-	targetDirManifest, err := a.flux.CreateKustomization(
-		info.automationTargetDirKustomizationName(),
-		info.Name,
-		info.appAutomationDir(),
-		info.Namespace)
-	if err != nil {
-		return nil, fmt.Errorf("could not create target dir kustomization for '%s': %w", info.Name, err)
-	}
+	manifests := appsDirManifest
+
+	if info.isTarget() {
+		targetDirManifest, err := a.flux.CreateKustomization(
+			info.automationTargetDirKustomizationName(),
+			info.Name,
+			info.appAutomationDir(),
+			"",
+			"",
+			info.decryptionSecret(),
+			info.GetFluxNamespace())
+		if err != nil {
+			return nil, fmt.Errorf("could not create target dir kustomization for '%s': %w", info.Name, err)
+		}
 
-	manifests := bytes.Join([][]byte{appsDirManifest, targetDirManifest}, []byte(""))
+		manifests = bytes.Join([][]byte{appsDirManifest, targetDirManifest}, []byte(""))
+	} else {
+		a.logger.Warningf("Cluster %s is not a target of %s, only the app definition will be synced to it", info.clusterName, info.Name)
+	}
 
 	return bytes.ReplaceAll(manifests, []byte("path: ./wego"), []byte("path: .wego")), nil
 }
@@ -426,7 +718,7 @@ func (a *App) generateAppWegoManifests(info *AppResourceInfo) ([]byte, error) {
 func (a *App) generateExternalRepoManifests(info *AppResourceInfo, secretRef string) ([]byte, []byte, error) {
 	repoName := generateResourceName(info.Spec.ConfigURL)
 
-	targetSource, err := a.flux.CreateSourceGit(repoName, info.Spec.ConfigURL, info.Spec.Branch, secretRef, info.Namespace)
+	targetSource, err := a.flux.CreateSourceGit(repoName, info.Spec.ConfigURL, info.Spec.Branch, "", "", secretRef, info.GetFluxNamespace())
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not generate target source manifests: %w", err)
 	}
@@ -435,21 +727,40 @@ func (a *App) generateExternalRepoManifests(info *AppResourceInfo, secretRef str
 		info.automationAppsDirKustomizationName(),
 		repoName,
 		info.appYamlDir(),
-		info.Namespace)
+		"",
+		"",
+		info.decryptionSecret(),
+		info.GetFluxNamespace())
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not generate app dir kustomization for '%s': %w", info.Name, err)
 	}
 
-	targetGoat, err := a.flux.CreateKustomization(
-		info.automationTargetDirKustomizationName(),
-		repoName,
-		info.appAutomationDir(),
-		info.Namespace)
-	if err != nil {
-		return nil, nil, fmt.Errorf("could not generate target dir kustomization for '%s': %w", info.Name, err)
+	manifests := appGoat
+
+	if info.isTarget() {
+		targetGoat, err := a.flux.CreateKustomization(
+			info.automationTargetDirKustomizationName(),
+			repoName,
+			info.appAutomationDir(),
+			"",
+			"",
+			info.decryptionSecret(),
+			info.GetFluxNamespace())
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not generate target dir kustomization for '%s': %w", info.Name, err)
+		}
+
+		manifests = bytes.Join([][]byte{targetGoat, appGoat}, []byte(""))
+	} else {
+		a.logger.Warningf("Cluster %s is not a target of %s, only the app definition will be synced to it", info.clusterName, info.Name)
 	}
 
-	manifests := bytes.Join([][]byte{targetGoat, appGoat}, []byte(""))
+	// The other targets are deployed by the sync of the install of their cluster, which covers their target directory
+	for _, target := range info.targetInfos() {
+		if target.clusterName != info.clusterName {
+			a.logger.Actionf("Target %s is deployed from %s by its cluster once it is installed with 'wego gitops install --config-repo %s'", target.targetName, TargetDir(target.clusterName), info.Spec.ConfigURL)
+		}
+	}
 
 	return targetSource, manifests, nil
 }
@@ -461,7 +772,7 @@ func (a *App) commitAndPush(params AddParams, filters ...func(string) bool) erro
 	a.logger.Actionf("Committing and pushing wego resources for application")
 
 	_, err := a.git.Commit(git.Commit{
-		Author:  git.Author{Name: "Weave Gitops", Email: "weave-gitops@weave.works"},
+		Author:  CommitAuthor(params.CommitAuthorName, params.CommitAuthorEmail),
 		Message: "Add App manifests",
 	}, filters...)
 	if err != nil && err != git.ErrNoStagedFiles {
@@ -471,6 +782,11 @@ func (a *App) commitAndPush(params AddParams, filters ...func(string) bool) erro
 	if err == nil {
 		a.logger.Actionf("Pushing app manifests to repository")
 		if err = a.git.Push(context.Background()); err != nil {
+			var conflict *git.PushConflictError
+			if errors.As(err, &conflict) {
+				return fmt.Errorf("failed to push manifests: the manifests of app %s were changed in the remote branch while it was being added, review them before adding the app again: %w", params.Name, err)
+			}
+
 			return fmt.Errorf("failed to push manifests: %w", err)
 		}
 	} else {
@@ -480,71 +796,120 @@ func (a *App) commitAndPush(params AddParams, filters ...func(string) bool) erro
 	return nil
 }
 
-func (a *App) createAndUploadDeployKey(info *AppResourceInfo, dryRun bool, repoUrl string, gitProvider gitproviders.GitProvider) (string, error) {
+// CommitAuthor returns the author of the commits wego pushes, the wego author unless they are set
+func CommitAuthor(name string, email string) git.Author {
+	author := git.Author{Name: name, Email: email}
+
+	if author.Name == "" {
+		author.Name = DefaultCommitAuthorName
+	}
+
+	if author.Email == "" {
+		author.Email = DefaultCommitAuthorEmail
+	}
+
+	return author
+}
+
+// createAndUploadDeployKey returns the name of the deploy key secret, and the secret manifest when a new key was generated.
+// The secret is applied when it is for the current cluster.
+func (a *App) createAndUploadDeployKey(info *AppResourceInfo, dryRun bool, repoUrl string, gitProvider gitproviders.GitProvider, apply bool) (string, []byte, error) {
 	if repoUrl == "" {
-		return "", nil
+		return "", nil, nil
 	}
 
 	secretRefName := info.appSecretName(repoUrl)
 	if dryRun {
-		return secretRefName, nil
+		return secretRefName, nil, nil
 	}
 
 	repoUrl = sanitizeRepoUrl(repoUrl)
 
 	owner, err := getOwnerFromUrl(repoUrl)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	repoName := urlToRepoName(repoUrl)
-	deployKeyExists, err := gitProvider.DeployKeyExists(owner, repoName)
+	deployKeys, err := gitProvider.ListDeployKeys(owner, repoName, info.targetName)
 	if err != nil {
-		return "", fmt.Errorf("failed check for existing deploy key: %w", err)
+		return "", nil, fmt.Errorf("failed check for existing deploy key: %w", err)
+	}
+
+	// The secret of another cluster can't be looked up, it was committed to the config repository with its key
+	secretPresent := !apply
+	if apply {
+		secretPresent, err = a.kube.SecretPresent(context.Background(), secretRefName, info.GetFluxNamespace())
+		if err != nil {
+			return "", nil, fmt.Errorf("failed check for existing secret: %w", err)
+		}
+	}
+
+	if len(deployKeys) > 0 && secretPresent {
+		return secretRefName, nil, nil
 	}
 
-	secretPresent, err := a.kube.SecretPresent(context.Background(), secretRefName, info.Namespace)
+	a.logger.Generatef("Generating deploy key for repo %s", repoUrl)
+	secret, err := a.flux.CreateSecretGit(secretRefName, repoUrl, info.GetFluxNamespace())
 	if err != nil {
-		return "", fmt.Errorf("failed check for existing secret: %w", err)
+		return "", nil, fmt.Errorf("could not create git secret: %w", err)
+	}
+	var secretData corev1.Secret
+	err = yaml.Unmarshal(secret, &secretData)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to unmarshal created secret: %w", err)
 	}
 
-	if !deployKeyExists || !secretPresent {
-		a.logger.Generatef("Generating deploy key for repo %s", repoUrl)
-		secret, err := a.flux.CreateSecretGit(secretRefName, repoUrl, info.Namespace)
-		if err != nil {
-			return "", fmt.Errorf("could not create git secret: %w", err)
-		}
-		var secretData corev1.Secret
-		err = yaml.Unmarshal(secret, &secretData)
-		if err != nil {
-			return string(secret), fmt.Errorf("failed to unmarshal created secret: %w", err)
-		}
+	deployKey := []byte(secretData.StringData["identity.pub"])
+
+	// A key whose secret was lost is replaced by a key uploaded next to it, and deleted as nothing can use it anymore
+	keyName := gitproviders.NextDeployKeyName(info.targetName, deployKeys)
+	if err := gitProvider.UploadDeployKey(owner, repoName, keyName, deployKey); err != nil {
+		return "", nil, fmt.Errorf("error uploading deploy key: %w", err)
+	}
 
-		deployKey := []byte(secretData.StringData["identity.pub"])
+	for _, oldKey := range deployKeys {
+		a.logger.Actionf("Deleting deploy key %s", oldKey)
 
-		if err := gitProvider.UploadDeployKey(owner, repoName, deployKey); err != nil {
-			return "", fmt.Errorf("error uploading deploy key: %w", err)
+		if err := gitProvider.DeleteDeployKey(owner, repoName, oldKey); err != nil {
+			return "", nil, fmt.Errorf("failed deleting deploy key %s: %w", oldKey, err)
 		}
+	}
 
-		if out, err := a.kube.Apply(secret, info.Namespace); err != nil {
-			return "", fmt.Errorf("could not apply manifest: %s: %w", string(out), err)
+	if apply {
+		if out, err := a.kube.Apply(secret, info.GetFluxNamespace()); err != nil {
+			return "", nil, fmt.Errorf("could not apply manifest: %s: %w", string(out), err)
 		}
 	}
 
-	return secretRefName, nil
+	return secretRefName, secret, nil
 }
 
 func (a *App) generateSource(info *AppResourceInfo, secretRef string) ([]byte, error) {
 	switch info.Spec.SourceType {
 	case wego.SourceTypeGit:
-		sourceManifest, err := a.flux.CreateSourceGit(info.Name, info.Spec.URL, info.Spec.Branch, secretRef, info.Namespace)
+		sourceManifest, err := a.flux.CreateSourceGit(info.Name, info.Spec.URL, info.Spec.Branch, info.Spec.Tag, info.Spec.SemVer, secretRef, info.GetFluxNamespace())
 		if err != nil {
 			return nil, fmt.Errorf("could not create git source: %w", err)
 		}
 
-		return sourceManifest, nil
+		sourceManifest, err = addSourceCommit(sourceManifest, info.Spec)
+		if err != nil {
+			return nil, err
+		}
+
+		return addSourceVerification(sourceManifest, info.Spec)
 	case wego.SourceTypeHelm:
-		return a.flux.CreateSourceHelm(info.Name, info.Spec.URL, info.Namespace)
+		return a.flux.CreateSourceHelm(info.Name, info.Spec.URL, info.GetFluxNamespace())
+	case wego.SourceTypeBucket:
+		bucket := info.Spec.Bucket
+
+		sourceManifest, err := a.flux.CreateSourceBucket(info.Name, bucket.Name, info.Spec.URL, bucket.Provider, bucket.Region, bucket.SecretRef, bucket.Insecure, info.GetFluxNamespace())
+		if err != nil {
+			return nil, fmt.Errorf("could not create bucket source: %w", err)
+		}
+
+		return sourceManifest, nil
 	default:
 		return nil, fmt.Errorf("unknown source type: %v", info.Spec.SourceType)
 	}
@@ -553,22 +918,38 @@ func (a *App) generateSource(info *AppResourceInfo, secretRef string) ([]byte, e
 func (a *App) generateApplicationGoat(info *AppResourceInfo) ([]byte, error) {
 	switch info.Spec.DeploymentType {
 	case wego.DeploymentTypeKustomize:
-		return a.flux.CreateKustomization(info.Name, info.Name, info.Spec.Path, info.Namespace)
+		source := info.Name
+		if info.Spec.SourceType == wego.SourceTypeBucket {
+			source = "Bucket/" + info.Name
+		}
+
+		return a.flux.CreateKustomization(info.Name, source, info.Spec.Path, info.targetNamespace, info.Spec.ServiceAccount, info.decryptionSecret(), info.GetFluxNamespace())
 	case wego.DeploymentTypeHelm:
+		var helmRelease []byte
+		var err error
+
 		switch info.Spec.SourceType {
 		case wego.SourceTypeHelm:
-			return a.flux.CreateHelmReleaseHelmRepository(info.Name, info.Spec.Path, info.Namespace)
+			helmRelease, err = a.flux.CreateHelmReleaseHelmRepository(info.Name, info.Spec.Path, info.Spec.ChartVersion, info.targetNamespace, info.Spec.ServiceAccount, info.GetFluxNamespace())
 		case wego.SourceTypeGit:
-			return a.flux.CreateHelmReleaseGitRepository(info.Name, info.Name, info.Spec.Path, info.Namespace)
+			helmRelease, err = a.flux.CreateHelmReleaseGitRepository(info.Name, info.Name, info.Spec.Path, info.Spec.ChartVersion, info.targetNamespace, info.Spec.ServiceAccount, info.GetFluxNamespace())
+		case wego.SourceTypeBucket:
+			helmRelease, err = a.flux.CreateHelmReleaseBucket(info.Name, info.Name, info.Spec.Path, info.Spec.ChartVersion, info.targetNamespace, info.Spec.ServiceAccount, info.GetFluxNamespace())
 		default:
 			return nil, fmt.Errorf("invalid source type: %v", info.Spec.SourceType)
 		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return addHelmValues(helmRelease, info.Spec)
 	default:
 		return nil, fmt.Errorf("invalid deployment type: %v", info.Spec.DeploymentType)
 	}
 }
 
-func (a *App) applyToCluster(info *AppResourceInfo, dryRun bool, manifests ...[]byte) error {
+func (a *App) applyToCluster(namespace string, dryRun bool, manifests ...[]byte) error {
 	if dryRun {
 		for _, manifest := range manifests {
 			fmt.Printf("%s\n", manifest)
@@ -577,7 +958,7 @@ func (a *App) applyToCluster(info *AppResourceInfo, dryRun bool, manifests ...[]
 	}
 
 	for _, manifest := range manifests {
-		if out, err := a.kube.Apply(manifest, info.Namespace); err != nil {
+		if out, err := a.kube.Apply(manifest, namespace); err != nil {
 			return fmt.Errorf("could not apply manifest: %s: %w", string(out), err)
 		}
 	}
@@ -585,19 +966,34 @@ func (a *App) applyToCluster(info *AppResourceInfo, dryRun bool, manifests ...[]
 	return nil
 }
 
-func (a *App) cloneRepo(url string, branch string, dryRun bool) (func(), error) {
-	if dryRun {
+// cloneRepo clones the head of a branch to write the automation to. Only the last commit is fetched,
+// as the automation is added on top of it.
+func (a *App) cloneRepo(url string, branch string, params AddParams) (func(), error) {
+	if params.DryRun {
 		return func() {}, nil
 	}
 
-	url = sanitizeRepoUrl(url)
+	// https urls have already been normalized for token auth
+	if !isHTTPSRepoUrl(url) {
+		url = sanitizeRepoUrl(url)
+	}
+
+	opts := git.CloneOptions{Depth: 1, InMemory: params.InMemoryClone}
+
+	if params.InMemoryClone {
+		if _, err := a.git.CloneWithOptions(context.Background(), "", url, branch, opts); err != nil {
+			return nil, fmt.Errorf("failed cloning user repo: %s: %w", url, err)
+		}
+
+		return func() {}, nil
+	}
 
 	repoDir, err := ioutil.TempDir("", "user-repo-")
 	if err != nil {
 		return nil, fmt.Errorf("failed creating temp. directory to clone repo: %w", err)
 	}
 
-	_, err = a.git.Clone(context.Background(), repoDir, url, branch)
+	_, err = a.git.CloneWithOptions(context.Background(), repoDir, url, branch, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed cloning user repo: %s: %w", url, err)
 	}
@@ -616,6 +1012,16 @@ func (a *App) writeAppGoats(info *AppResourceInfo, manifests ...[]byte) error {
 	return a.git.Write(info.appAutomationPath(), goat)
 }
 
+func (a *App) writeTargetGoats(targets []targetAutomation) error {
+	for _, target := range targets {
+		if err := a.writeAppGoats(target.info, target.source, target.goat); err != nil {
+			return err
+		}
+	}
+
+	return a.writeTargetNotifications(targets)
+}
+
 func makeWegoApplication(params AddParams) wego.Application {
 	gvk := wego.GroupVersion.WithKind(wego.ApplicationKind)
 	app := wego.Application{
@@ -628,15 +1034,29 @@ func makeWegoApplication(params AddParams) wego.Application {
 			Namespace: params.Namespace,
 		},
 		Spec: wego.ApplicationSpec{
-			ConfigURL:      params.AppConfigUrl,
-			Branch:         params.Branch,
-			URL:            params.Url,
-			Path:           params.Path,
-			DeploymentType: wego.DeploymentType(params.DeploymentType),
-			SourceType:     wego.SourceType(params.SourceType),
+			ConfigURL:       params.AppConfigUrl,
+			Branch:          params.Branch,
+			URL:             params.Url,
+			Path:            params.Path,
+			DeploymentType:  wego.DeploymentType(params.DeploymentType),
+			SourceType:      wego.SourceType(params.SourceType),
+			ChartVersion:    params.ChartVersion,
+			FluxNamespace:   params.FluxNamespace,
+			TargetNamespace: params.TargetNamespace,
+			ServiceAccount:  params.ServiceAccount,
 		},
 	}
 
+	if app.Spec.SourceType == wego.SourceTypeBucket {
+		app.Spec.Bucket = &wego.BucketSource{
+			Name:      params.BucketName,
+			Provider:  params.BucketProvider,
+			Region:    params.BucketRegion,
+			SecretRef: params.BucketSecretRef,
+			Insecure:  params.BucketInsecure,
+		}
+	}
+
 	return app
 }
 
@@ -659,12 +1079,14 @@ func generateResourceName(url string) string {
 	return strings.ReplaceAll(urlToRepoName(url), "_", "-")
 }
 
+// getOwnerFromUrl returns the owner of a repository, with the groups of nested repositories
 func getOwnerFromUrl(url string) (string, error) {
-	parts := strings.Split(url, "/")
-	if len(parts) < 2 {
-		return "", fmt.Errorf("could not get owner from url %s", url)
+	repo, err := gitproviders.ParseRepoURL(url)
+	if err != nil {
+		return "", err
 	}
-	return parts[len(parts)-2], nil
+
+	return repo.Owner, nil
 }
 
 func urlToRepoName(url string) string {
@@ -695,26 +1117,53 @@ func sanitizeRepoUrl(url string) string {
 	return url
 }
 
-func (a *App) createPullRequestToRepo(info *AppResourceInfo, gitProvider gitproviders.GitProvider, repo string, appHash string, appYaml []byte, goatManifests ...[]byte) error {
-	repoName := generateResourceName(repo)
-
+func (a *App) createPullRequestToRepo(info *AppResourceInfo, gitProvider gitproviders.GitProvider, repo string, appHash string, appYaml []byte, secretFiles []secretFile, targets ...targetAutomation) error {
 	appPath := info.appYamlPath()
-	goatPath := info.appAutomationPath()
-	goat := bytes.Join(goatManifests, []byte(""))
-
 	appcontent := string(appYaml)
-	goatContent := string(goat)
 	files := []gitprovider.CommitFile{
 		{
 			Path:    &appPath,
 			Content: &appcontent,
 		},
-		{
+	}
+
+	for _, target := range targets {
+		goatPath := target.info.appAutomationPath()
+		goatContent := string(bytes.Join([][]byte{target.source, target.goat}, []byte("")))
+
+		files = append(files, gitprovider.CommitFile{
 			Path:    &goatPath,
 			Content: &goatContent,
-		},
+		})
+
+		if len(target.notifications) > 0 {
+			notificationsPath := target.info.appNotificationsPath()
+			notificationsContent := string(target.notifications)
+
+			files = append(files, gitprovider.CommitFile{
+				Path:    &notificationsPath,
+				Content: &notificationsContent,
+			})
+		}
+	}
+
+	for _, secret := range secretFiles {
+		secretPath := secret.path
+		secretContent := string(secret.manifest)
+
+		files = append(files, gitprovider.CommitFile{
+			Path:    &secretPath,
+			Content: &secretContent,
+		})
 	}
 
+	return a.createPullRequest(gitProvider, repo, info.Spec.Branch, appHash, files, utils.GetCommitMessage(), fmt.Sprintf("wego add %s", info.Name), fmt.Sprintf("Added yamls for %s", info.Name))
+}
+
+// createPullRequest opens a pull request adding files to a branch of a repository, from a new branch
+func (a *App) createPullRequest(gitProvider gitproviders.GitProvider, repo string, branch string, newBranch string, files []gitprovider.CommitFile, commitMessage string, title string, description string) error {
+	repoName := generateResourceName(repo)
+
 	owner, err := getOwnerFromUrl(repo)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve owner: %w", err)
@@ -727,7 +1176,7 @@ func (a *App) createPullRequestToRepo(info *AppResourceInfo, gitProvider gitprov
 
 	if accountType == gitproviders.AccountTypeOrg {
 		orgRepoRef := gitproviders.NewOrgRepositoryRef(github.DefaultDomain, owner, repoName)
-		prLink, err := gitProvider.CreatePullRequestToOrgRepo(orgRepoRef, info.Spec.Branch, appHash, files, utils.GetCommitMessage(), fmt.Sprintf("wego add %s", info.Name), fmt.Sprintf("Added yamls for %s", info.Name))
+		prLink, err := gitProvider.CreatePullRequestToOrgRepo(orgRepoRef, branch, newBranch, files, commitMessage, title, description)
 		if err != nil {
 			return fmt.Errorf("unable to create pull request: %w", err)
 		}
@@ -736,7 +1185,7 @@ func (a *App) createPullRequestToRepo(info *AppResourceInfo, gitProvider gitprov
 	}
 
 	userRepoRef := gitproviders.NewUserRepositoryRef(github.DefaultDomain, owner, repoName)
-	prLink, err := gitProvider.CreatePullRequestToUserRepo(userRepoRef, info.Spec.Branch, appHash, files, utils.GetCommitMessage(), fmt.Sprintf("wego add %s", info.Name), fmt.Sprintf("Added yamls for %s", info.Name))
+	prLink, err := gitProvider.CreatePullRequestToUserRepo(userRepoRef, branch, newBranch, files, commitMessage, title, description)
 	if err != nil {
 		return fmt.Errorf("unable to create pull request: %w", err)
 	}
@@ -746,9 +1195,10 @@ func (a *App) createPullRequestToRepo(info *AppResourceInfo, gitProvider gitprov
 
 func getAppResourceInfo(app wego.Application, clusterName string) *AppResourceInfo {
 	return &AppResourceInfo{
-		Application: app,
-		clusterName: clusterName,
-		targetName:  clusterName,
+		Application:     app,
+		clusterName:     clusterName,
+		targetName:      clusterName,
+		targetNamespace: app.Spec.TargetNamespace,
 	}
 }
 
@@ -775,7 +1225,22 @@ func (a *AppResourceInfo) appAutomationPath() string {
 }
 
 func (a *AppResourceInfo) appAutomationDir() string {
-	return filepath.Join(a.automationRoot(), "targets", a.clusterName, a.Name)
+	return filepath.Join(a.automationRoot(), TargetDir(a.clusterName), a.Name)
+}
+
+// TargetDir returns the directory of a config repository holding the automation and the secrets of the apps
+// deployed to a cluster. A cluster installed from the config repository syncs all of it.
+func TargetDir(clusterName string) string {
+	return filepath.Join("targets", clusterName)
+}
+
+// automationRepoUrl returns the repository the app's automation is committed to
+func (a *AppResourceInfo) automationRepoUrl() string {
+	if isExternalConfigUrl(a.Application) {
+		return a.Spec.ConfigURL
+	}
+
+	return a.Spec.URL
 }
 
 func (a *AppResourceInfo) appSourceName() string {
@@ -805,8 +1270,11 @@ func (a *AppResourceInfo) automationTargetDirKustomizationName() string {
 func (a *AppResourceInfo) sourceKind() string {
 	result := "GitRepository"
 
-	if a.Spec.SourceType == "helm" {
+	switch a.Spec.SourceType {
+	case wego.SourceTypeHelm:
 		result = "HelmRepository"
+	case wego.SourceTypeBucket:
+		result = "Bucket"
 	}
 
 	return result
@@ -836,7 +1304,16 @@ func (a *AppResourceInfo) clusterResources() []ResourceRef {
 	if a.sourceKind() == "GitRepository" {
 		resources = append(
 			resources,
-			ResourceRef{kind: "Secret", name: a.appSecretName(a.Spec.URL)})
+			ResourceRef{kind: "Secret", name: a.appSecretName(a.Spec.URL)},
+			// Secret for the write-enabled deploy key of image updates, when the app has any
+			ResourceRef{kind: "Secret", name: a.appWriteSecretName()})
+	}
+
+	// Secret holding the public keys trusted to sign the app's commits
+	if a.Spec.VerificationSecret != "" {
+		resources = append(
+			resources,
+			ResourceRef{kind: "Secret", name: a.Spec.VerificationSecret})
 	}
 
 	if strings.ToUpper(a.Spec.ConfigURL) == string(ConfigTypeNone) {
@@ -867,7 +1344,13 @@ func (a *AppResourceInfo) clusterResources() []ResourceRef {
 }
 
 func (a *AppResourceInfo) clusterResourcePaths() []string {
-	return []string{a.appYamlPath(), a.appAutomationPath()}
+	paths := []string{a.appYamlPath()}
+
+	for _, target := range a.targetInfos() {
+		paths = append(paths, target.appAutomationPath())
+	}
+
+	return append(paths, a.secretPaths()...)
 }
 
 // NOTE: ready to save the targets automation in phase 2
@@ -1,11 +1,17 @@
 package app
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
 	"github.com/go-git/go-billy/v5/memfs"
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
@@ -25,7 +31,7 @@ var addParams AddParams
 var _ = Describe("Add", func() {
 	var _ = BeforeEach(func() {
 		addParams = AddParams{
-			Url:            "https://github.com/foo/bar",
+			Url:            "git@github.com:foo/bar",
 			Path:           "./kustomize",
 			Branch:         "main",
 			Dir:            ".",
@@ -48,6 +54,12 @@ var _ = Describe("Add", func() {
 		err = appSrv.Add(addParams)
 		Expect(err).To(MatchError("Wego not installed... exiting"))
 
+		kubeClient.GetClusterStatusStub = func(ctx context.Context) kube.ClusterStatus {
+			return kube.FluxInstalled
+		}
+		err = appSrv.Add(addParams)
+		Expect(err).To(MatchError("Wego not installed alongside flux, run 'wego gitops install'... exiting"))
+
 		kubeClient.GetClusterStatusStub = func(ctx context.Context) kube.ClusterStatus {
 			return kube.Unknown
 		}
@@ -86,9 +98,10 @@ stringData:
 		Expect(repoUrl).To(Equal("ssh://git@github.com/foo/bar.git"))
 		Expect(namespace).To(Equal("wego-system"))
 
-		owner, repoName, deployKey := gitProviders.UploadDeployKeyArgsForCall(0)
+		owner, repoName, keyName, deployKey := gitProviders.UploadDeployKeyArgsForCall(0)
 		Expect(owner).To(Equal("foo"))
 		Expect(repoName).To(Equal("bar"))
+		Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key"))
 		Expect(deployKey).To(Equal([]byte("foo")))
 	})
 
@@ -96,9 +109,7 @@ stringData:
 		It("looks up deploy key and skips creating secret if found", func() {
 			addParams.SourceType = string(wego.SourceTypeGit)
 
-			gitProviders.DeployKeyExistsStub = func(s1, s2 string) (bool, error) {
-				return true, nil
-			}
+			gitProviders.ListDeployKeysReturns([]string{"weave-gitops-test-cluster-deploy-key"}, nil)
 
 			kubeClient.SecretPresentStub = func(ctx context.Context, s1, s2 string) (bool, error) {
 				return true, nil
@@ -109,7 +120,10 @@ stringData:
 			Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(0))
 			Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
 			Expect(kubeClient.SecretPresentCallCount()).To(Equal(1))
-			Expect(gitProviders.DeployKeyExistsCallCount()).To(Equal(1))
+			Expect(gitProviders.ListDeployKeysCallCount()).To(Equal(1))
+
+			_, _, clusterName := gitProviders.ListDeployKeysArgsForCall(0)
+			Expect(clusterName).To(Equal("test-cluster"))
 		})
 
 		It("looks up deploy key and creates secret if not found", func() {
@@ -120,7 +134,47 @@ stringData:
 			Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(1))
 			Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(1))
 			Expect(kubeClient.SecretPresentCallCount()).To(Equal(1))
-			Expect(gitProviders.DeployKeyExistsCallCount()).To(Equal(1))
+			Expect(gitProviders.ListDeployKeysCallCount()).To(Equal(1))
+		})
+
+		It("uploads a key next to the cluster's key when its secret was lost", func() {
+			addParams.SourceType = string(wego.SourceTypeGit)
+
+			gitProviders.ListDeployKeysReturns([]string{"weave-gitops-test-cluster-deploy-key"}, nil)
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, _, keyName, _ := gitProviders.UploadDeployKeyArgsForCall(0)
+			Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key-2"))
+
+			Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(1))
+			_, _, keyName = gitProviders.DeleteDeployKeyArgsForCall(0)
+			Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key"))
+		})
+
+		It("reuses the legacy key of an install predating per-cluster keys", func() {
+			addParams.SourceType = string(wego.SourceTypeGit)
+
+			gitProviders.ListDeployKeysStub = func(owner, repoName, clusterName string) ([]string, error) {
+				keys := []string{}
+				for _, key := range []string{gitproviders.LegacyDeployKeyName, "weave-gitops-staging-deploy-key"} {
+					if gitproviders.IsDeployKeyName(key, clusterName) {
+						keys = append(keys, key)
+					}
+				}
+
+				return keys, nil
+			}
+
+			kubeClient.SecretPresentStub = func(ctx context.Context, s1, s2 string) (bool, error) {
+				return true, nil
+			}
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(0))
+			Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
 		})
 	})
 
@@ -135,7 +189,7 @@ stringData:
 				Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(0))
 				Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
 				Expect(kubeClient.SecretPresentCallCount()).To(Equal(0))
-				Expect(gitProviders.DeployKeyExistsCallCount()).To(Equal(0))
+				Expect(gitProviders.ListDeployKeysCallCount()).To(Equal(0))
 			})
 		})
 
@@ -148,7 +202,7 @@ stringData:
 
 				Expect(fluxClient.CreateSourceGitCallCount()).To(Equal(1))
 
-				name, url, branch, secretRef, namespace := fluxClient.CreateSourceGitArgsForCall(0)
+				name, url, branch, _, _, secretRef, namespace := fluxClient.CreateSourceGitArgsForCall(0)
 				Expect(name).To(Equal("bar"))
 				Expect(url).To(Equal("ssh://git@github.com/foo/bar.git"))
 				Expect(branch).To(Equal("main"))
@@ -178,7 +232,7 @@ stringData:
 
 				Expect(fluxClient.CreateKustomizationCallCount()).To(Equal(1))
 
-				name, source, path, namespace := fluxClient.CreateKustomizationArgsForCall(0)
+				name, source, path, _, _, _, namespace := fluxClient.CreateKustomizationArgsForCall(0)
 				Expect(name).To(Equal("bar"))
 				Expect(source).To(Equal("bar"))
 				Expect(path).To(Equal("./kustomize"))
@@ -193,7 +247,7 @@ stringData:
 
 				Expect(fluxClient.CreateHelmReleaseHelmRepositoryCallCount()).To(Equal(1))
 
-				name, chart, namespace := fluxClient.CreateHelmReleaseHelmRepositoryArgsForCall(0)
+				name, chart, _, _, _, namespace := fluxClient.CreateHelmReleaseHelmRepositoryArgsForCall(0)
 				Expect(name).To(Equal("loki"))
 				Expect(chart).To(Equal("loki"))
 				Expect(namespace).To(Equal("wego-system"))
@@ -208,7 +262,7 @@ stringData:
 
 				Expect(fluxClient.CreateHelmReleaseGitRepositoryCallCount()).To(Equal(1))
 
-				name, source, path, namespace := fluxClient.CreateHelmReleaseGitRepositoryArgsForCall(0)
+				name, source, path, _, _, _, namespace := fluxClient.CreateHelmReleaseGitRepositoryArgsForCall(0)
 				Expect(name).To(Equal("bar"))
 				Expect(source).To(Equal("bar"))
 				Expect(path).To(Equal("./charts/my-chart"))
@@ -223,11 +277,237 @@ stringData:
 			})
 		})
 
+		Describe("sets helm values", func() {
+			BeforeEach(func() {
+				addParams.Url = "https://charts.kube-ops.io"
+				addParams.Chart = "loki"
+
+				fluxClient.CreateHelmReleaseHelmRepositoryStub = func(name, chart, version, targetNamespace, serviceAccount, namespace string) ([]byte, error) {
+					return []byte(`---
+apiVersion: helm.toolkit.fluxcd.io/v2beta1
+kind: HelmRelease
+metadata:
+  name: loki
+  namespace: wego-system
+spec:
+  chart:
+    spec:
+      chart: loki
+      sourceRef:
+        kind: HelmRepository
+        name: loki
+  interval: 5m0s
+`), nil
+				}
+			})
+
+			It("passes the chart version to the helm release", func() {
+				addParams.ChartVersion = ">=2.0.0"
+
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				_, _, version, _, _, _ := fluxClient.CreateHelmReleaseHelmRepositoryArgsForCall(0)
+				Expect(version).To(Equal(">=2.0.0"))
+			})
+
+			It("adds values and values references to the helm release and app spec", func() {
+				valuesFile, err := ioutil.TempFile("", "values-*.yaml")
+				Expect(err).ShouldNot(HaveOccurred())
+				defer os.Remove(valuesFile.Name())
+
+				_, err = valuesFile.WriteString("persistence:\n  enabled: false\n  size: 1Gi\n")
+				Expect(err).ShouldNot(HaveOccurred())
+
+				addParams.ValuesFiles = []string{valuesFile.Name()}
+				addParams.SetValues = []string{"persistence.enabled=true,replicas=2"}
+				addParams.ValuesFrom = []string{"ConfigMap/loki-values", "Secret/loki-secrets:secret-values.yaml"}
+
+				err = appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				helmRelease, _ := kubeClient.ApplyArgsForCall(1)
+				Expect(string(helmRelease)).To(ContainSubstring(`  values:
+    persistence:
+      enabled: true
+      size: 1Gi
+    replicas: 2
+`))
+				Expect(string(helmRelease)).To(ContainSubstring(`  valuesFrom:
+  - kind: ConfigMap
+    name: loki-values
+  - kind: Secret
+    name: loki-secrets
+    valuesKey: secret-values.yaml`))
+
+				appSpec, _ := kubeClient.ApplyArgsForCall(2)
+				Expect(string(appSpec)).To(ContainSubstring("values_key: secret-values.yaml"))
+			})
+
+			It("keeps set values helm reads as strings, and escaped commas", func() {
+				addParams.SetValues = []string{`image.tag=1.10,podAnnotations.hosts=a\,b`, "replicas=2"}
+
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				helmRelease, _ := kubeClient.ApplyArgsForCall(1)
+				Expect(string(helmRelease)).To(ContainSubstring(`  values:
+    image:
+      tag: "1.10"
+    podAnnotations:
+      hosts: a,b
+    replicas: 2
+`))
+			})
+
+			It("fails for an invalid set value", func() {
+				addParams.SetValues = []string{"replicas"}
+
+				err := appSrv.Add(addParams)
+				Expect(err).To(MatchError(`could not set helm values: invalid helm value "replicas": key "replicas" has no value`))
+			})
+
+			It("fails for a non helm deployment", func() {
+				addParams.Chart = ""
+				addParams.Url = "git@github.com:foo/bar"
+				addParams.SetValues = []string{"replicas=2"}
+
+				err := appSrv.Add(addParams)
+				Expect(err).To(MatchError("could not set helm values: helm values can only be set for helm deployments"))
+			})
+
+			It("fails for an invalid values reference", func() {
+				addParams.ValuesFrom = []string{"Deployment/foo"}
+
+				err := appSrv.Add(addParams)
+				Expect(err).To(MatchError(`could not set helm values: invalid values reference kind "Deployment", expected ConfigMap or Secret`))
+			})
+		})
+
+		Describe("uses a bucket source", func() {
+			BeforeEach(func() {
+				addParams.Url = "minio.minio.svc:9000"
+				addParams.BucketName = "manifests"
+				addParams.BucketSecretRef = "minio-credentials"
+				addParams.BucketInsecure = true
+			})
+
+			It("skips deploy key creation", func() {
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(0))
+				Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
+			})
+
+			It("creates a Bucket source", func() {
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Expect(fluxClient.CreateSourceBucketCallCount()).To(Equal(1))
+
+				name, bucketName, endpoint, provider, region, secretRef, insecure, namespace := fluxClient.CreateSourceBucketArgsForCall(0)
+				Expect(name).To(Equal("manifests"))
+				Expect(bucketName).To(Equal("manifests"))
+				Expect(endpoint).To(Equal("minio.minio.svc:9000"))
+				Expect(provider).To(Equal(""))
+				Expect(region).To(Equal(""))
+				Expect(secretRef).To(Equal("minio-credentials"))
+				Expect(insecure).To(BeTrue())
+				Expect(namespace).To(Equal("wego-system"))
+			})
+
+			It("creates a kustomization referencing the bucket", func() {
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				name, source, path, _, _, _, _ := fluxClient.CreateKustomizationArgsForCall(0)
+				Expect(name).To(Equal("manifests"))
+				Expect(source).To(Equal("Bucket/manifests"))
+				Expect(path).To(Equal("./kustomize"))
+			})
+
+			It("creates a helm release using the bucket if deployment type is helm", func() {
+				addParams.Path = "./charts/my-chart"
+				addParams.DeploymentType = string(wego.DeploymentTypeHelm)
+
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Expect(fluxClient.CreateHelmReleaseBucketCallCount()).To(Equal(1))
+
+				name, source, path, _, _, _, _ := fluxClient.CreateHelmReleaseBucketArgsForCall(0)
+				Expect(name).To(Equal("manifests"))
+				Expect(source).To(Equal("manifests"))
+				Expect(path).To(Equal("./charts/my-chart"))
+			})
+
+			It("stores the bucket in the app spec", func() {
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				appSpecManifest, _ := kubeClient.ApplyArgsForCall(2)
+				Expect(string(appSpecManifest)).To(ContainSubstring("source_type: bucket"))
+				Expect(string(appSpecManifest)).To(ContainSubstring("  bucket:\n    insecure: true\n    name: manifests\n    secret_ref: minio-credentials\n"))
+			})
+
+			It("fails without an endpoint", func() {
+				addParams.Url = ""
+
+				err := appSrv.Add(addParams)
+				Expect(err).To(MatchError("could not update parameters: --url must be set to the bucket endpoint when using a bucket source"))
+			})
+
+			It("fails when storing the config in the app repo", func() {
+				addParams.AppConfigUrl = ""
+
+				err := appSrv.Add(addParams)
+				Expect(err).To(MatchError("could not update parameters: --app-config-url must be set to NONE or an external repository when using a bucket source"))
+			})
+		})
+
+		Describe("sets the target namespace and service account", func() {
+			BeforeEach(func() {
+				addParams.TargetNamespace = "team-a"
+				addParams.ServiceAccount = "team-a-deployer"
+			})
+
+			It("passes them to the kustomization", func() {
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				_, _, _, targetNamespace, serviceAccount, _, _ := fluxClient.CreateKustomizationArgsForCall(0)
+				Expect(targetNamespace).To(Equal("team-a"))
+				Expect(serviceAccount).To(Equal("team-a-deployer"))
+			})
+
+			It("passes them to the helm release", func() {
+				addParams.Url = "https://charts.kube-ops.io"
+				addParams.Chart = "loki"
+
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				_, _, _, targetNamespace, serviceAccount, _ := fluxClient.CreateHelmReleaseHelmRepositoryArgsForCall(0)
+				Expect(targetNamespace).To(Equal("team-a"))
+				Expect(serviceAccount).To(Equal("team-a-deployer"))
+			})
+
+			It("stores them in the app spec", func() {
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				appSpecManifest, _ := kubeClient.ApplyArgsForCall(3)
+				Expect(string(appSpecManifest)).To(ContainSubstring("  service_account: team-a-deployer\n"))
+				Expect(string(appSpecManifest)).To(ContainSubstring("  target_namespace: team-a\n"))
+			})
+		})
+
 		It("applies the manifests to the cluster", func() {
-			fluxClient.CreateSourceGitStub = func(s1, s2, s3, s4, s5 string) ([]byte, error) {
+			fluxClient.CreateSourceGitStub = func(s1, s2, s3, s4, s5, s6, s7 string) ([]byte, error) {
 				return []byte("git source"), nil
 			}
-			fluxClient.CreateKustomizationStub = func(s1, s2, s3, s4 string) ([]byte, error) {
+			fluxClient.CreateKustomizationStub = func(s1, s2, s3, s4, s5, s6, s7 string) ([]byte, error) {
 				return []byte("kustomization"), nil
 			}
 
@@ -248,6 +528,32 @@ stringData:
 			Expect(string(appSpecManifest)).To(ContainSubstring("kind: Application"))
 			Expect(namespace).To(Equal("wego-system"))
 		})
+
+		It("creates the flux objects and secrets in the flux namespace", func() {
+			addParams.FluxNamespace = "flux-system"
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, _, namespace := fluxClient.CreateSecretGitArgsForCall(0)
+			Expect(namespace).To(Equal("flux-system"))
+
+			_, _, _, _, _, _, namespace = fluxClient.CreateSourceGitArgsForCall(0)
+			Expect(namespace).To(Equal("flux-system"))
+
+			_, _, _, _, _, _, namespace = fluxClient.CreateKustomizationArgsForCall(0)
+			Expect(namespace).To(Equal("flux-system"))
+
+			Expect(kubeClient.ApplyCallCount()).To(Equal(4))
+
+			for i, expected := range []string{"flux-system", "flux-system", "flux-system", "wego-system"} {
+				_, namespace := kubeClient.ApplyArgsForCall(i)
+				Expect(namespace).To(Equal(expected))
+			}
+
+			appSpecManifest, _ := kubeClient.ApplyArgsForCall(3)
+			Expect(string(appSpecManifest)).To(ContainSubstring("  flux_namespace: flux-system\n"))
+		})
 	})
 
 	Context("add app with config in app repo", func() {
@@ -277,7 +583,7 @@ stringData:
 				Expect(err).ShouldNot(HaveOccurred())
 				Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(0))
 				Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
-				Expect(gitProviders.DeployKeyExistsCallCount()).To(Equal(0))
+				Expect(gitProviders.ListDeployKeysCallCount()).To(Equal(0))
 				Expect(kubeClient.SecretPresentCallCount()).To(Equal(0))
 			})
 		})
@@ -291,7 +597,7 @@ stringData:
 
 				Expect(fluxClient.CreateSourceGitCallCount()).To(Equal(1))
 
-				name, url, branch, secretRef, namespace := fluxClient.CreateSourceGitArgsForCall(0)
+				name, url, branch, _, _, secretRef, namespace := fluxClient.CreateSourceGitArgsForCall(0)
 				Expect(name).To(Equal("bar"))
 				Expect(url).To(Equal("ssh://git@github.com/foo/bar.git"))
 				Expect(branch).To(Equal("main"))
@@ -322,19 +628,19 @@ stringData:
 
 				Expect(fluxClient.CreateKustomizationCallCount()).To(Equal(3))
 
-				name, source, path, namespace := fluxClient.CreateKustomizationArgsForCall(0)
+				name, source, path, _, _, _, namespace := fluxClient.CreateKustomizationArgsForCall(0)
 				Expect(name).To(Equal("bar"))
 				Expect(source).To(Equal("bar"))
 				Expect(path).To(Equal("./kustomize"))
 				Expect(namespace).To(Equal("wego-system"))
 
-				name, source, path, namespace = fluxClient.CreateKustomizationArgsForCall(1)
+				name, source, path, _, _, _, namespace = fluxClient.CreateKustomizationArgsForCall(1)
 				Expect(name).To(Equal("bar-apps-dir"))
 				Expect(source).To(Equal("bar"))
 				Expect(path).To(Equal(".wego/apps/bar"))
 				Expect(namespace).To(Equal("wego-system"))
 
-				name, source, path, namespace = fluxClient.CreateKustomizationArgsForCall(2)
+				name, source, path, _, _, _, namespace = fluxClient.CreateKustomizationArgsForCall(2)
 				Expect(name).To(Equal("test-cluster-bar"))
 				Expect(source).To(Equal("bar"))
 				Expect(path).To(Equal(".wego/targets/test-cluster/bar"))
@@ -349,7 +655,7 @@ stringData:
 
 				Expect(fluxClient.CreateHelmReleaseHelmRepositoryCallCount()).To(Equal(1))
 
-				name, chart, namespace := fluxClient.CreateHelmReleaseHelmRepositoryArgsForCall(0)
+				name, chart, _, _, _, namespace := fluxClient.CreateHelmReleaseHelmRepositoryArgsForCall(0)
 				Expect(name).To(Equal("loki"))
 				Expect(chart).To(Equal("loki"))
 				Expect(namespace).To(Equal("wego-system"))
@@ -364,7 +670,7 @@ stringData:
 
 				Expect(fluxClient.CreateHelmReleaseGitRepositoryCallCount()).To(Equal(1))
 
-				name, source, path, namespace := fluxClient.CreateHelmReleaseGitRepositoryArgsForCall(0)
+				name, source, path, _, _, _, namespace := fluxClient.CreateHelmReleaseGitRepositoryArgsForCall(0)
 				Expect(name).To(Equal("bar"))
 				Expect(source).To(Equal("bar"))
 				Expect(path).To(Equal("./charts/my-chart"))
@@ -380,10 +686,10 @@ stringData:
 		})
 
 		It("applies the manifests to the cluster", func() {
-			fluxClient.CreateSourceGitStub = func(s1, s2, s3, s4, s5 string) ([]byte, error) {
+			fluxClient.CreateSourceGitStub = func(s1, s2, s3, s4, s5, s6, s7 string) ([]byte, error) {
 				return []byte("git source"), nil
 			}
-			fluxClient.CreateKustomizationStub = func(s1, s2, s3, s4 string) ([]byte, error) {
+			fluxClient.CreateKustomizationStub = func(s1, s2, s3, s4, s5, s6, s7 string) ([]byte, error) {
 				return []byte("kustomization"), nil
 			}
 
@@ -410,20 +716,34 @@ stringData:
 				err := appSrv.Add(addParams)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				Expect(gitClient.CloneCallCount()).To(Equal(1))
-				_, repoDir, url, branch := gitClient.CloneArgsForCall(0)
+				Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(1))
+				_, repoDir, url, branch, opts := gitClient.CloneWithOptionsArgsForCall(0)
 
 				Expect(repoDir).To(ContainSubstring("user-repo-"))
 				Expect(url).To(Equal("ssh://git@github.com/foo/bar.git"))
 				Expect(branch).To(Equal("main"))
+				Expect(opts).To(Equal(git.CloneOptions{Depth: 1}))
+			})
+
+			It("clones the repo in memory", func() {
+				addParams.InMemoryClone = true
+
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(1))
+				_, repoDir, _, _, opts := gitClient.CloneWithOptionsArgsForCall(0)
+
+				Expect(repoDir).To(BeEmpty())
+				Expect(opts).To(Equal(git.CloneOptions{Depth: 1, InMemory: true}))
 			})
 
 			It("writes the files to the disk", func() {
 				addParams.AppConfigUrl = addParams.Url // so we know the root is ".wego"
-				fluxClient.CreateSourceGitStub = func(s1, s2, s3, s4, s5 string) ([]byte, error) {
+				fluxClient.CreateSourceGitStub = func(s1, s2, s3, s4, s5, s6, s7 string) ([]byte, error) {
 					return []byte("git"), nil
 				}
-				fluxClient.CreateKustomizationStub = func(s1, s2, s3, s4 string) ([]byte, error) {
+				fluxClient.CreateKustomizationStub = func(s1, s2, s3, s4, s5, s6, s7 string) ([]byte, error) {
 					return []byte("kustomization"), nil
 				}
 
@@ -460,8 +780,8 @@ stringData:
 
 	Context("add app with external config repo", func() {
 		BeforeEach(func() {
-			addParams.Url = "https://github.com/user/repo"
-			addParams.AppConfigUrl = "https://github.com/foo/bar"
+			addParams.Url = "git@github.com:user/repo"
+			addParams.AppConfigUrl = "git@github.com:foo/bar"
 		})
 
 		Describe("generates source manifest", func() {
@@ -473,14 +793,14 @@ stringData:
 
 				Expect(fluxClient.CreateSourceGitCallCount()).To(Equal(2))
 
-				name, url, branch, secretRef, namespace := fluxClient.CreateSourceGitArgsForCall(0)
+				name, url, branch, _, _, secretRef, namespace := fluxClient.CreateSourceGitArgsForCall(0)
 				Expect(name).To(Equal("repo"))
 				Expect(url).To(Equal("ssh://git@github.com/user/repo.git"))
 				Expect(branch).To(Equal("main"))
 				Expect(secretRef).To(Equal("weave-gitops-test-cluster-repo"))
 				Expect(namespace).To(Equal("wego-system"))
 
-				name, url, branch, secretRef, namespace = fluxClient.CreateSourceGitArgsForCall(1)
+				name, url, branch, _, _, secretRef, namespace = fluxClient.CreateSourceGitArgsForCall(1)
 				Expect(name).To(Equal("bar"))
 				Expect(url).To(Equal("ssh://git@github.com/foo/bar.git"))
 				Expect(branch).To(Equal("main"))
@@ -551,13 +871,13 @@ stringData:
 
 				Expect(fluxClient.CreateKustomizationCallCount()).To(Equal(3))
 
-				name, source, path, namespace := fluxClient.CreateKustomizationArgsForCall(0)
+				name, source, path, _, _, _, namespace := fluxClient.CreateKustomizationArgsForCall(0)
 				Expect(name).To(Equal("repo"))
 				Expect(source).To(Equal("repo"))
 				Expect(path).To(Equal("./kustomize"))
 				Expect(namespace).To(Equal("wego-system"))
 
-				name, source, path, namespace = fluxClient.CreateKustomizationArgsForCall(1)
+				name, source, path, _, _, _, namespace = fluxClient.CreateKustomizationArgsForCall(1)
 				Expect(name).To(Equal("repo-apps-dir"))
 				Expect(source).To(Equal("bar"))
 				Expect(path).To(Equal("apps/repo"))
@@ -572,7 +892,7 @@ stringData:
 
 				Expect(fluxClient.CreateHelmReleaseHelmRepositoryCallCount()).To(Equal(1))
 
-				name, chart, namespace := fluxClient.CreateHelmReleaseHelmRepositoryArgsForCall(0)
+				name, chart, _, _, _, namespace := fluxClient.CreateHelmReleaseHelmRepositoryArgsForCall(0)
 				Expect(name).To(Equal("loki"))
 				Expect(chart).To(Equal("loki"))
 				Expect(namespace).To(Equal("wego-system"))
@@ -587,7 +907,7 @@ stringData:
 
 				Expect(fluxClient.CreateHelmReleaseGitRepositoryCallCount()).To(Equal(1))
 
-				name, source, path, namespace := fluxClient.CreateHelmReleaseGitRepositoryArgsForCall(0)
+				name, source, path, _, _, _, namespace := fluxClient.CreateHelmReleaseGitRepositoryArgsForCall(0)
 				Expect(name).To(Equal("repo"))
 				Expect(source).To(Equal("repo"))
 				Expect(path).To(Equal("./charts/my-chart"))
@@ -603,10 +923,10 @@ stringData:
 		})
 
 		It("applies the manifests to the cluster", func() {
-			fluxClient.CreateSourceGitStub = func(s1, s2, s3, s4, s5 string) ([]byte, error) {
+			fluxClient.CreateSourceGitStub = func(s1, s2, s3, s4, s5, s6, s7 string) ([]byte, error) {
 				return []byte("git source"), nil
 			}
-			fluxClient.CreateKustomizationStub = func(s1, s2, s3, s4 string) ([]byte, error) {
+			fluxClient.CreateKustomizationStub = func(s1, s2, s3, s4, s5, s6, s7 string) ([]byte, error) {
 				return []byte("kustomization"), nil
 			}
 
@@ -628,8 +948,8 @@ stringData:
 			err := appSrv.Add(addParams)
 			Expect(err).ShouldNot(HaveOccurred())
 
-			Expect(gitClient.CloneCallCount()).To(Equal(1))
-			_, repoDir, url, branch := gitClient.CloneArgsForCall(0)
+			Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(1))
+			_, repoDir, url, branch, _ := gitClient.CloneWithOptionsArgsForCall(0)
 
 			Expect(repoDir).To(ContainSubstring("user-repo-"))
 			Expect(url).To(Equal("ssh://git@github.com/foo/bar.git"))
@@ -637,10 +957,10 @@ stringData:
 		})
 
 		It("writes the files to the disk", func() {
-			fluxClient.CreateSourceGitStub = func(s1, s2, s3, s4, s5 string) ([]byte, error) {
+			fluxClient.CreateSourceGitStub = func(s1, s2, s3, s4, s5, s6, s7 string) ([]byte, error) {
 				return []byte("git"), nil
 			}
-			fluxClient.CreateKustomizationStub = func(s1, s2, s3, s4 string) ([]byte, error) {
+			fluxClient.CreateKustomizationStub = func(s1, s2, s3, s4, s5, s6, s7 string) ([]byte, error) {
 				return []byte("kustomization"), nil
 			}
 
@@ -672,12 +992,590 @@ stringData:
 
 			Expect(len(filters)).To(Equal(0))
 		})
+
+		It("commits with the configured author", func() {
+			addParams.CommitAuthorName = "Jane Doe"
+			addParams.CommitAuthorEmail = "jane@example.com"
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			msg, _ := gitClient.CommitArgsForCall(0)
+			Expect(msg.Author).To(Equal(git.Author{Name: "Jane Doe", Email: "jane@example.com"}))
+		})
+
+		It("reports the conflicting files when the remote branch changed the app", func() {
+			gitClient.PushReturns(&git.PushConflictError{Branch: "main", Files: []string{"apps/repo/app.yaml"}})
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError("failed to push manifests: the manifests of app repo were changed in the remote branch while it was being added, review them before adding the app again: the remote branch main changed files that are also changed locally: apps/repo/app.yaml"))
+		})
+	})
+
+	Context("add app with multiple targets", func() {
+		BeforeEach(func() {
+			addParams.Url = "git@github.com:user/repo"
+			addParams.AppConfigUrl = "git@github.com:foo/bar"
+			addParams.Targets = []string{"test-cluster", "prod:branch=release,path=./overlays/prod,namespace=podinfo"}
+			addParams.SecretEncryption = string(wego.SecretEncryptionSops)
+			addParams.SopsAgeRecipients = []string{"age1abc"}
+
+			fluxClient.CreateSourceGitStub = func(name, url, branch, tag, semver, secretRef, namespace string) ([]byte, error) {
+				return []byte("git " + branch + " " + secretRef + "\n"), nil
+			}
+			fluxClient.CreateKustomizationStub = func(name, source, path, targetNamespace, serviceAccount, decryptionSecret, namespace string) ([]byte, error) {
+				return []byte("kustomization " + path + " " + targetNamespace + "\n"), nil
+			}
+		})
+
+		It("writes the automation for each target", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(gitClient.WriteCallCount()).To(Equal(3))
+
+			path, content := gitClient.WriteArgsForCall(0)
+			Expect(path).To(Equal("apps/repo/app.yaml"))
+			Expect(string(content)).To(ContainSubstring("  targets:\n  - name: test-cluster\n  - branch: release\n    name: prod\n    namespace: podinfo\n    path: ./overlays/prod\n"))
+
+			path, content = gitClient.WriteArgsForCall(1)
+			Expect(path).To(Equal("targets/test-cluster/repo/repo-gitops-runtime.yaml"))
+			Expect(string(content)).To(Equal("git main weave-gitops-test-cluster-repo\nkustomization ./kustomize \n"))
+
+			path, content = gitClient.WriteArgsForCall(2)
+			Expect(path).To(Equal("targets/prod/repo/repo-gitops-runtime.yaml"))
+			Expect(string(content)).To(Equal("git release weave-gitops-prod-repo\nkustomization ./overlays/prod podinfo\n"))
+		})
+
+		It("uses the app target namespace unless the target sets its own", func() {
+			addParams.TargetNamespace = "default-ns"
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, content := gitClient.WriteArgsForCall(1)
+			Expect(string(content)).To(HaveSuffix("kustomization ./kustomize default-ns\n"))
+
+			_, content = gitClient.WriteArgsForCall(2)
+			Expect(string(content)).To(HaveSuffix("kustomization ./overlays/prod podinfo\n"))
+		})
+
+		It("syncs the target of the current cluster", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			name, _, path, _, _, _, _ := fluxClient.CreateKustomizationArgsForCall(3)
+			Expect(name).To(Equal("test-cluster-repo"))
+			Expect(path).To(Equal("targets/test-cluster/repo"))
+		})
+
+		It("only syncs the app definition when the current cluster is not a target", func() {
+			addParams.Targets = []string{"staging", "prod"}
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(fluxClient.CreateKustomizationCallCount()).To(Equal(3))
+
+			name, _, _, _, _, _, _ := fluxClient.CreateKustomizationArgsForCall(2)
+			Expect(name).To(Equal("repo-apps-dir"))
+		})
+
+		Context("when the current cluster is not a target", func() {
+			BeforeEach(func() {
+				addParams.Targets = []string{"staging", "prod"}
+
+				fluxClient.CreateSecretGitStub = func(name, url, namespace string) ([]byte, error) {
+					return []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: " + name + "\nstringData:\n  identity.pub: " + name + "-key\n"), nil
+				}
+				encryptor.EncryptSopsStub = func(manifest []byte, ageRecipients, pgpFingerprints []string) ([]byte, error) {
+					return append([]byte("sops "), manifest...), nil
+				}
+			})
+
+			It("uploads a deploy key for each target", func() {
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(3))
+
+				_, repoName, keyName, key := gitProviders.UploadDeployKeyArgsForCall(0)
+				Expect(repoName).To(Equal("repo"))
+				Expect(keyName).To(Equal("weave-gitops-staging-deploy-key"))
+				Expect(string(key)).To(Equal("weave-gitops-staging-repo-key"))
+
+				_, repoName, keyName, _ = gitProviders.UploadDeployKeyArgsForCall(1)
+				Expect(repoName).To(Equal("repo"))
+				Expect(keyName).To(Equal("weave-gitops-prod-deploy-key"))
+
+				// The config repo key of the current cluster
+				_, repoName, keyName, _ = gitProviders.UploadDeployKeyArgsForCall(2)
+				Expect(repoName).To(Equal("bar"))
+				Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key"))
+			})
+
+			It("commits the secret of each target next to its automation instead of applying it", func() {
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				for i := 0; i < kubeClient.ApplyCallCount(); i++ {
+					manifest, _ := kubeClient.ApplyArgsForCall(i)
+					Expect(string(manifest)).NotTo(ContainSubstring("name: weave-gitops-staging-repo"))
+					Expect(string(manifest)).NotTo(ContainSubstring("name: weave-gitops-prod-repo"))
+				}
+
+				paths := []string{}
+				for i := 0; i < gitClient.WriteCallCount(); i++ {
+					path, _ := gitClient.WriteArgsForCall(i)
+					paths = append(paths, path)
+				}
+
+				Expect(paths).To(Equal([]string{
+					"apps/repo/app.yaml",
+					"targets/staging/repo/repo-gitops-runtime.yaml",
+					"targets/prod/repo/repo-gitops-runtime.yaml",
+					"targets/staging/repo/weave-gitops-staging-repo.yaml",
+					"targets/prod/repo/weave-gitops-prod-repo.yaml",
+				}))
+
+				_, content := gitClient.WriteArgsForCall(4)
+				Expect(string(content)).To(HavePrefix("sops apiVersion: v1"))
+			})
+
+			It("commits everything another target deploys to the directory its cluster syncs", func() {
+				addParams.Targets = []string{"test-cluster", "prod"}
+
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				files := map[string]string{}
+				for i := 0; i < gitClient.WriteCallCount(); i++ {
+					path, content := gitClient.WriteArgsForCall(i)
+					if strings.HasPrefix(path, TargetDir("prod")+"/") {
+						files[path] = string(content)
+					}
+				}
+
+				Expect(files).To(Equal(map[string]string{
+					"targets/prod/repo/repo-gitops-runtime.yaml":    "git main weave-gitops-prod-repo\nkustomization ./kustomize \n",
+					"targets/prod/repo/weave-gitops-prod-repo.yaml": "sops apiVersion: v1\nkind: Secret\nmetadata:\n  name: weave-gitops-prod-repo\nstringData:\n  identity.pub: weave-gitops-prod-repo-key\n",
+				}))
+
+				// Nothing of the other target is applied to the current cluster
+				for i := 0; i < kubeClient.ApplyCallCount(); i++ {
+					manifest, _ := kubeClient.ApplyArgsForCall(i)
+					Expect(string(manifest)).NotTo(ContainSubstring("prod"))
+				}
+			})
+
+			It("keeps the committed secret of a target whose deploy key exists", func() {
+				gitProviders.ListDeployKeysStub = func(owner, repoName, clusterName string) ([]string, error) {
+					if clusterName == "prod" {
+						return []string{"weave-gitops-prod-deploy-key"}, nil
+					}
+
+					return []string{}, nil
+				}
+
+				err := appSrv.Add(addParams)
+				Expect(err).ShouldNot(HaveOccurred())
+
+				Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(2))
+
+				name, _, _ := fluxClient.CreateSecretGitArgsForCall(0)
+				Expect(name).To(Equal("weave-gitops-staging-repo"))
+			})
+
+			It("fails without secret encryption", func() {
+				addParams.SecretEncryption = ""
+
+				err := appSrv.Add(addParams)
+				Expect(err).To(MatchError(`could not set targets: target "staging" is not the current cluster, set --encrypt-secrets to commit the secrets of its cluster to the config repository`))
+				Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
+			})
+
+			It("fails when the automation is stored in the app repo", func() {
+				addParams.AppConfigUrl = ""
+
+				err := appSrv.Add(addParams)
+				Expect(err).To(MatchError(`could not set targets: target "staging" is not the current cluster, which requires an external config repository for its cluster to sync from`))
+			})
+		})
+
+		It("fails when the config is only stored in the cluster", func() {
+			addParams.AppConfigUrl = "NONE"
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError("could not set targets: targets are written to the config repository and can not be used with --app-config-url=NONE"))
+		})
+
+		It("fails when a target sets a branch and the config is in the app repo", func() {
+			addParams.AppConfigUrl = ""
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError(`could not set targets: target "prod" sets a branch, which requires an external config repository`))
+		})
+
+		It("fails for an unknown target setting", func() {
+			addParams.Targets = []string{"prod:cluster=foo"}
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError(`could not set targets: unknown setting "cluster" for target "prod", expected branch, path or namespace`))
+		})
+	})
+
+	Context("add app with https git auth", func() {
+		BeforeEach(func() {
+			addParams.Url = "https://github.com/foo/bar"
+			addParams.GitProviderToken = "my-token"
+			addParams.SourceToken = "read-only-token"
+		})
+
+		It("stores the source token in a secret instead of uploading a deploy key", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(0))
+			Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
+
+			secret, namespace := kubeClient.ApplyArgsForCall(0)
+			Expect(string(secret)).To(ContainSubstring("kind: Secret"))
+			Expect(string(secret)).To(ContainSubstring("name: weave-gitops-test-cluster-bar"))
+			Expect(string(secret)).To(ContainSubstring("stringData:\n  password: read-only-token\n  username: git\n"))
+			Expect(namespace).To(Equal("wego-system"))
+		})
+
+		It("only stores the git provider token when requested", func() {
+			addParams.SourceToken = ""
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError("flux needs a token to read https repositories: set --source-token to a token with read access to them, or --use-provider-token to store the git provider token in the cluster"))
+			Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+
+			addParams.UseProviderToken = true
+
+			err = appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			secret, _ := kubeClient.ApplyArgsForCall(0)
+			Expect(string(secret)).To(ContainSubstring("password: my-token\n"))
+		})
+
+		It("does not need a source token for ssh repositories", func() {
+			addParams.Url = "git@github.com:foo/bar"
+			addParams.SourceToken = ""
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+
+		It("keeps the https url for the source", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, url, _, _, _, secretRef, _ := fluxClient.CreateSourceGitArgsForCall(0)
+			Expect(url).To(Equal("https://github.com/foo/bar.git"))
+			Expect(secretRef).To(Equal("weave-gitops-test-cluster-bar"))
+		})
+
+		It("converts ssh urls when https is requested", func() {
+			addParams.Url = "git@github.com:foo/bar"
+			addParams.AppConfigUrl = "ssh://git@github.com/foo/config.git"
+			addParams.GitAuth = string(GitAuthHTTPS)
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, url, _, _, _, _, _ := fluxClient.CreateSourceGitArgsForCall(0)
+			Expect(url).To(Equal("https://github.com/foo/bar.git"))
+
+			_, repoDir, url, _, _ := gitClient.CloneWithOptionsArgsForCall(0)
+			Expect(repoDir).To(ContainSubstring("user-repo-"))
+			Expect(url).To(Equal("https://github.com/foo/config.git"))
+		})
+
+		It("fails for an invalid git auth", func() {
+			addParams.GitAuth = "ftp"
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError(`could not update parameters: invalid git auth "ftp", expected ssh or https`))
+		})
+	})
+
+	Context("add app with encrypted secrets", func() {
+		BeforeEach(func() {
+			addParams.Url = "git@github.com:user/repo"
+			addParams.AppConfigUrl = "git@github.com:foo/bar"
+			addParams.SecretEncryption = string(wego.SecretEncryptionSops)
+			addParams.SopsAgeRecipients = []string{"age1abc"}
+
+			fluxClient.CreateSecretGitStub = func(name, url, namespace string) ([]byte, error) {
+				return []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: " + name + "\n"), nil
+			}
+			encryptor.EncryptSopsStub = func(manifest []byte, ageRecipients, pgpFingerprints []string) ([]byte, error) {
+				return append([]byte("sops "), manifest...), nil
+			}
+			encryptor.SealStub = func(manifest []byte, certFile string) ([]byte, error) {
+				return append([]byte("sealed "), manifest...), nil
+			}
+		})
+
+		It("writes the encrypted secrets next to the app automation", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(encryptor.EncryptSopsCallCount()).To(Equal(2))
+
+			_, ageRecipients, pgpFingerprints := encryptor.EncryptSopsArgsForCall(0)
+			Expect(ageRecipients).To(Equal([]string{"age1abc"}))
+			Expect(pgpFingerprints).To(BeEmpty())
+
+			Expect(gitClient.WriteCallCount()).To(Equal(4))
+
+			path, content := gitClient.WriteArgsForCall(2)
+			Expect(path).To(Equal("targets/test-cluster/repo/weave-gitops-test-cluster-repo.yaml"))
+			Expect(string(content)).To(HavePrefix("sops apiVersion: v1"))
+
+			path, _ = gitClient.WriteArgsForCall(3)
+			Expect(path).To(Equal("targets/test-cluster/repo/weave-gitops-test-cluster-bar.yaml"))
+		})
+
+		It("still applies the plain secrets to the cluster", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			secret, _ := kubeClient.ApplyArgsForCall(0)
+			Expect(string(secret)).To(HavePrefix("apiVersion: v1\nkind: Secret"))
+		})
+
+		It("configures the kustomizations to decrypt with sops", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(fluxClient.CreateKustomizationCallCount()).To(Equal(3))
+
+			for i := 0; i < 3; i++ {
+				_, _, _, _, _, decryptionSecret, _ := fluxClient.CreateKustomizationArgsForCall(i)
+				Expect(decryptionSecret).To(Equal(DefaultDecryptionSecret))
+			}
+		})
+
+		It("stores the encryption in the app spec", func() {
+			addParams.DecryptionSecret = "sops-age"
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, content := gitClient.WriteArgsForCall(0)
+			Expect(string(content)).To(ContainSubstring("  decryption_secret: sops-age\n"))
+			Expect(string(content)).To(ContainSubstring("  secret_encryption: sops\n"))
+		})
+
+		It("adds the encrypted secrets to the pull request", func() {
+			addParams.AutoMerge = false
+
+			gitProviders.GetAccountTypeStub = func(s string) (gitproviders.ProviderAccountType, error) {
+				return gitproviders.AccountTypeUser, nil
+			}
+
+			gitProviders.CreatePullRequestToUserRepoReturns(nil, fmt.Errorf("stop after the pull request"))
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError("unable to create pull request: stop after the pull request"))
+
+			_, _, _, files, _, _, _ := gitProviders.CreatePullRequestToUserRepoArgsForCall(0)
+			Expect(files).To(HaveLen(4))
+			Expect(*files[2].Path).To(Equal("targets/test-cluster/repo/weave-gitops-test-cluster-repo.yaml"))
+			Expect(*files[2].Content).To(HavePrefix("sops "))
+		})
+
+		It("seals the secrets without configuring decryption", func() {
+			addParams.SecretEncryption = string(wego.SecretEncryptionSealedSecrets)
+			addParams.SealedSecretsCert = "pub-cert.pem"
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(encryptor.SealCallCount()).To(Equal(2))
+
+			_, certFile := encryptor.SealArgsForCall(0)
+			Expect(certFile).To(Equal("pub-cert.pem"))
+
+			_, content := gitClient.WriteArgsForCall(2)
+			Expect(string(content)).To(HavePrefix("sealed "))
+
+			_, _, _, _, _, decryptionSecret, _ := fluxClient.CreateKustomizationArgsForCall(0)
+			Expect(decryptionSecret).To(BeEmpty())
+		})
+
+		It("does not commit secrets that already exist", func() {
+			gitProviders.ListDeployKeysReturns([]string{"weave-gitops-test-cluster-deploy-key"}, nil)
+			kubeClient.SecretPresentStub = func(ctx context.Context, s1, s2 string) (bool, error) {
+				return true, nil
+			}
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(encryptor.EncryptSopsCallCount()).To(Equal(0))
+			Expect(gitClient.WriteCallCount()).To(Equal(2))
+		})
+
+		It("removes the secret files with the app", func() {
+			params, err := appSrv.(*App).updateParametersIfNecessary(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			err = appSrv.Add(params)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			app := makeWegoApplication(params)
+			Expect(setSecretEncryption(&app, params)).To(Succeed())
+
+			paths := getAppResourceInfo(app, "test-cluster").clusterResourcePaths()
+
+			for i := 0; i < gitClient.WriteCallCount(); i++ {
+				path, _ := gitClient.WriteArgsForCall(i)
+				Expect(paths).To(ContainElement(path))
+			}
+		})
+
+		It("does not commit secrets for the current cluster when it is not a target", func() {
+			addParams.Targets = []string{"staging"}
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			for i := 0; i < gitClient.WriteCallCount(); i++ {
+				path, _ := gitClient.WriteArgsForCall(i)
+				Expect(path).NotTo(HavePrefix("targets/test-cluster/"))
+			}
+		})
+
+		It("fails without sops recipients", func() {
+			addParams.SopsAgeRecipients = nil
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError("could not set secret encryption: --sops-age-recipients or --sops-pgp-fingerprints must be set to encrypt secrets with sops"))
+		})
+
+		It("fails when the config is only stored in the cluster", func() {
+			addParams.AppConfigUrl = "NONE"
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError("could not set secret encryption: encrypted secrets are committed to the config repository and can not be used with --app-config-url=NONE"))
+		})
+
+		It("fails for an unknown encryption", func() {
+			addParams.SecretEncryption = "vault"
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError(`could not set secret encryption: invalid secret encryption "vault", expected sops or sealed-secrets`))
+		})
+	})
+
+	Context("add app with signature verification", func() {
+		var keyringFile string
+
+		BeforeEach(func() {
+			entity, err := openpgp.NewEntity("Release Engineer", "", "release@example.com", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			var keyring bytes.Buffer
+			w, err := armor.Encode(&keyring, openpgp.PublicKeyType, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(entity.Serialize(w)).To(Succeed())
+			Expect(w.Close()).To(Succeed())
+
+			f, err := ioutil.TempFile("", "keyring-*.asc")
+			Expect(err).ShouldNot(HaveOccurred())
+			_, err = f.Write(keyring.Bytes())
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(f.Close()).To(Succeed())
+
+			keyringFile = f.Name()
+
+			addParams.Url = "git@github.com:foo/bar"
+			addParams.VerifySignatures = true
+			addParams.VerificationKeyring = keyringFile
+
+			fluxClient.CreateSourceGitStub = func(name, url, branch, tag, semver, secretRef, namespace string) ([]byte, error) {
+				return []byte("apiVersion: source.toolkit.fluxcd.io/v1beta1\nkind: GitRepository\nmetadata:\n  name: " + name + "\n  namespace: " + namespace + "\nspec:\n  interval: 30s\n  url: " + url + "\n"), nil
+			}
+		})
+
+		AfterEach(func() {
+			os.Remove(keyringFile)
+		})
+
+		appliedManifests := func() []string {
+			manifests := []string{}
+			for i := 0; i < kubeClient.ApplyCallCount(); i++ {
+				manifest, _ := kubeClient.ApplyArgsForCall(i)
+				manifests = append(manifests, string(manifest))
+			}
+
+			return manifests
+		}
+
+		It("creates a secret holding the trusted keys", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(appliedManifests()).To(ContainElement(And(
+				ContainSubstring("kind: Secret"),
+				ContainSubstring("name: weave-gitops-bar-trusted-keys"),
+				ContainSubstring("keyring.asc: |-\n    -----BEGIN PGP PUBLIC KEY BLOCK-----"),
+			)))
+		})
+
+		It("verifies the head commit of the git source", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(appliedManifests()).To(ContainElement(And(
+				ContainSubstring("kind: GitRepository"),
+				ContainSubstring("  verify:\n    mode: head\n    secretRef:\n      name: weave-gitops-bar-trusted-keys\n"),
+			)))
+		})
+
+		It("stores the verification secret in the app spec", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(appliedManifests()).To(ContainElement(And(
+				ContainSubstring("kind: Application"),
+				ContainSubstring("  verification_secret: weave-gitops-bar-trusted-keys\n"),
+			)))
+		})
+
+		It("fails without a keyring", func() {
+			addParams.VerificationKeyring = ""
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError("could not set signature verification: --verify-keyring must be set to verify commit signatures"))
+		})
+
+		It("fails for a keyring without armored keys", func() {
+			Expect(ioutil.WriteFile(keyringFile, []byte("not a keyring"), 0600)).To(Succeed())
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("could not create verification secret: failed to read armored keyring"))
+		})
+
+		It("fails for helm repository sources", func() {
+			addParams.Url = "https://charts.kube-ops.io"
+			addParams.Chart = "loki"
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError("could not set signature verification: commit signatures can only be verified for git sources"))
+		})
 	})
 
 	Context("when creating a pull request", func() {
 		It("generates an appropriate error when the owner cannot be retrieved from the URL", func() {
 			info := getAppResourceInfo(makeWegoApplication(addParams), "cluster")
-			err := appSrv.(*App).createPullRequestToRepo(info, gitProviders, "foo", "hash", []byte{})
+			err := appSrv.(*App).createPullRequestToRepo(info, gitProviders, "foo", "hash", []byte{}, nil)
 			Expect(err.Error()).To(HavePrefix("failed to retrieve owner"))
 		})
 
@@ -686,7 +1584,7 @@ stringData:
 				return gitproviders.AccountTypeOrg, fmt.Errorf("no account found")
 			}
 			info := getAppResourceInfo(makeWegoApplication(addParams), "cluster")
-			err := appSrv.(*App).createPullRequestToRepo(info, gitProviders, "ssh://git@github.com/ewojfewoj3323w/abc", "hash", []byte{})
+			err := appSrv.(*App).createPullRequestToRepo(info, gitProviders, "ssh://git@github.com/ewojfewoj3323w/abc", "hash", []byte{}, nil)
 			Expect(err.Error()).To(HavePrefix("failed to retrieve account type"))
 		})
 	})
@@ -700,7 +1598,7 @@ stringData:
 			Expect(err).ShouldNot(HaveOccurred())
 
 			Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(0))
-			Expect(gitClient.CloneCallCount()).To(Equal(0))
+			Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(0))
 			Expect(gitClient.WriteCallCount()).To(Equal(0))
 			Expect(kubeClient.ApplyCallCount()).To(Equal(0))
 		})
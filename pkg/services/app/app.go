@@ -7,12 +7,15 @@ import (
 	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
 	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/encryption"
 	"github.com/weaveworks/weave-gitops/pkg/flux"
 	"github.com/weaveworks/weave-gitops/pkg/git"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
 	"github.com/weaveworks/weave-gitops/pkg/logger"
 	"github.com/weaveworks/weave-gitops/pkg/osys"
+	"github.com/weaveworks/weave-gitops/pkg/render"
+	"github.com/weaveworks/weave-gitops/pkg/runner"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -24,8 +27,9 @@ const (
 	DeployTypeKustomize DeploymentType = "kustomize"
 	DeployTypeHelm      DeploymentType = "helm"
 
-	SourceTypeGit  SourceType = "git"
-	SourceTypeHelm SourceType = "helm"
+	SourceTypeGit    SourceType = "git"
+	SourceTypeHelm   SourceType = "helm"
+	SourceTypeBucket SourceType = "bucket"
 )
 
 // AppService entity that manages applications
@@ -40,6 +44,20 @@ type AppService interface {
 	Pause(params PauseParams) error
 	// Unpause resumes the gitops automation for an app
 	Unpause(params UnpauseParams) error
+	// RotateKey replaces the deploy keys used by an app or a repository
+	RotateKey(params RotateKeyParams) error
+	// Diff returns the differences between the app's source and the cluster
+	Diff(params DiffParams) (string, error)
+	// Resources lists the objects deployed by an app, with their health
+	Resources(params ResourcesParams) ([]AppResource, error)
+	// Logs prints the events and controller logs about the objects of an app
+	Logs(params LogsParams) error
+	// Notify sends the events of an app to notification providers
+	Notify(params NotifyParams) error
+	// ImagePolicy updates the images of an app to the latest tags selected by a policy
+	ImagePolicy(params ImagePolicyParams) error
+	// Purge removes every app of a namespace, with their deploy keys and, optionally, their automation
+	Purge(params PurgeParams) error
 }
 
 type App struct {
@@ -48,6 +66,8 @@ type App struct {
 	flux               flux.Flux
 	kube               kube.Kube
 	logger             logger.Logger
+	encryptor          encryption.Encryptor
+	renderer           render.Renderer
 	gitProviderFactory func(token string) (gitproviders.GitProvider, error)
 }
 
@@ -58,6 +78,8 @@ func New(logger logger.Logger, git git.Git, flux flux.Flux, kube kube.Kube, osys
 		kube:               kube,
 		logger:             logger,
 		osys:               osys,
+		encryptor:          encryption.New(&runner.CLIRunner{}),
+		renderer:           render.New(&runner.CLIRunner{}),
 		gitProviderFactory: createGitProvider,
 	}
 }
@@ -77,15 +99,6 @@ func createGitProvider(token string) (gitproviders.GitProvider, error) {
 	return provider, nil
 }
 
-func (a *App) getDeploymentType(ctx context.Context, name string, namespace string) (wego.DeploymentType, error) {
-	app, err := a.kube.GetApplication(ctx, types.NamespacedName{Name: name, Namespace: namespace})
-	if err != nil {
-		return wego.DeploymentTypeKustomize, err
-	}
-
-	return wego.DeploymentType(app.Spec.DeploymentType), nil
-}
-
 func (a *App) getSuspendedStatus(ctx context.Context, name, namespace string, deploymentType wego.DeploymentType) (bool, error) {
 	var automation client.Object
 
@@ -115,11 +128,14 @@ func (a *App) getSuspendedStatus(ctx context.Context, name, namespace string, de
 
 func (a *App) pauseOrUnpause(suspendAction wego.SuspendActionType, name, namespace string) error {
 	ctx := context.Background()
-	deploymentType, err := a.getDeploymentType(ctx, name, namespace)
+	app, err := a.kube.GetApplication(ctx, types.NamespacedName{Name: name, Namespace: namespace})
 	if err != nil {
 		return fmt.Errorf("unable to determine deployment type for %s: %s", name, err)
 	}
 
+	deploymentType := app.Spec.DeploymentType
+	namespace = app.GetFluxNamespace()
+
 	suspendStatus, err := a.getSuspendedStatus(ctx, name, namespace, deploymentType)
 	if err != nil {
 		return fmt.Errorf("failed to get suspended status: %s", err)
@@ -7,6 +7,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/encryption/encryptionfakes"
 	"github.com/weaveworks/weave-gitops/pkg/flux/fluxfakes"
 	"github.com/weaveworks/weave-gitops/pkg/git/gitfakes"
 	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
@@ -15,6 +16,7 @@ import (
 	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
 	"github.com/weaveworks/weave-gitops/pkg/logger"
 	"github.com/weaveworks/weave-gitops/pkg/osys"
+	"github.com/weaveworks/weave-gitops/pkg/render/renderfakes"
 )
 
 var (
@@ -23,6 +25,8 @@ var (
 	kubeClient   *kubefakes.FakeKube
 	osysClient   osys.Osys
 	gitProviders *gitprovidersfakes.FakeGitProvider
+	encryptor    *encryptionfakes.FakeEncryptor
+	renderer     *renderfakes.FakeRenderer
 
 	appSrv AppService
 )
@@ -41,9 +45,13 @@ var _ = BeforeEach(func() {
 	}
 
 	gitProviders = &gitprovidersfakes.FakeGitProvider{}
+	encryptor = &encryptionfakes.FakeEncryptor{}
+	renderer = &renderfakes.FakeRenderer{}
 
 	appSrv = New(logger.New(os.Stderr), gitClient, fluxClient, kubeClient, osysClient)
 
+	appSrv.(*App).encryptor = encryptor
+	appSrv.(*App).renderer = renderer
 	appSrv.(*App).gitProviderFactory = func(token string) (gitproviders.GitProvider, error) {
 		return gitProviders, nil
 	}
@@ -0,0 +1,283 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/utils"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+type GitAuthType string
+
+const (
+	GitAuthSSH   GitAuthType = "ssh"
+	GitAuthHTTPS GitAuthType = "https"
+
+	// GitHub accepts a token as the password for any user name
+	gitHTTPSUsername = "git"
+)
+
+// ResolveGitAuth returns the git auth type requested with --git-auth, or, when none was requested,
+// https for https:// URLs and ssh for everything else
+func ResolveGitAuth(requested string, url string) (GitAuthType, error) {
+	switch GitAuthType(requested) {
+	case GitAuthSSH, GitAuthHTTPS:
+		return GitAuthType(requested), nil
+	case "":
+		if isHTTPSRepoUrl(url) {
+			return GitAuthHTTPS, nil
+		}
+
+		return GitAuthSSH, nil
+	default:
+		return "", fmt.Errorf("invalid git auth %q, expected ssh or https", requested)
+	}
+}
+
+// NewHTTPSAuth returns the auth method used to clone from and push to repositories over HTTPS
+func NewHTTPSAuth(token string) *http.BasicAuth {
+	return &http.BasicAuth{
+		Username: gitHTTPSUsername,
+		Password: token,
+	}
+}
+
+// AutomationRepoAuthMethod returns the auth method used to push to the repository holding the automation of an
+// existing app, or nil when the automation is only stored in the cluster, see RepoAuthMethod
+func AutomationRepoAuthMethod(app *wego.Application, gitAuth string, privateKey string, token string) (transport.AuthMethod, error) {
+	repoUrl := AutomationRepoUrl(app)
+	if repoUrl == "" {
+		return nil, nil
+	}
+
+	return RepoAuthMethod(repoUrl, gitAuth, privateKey, token)
+}
+
+// AutomationRepoUrl returns the repository holding the automation of an existing app, or an empty string when the
+// automation is only stored in the cluster
+func AutomationRepoUrl(app *wego.Application) string {
+	if strings.ToUpper(app.Spec.ConfigURL) == string(ConfigTypeNone) {
+		return ""
+	}
+
+	return getAppResourceInfo(*app, "").automationRepoUrl()
+}
+
+// RepoAuthMethod returns the auth method used to push to a repository, with the git provider token over https
+// or the private key over ssh, see SSHAuthMethod
+func RepoAuthMethod(repoUrl string, gitAuth string, privateKey string, token string) (transport.AuthMethod, error) {
+	resolved, err := ResolveGitAuth(gitAuth, repoUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolved == GitAuthHTTPS {
+		return NewHTTPSAuth(token), nil
+	}
+
+	return SSHAuthMethod(privateKey)
+}
+
+// SSHAuthMethod returns the auth method used to clone from and push to repositories over ssh with a private key
+// file, ~/.ssh/id_ed25519 or ~/.ssh/id_rsa by default. The passphrase of an encrypted key is read from the terminal.
+func SSHAuthMethod(privateKey string) (transport.AuthMethod, error) {
+	if strings.HasPrefix(privateKey, "~/") {
+		dir, err := getHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		privateKey = filepath.Join(dir, privateKey[2:])
+	} else if privateKey == "" {
+		keyFile, err := findPrivateKeyFile()
+		if err != nil {
+			return nil, err
+		}
+		privateKey = keyFile
+	}
+
+	authMethod, err := ssh.NewPublicKeysFromFile("git", privateKey, "")
+	if err != nil {
+		fmt.Print("Private Key Password: ")
+		pw, err := term.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return nil, fmt.Errorf("failed reading ssh key password: %w", err)
+		}
+
+		authMethod, err = ssh.NewPublicKeysFromFile("git", privateKey, string(pw))
+		if err != nil {
+			return nil, fmt.Errorf("failed reading ssh keys: %w", err)
+		}
+	}
+
+	return authMethod, nil
+}
+
+func getHomeDir() (string, error) {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine user home directory")
+	}
+	return dir, nil
+}
+
+func findPrivateKeyFile() (string, error) {
+	dir, err := getHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	modernFilePath := filepath.Join(dir, ".ssh", "id_ed25519")
+	if utils.Exists(modernFilePath) {
+		return modernFilePath, nil
+	}
+
+	legacyFilePath := filepath.Join(dir, ".ssh", "id_rsa")
+	if utils.Exists(legacyFilePath) {
+		return legacyFilePath, nil
+	}
+
+	return "", fmt.Errorf("could not locate ssh key file; please specify '--private-key'")
+}
+
+func isHTTPSRepoUrl(url string) bool {
+	return strings.HasPrefix(url, "https://")
+}
+
+// NormalizeRepoUrl puts a repository url in the format used for the given auth type, on any git provider. Urls
+// with a port are kept as given, their host may serve ssh and https on other ports.
+func NormalizeRepoUrl(repoUrl string, gitAuth GitAuthType) string {
+	if !strings.HasSuffix(repoUrl, ".git") {
+		repoUrl = repoUrl + ".git"
+	}
+
+	if u, err := url.Parse(repoUrl); err == nil && u.Port() != "" {
+		return repoUrl
+	}
+
+	repo, err := gitproviders.ParseRepoURL(repoUrl)
+	if err != nil {
+		return repoUrl
+	}
+
+	if gitAuth == GitAuthHTTPS {
+		return repo.HTTPSURL() + ".git"
+	}
+
+	return repo.SSHURL() + ".git"
+}
+
+// createRepoSecret creates the secret flux uses to access a repository: a deploy key for ssh URLs,
+// or the git provider token for https URLs. The secret manifest is returned when it was (re)generated,
+// and it is applied to the cluster when apply is set.
+func (a *App) createRepoSecret(info *AppResourceInfo, params AddParams, repoUrl string, gitProvider gitproviders.GitProvider, apply bool) (string, []byte, error) {
+	if isHTTPSRepoUrl(repoUrl) {
+		return a.createGitCredentialsSecret(info, params.DryRun, repoUrl, params.SourceToken, apply)
+	}
+
+	return a.createAndUploadDeployKey(info, params.DryRun, repoUrl, gitProvider, apply)
+}
+
+// createTargetRepoSecrets creates the secret each target of the app reads the app repository with, and returns
+// the name of the current cluster's one. Only the secret of the current cluster can be applied: the secrets of
+// the other targets reach their clusters encrypted in the config repository, next to their automation.
+func (a *App) createTargetRepoSecrets(info *AppResourceInfo, params AddParams, gitProvider gitproviders.GitProvider) (string, []targetSecret, error) {
+	secrets := []targetSecret{}
+
+	for _, target := range info.targetInfos() {
+		_, secret, err := a.createRepoSecret(target, params, info.Spec.URL, gitProvider, target.clusterName == info.clusterName)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if secret != nil {
+			secrets = append(secrets, targetSecret{target: target, manifest: secret})
+		}
+	}
+
+	return info.appSecretName(info.Spec.URL), secrets, nil
+}
+
+// resolveSourceToken returns the token flux reads the app's https repositories with. The git provider token usually
+// grants access to every repository of its account, so it is only stored in the cluster, and committed with the
+// encrypted secrets, when that is explicitly requested.
+func (a *App) resolveSourceToken(info *AppResourceInfo, params AddParams) (string, error) {
+	readsHTTPSRepo := isHTTPSRepoUrl(info.Spec.ConfigURL) ||
+		(info.Spec.SourceType == wego.SourceTypeGit && isHTTPSRepoUrl(info.Spec.URL))
+
+	if !readsHTTPSRepo || params.SourceToken != "" {
+		return params.SourceToken, nil
+	}
+
+	if !params.UseProviderToken {
+		return "", fmt.Errorf("flux needs a token to read https repositories: set --source-token to a token with read access to them, or --use-provider-token to store the git provider token in the cluster")
+	}
+
+	stored := "in the cluster"
+	if info.Spec.SecretEncryption != "" {
+		stored = "in the cluster and, encrypted, in the config repository"
+	}
+
+	a.logger.Warningf("The git provider token is stored %s for flux to read the app's repositories. It usually grants access to every repository of the account, a read-only --source-token is safer.", stored)
+
+	return params.GitProviderToken, nil
+}
+
+// GitCredentialsSecret returns the secret flux reads a repository with over HTTPS, with the git provider token
+func GitCredentialsSecret(name string, namespace string, token string) ([]byte, error) {
+	secret := corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		StringData: map[string]string{
+			"username": gitHTTPSUsername,
+			"password": token,
+		},
+	}
+
+	manifest, err := yaml.Marshal(&secret)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal git credentials secret: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func (a *App) createGitCredentialsSecret(info *AppResourceInfo, dryRun bool, repoUrl string, token string, apply bool) (string, []byte, error) {
+	secretRefName := info.appSecretName(repoUrl)
+	if dryRun {
+		return secretRefName, nil, nil
+	}
+
+	a.logger.Generatef("Generating git credentials for repo %s", repoUrl)
+
+	manifest, err := GitCredentialsSecret(secretRefName, info.GetFluxNamespace(), token)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The secret is always applied so that it picks up a new token
+	if apply {
+		if out, err := a.kube.Apply(manifest, info.GetFluxNamespace()); err != nil {
+			return "", nil, fmt.Errorf("could not apply manifest: %s: %w", string(out), err)
+		}
+	}
+
+	return secretRefName, manifest, nil
+}
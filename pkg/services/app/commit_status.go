@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+)
+
+// setCommitStatus stores how the statuses of the applied commits are reported in the application spec
+func setCommitStatus(app *wego.Application, params AddParams) error {
+	if params.CommitStatus == "" {
+		return nil
+	}
+
+	reporter := wego.CommitStatusReporter(params.CommitStatus)
+	if reporter != wego.CommitStatusReporterFlux && reporter != wego.CommitStatusReporterWego {
+		return fmt.Errorf("invalid commit status reporter %q, expected flux or wego", params.CommitStatus)
+	}
+
+	// Only kustomizations report the commit of the revision they applied
+	if app.Spec.SourceType != wego.SourceTypeGit || app.Spec.DeploymentType != wego.DeploymentTypeKustomize {
+		return fmt.Errorf("commit statuses can only be reported for git sources deployed with kustomize")
+	}
+
+	if params.CommitStatusSecretRef == "" {
+		return fmt.Errorf("--commit-status-secret-ref must be set to the secret holding the git provider token")
+	}
+
+	app.Spec.CommitStatus = &wego.CommitStatus{
+		Reporter:  reporter,
+		SecretRef: params.CommitStatusSecretRef,
+	}
+
+	return nil
+}
+
+// reportsCommitStatusWithFlux reports whether the app's automation includes the provider posting its commit statuses
+func (a *AppResourceInfo) reportsCommitStatusWithFlux() bool {
+	return a.Spec.CommitStatus != nil && a.Spec.CommitStatus.Reporter == wego.CommitStatusReporterFlux
+}
+
+// generateCommitStatusNotification returns the github or gitlab provider and the alert posting the status
+// of the revisions applied by the app's kustomization to its repository
+func (a *App) generateCommitStatusNotification(info *AppResourceInfo) ([]byte, error) {
+	repo, err := gitproviders.ParseRepoURL(info.Spec.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	name := info.notificationName("commit-status")
+
+	provider, err := a.flux.CreateAlertProvider(name, string(repo.Provider), "", repo.HTTPSURL(), info.Spec.CommitStatus.SecretRef, info.GetFluxNamespace())
+	if err != nil {
+		return nil, fmt.Errorf("could not create commit status provider: %w", err)
+	}
+
+	alert, err := a.flux.CreateAlert(name, name, []string{fmt.Sprintf("%s/%s", info.deployKind(), info.appDeployName())}, DefaultNotifySeverity, info.GetFluxNamespace())
+	if err != nil {
+		return nil, fmt.Errorf("could not create commit status alert: %w", err)
+	}
+
+	return append(provider, alert...), nil
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+)
+
+var _ = Describe("Commit statuses", func() {
+	var _ = BeforeEach(func() {
+		addParams = AddParams{
+			Url:                   "git@github.com:foo/bar",
+			Path:                  "./kustomize",
+			Branch:                "main",
+			Dir:                   ".",
+			DeploymentType:        "kustomize",
+			Namespace:             "wego-system",
+			AppConfigUrl:          "NONE",
+			AutoMerge:             true,
+			CommitStatus:          "flux",
+			CommitStatusSecretRef: "github-token",
+		}
+
+		fluxClient.CreateAlertProviderStub = func(name, providerType, channel, address, secretRef, namespace string) ([]byte, error) {
+			return []byte(fmt.Sprintf("provider %s %s %s %s\n", name, providerType, address, secretRef)), nil
+		}
+		fluxClient.CreateAlertStub = func(name, providerRef string, eventSources []string, eventSeverity, namespace string) ([]byte, error) {
+			return []byte(fmt.Sprintf("alert %s %s %s %s\n", name, providerRef, strings.Join(eventSources, ","), eventSeverity)), nil
+		}
+	})
+
+	It("stores the reporter in the app and applies the flux provider posting the statuses", func() {
+		err := appSrv.Add(addParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.ApplyCallCount()).To(Equal(5))
+
+		manifests := []string{}
+		for i := 0; i < kubeClient.ApplyCallCount(); i++ {
+			manifest, _ := kubeClient.ApplyArgsForCall(i)
+			manifests = append(manifests, string(manifest))
+		}
+
+		Expect(manifests).To(ContainElement(And(
+			ContainSubstring("kind: Application"),
+			ContainSubstring("  commit_status:\n    reporter: flux\n    secret_ref: github-token\n"),
+		)))
+
+		manifest, namespace := kubeClient.ApplyArgsForCall(4)
+		Expect(string(manifest)).To(Equal(
+			"provider bar-commit-status github https://github.com/foo/bar github-token\n" +
+				"alert bar-commit-status bar-commit-status Kustomization/bar info\n"))
+		Expect(namespace).To(Equal("wego-system"))
+	})
+
+	It("uses a gitlab provider for gitlab repositories", func() {
+		addParams.Url = "ssh://git@gitlab.example.com/foo/bar.git"
+
+		err := appSrv.Add(addParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		manifest, _ := kubeClient.ApplyArgsForCall(4)
+		Expect(string(manifest)).To(HavePrefix("provider bar-commit-status gitlab https://gitlab.example.com/foo/bar github-token\n"))
+	})
+
+	It("leaves the statuses to the wego controller without generating a provider", func() {
+		addParams.CommitStatus = "wego"
+
+		err := appSrv.Add(addParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.ApplyCallCount()).To(Equal(4))
+		Expect(fluxClient.CreateAlertProviderCallCount()).To(Equal(0))
+	})
+
+	It("fails for helm releases", func() {
+		addParams.DeploymentType = string(wego.DeploymentTypeHelm)
+		addParams.Chart = "podinfo"
+		addParams.Url = "https://stefanprodan.github.io/podinfo"
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError("could not set commit status: commit statuses can only be reported for git sources deployed with kustomize"))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+	})
+
+	It("fails without a secret", func() {
+		addParams.CommitStatusSecretRef = ""
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError("could not set commit status: --commit-status-secret-ref must be set to the secret holding the git provider token"))
+	})
+
+	It("fails for an unknown reporter", func() {
+		addParams.CommitStatus = "jenkins"
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError(`could not set commit status: invalid commit status reporter "jenkins", expected flux or wego`))
+	})
+})
@@ -0,0 +1,243 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/render"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// kustomizationFiles are the file names kustomize looks for in a directory
+var kustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+type DiffParams struct {
+	Name      string
+	Namespace string
+	// Dir is the local checkout of the app repository; unused for helm repository sources
+	Dir string
+}
+
+// Diff renders the app's manifests from its source and returns a unified diff of the fields they set
+// against the objects in the cluster, or an empty string when the cluster matches the source
+func (a *App) Diff(params DiffParams) (string, error) {
+	ctx := context.Background()
+
+	app, err := a.kube.GetApplication(ctx, types.NamespacedName{Name: params.Name, Namespace: params.Namespace})
+	if err != nil {
+		return "", fmt.Errorf("could not get application: %w", err)
+	}
+
+	manifests, err := a.renderAppManifests(*app, params.Dir)
+	if err != nil {
+		return "", err
+	}
+
+	objects, err := decodeObjects(manifests)
+	if err != nil {
+		return "", fmt.Errorf("could not decode the manifests of app %s: %w", app.Name, err)
+	}
+
+	diffs := []string{}
+
+	for _, desired := range objects {
+		if desired.GetKind() == "Secret" && desired.GetAPIVersion() == "v1" {
+			a.logger.Warningf("Skipping secret %s, its data can't be compared as it may be encrypted in the source", desired.GetName())
+			continue
+		}
+
+		diff, err := a.diffObject(ctx, desired, app.Spec.TargetNamespace)
+		if err != nil {
+			return "", err
+		}
+
+		if diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	return strings.Join(diffs, ""), nil
+}
+
+// renderAppManifests builds the app's manifests like the kustomize and helm controllers would
+func (a *App) renderAppManifests(app wego.Application, dir string) ([][]byte, error) {
+	if app.Spec.SourceType == wego.SourceTypeBucket {
+		return nil, fmt.Errorf("diff is not supported for bucket sources")
+	}
+
+	if app.Spec.DeploymentType == wego.DeploymentTypeHelm {
+		return a.renderHelmChart(app, dir)
+	}
+
+	root := filepath.Join(dir, app.Spec.Path)
+
+	for _, name := range kustomizationFiles {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			a.logger.Actionf("Building kustomization in %s", root)
+
+			manifests, err := a.renderer.Kustomize(root)
+			if err != nil {
+				return nil, err
+			}
+
+			return [][]byte{manifests}, nil
+		}
+	}
+
+	// The kustomize controller generates a kustomization including every manifest of plain directories
+	return findAppManifests(app, dir)
+}
+
+func (a *App) renderHelmChart(app wego.Application, dir string) ([][]byte, error) {
+	chart := render.HelmChart{Chart: app.Spec.Path, Version: app.Spec.ChartVersion}
+
+	if app.Spec.SourceType == wego.SourceTypeHelm {
+		chart.RepoURL = app.Spec.URL
+	} else {
+		chart.Chart = filepath.Join(dir, app.Spec.Path)
+	}
+
+	// The helm controller prefixes the release name with the target namespace
+	releaseName := app.Name
+	releaseNamespace := app.GetFluxNamespace()
+
+	if app.Spec.TargetNamespace != "" {
+		releaseName = fmt.Sprintf("%s-%s", app.Spec.TargetNamespace, app.Name)
+		releaseNamespace = app.Spec.TargetNamespace
+	}
+
+	if len(app.Spec.ValuesFrom) > 0 {
+		a.logger.Warningf("Values from ConfigMaps and Secrets are not included in the diff")
+	}
+
+	var values []byte
+	if app.Spec.Values != nil {
+		values = app.Spec.Values.Raw
+	}
+
+	a.logger.Actionf("Templating chart %s", chart.Chart)
+
+	manifests, err := a.renderer.HelmTemplate(releaseName, releaseNamespace, chart, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return [][]byte{manifests}, nil
+}
+
+// diffObject diffs the fields set by an object of the source against the same object in the cluster
+func (a *App) diffObject(ctx context.Context, desired *unstructured.Unstructured, targetNamespace string) (string, error) {
+	namespace := desired.GetNamespace()
+	if namespace == "" {
+		namespace = targetNamespace
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(desired.GroupVersionKind())
+
+	// The namespace is ignored for cluster scoped objects
+	if err := a.kube.GetResource(ctx, types.NamespacedName{Name: desired.GetName(), Namespace: namespace}, live); err != nil {
+		return "", fmt.Errorf("could not get %s %s: %w", desired.GetKind(), desired.GetName(), err)
+	}
+
+	delete(desired.Object, "status")
+
+	var liveYaml []byte
+
+	// Objects that were not found are left empty
+	if live.GetUID() != "" {
+		managed := managedFields(live.Object, desired.Object).(map[string]interface{})
+
+		var err error
+		if liveYaml, err = yaml.Marshal(managed); err != nil {
+			return "", fmt.Errorf("could not marshal %s %s: %w", live.GetKind(), live.GetName(), err)
+		}
+	}
+
+	desiredYaml, err := yaml.Marshal(desired.Object)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal %s %s: %w", desired.GetKind(), desired.GetName(), err)
+	}
+
+	if bytes.Equal(liveYaml, desiredYaml) {
+		return "", nil
+	}
+
+	name := fmt.Sprintf("%s/%s/%s", desired.GetKind(), namespace, desired.GetName())
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(liveYaml)),
+		B:        difflib.SplitLines(string(desiredYaml)),
+		FromFile: "cluster/" + name,
+		ToFile:   "source/" + name,
+		Context:  3,
+	})
+}
+
+// managedFields keeps the fields of a live object that the source sets, dropping the ones defaulted by the
+// API server or added by controllers. Lists are kept whole unless they have as many items as in the source.
+func managedFields(live interface{}, desired interface{}) interface{} {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+
+		result := map[string]interface{}{}
+
+		for key, value := range desiredValue {
+			if liveValue, ok := liveMap[key]; ok {
+				result[key] = managedFields(liveValue, value)
+			}
+		}
+
+		return result
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok || len(liveList) != len(desiredValue) {
+			return live
+		}
+
+		result := make([]interface{}, len(liveList))
+		for i := range liveList {
+			result[i] = managedFields(liveList[i], desiredValue[i])
+		}
+
+		return result
+	}
+
+	return live
+}
+
+// decodeObjects splits multi-document manifests into objects, sorted by kind, namespace and name
+func decodeObjects(manifests [][]byte) ([]*unstructured.Unstructured, error) {
+	objects, err := kube.DecodeObjects(manifests...)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objectKey(objects[i]) < objectKey(objects[j])
+	})
+
+	return objects, nil
+}
+
+func objectKey(object *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s/%s", object.GetKind(), object.GetNamespace(), object.GetName())
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/render"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+const diffDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+  namespace: apps
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: podinfo
+        image: ghcr.io/stefanprodan/podinfo:6.0.0
+`
+
+var _ = Describe("Diff", func() {
+	var (
+		application *wego.Application
+		diffParams  DiffParams
+		liveObjects map[string]string
+		dir         string
+	)
+
+	var _ = BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "app-diff-")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(dir, "deploy"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "deploy", "deployment.yaml"), []byte(diffDeployment), 0644)).To(Succeed())
+
+		application = &wego.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "wego-system"},
+			Spec: wego.ApplicationSpec{
+				Path:           "./deploy",
+				DeploymentType: wego.DeploymentTypeKustomize,
+				SourceType:     wego.SourceTypeGit,
+			},
+		}
+
+		diffParams = DiffParams{Name: "podinfo", Namespace: "wego-system", Dir: dir}
+
+		liveObjects = map[string]string{}
+
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return application, nil
+		}
+
+		kubeClient.GetResourceStub = func(ctx context.Context, name types.NamespacedName, r kube.Resource) error {
+			live, ok := r.(*unstructured.Unstructured)
+			Expect(ok).To(BeTrue())
+
+			manifest, found := liveObjects[live.GetKind()+"/"+name.Namespace+"/"+name.Name]
+			if !found {
+				return nil
+			}
+
+			return yaml.Unmarshal([]byte(manifest), &live.Object)
+		}
+	})
+
+	var _ = AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("ignores the fields the source doesn't set", func() {
+		liveObjects["Deployment/apps/podinfo"] = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+  namespace: apps
+  uid: "1234"
+  labels:
+    kustomize.toolkit.fluxcd.io/name: podinfo
+spec:
+  replicas: 2
+  revisionHistoryLimit: 10
+  template:
+    spec:
+      containers:
+      - name: podinfo
+        image: ghcr.io/stefanprodan/podinfo:6.0.0
+        imagePullPolicy: IfNotPresent
+status:
+  replicas: 2
+`
+
+		diff, err := appSrv.Diff(diffParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(diff).To(BeEmpty())
+	})
+
+	It("shows the fields that drifted", func() {
+		liveObjects["Deployment/apps/podinfo"] = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+  namespace: apps
+  uid: "1234"
+spec:
+  replicas: 5
+  template:
+    spec:
+      containers:
+      - name: podinfo
+        image: ghcr.io/stefanprodan/podinfo:6.0.0
+`
+
+		diff, err := appSrv.Diff(diffParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(diff).To(ContainSubstring("--- cluster/Deployment/apps/podinfo\n+++ source/Deployment/apps/podinfo\n"))
+		Expect(diff).To(ContainSubstring("-  replicas: 5\n+  replicas: 2\n"))
+	})
+
+	It("shows objects missing from the cluster as added", func() {
+		diff, err := appSrv.Diff(diffParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(diff).To(ContainSubstring("+kind: Deployment\n"))
+		Expect(diff).NotTo(ContainSubstring("\n-"))
+	})
+
+	It("builds kustomizations", func() {
+		Expect(ioutil.WriteFile(filepath.Join(diffParams.Dir, "deploy", "kustomization.yaml"), []byte("resources:\n- deployment.yaml\n"), 0644)).To(Succeed())
+		renderer.KustomizeReturns([]byte(diffDeployment), nil)
+
+		_, err := appSrv.Diff(diffParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(renderer.KustomizeCallCount()).To(Equal(1))
+		Expect(renderer.KustomizeArgsForCall(0)).To(Equal(filepath.Join(diffParams.Dir, "deploy")))
+	})
+
+	It("templates helm charts from helm repositories", func() {
+		application.Spec = wego.ApplicationSpec{
+			URL:             "https://stefanprodan.github.io/podinfo",
+			Path:            "podinfo",
+			ChartVersion:    "6.0.0",
+			DeploymentType:  wego.DeploymentTypeHelm,
+			SourceType:      wego.SourceTypeHelm,
+			TargetNamespace: "apps",
+			Values:          &apiextensionsv1.JSON{Raw: []byte(`{"replicaCount":2}`)},
+		}
+		renderer.HelmTemplateReturns([]byte(diffDeployment), nil)
+
+		_, err := appSrv.Diff(diffParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		releaseName, namespace, chart, values := renderer.HelmTemplateArgsForCall(0)
+		Expect(releaseName).To(Equal("apps-podinfo"))
+		Expect(namespace).To(Equal("apps"))
+		Expect(chart).To(Equal(render.HelmChart{Chart: "podinfo", RepoURL: "https://stefanprodan.github.io/podinfo", Version: "6.0.0"}))
+		Expect(string(values)).To(Equal(`{"replicaCount":2}`))
+	})
+
+	It("skips secrets", func() {
+		Expect(ioutil.WriteFile(filepath.Join(diffParams.Dir, "deploy", "secret.yaml"), []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: token\n"), 0644)).To(Succeed())
+
+		_, err := appSrv.Diff(diffParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(kubeClient.GetResourceCallCount()).To(Equal(1))
+	})
+
+	It("fails for bucket sources", func() {
+		application.Spec.SourceType = wego.SourceTypeBucket
+
+		_, err := appSrv.Diff(diffParams)
+		Expect(err).To(MatchError("diff is not supported for bucket sources"))
+	})
+})
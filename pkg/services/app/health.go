@@ -0,0 +1,24 @@
+package app
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+)
+
+// objectHealth computes the health of an object with kstatus. Objects without a UID were not found in the cluster.
+func objectHealth(object *unstructured.Unstructured) (string, string) {
+	if object.GetUID() == "" {
+		return HealthNotFound, "not found in the cluster"
+	}
+
+	result, err := status.Compute(object)
+	if err != nil {
+		return HealthUnknown, err.Error()
+	}
+
+	if result.Status == status.CurrentStatus {
+		return HealthCurrent, ""
+	}
+
+	return string(result.Status), result.Message
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxSetIndex bounds the list indexes of --set values, as the lists are grown to hold them
+const maxSetIndex = 65536
+
+// parseSetValues merges a --set value, comma separated key=value pairs, into the values the way helm does.
+// Keys are dotted paths with list indexes, e.g. servers[0].port, and lists are written {a,b}. Commas, dots,
+// equal signs and brackets are escaped with a backslash. Integers, booleans and null keep their type; anything
+// else, e.g. 1.10, is a string.
+func parseSetValues(set string, values map[string]interface{}) error {
+	pairs := splitUnescaped(set, ',', true)
+
+	// A trailing comma ends the last pair
+	if len(pairs) > 1 && pairs[len(pairs)-1] == "" {
+		pairs = pairs[:len(pairs)-1]
+	}
+
+	for _, pair := range pairs {
+		keyAndValue := splitUnescaped(pair, '=', false)
+		if len(keyAndValue) < 2 {
+			return fmt.Errorf("key %q has no value", unescape(pair))
+		}
+
+		key := keyAndValue[0]
+		value := strings.Join(keyAndValue[1:], "=")
+
+		path, err := parseSetKey(key)
+		if err != nil {
+			return err
+		}
+
+		setPath(values, path, parseSetValue(value))
+	}
+
+	return nil
+}
+
+// parseSetKey splits a key into the map keys, as strings, and list indexes, as ints, leading to its value
+func parseSetKey(key string) ([]interface{}, error) {
+	path := []interface{}{}
+
+	for _, part := range splitUnescaped(key, '.', false) {
+		name, indexes, err := splitIndexes(part)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", unescape(key), err)
+		}
+
+		if name == "" {
+			return nil, fmt.Errorf("key %q has an empty name", unescape(key))
+		}
+
+		path = append(path, unescape(name))
+
+		for _, index := range indexes {
+			path = append(path, index)
+		}
+	}
+
+	return path, nil
+}
+
+// splitIndexes splits the list indexes off a key name, e.g. servers[0][1]
+func splitIndexes(part string) (string, []int, error) {
+	indexes := []int{}
+
+	for strings.HasSuffix(part, "]") && !isEscaped(part, len(part)-1) {
+		open := strings.LastIndex(part, "[")
+		if open < 0 || isEscaped(part, open) {
+			return "", nil, fmt.Errorf("unmatched ] in %q", unescape(part))
+		}
+
+		index, err := strconv.Atoi(part[open+1 : len(part)-1])
+		if err != nil || index < 0 || index > maxSetIndex {
+			return "", nil, fmt.Errorf("invalid list index %q", part[open+1:len(part)-1])
+		}
+
+		indexes = append([]int{index}, indexes...)
+		part = part[:open]
+	}
+
+	return part, indexes, nil
+}
+
+// parseSetValue types a value, or each item of a {a,b} list
+func parseSetValue(value string) interface{} {
+	if len(value) >= 2 && strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") && !isEscaped(value, len(value)-1) {
+		list := []interface{}{}
+
+		for _, item := range splitUnescaped(value[1:len(value)-1], ',', false) {
+			if item != "" {
+				list = append(list, typedSetValue(unescape(item)))
+			}
+		}
+
+		return list
+	}
+
+	return typedSetValue(unescape(value))
+}
+
+// typedSetValue keeps the type of integers without a leading zero, booleans and null, like helm
+func typedSetValue(value string) interface{} {
+	switch {
+	case strings.EqualFold(value, "true"):
+		return true
+	case strings.EqualFold(value, "false"):
+		return false
+	case strings.EqualFold(value, "null"):
+		return nil
+	case value == "0":
+		return int64(0)
+	}
+
+	if value != "" && value[0] != '0' {
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	}
+
+	return value
+}
+
+// setPath sets the value at a path of map keys and list indexes, creating the maps and growing the lists on the
+// way, and returns the container, which is a new one when it was not a map or list or a list grew
+func setPath(container interface{}, path []interface{}, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+
+	if index, ok := path[0].(int); ok {
+		list, _ := container.([]interface{})
+		for len(list) <= index {
+			list = append(list, nil)
+		}
+
+		list[index] = setPath(list[index], path[1:], value)
+
+		return list
+	}
+
+	values, ok := container.(map[string]interface{})
+	if !ok {
+		values = map[string]interface{}{}
+	}
+
+	key := path[0].(string)
+	values[key] = setPath(values[key], path[1:], value)
+
+	return values
+}
+
+// splitUnescaped splits s at the separators not escaped with a backslash, skipping the ones within {} lists when
+// skipLists is set. The escapes are kept.
+func splitUnescaped(s string, sep byte, skipLists bool) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			if skipLists {
+				depth++
+			}
+		case '}':
+			if skipLists && depth > 0 {
+				depth--
+			}
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// isEscaped reports whether the byte at i is escaped by an odd number of backslashes
+func isEscaped(s string, i int) bool {
+	backslashes := 0
+	for j := i - 1; j >= 0 && s[j] == '\\'; j-- {
+		backslashes++
+	}
+
+	return backslashes%2 == 1
+}
+
+// unescape removes the backslashes escaping the characters following them
+func unescape(s string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Helm set values", func() {
+	table.DescribeTable("parses the values like helm",
+		func(set string, expected map[string]interface{}) {
+			values := map[string]interface{}{}
+			Expect(parseSetValues(set, values)).To(Succeed())
+			Expect(values).To(Equal(expected))
+		},
+		table.Entry("single value", "name1=value1", map[string]interface{}{"name1": "value1"}),
+		table.Entry("several values and a trailing comma", "name1=value1,name2=value2,", map[string]interface{}{"name1": "value1", "name2": "value2"}),
+		table.Entry("booleans and null", "name1=null,f=false,t=true", map[string]interface{}{"name1": nil, "f": false, "t": true}),
+		table.Entry("integers", "long_int=1234567890,zero=0", map[string]interface{}{"long_int": int64(1234567890), "zero": int64(0)}),
+		table.Entry("numbers kept as strings", "leading_zero=007,version=1.10", map[string]interface{}{"leading_zero": "007", "version": "1.10"}),
+		table.Entry("empty value", "name1=", map[string]interface{}{"name1": ""}),
+		table.Entry("equal signs in the value", "url=http://example.com?a=b", map[string]interface{}{"url": "http://example.com?a=b"}),
+		table.Entry("escaped commas", `name1=one\,two,name2=three`, map[string]interface{}{"name1": "one,two", "name2": "three"}),
+		table.Entry("escaped dots in the key", `name1\.with\.dots=value`, map[string]interface{}{"name1.with.dots": "value"}),
+		table.Entry("nested keys", "outer.inner1=value1,outer.inner2=value2", map[string]interface{}{
+			"outer": map[string]interface{}{"inner1": "value1", "inner2": "value2"},
+		}),
+		table.Entry("lists", "list={a,b,3}", map[string]interface{}{"list": []interface{}{"a", "b", int64(3)}}),
+		table.Entry("list indexes", "list[1]=b,servers[0].port=80", map[string]interface{}{
+			"list":    []interface{}{nil, "b"},
+			"servers": []interface{}{map[string]interface{}{"port": int64(80)}},
+		}),
+		table.Entry("nested list indexes", "nested[0][1]=x", map[string]interface{}{
+			"nested": []interface{}{[]interface{}{nil, "x"}},
+		}),
+	)
+
+	table.DescribeTable("fails for invalid values",
+		func(set string, expected string) {
+			Expect(parseSetValues(set, map[string]interface{}{})).To(MatchError(expected))
+		},
+		table.Entry("missing value", "name1", `key "name1" has no value`),
+		table.Entry("empty pair", "name1=value1,,name2=value2", `key "" has no value`),
+		table.Entry("empty key name", ".name=value", `key ".name" has an empty name`),
+		table.Entry("invalid list index", "list[a]=b", `key "list[a]": invalid list index "a"`),
+		table.Entry("list index too large", "list[99999]=b", `key "list[99999]": invalid list index "99999"`),
+	)
+
+	It("merges the values into the existing ones", func() {
+		values := map[string]interface{}{
+			"persistence": map[string]interface{}{"enabled": false, "size": "1Gi"},
+		}
+
+		Expect(parseSetValues("persistence.enabled=true,replicas=2", values)).To(Succeed())
+		Expect(values).To(Equal(map[string]interface{}{
+			"persistence": map[string]interface{}{"enabled": true, "size": "1Gi"},
+			"replicas":    int64(2),
+		}))
+	})
+})
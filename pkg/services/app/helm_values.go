@@ -0,0 +1,155 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func hasHelmValues(params AddParams) bool {
+	return len(params.ValuesFiles) > 0 || len(params.SetValues) > 0 || len(params.ValuesFrom) > 0
+}
+
+// setHelmValues stores the helm values and values references passed to `wego app add` in the application spec
+func setHelmValues(app *wego.Application, params AddParams) error {
+	if !hasHelmValues(params) {
+		return nil
+	}
+
+	if app.Spec.DeploymentType != wego.DeploymentTypeHelm {
+		return fmt.Errorf("helm values can only be set for helm deployments")
+	}
+
+	values, err := parseHelmValues(params.ValuesFiles, params.SetValues)
+	if err != nil {
+		return err
+	}
+
+	valuesFrom, err := parseValuesFrom(params.ValuesFrom)
+	if err != nil {
+		return err
+	}
+
+	app.Spec.Values = values
+	app.Spec.ValuesFrom = valuesFrom
+
+	return nil
+}
+
+// parseHelmValues merges the values files, in order, and then the key=value pairs
+// into a single set of helm values. Later values override earlier ones.
+func parseHelmValues(valuesFiles []string, setValues []string) (*apiextensionsv1.JSON, error) {
+	if len(valuesFiles) == 0 && len(setValues) == 0 {
+		return nil, nil
+	}
+
+	values := map[string]interface{}{}
+
+	for _, file := range valuesFiles {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading values file %s: %w", file, err)
+		}
+
+		fileValues := map[string]interface{}{}
+		if err := yaml.Unmarshal(data, &fileValues); err != nil {
+			return nil, fmt.Errorf("failed parsing values file %s: %w", file, err)
+		}
+
+		mergeValues(values, fileValues)
+	}
+
+	for _, set := range setValues {
+		if err := parseSetValues(set, values); err != nil {
+			return nil, fmt.Errorf("invalid helm value %q: %w", set, err)
+		}
+	}
+
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal helm values: %w", err)
+	}
+
+	return &apiextensionsv1.JSON{Raw: raw}, nil
+}
+
+func mergeValues(dst map[string]interface{}, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+
+		dst[key] = value
+	}
+}
+
+// parseValuesFrom parses references in the form Kind/name[:key], e.g. ConfigMap/my-values or Secret/my-secret:values.yaml
+func parseValuesFrom(refs []string) ([]wego.ValuesReference, error) {
+	result := []wego.ValuesReference{}
+
+	for _, ref := range refs {
+		parts := strings.SplitN(ref, "/", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid values reference %q, expected Kind/name[:key]", ref)
+		}
+
+		kind := parts[0]
+		if kind != "ConfigMap" && kind != "Secret" {
+			return nil, fmt.Errorf("invalid values reference kind %q, expected ConfigMap or Secret", kind)
+		}
+
+		nameAndKey := strings.SplitN(parts[1], ":", 2)
+
+		valuesRef := wego.ValuesReference{
+			Kind: kind,
+			Name: nameAndKey[0],
+		}
+
+		if len(nameAndKey) == 2 {
+			valuesRef.ValuesKey = nameAndKey[1]
+		}
+
+		result = append(result, valuesRef)
+	}
+
+	return result, nil
+}
+
+// addHelmValues carries the values stored in the application spec into a generated HelmRelease manifest
+func addHelmValues(manifest []byte, spec wego.ApplicationSpec) ([]byte, error) {
+	if spec.Values == nil && len(spec.ValuesFrom) == 0 {
+		return manifest, nil
+	}
+
+	helmRelease := helmv2.HelmRelease{}
+	if err := yaml.Unmarshal(manifest, &helmRelease); err != nil {
+		return nil, fmt.Errorf("could not unmarshal helm release: %w", err)
+	}
+
+	helmRelease.Spec.Values = spec.Values
+
+	for _, ref := range spec.ValuesFrom {
+		helmRelease.Spec.ValuesFrom = append(helmRelease.Spec.ValuesFrom, helmv2.ValuesReference{
+			Kind:      ref.Kind,
+			Name:      ref.Name,
+			ValuesKey: ref.ValuesKey,
+		})
+	}
+
+	out, err := yaml.Marshal(&helmRelease)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal helm release: %w", err)
+	}
+
+	return sanitizeK8sYaml(out), nil
+}
@@ -0,0 +1,303 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	ImagePolicySemver       = "semver"
+	ImagePolicyAlphabetical = "alphabetical"
+	ImagePolicyNumerical    = "numerical"
+)
+
+type ImagePolicyParams struct {
+	Name      string
+	Namespace string
+	// Image is the image whose tags are scanned, without a tag, e.g. ghcr.io/stefanprodan/podinfo
+	Image string
+	// ImageSecretRef is the docker-registry secret used to list the tags of private images
+	ImageSecretRef string
+	// Policy is how the latest tag is selected, semver, alphabetical or numerical
+	Policy string
+	// Select is the semver range of semver policies, or the asc or desc order of alphabetical and numerical ones
+	Select        string
+	FilterRegex   string
+	FilterExtract string
+	// UpdatePath is the path of the manifests updated in the app repository, the app's path by default
+	UpdatePath        string
+	DryRun            bool
+	AutoMerge         bool
+	GitProviderToken  string
+	CommitAuthorName  string
+	CommitAuthorEmail string
+	InMemoryClone     bool
+}
+
+// ImagePolicy writes the image repository and policy selecting the latest tag of an image next to the automation of an
+// existing app, with the image update automation writing the tag back to the app's manifests in its repository and branch
+func (a *App) ImagePolicy(params ImagePolicyParams) error {
+	ctx := context.Background()
+
+	if err := validateImagePolicy(params); err != nil {
+		return err
+	}
+
+	app, err := a.kube.GetApplication(ctx, types.NamespacedName{Name: params.Name, Namespace: params.Namespace})
+	if err != nil {
+		return fmt.Errorf("could not get application: %w", err)
+	}
+
+	// The update automation pushes to the app's git repository
+	if app.Spec.SourceType != wego.SourceTypeGit {
+		return fmt.Errorf("images can only be updated for apps with a git source")
+	}
+
+	clusterName, err := a.kube.GetClusterName(ctx)
+	if err != nil {
+		return err
+	}
+
+	info := getAppResourceInfo(*app, clusterName)
+
+	// The updates are pushed from this cluster only, the other targets would push the same commits
+	pushTarget := info.currentTarget()
+	if pushTarget == nil {
+		return fmt.Errorf("image updates are pushed from the current cluster, which is not a target of app %s", info.Name)
+	}
+
+	writeSecretRef, err := a.createAndUploadWriteKey(pushTarget, params)
+	if err != nil {
+		return err
+	}
+
+	files := []gitprovider.CommitFile{}
+
+	for _, target := range info.targetInfos() {
+		a.logger.Generatef("Generating image update manifests for target %s", target.targetName)
+
+		policy, err := a.generateImagePolicy(target, params)
+		if err != nil {
+			return err
+		}
+
+		policyPath, policyContent := target.appImagePolicyPath(params.Image), string(policy)
+		files = append(files, gitprovider.CommitFile{Path: &policyPath, Content: &policyContent})
+
+		if target.clusterName != pushTarget.clusterName {
+			continue
+		}
+
+		automation, err := a.generateImageUpdate(target, params, writeSecretRef)
+		if err != nil {
+			return err
+		}
+
+		automationPath, automationContent := target.appImageUpdatePath(), string(automation)
+		files = append(files, gitprovider.CommitFile{Path: &automationPath, Content: &automationContent})
+	}
+
+	if err := a.saveAutomationFiles(info, files, automationChange{
+		command:           "image-policy",
+		description:       fmt.Sprintf("Added image updates of %s for %s", params.Image, info.Name),
+		dryRun:            params.DryRun,
+		autoMerge:         params.AutoMerge,
+		gitProviderToken:  params.GitProviderToken,
+		commitAuthorName:  params.CommitAuthorName,
+		commitAuthorEmail: params.CommitAuthorEmail,
+		inMemoryClone:     params.InMemoryClone,
+	}); err != nil {
+		return err
+	}
+
+	policyName := info.imagePolicyName(params.Image)
+	a.logger.Println("Mark the images to update in the app's manifests with the image policy:\n  image: %s:<tag> %s\nor the tag of kustomize images with:\n  newTag: <tag> %s",
+		params.Image, imagePolicySetterMarker(info.GetFluxNamespace(), policyName, ""), imagePolicySetterMarker(info.GetFluxNamespace(), policyName, "tag"))
+
+	return nil
+}
+
+func validateImagePolicy(params ImagePolicyParams) error {
+	if params.Image == "" {
+		return fmt.Errorf("--image must be set to the image to update")
+	}
+
+	if strings.Contains(params.Image[strings.LastIndex(params.Image, "/")+1:], ":") {
+		return fmt.Errorf("image %s should not have a tag, the image policy selects it", params.Image)
+	}
+
+	switch params.Policy {
+	case ImagePolicySemver:
+		if params.Select == "" {
+			return fmt.Errorf("--select must be set to a semver range for semver policies, e.g. >=1.0.0")
+		}
+	case ImagePolicyAlphabetical, ImagePolicyNumerical:
+		if params.Select != "" && params.Select != "asc" && params.Select != "desc" {
+			return fmt.Errorf("invalid order %q for %s policies, expected asc or desc", params.Select, params.Policy)
+		}
+	default:
+		return fmt.Errorf("unsupported image policy %q, expected semver, alphabetical or numerical", params.Policy)
+	}
+
+	if params.FilterExtract != "" && params.FilterRegex == "" {
+		return fmt.Errorf("--filter-extract needs the --filter-regex it extracts from")
+	}
+
+	return nil
+}
+
+// generateImagePolicy returns the image repository and policy of an image
+func (a *App) generateImagePolicy(info *AppResourceInfo, params ImagePolicyParams) ([]byte, error) {
+	name := info.imagePolicyName(params.Image)
+
+	repository, err := a.flux.CreateImageRepository(name, params.Image, params.ImageSecretRef, info.GetFluxNamespace())
+	if err != nil {
+		return nil, fmt.Errorf("could not create image repository: %w", err)
+	}
+
+	selection := params.Select
+	if selection == "" {
+		selection = "asc"
+	}
+
+	policy, err := a.flux.CreateImagePolicy(name, name, params.Policy, selection, params.FilterRegex, params.FilterExtract, info.GetFluxNamespace())
+	if err != nil {
+		return nil, fmt.Errorf("could not create image policy: %w", err)
+	}
+
+	return bytes.Join([][]byte{repository, policy}, []byte("")), nil
+}
+
+// generateImageUpdate returns the automation updating the app's manifests, and the source it pushes the updates
+// through with the write-enabled deploy key of the cluster. The app's own source reads the repository with a
+// read-only key.
+func (a *App) generateImageUpdate(info *AppResourceInfo, params ImagePolicyParams, writeSecretRef string) ([]byte, error) {
+	source, err := a.flux.CreateSourceGit(info.imageUpdateSourceName(), sanitizeRepoUrl(info.Spec.URL), info.Spec.Branch, "", "", writeSecretRef, info.GetFluxNamespace())
+	if err != nil {
+		return nil, fmt.Errorf("could not create image update source: %w", err)
+	}
+
+	updatePath := params.UpdatePath
+	if updatePath == "" {
+		updatePath = info.Spec.Path
+	}
+
+	author := CommitAuthor(params.CommitAuthorName, params.CommitAuthorEmail)
+
+	automation, err := a.flux.CreateImageUpdateAutomation(info.imageUpdateName(), info.imageUpdateSourceName(), updatePath, info.Spec.Branch, author.Name, author.Email, info.GetFluxNamespace())
+	if err != nil {
+		return nil, fmt.Errorf("could not create image update automation: %w", err)
+	}
+
+	return bytes.Join([][]byte{source, automation}, []byte("")), nil
+}
+
+// createAndUploadWriteKey returns the name of the secret holding the write-enabled deploy key the cluster pushes image
+// updates with. The key is generated, uploaded and applied when the cluster doesn't have it yet; a key whose secret
+// was lost is replaced.
+func (a *App) createAndUploadWriteKey(info *AppResourceInfo, params ImagePolicyParams) (string, error) {
+	secretRefName := info.appWriteSecretName()
+	if params.DryRun {
+		return secretRefName, nil
+	}
+
+	secretPresent, err := a.kube.SecretPresent(context.Background(), secretRefName, info.GetFluxNamespace())
+	if err != nil {
+		return "", fmt.Errorf("failed check for existing secret: %w", err)
+	}
+
+	if secretPresent {
+		return secretRefName, nil
+	}
+
+	repoUrl := sanitizeRepoUrl(info.Spec.URL)
+
+	owner, err := getOwnerFromUrl(repoUrl)
+	if err != nil {
+		return "", err
+	}
+
+	repoName := urlToRepoName(repoUrl)
+
+	gitProvider, err := a.gitProviderFactory(params.GitProviderToken)
+	if err != nil {
+		return "", err
+	}
+
+	a.logger.Generatef("Generating write-enabled deploy key for repo %s", repoUrl)
+
+	secret, err := a.flux.CreateSecretGit(secretRefName, repoUrl, info.GetFluxNamespace())
+	if err != nil {
+		return "", fmt.Errorf("could not create git secret: %w", err)
+	}
+
+	var secretData corev1.Secret
+	if err := yaml.Unmarshal(secret, &secretData); err != nil {
+		return "", fmt.Errorf("failed to unmarshal created secret: %w", err)
+	}
+
+	keyName := gitproviders.WriteDeployKeyName(info.targetName)
+	if err := gitProvider.DeleteDeployKey(owner, repoName, keyName); err != nil {
+		return "", fmt.Errorf("error deleting the previous write-enabled deploy key: %w", err)
+	}
+
+	if err := gitProvider.UploadWriteDeployKey(owner, repoName, keyName, []byte(secretData.StringData["identity.pub"])); err != nil {
+		return "", fmt.Errorf("error uploading write-enabled deploy key: %w", err)
+	}
+
+	if out, err := a.kube.Apply(secret, info.GetFluxNamespace()); err != nil {
+		return "", fmt.Errorf("could not apply manifest: %s: %w", string(out), err)
+	}
+
+	return secretRefName, nil
+}
+
+// imagePolicySetterMarker returns the comment marking a field of the app's manifests to be set to the image selected by a policy,
+// or to its tag or name only
+func imagePolicySetterMarker(namespace string, policyName string, field string) string {
+	ref := fmt.Sprintf("%s:%s", namespace, policyName)
+	if field != "" {
+		ref = fmt.Sprintf("%s:%s", ref, field)
+	}
+
+	return fmt.Sprintf(`# {"$imagepolicy": "%s"}`, ref)
+}
+
+// imagePolicyName is the name of the image repository and policy of one of the app's images,
+// e.g. podinfo-podinfo for ghcr.io/stefanprodan/podinfo
+func (a *AppResourceInfo) imagePolicyName(image string) string {
+	return fmt.Sprintf("%s-%s", a.Name, image[strings.LastIndex(image, "/")+1:])
+}
+
+// imageUpdateName is the name of the automation updating all the images of the app
+func (a *AppResourceInfo) imageUpdateName() string {
+	return a.Name
+}
+
+// imageUpdateSourceName is the name of the source the image update automation pushes through
+func (a *AppResourceInfo) imageUpdateSourceName() string {
+	return fmt.Sprintf("%s-image-update", a.Name)
+}
+
+// appWriteSecretName is the name of the secret holding the write-enabled deploy key of the app's repository
+func (a *AppResourceInfo) appWriteSecretName() string {
+	return fmt.Sprintf("weave-gitops-%s-%s-write", a.targetName, urlToRepoName(a.Spec.URL))
+}
+
+func (a *AppResourceInfo) appImagePolicyPath(image string) string {
+	return filepath.Join(a.appAutomationDir(), fmt.Sprintf("%s-image.yaml", a.imagePolicyName(image)))
+}
+
+func (a *AppResourceInfo) appImageUpdatePath() string {
+	return filepath.Join(a.appAutomationDir(), fmt.Sprintf("%s-image-update.yaml", a.Name))
+}
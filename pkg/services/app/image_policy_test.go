@@ -0,0 +1,232 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Image policies", func() {
+	var (
+		application *wego.Application
+		params      ImagePolicyParams
+	)
+
+	var _ = BeforeEach(func() {
+		application = &wego.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "wego-system"},
+			Spec: wego.ApplicationSpec{
+				URL:            "ssh://git@github.com/foo/podinfo.git",
+				ConfigURL:      "ssh://git@github.com/foo/config.git",
+				Branch:         "main",
+				Path:           "./kustomize",
+				SourceType:     wego.SourceTypeGit,
+				DeploymentType: wego.DeploymentTypeKustomize,
+			},
+		}
+
+		params = ImagePolicyParams{
+			Name:      "podinfo",
+			Namespace: "wego-system",
+			Image:     "ghcr.io/stefanprodan/podinfo",
+			Policy:    ImagePolicySemver,
+			Select:    "5.0.x",
+			AutoMerge: true,
+		}
+
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return application, nil
+		}
+
+		fluxClient.CreateImageRepositoryStub = func(name, image, secretRef, namespace string) ([]byte, error) {
+			return []byte(fmt.Sprintf("repository %s %s %s\n", name, image, secretRef)), nil
+		}
+		fluxClient.CreateImagePolicyStub = func(name, imageRef, policy, selection, filterRegex, filterExtract, namespace string) ([]byte, error) {
+			return []byte(fmt.Sprintf("policy %s %s %s %s\n", name, imageRef, policy, selection)), nil
+		}
+		fluxClient.CreateImageUpdateAutomationStub = func(name, gitRepoRef, gitRepoPath, branch, authorName, authorEmail, namespace string) ([]byte, error) {
+			return []byte(fmt.Sprintf("update %s %s %s %s\n", name, gitRepoRef, gitRepoPath, branch)), nil
+		}
+		fluxClient.CreateSourceGitStub = func(name, url, branch, tag, semver, secretRef, namespace string) ([]byte, error) {
+			return []byte(fmt.Sprintf("source %s %s %s %s\n", name, url, branch, secretRef)), nil
+		}
+		fluxClient.CreateSecretGitStub = func(name, url, namespace string) ([]byte, error) {
+			return []byte(fmt.Sprintf("metadata:\n  name: %s\nstringData:\n  identity.pub: write-key\n", name)), nil
+		}
+	})
+
+	It("writes the image policy and the update automation next to the app's automation", func() {
+		err := appSrv.ImagePolicy(params)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, _, url, branch, _ := gitClient.CloneWithOptionsArgsForCall(0)
+		Expect(url).To(Equal("ssh://git@github.com/foo/config.git"))
+		Expect(branch).To(Equal("main"))
+
+		Expect(gitClient.WriteCallCount()).To(Equal(2))
+
+		path, content := gitClient.WriteArgsForCall(0)
+		Expect(path).To(Equal("targets/test-cluster/podinfo/podinfo-podinfo-image.yaml"))
+		Expect(string(content)).To(Equal(
+			"repository podinfo-podinfo ghcr.io/stefanprodan/podinfo \n" +
+				"policy podinfo-podinfo podinfo-podinfo semver 5.0.x\n"))
+
+		path, content = gitClient.WriteArgsForCall(1)
+		Expect(path).To(Equal("targets/test-cluster/podinfo/podinfo-image-update.yaml"))
+		Expect(string(content)).To(Equal(
+			"source podinfo-image-update ssh://git@github.com/foo/podinfo.git main weave-gitops-test-cluster-podinfo-write\n" +
+				"update podinfo podinfo-image-update ./kustomize main\n"))
+
+		_, _, _, _, authorName, authorEmail, _ := fluxClient.CreateImageUpdateAutomationArgsForCall(0)
+		Expect(authorName).To(Equal(DefaultCommitAuthorName))
+		Expect(authorEmail).To(Equal(DefaultCommitAuthorEmail))
+
+		Expect(gitClient.PushCallCount()).To(Equal(1))
+	})
+
+	It("defaults the order of numerical policies and updates the given path", func() {
+		params.Policy = ImagePolicyNumerical
+		params.Select = ""
+		params.FilterRegex = "^main-[a-f0-9]+-(?P<ts>[0-9]+)"
+		params.FilterExtract = "$ts"
+		params.UpdatePath = "./deploy"
+
+		err := appSrv.ImagePolicy(params)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, _, policy, selection, filterRegex, filterExtract, _ := fluxClient.CreateImagePolicyArgsForCall(0)
+		Expect(policy).To(Equal("numerical"))
+		Expect(selection).To(Equal("asc"))
+		Expect(filterRegex).To(Equal("^main-[a-f0-9]+-(?P<ts>[0-9]+)"))
+		Expect(filterExtract).To(Equal("$ts"))
+
+		_, _, gitRepoPath, _, _, _, _ := fluxClient.CreateImageUpdateAutomationArgsForCall(0)
+		Expect(gitRepoPath).To(Equal("./deploy"))
+	})
+
+	It("opens a pull request with the image update manifests", func() {
+		params.AutoMerge = false
+		gitProviders.CreatePullRequestToUserRepoReturns(nil, fmt.Errorf("stop after the pull request"))
+
+		err := appSrv.ImagePolicy(params)
+		Expect(err).To(MatchError("unable to create pull request: stop after the pull request"))
+
+		_, _, newBranch, files, _, title, _ := gitProviders.CreatePullRequestToUserRepoArgsForCall(0)
+		Expect(newBranch).To(Equal("wego-image-policy-podinfo"))
+		Expect(title).To(Equal("wego app image-policy podinfo"))
+		Expect(files).To(HaveLen(2))
+	})
+
+	It("applies the manifests when the automation is only in the cluster", func() {
+		application.Spec.ConfigURL = "NONE"
+
+		err := appSrv.ImagePolicy(params)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(0))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(3))
+	})
+
+	It("pushes the updates with a write-enabled deploy key of the cluster", func() {
+		err := appSrv.ImagePolicy(params)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, secretName, namespace := kubeClient.SecretPresentArgsForCall(0)
+		Expect(secretName).To(Equal("weave-gitops-test-cluster-podinfo-write"))
+		Expect(namespace).To(Equal("wego-system"))
+
+		// A key left behind by a lost secret is replaced
+		Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(1))
+		_, _, keyName := gitProviders.DeleteDeployKeyArgsForCall(0)
+		Expect(keyName).To(Equal("weave-gitops-test-cluster-write-key"))
+
+		Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
+		Expect(gitProviders.UploadWriteDeployKeyCallCount()).To(Equal(1))
+		owner, repoName, keyName, key := gitProviders.UploadWriteDeployKeyArgsForCall(0)
+		Expect(owner).To(Equal("foo"))
+		Expect(repoName).To(Equal("podinfo"))
+		Expect(keyName).To(Equal("weave-gitops-test-cluster-write-key"))
+		Expect(key).To(Equal([]byte("write-key")))
+
+		secret, _ := kubeClient.ApplyArgsForCall(0)
+		Expect(string(secret)).To(ContainSubstring("name: weave-gitops-test-cluster-podinfo-write\n"))
+	})
+
+	It("reuses the write-enabled deploy key of the cluster", func() {
+		kubeClient.SecretPresentReturns(true, nil)
+
+		err := appSrv.ImagePolicy(params)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(0))
+		Expect(gitProviders.UploadWriteDeployKeyCallCount()).To(Equal(0))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+	})
+
+	It("only pushes the updates from the current cluster", func() {
+		application.Spec.Targets = []wego.ApplicationTarget{{Name: "test-cluster"}, {Name: "prod"}}
+
+		err := appSrv.ImagePolicy(params)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		paths := []string{}
+		for i := 0; i < gitClient.WriteCallCount(); i++ {
+			path, _ := gitClient.WriteArgsForCall(i)
+			paths = append(paths, path)
+		}
+
+		Expect(paths).To(Equal([]string{
+			"targets/test-cluster/podinfo/podinfo-podinfo-image.yaml",
+			"targets/test-cluster/podinfo/podinfo-image-update.yaml",
+			"targets/prod/podinfo/podinfo-podinfo-image.yaml",
+		}))
+	})
+
+	It("fails when the current cluster is not a target", func() {
+		application.Spec.Targets = []wego.ApplicationTarget{{Name: "prod"}}
+
+		err := appSrv.ImagePolicy(params)
+		Expect(err).To(MatchError("image updates are pushed from the current cluster, which is not a target of app podinfo"))
+		Expect(gitProviders.UploadWriteDeployKeyCallCount()).To(Equal(0))
+	})
+
+	It("fails for apps without a git source", func() {
+		application.Spec.SourceType = wego.SourceTypeHelm
+
+		err := appSrv.ImagePolicy(params)
+		Expect(err).To(MatchError("images can only be updated for apps with a git source"))
+	})
+
+	table.DescribeTable("validates the policy",
+		func(change func(*ImagePolicyParams), expectedErr string) {
+			change(&params)
+
+			err := appSrv.ImagePolicy(params)
+			Expect(err).To(MatchError(expectedErr))
+			Expect(kubeClient.GetApplicationCallCount()).To(Equal(0))
+		},
+		table.Entry("missing image", func(p *ImagePolicyParams) { p.Image = "" },
+			"--image must be set to the image to update"),
+		table.Entry("image with a tag", func(p *ImagePolicyParams) { p.Image = "ghcr.io/stefanprodan/podinfo:5.0.0" },
+			"image ghcr.io/stefanprodan/podinfo:5.0.0 should not have a tag, the image policy selects it"),
+		table.Entry("semver without a range", func(p *ImagePolicyParams) { p.Select = "" },
+			"--select must be set to a semver range for semver policies, e.g. >=1.0.0"),
+		table.Entry("invalid order", func(p *ImagePolicyParams) { p.Policy = ImagePolicyAlphabetical; p.Select = "newest" },
+			`invalid order "newest" for alphabetical policies, expected asc or desc`),
+		table.Entry("unknown policy", func(p *ImagePolicyParams) { p.Policy = "latest" },
+			`unsupported image policy "latest", expected semver, alphabetical or numerical`),
+		table.Entry("extract without a regex", func(p *ImagePolicyParams) { p.FilterExtract = "$ts" },
+			"--filter-extract needs the --filter-regex it extracts from"),
+	)
+
+	It("marks the image and tag fields of the app's manifests", func() {
+		Expect(imagePolicySetterMarker("wego-system", "podinfo-podinfo", "")).To(Equal(`# {"$imagepolicy": "wego-system:podinfo-podinfo"}`))
+		Expect(imagePolicySetterMarker("wego-system", "podinfo-podinfo", "tag")).To(Equal(`# {"$imagepolicy": "wego-system:podinfo-podinfo:tag"}`))
+	})
+})
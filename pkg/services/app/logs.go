@@ -0,0 +1,373 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SourceEvent is the source of log entries read from Kubernetes events
+const SourceEvent = "event"
+
+// fluxControllers are the controllers reconciling the flux objects of apps, labelled app=<name>
+var fluxControllers = []string{"source-controller", "kustomize-controller", "helm-controller"}
+
+// eventPollInterval is how often new events are listed when following the logs of an app
+var eventPollInterval = 5 * time.Second
+
+type LogsParams struct {
+	Name      string
+	Namespace string
+	// Since only returns the entries newer than this duration, when set
+	Since  time.Duration
+	Follow bool
+	// FluxNamespace is the namespace the flux controllers run in, the flux namespace of the app when empty
+	FluxNamespace string
+}
+
+// LogEntry is a Kubernetes event or a controller log line about one of the objects of an app
+type LogEntry struct {
+	Time time.Time
+	// Source is "event" for Kubernetes events, or the name of the controller that logged the line
+	Source string
+	// Level is the event type or the log level
+	Level string
+	// Object is the object the entry is about, as Kind/name.namespace
+	Object  string
+	Message string
+}
+
+func (e LogEntry) String() string {
+	return fmt.Sprintf("%s %s %s %s %s", e.Time.UTC().Format(time.RFC3339), e.Source, e.Level, e.Object, e.Message)
+}
+
+// objectRef identifies an object in events and controller logs
+type objectRef struct {
+	kind      string
+	name      string
+	namespace string
+}
+
+func (r objectRef) String() string {
+	if r.namespace == "" {
+		return fmt.Sprintf("%s/%s", r.kind, r.name)
+	}
+
+	return fmt.Sprintf("%s/%s.%s", r.kind, r.name, r.namespace)
+}
+
+// Logs prints the events and controller logs about the objects of an app, until interrupted when following
+func (a *App) Logs(params LogsParams) error {
+	ctx := context.Background()
+
+	app, err := a.kube.GetApplication(ctx, types.NamespacedName{Name: params.Name, Namespace: params.Namespace})
+	if err != nil {
+		return fmt.Errorf("could not get application: %w", err)
+	}
+
+	return GetAppLogs(ctx, a.kube, *app, params, func(entry LogEntry) {
+		a.logger.Println("%s", entry)
+	})
+}
+
+// GetAppLogs passes the events and controller log lines about an app's flux objects and inventory to handler,
+// ordered by time. When following, new entries are passed as they come until the context is done.
+func GetAppLogs(ctx context.Context, kubeClient kube.Kube, app wego.Application, params LogsParams, handler func(LogEntry)) error {
+	objects, err := appObjectRefs(ctx, kubeClient, app)
+	if err != nil {
+		return err
+	}
+
+	since := time.Time{}
+	if params.Since > 0 {
+		since = time.Now().Add(-params.Since)
+	}
+
+	seenEvents := map[string]time.Time{}
+
+	entries, err := appEvents(ctx, kubeClient, objects, since, seenEvents)
+	if err != nil {
+		return err
+	}
+
+	logOptions := kube.LogOptions{Since: params.Since, Follow: params.Follow}
+
+	streams := map[string][]io.ReadCloser{}
+
+	defer func() {
+		for _, controllerStreams := range streams {
+			for _, stream := range controllerStreams {
+				stream.Close()
+			}
+		}
+	}()
+
+	fluxNamespace := params.FluxNamespace
+	if fluxNamespace == "" {
+		fluxNamespace = app.GetFluxNamespace()
+	}
+
+	for _, controller := range fluxControllers {
+		if streams[controller], err = kubeClient.GetPodLogs(ctx, fluxNamespace, map[string]string{"app": controller}, logOptions); err != nil {
+			return fmt.Errorf("could not get the logs of %s: %w", controller, err)
+		}
+	}
+
+	if !params.Follow {
+		for controller, controllerStreams := range streams {
+			for _, stream := range controllerStreams {
+				lines, err := controllerLogEntries(stream, controller, objects)
+				if err != nil {
+					return err
+				}
+
+				entries = append(entries, lines...)
+			}
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+
+	for _, entry := range entries {
+		handler(entry)
+	}
+
+	if !params.Follow {
+		return nil
+	}
+
+	return followAppLogs(ctx, kubeClient, objects, streams, since, seenEvents, handler)
+}
+
+// followAppLogs passes new controller log lines and events newer than since to handler until the context is done
+func followAppLogs(ctx context.Context, kubeClient kube.Kube, objects map[objectRef]bool, streams map[string][]io.ReadCloser, since time.Time, seenEvents map[string]time.Time, handler func(LogEntry)) error {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	safeHandler := func(entry LogEntry) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		handler(entry)
+	}
+
+	for controller, controllerStreams := range streams {
+		for _, stream := range controllerStreams {
+			wg.Add(1)
+
+			go func(controller string, stream io.Reader) {
+				defer wg.Done()
+
+				scanner := bufio.NewScanner(stream)
+				for scanner.Scan() {
+					if entry, ok := parseControllerLogLine(scanner.Bytes(), controller, objects); ok {
+						safeHandler(entry)
+					}
+				}
+			}(controller, stream)
+		}
+	}
+
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Closing the streams stops the readers
+			for _, controllerStreams := range streams {
+				for _, stream := range controllerStreams {
+					stream.Close()
+				}
+			}
+
+			wg.Wait()
+
+			return nil
+		case <-ticker.C:
+			entries, err := appEvents(ctx, kubeClient, objects, since, seenEvents)
+			if err != nil {
+				if ctx.Err() != nil {
+					continue
+				}
+
+				return err
+			}
+
+			for _, entry := range entries {
+				safeHandler(entry)
+			}
+		}
+	}
+}
+
+// appObjectRefs returns the app's flux objects and the objects in its inventory
+func appObjectRefs(ctx context.Context, kubeClient kube.Kube, app wego.Application) (map[objectRef]bool, error) {
+	sourceKind := "GitRepository"
+
+	switch app.Spec.SourceType {
+	case wego.SourceTypeHelm:
+		sourceKind = "HelmRepository"
+	case wego.SourceTypeBucket:
+		sourceKind = "Bucket"
+	}
+
+	namespace := app.GetFluxNamespace()
+
+	objects := map[objectRef]bool{
+		{kind: sourceKind, name: app.Name, namespace: namespace}: true,
+	}
+
+	if app.Spec.DeploymentType == wego.DeploymentTypeHelm {
+		objects[objectRef{kind: "HelmRelease", name: app.Name, namespace: namespace}] = true
+		// The helm controller names the chart after the namespace and name of the release
+		objects[objectRef{kind: "HelmChart", name: fmt.Sprintf("%s-%s", namespace, app.Name), namespace: namespace}] = true
+	} else {
+		objects[objectRef{kind: "Kustomization", name: app.Name, namespace: namespace}] = true
+	}
+
+	resources, err := GetAppResources(ctx, kubeClient, app)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range resources {
+		objects[objectRef{kind: r.Kind, name: r.Name, namespace: r.Namespace}] = true
+	}
+
+	return objects, nil
+}
+
+// appEvents lists the events about the given objects that are newer than since and were not seen yet
+func appEvents(ctx context.Context, kubeClient kube.Kube, objects map[objectRef]bool, since time.Time, seen map[string]time.Time) ([]LogEntry, error) {
+	namespaces := map[string]bool{}
+	for object := range objects {
+		namespaces[object.namespace] = true
+	}
+
+	// Events about cluster scoped objects are in the default namespace
+	if namespaces[""] {
+		delete(namespaces, "")
+		namespaces[corev1.NamespaceDefault] = true
+	}
+
+	entries := []LogEntry{}
+
+	for namespace := range namespaces {
+		events, err := kubeClient.GetEvents(ctx, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("could not get events: %w", err)
+		}
+
+		for _, event := range events {
+			ref := objectRef{kind: event.InvolvedObject.Kind, name: event.InvolvedObject.Name, namespace: event.InvolvedObject.Namespace}
+			if !objects[ref] {
+				continue
+			}
+
+			timestamp := eventTime(event)
+			if timestamp.Before(since) {
+				continue
+			}
+
+			// Repeated events are updated in place with a new timestamp
+			if last, ok := seen[string(event.UID)]; ok && !timestamp.After(last) {
+				continue
+			}
+
+			seen[string(event.UID)] = timestamp
+
+			entries = append(entries, LogEntry{
+				Time:    timestamp,
+				Source:  SourceEvent,
+				Level:   event.Type,
+				Object:  ref.String(),
+				Message: event.Message,
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+
+	return event.FirstTimestamp.Time
+}
+
+func controllerLogEntries(stream io.Reader, controller string, objects map[objectRef]bool) ([]LogEntry, error) {
+	entries := []LogEntry{}
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		if entry, ok := parseControllerLogLine(scanner.Bytes(), controller, objects); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read the logs of %s: %w", controller, err)
+	}
+
+	return entries, nil
+}
+
+// controllerLogLine is a line of the JSON logs of the flux controllers
+type controllerLogLine struct {
+	Level     string `json:"level"`
+	Timestamp string `json:"ts"`
+	Message   string `json:"msg"`
+	Kind      string `json:"reconciler kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Error     string `json:"error"`
+}
+
+// parseControllerLogLine returns the entry of a controller log line about one of the given objects
+func parseControllerLogLine(data []byte, controller string, objects map[objectRef]bool) (LogEntry, bool) {
+	line := controllerLogLine{}
+	if err := json.Unmarshal(data, &line); err != nil {
+		return LogEntry{}, false
+	}
+
+	ref := objectRef{kind: line.Kind, name: line.Name, namespace: line.Namespace}
+	if !objects[ref] {
+		return LogEntry{}, false
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, line.Timestamp)
+	if err != nil {
+		return LogEntry{}, false
+	}
+
+	message := line.Message
+	if line.Error != "" {
+		message = fmt.Sprintf("%s: %s", message, line.Error)
+	}
+
+	return LogEntry{
+		Time:    timestamp,
+		Source:  controller,
+		Level:   line.Level,
+		Object:  ref.String(),
+		Message: message,
+	}, true
+}
@@ -0,0 +1,236 @@
+package app
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func appEvent(uid string, kind string, name string, namespace string, timestamp time.Time, message string) corev1.Event {
+	return corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{UID: types.UID(uid), Namespace: namespace},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Name: name, Namespace: namespace},
+		Type:           corev1.EventTypeNormal,
+		LastTimestamp:  metav1.NewTime(timestamp),
+		Message:        message,
+	}
+}
+
+var _ = Describe("Logs", func() {
+	var (
+		application *wego.Application
+		now         time.Time
+		logs        map[string]string
+	)
+
+	var _ = BeforeEach(func() {
+		application = &wego.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "wego-system"},
+			Spec:       wego.ApplicationSpec{DeploymentType: wego.DeploymentTypeKustomize},
+		}
+
+		now = time.Now().UTC().Truncate(time.Second)
+		logs = map[string]string{}
+
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return application, nil
+		}
+
+		kubeClient.GetResourceStub = func(ctx context.Context, name types.NamespacedName, r kube.Resource) error {
+			if kustomization, ok := r.(*kustomizev1.Kustomization); ok {
+				kustomization.Name = name.Name
+				kustomization.Namespace = name.Namespace
+				kustomization.Status.Snapshot = &kustomizev1.Snapshot{
+					Entries: []kustomizev1.SnapshotEntry{{Namespace: "apps", Kinds: map[string]string{"apps/v1, Kind=Deployment": "Deployment"}}},
+				}
+			}
+
+			return nil
+		}
+
+		kubeClient.ListResourcesStub = func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+			return []unstructured.Unstructured{unstructuredObject("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: podinfo\n  namespace: apps\n  uid: a\n")}, nil
+		}
+
+		kubeClient.GetEventsStub = func(ctx context.Context, namespace string) ([]corev1.Event, error) {
+			switch namespace {
+			case "wego-system":
+				return []corev1.Event{
+					appEvent("1", "Kustomization", "podinfo", "wego-system", now.Add(-2*time.Hour), "old reconciliation"),
+					appEvent("2", "Kustomization", "other", "wego-system", now.Add(-time.Minute), "another app"),
+				}, nil
+			case "apps":
+				return []corev1.Event{
+					appEvent("3", "Deployment", "podinfo", "apps", now.Add(-time.Minute), "Scaled up replica set podinfo-1234 to 2"),
+				}, nil
+			}
+
+			return nil, nil
+		}
+
+		kubeClient.GetPodLogsStub = func(ctx context.Context, namespace string, labels map[string]string, opts kube.LogOptions) ([]io.ReadCloser, error) {
+			Expect(namespace).To(Equal("wego-system"))
+
+			return []io.ReadCloser{ioutil.NopCloser(strings.NewReader(logs[labels["app"]]))}, nil
+		}
+	})
+
+	It("shows the events and controller log lines about the objects of the app", func() {
+		logs["kustomize-controller"] = strings.Join([]string{
+			`{"level":"info","ts":"` + now.Add(-30*time.Second).Format(time.RFC3339) + `","msg":"Reconciliation finished","reconciler kind":"Kustomization","name":"podinfo","namespace":"wego-system"}`,
+			`{"level":"error","ts":"` + now.Add(-20*time.Second).Format(time.RFC3339) + `","msg":"Reconciliation failed","reconciler kind":"Kustomization","name":"other","namespace":"wego-system"}`,
+			`not a json line`,
+		}, "\n")
+		logs["source-controller"] = `{"level":"error","ts":"` + now.Add(-90*time.Second).Format(time.RFC3339) + `","msg":"Reconciliation failed","reconciler kind":"GitRepository","name":"podinfo","namespace":"wego-system","error":"auth failed"}`
+
+		entries := []LogEntry{}
+		err := GetAppLogs(context.Background(), kubeClient, *application, LogsParams{}, func(entry LogEntry) {
+			entries = append(entries, entry)
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(entries).To(Equal([]LogEntry{
+			{Time: now.Add(-2 * time.Hour), Source: SourceEvent, Level: "Normal", Object: "Kustomization/podinfo.wego-system", Message: "old reconciliation"},
+			{Time: now.Add(-90 * time.Second), Source: "source-controller", Level: "error", Object: "GitRepository/podinfo.wego-system", Message: "Reconciliation failed: auth failed"},
+			{Time: now.Add(-time.Minute), Source: SourceEvent, Level: "Normal", Object: "Deployment/podinfo.apps", Message: "Scaled up replica set podinfo-1234 to 2"},
+			{Time: now.Add(-30 * time.Second), Source: "kustomize-controller", Level: "info", Object: "Kustomization/podinfo.wego-system", Message: "Reconciliation finished"},
+		}))
+	})
+
+	It("only shows the events newer than since", func() {
+		entries := []LogEntry{}
+		err := GetAppLogs(context.Background(), kubeClient, *application, LogsParams{Since: time.Hour}, func(entry LogEntry) {
+			entries = append(entries, entry)
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Object).To(Equal("Deployment/podinfo.apps"))
+
+		_, _, _, opts := kubeClient.GetPodLogsArgsForCall(0)
+		Expect(opts).To(Equal(kube.LogOptions{Since: time.Hour}))
+	})
+
+	It("streams new events and log lines when following", func() {
+		defer func(interval time.Duration) { eventPollInterval = interval }(eventPollInterval)
+		eventPollInterval = 10 * time.Millisecond
+
+		logs["helm-controller"] = ""
+		reader, writer := io.Pipe()
+
+		kubeClient.GetPodLogsStub = func(ctx context.Context, namespace string, labels map[string]string, opts kube.LogOptions) ([]io.ReadCloser, error) {
+			Expect(opts.Follow).To(BeTrue())
+
+			if labels["app"] == "kustomize-controller" {
+				return []io.ReadCloser{reader}, nil
+			}
+
+			return []io.ReadCloser{}, nil
+		}
+
+		var (
+			mu      sync.Mutex
+			entries []LogEntry
+		)
+
+		messages := func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+
+			result := []string{}
+			for _, entry := range entries {
+				result = append(result, entry.Message)
+			}
+
+			return result
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		go func() {
+			done <- GetAppLogs(ctx, kubeClient, *application, LogsParams{Follow: true}, func(entry LogEntry) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				entries = append(entries, entry)
+			})
+		}()
+
+		Eventually(messages).Should(Equal([]string{"old reconciliation", "Scaled up replica set podinfo-1234 to 2"}))
+
+		_, err := writer.Write([]byte(`{"level":"info","ts":"` + now.Format(time.RFC3339) + `","msg":"Reconciliation finished","reconciler kind":"Kustomization","name":"podinfo","namespace":"wego-system"}` + "\n"))
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Eventually(messages).Should(ContainElement("Reconciliation finished"))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+
+		// Events are only shown once while following
+		Expect(messages()).To(HaveLen(3))
+	})
+
+	It("does not show the events older than since when following", func() {
+		defer func(interval time.Duration) { eventPollInterval = interval }(eventPollInterval)
+		eventPollInterval = 10 * time.Millisecond
+
+		var (
+			mu       sync.Mutex
+			messages []string
+		)
+
+		received := func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+
+			return append([]string{}, messages...)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		go func() {
+			done <- GetAppLogs(ctx, kubeClient, *application, LogsParams{Since: time.Hour, Follow: true}, func(entry LogEntry) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				messages = append(messages, entry.Message)
+			})
+		}()
+
+		Eventually(kubeClient.GetEventsCallCount).Should(BeNumerically(">", 10))
+		Consistently(received, 50*time.Millisecond).Should(Equal([]string{"Scaled up replica set podinfo-1234 to 2"}))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("reads the controller logs in the flux namespace", func() {
+		kubeClient.GetPodLogsStub = nil
+
+		err := GetAppLogs(context.Background(), kubeClient, *application, LogsParams{FluxNamespace: "flux-system"}, func(entry LogEntry) {})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.GetPodLogsCallCount()).To(Equal(3))
+
+		for i := 0; i < kubeClient.GetPodLogsCallCount(); i++ {
+			_, namespace, _, _ := kubeClient.GetPodLogsArgsForCall(i)
+			Expect(namespace).To(Equal("flux-system"))
+		}
+	})
+})
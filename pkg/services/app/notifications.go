@@ -0,0 +1,315 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// NotificationProviderGeneric posts the events to a webhook address, e.g. generic:http://receiver.default:8080
+	NotificationProviderGeneric = "generic"
+
+	DefaultNotifySeverity = "info"
+)
+
+// notificationChannelProviders are the providers posting to a channel of a chat service. Their webhook
+// address is read from the address key of a secret.
+var notificationChannelProviders = map[string]bool{
+	"slack":   true,
+	"discord": true,
+	"rocket":  true,
+}
+
+type NotifyParams struct {
+	Name      string
+	Namespace string
+	// Notify are the notifications to send the app's events to, in the form <provider>:<channel or address>
+	Notify []string
+	// NotifySecretRef is the secret holding the webhook address of the chat providers
+	NotifySecretRef   string
+	NotifySeverity    string
+	DryRun            bool
+	AutoMerge         bool
+	GitProviderToken  string
+	CommitAuthorName  string
+	CommitAuthorEmail string
+	InMemoryClone     bool
+}
+
+// notification is a provider the events of an app are sent to
+type notification struct {
+	providerType string
+	channel      string
+	address      string
+}
+
+// parseNotifications parses notifications in the form <provider>:<channel> for chat providers,
+// or generic:<address> for webhooks
+func parseNotifications(notify []string, secretRef string) ([]notification, error) {
+	result := []notification{}
+	seen := map[string]bool{}
+
+	for _, n := range notify {
+		parts := strings.SplitN(n, ":", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid notification %q, expected <provider>:<channel> or generic:<address>", n)
+		}
+
+		providerType := parts[0]
+		if seen[providerType] {
+			return nil, fmt.Errorf("%s notifications are specified more than once", providerType)
+		}
+
+		seen[providerType] = true
+
+		switch {
+		case providerType == NotificationProviderGeneric:
+			result = append(result, notification{providerType: providerType, address: parts[1]})
+		case notificationChannelProviders[providerType]:
+			if secretRef == "" {
+				return nil, fmt.Errorf("%s notifications need the webhook address in a secret, set --notify-secret-ref", providerType)
+			}
+
+			result = append(result, notification{providerType: providerType, channel: parts[1]})
+		default:
+			return nil, fmt.Errorf("unsupported notification provider %q, expected generic, slack, discord or rocket", providerType)
+		}
+	}
+
+	return result, nil
+}
+
+// generateNotifications returns the providers and alerts sending the events of an app's source and
+// kustomization or helm release to the given notifications, and the ones posting its commit statuses
+func (a *App) generateNotifications(info *AppResourceInfo, notifications []notification, secretRef string, severity string) ([]byte, error) {
+	eventSources := []string{
+		fmt.Sprintf("%s/%s", info.sourceKind(), info.appSourceName()),
+		fmt.Sprintf("%s/%s", info.deployKind(), info.appDeployName()),
+	}
+
+	manifests := [][]byte{}
+
+	for _, n := range notifications {
+		name := info.notificationName(n.providerType)
+
+		providerSecretRef := ""
+		if n.providerType != NotificationProviderGeneric {
+			providerSecretRef = secretRef
+		}
+
+		provider, err := a.flux.CreateAlertProvider(name, n.providerType, n.channel, n.address, providerSecretRef, info.GetFluxNamespace())
+		if err != nil {
+			return nil, fmt.Errorf("could not create %s notification provider: %w", n.providerType, err)
+		}
+
+		alert, err := a.flux.CreateAlert(name, name, eventSources, severity, info.GetFluxNamespace())
+		if err != nil {
+			return nil, fmt.Errorf("could not create %s alert: %w", n.providerType, err)
+		}
+
+		manifests = append(manifests, provider, alert)
+	}
+
+	if info.reportsCommitStatusWithFlux() {
+		commitStatus, err := a.generateCommitStatusNotification(info)
+		if err != nil {
+			return nil, err
+		}
+
+		manifests = append(manifests, commitStatus)
+	}
+
+	return bytes.Join(manifests, []byte("")), nil
+}
+
+// generateTargetNotifications sets the notification manifests of each target of an app being added
+func (a *App) generateTargetNotifications(targets []targetAutomation, params AddParams) error {
+	if len(params.Notify) == 0 && !targets[0].info.reportsCommitStatusWithFlux() {
+		return nil
+	}
+
+	notifications, err := parseNotifications(params.Notify, params.NotifySecretRef)
+	if err != nil {
+		return err
+	}
+
+	a.logger.Generatef("Generating notification manifests")
+
+	for i := range targets {
+		if targets[i].notifications, err = a.generateNotifications(targets[i].info, notifications, params.NotifySecretRef, notifySeverity(params.NotifySeverity)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Notify writes the notification providers and alerts of an existing app next to its automation,
+// or applies them to the cluster when the automation is only stored there
+func (a *App) Notify(params NotifyParams) error {
+	ctx := context.Background()
+
+	notifications, err := parseNotifications(params.Notify, params.NotifySecretRef)
+	if err != nil {
+		return err
+	}
+
+	if len(notifications) == 0 {
+		return fmt.Errorf("no notifications were given, use --notify <provider>:<channel> or --notify generic:<address>")
+	}
+
+	app, err := a.kube.GetApplication(ctx, types.NamespacedName{Name: params.Name, Namespace: params.Namespace})
+	if err != nil {
+		return fmt.Errorf("could not get application: %w", err)
+	}
+
+	clusterName, err := a.kube.GetClusterName(ctx)
+	if err != nil {
+		return err
+	}
+
+	info := getAppResourceInfo(*app, clusterName)
+
+	files := []gitprovider.CommitFile{}
+
+	for _, target := range info.targetInfos() {
+		a.logger.Generatef("Generating notification manifests for target %s", target.targetName)
+
+		manifest, err := a.generateNotifications(target, notifications, params.NotifySecretRef, notifySeverity(params.NotifySeverity))
+		if err != nil {
+			return err
+		}
+
+		path := target.appNotificationsPath()
+		content := string(manifest)
+
+		files = append(files, gitprovider.CommitFile{Path: &path, Content: &content})
+	}
+
+	return a.saveAutomationFiles(info, files, automationChange{
+		command:           "notify",
+		description:       fmt.Sprintf("Added notifications for %s", info.Name),
+		dryRun:            params.DryRun,
+		autoMerge:         params.AutoMerge,
+		gitProviderToken:  params.GitProviderToken,
+		commitAuthorName:  params.CommitAuthorName,
+		commitAuthorEmail: params.CommitAuthorEmail,
+		inMemoryClone:     params.InMemoryClone,
+	})
+}
+
+// automationChange describes how files added to the automation of an existing app are saved
+type automationChange struct {
+	// command is the wego app subcommand making the change, naming its pull request and branch
+	command           string
+	description       string
+	dryRun            bool
+	autoMerge         bool
+	gitProviderToken  string
+	commitAuthorName  string
+	commitAuthorEmail string
+	inMemoryClone     bool
+}
+
+// saveAutomationFiles writes files next to the automation of an existing app and pushes them, or opens
+// a pull request with them. They are applied to the cluster when the automation is only stored there.
+func (a *App) saveAutomationFiles(info *AppResourceInfo, files []gitprovider.CommitFile, change automationChange) error {
+	app := info.Application
+
+	if strings.ToUpper(app.Spec.ConfigURL) == string(ConfigTypeNone) {
+		manifests := [][]byte{}
+		for _, file := range files {
+			manifests = append(manifests, []byte(*file.Content))
+		}
+
+		a.logger.Actionf("Applying manifests to the cluster")
+
+		return a.applyToCluster(info.GetFluxNamespace(), change.dryRun, manifests...)
+	}
+
+	if change.dryRun {
+		for _, file := range files {
+			a.logger.Println("%s", *file.Content)
+		}
+
+		return nil
+	}
+
+	repoUrl := app.Spec.URL
+	if isExternalConfigUrl(app) {
+		repoUrl = app.Spec.ConfigURL
+	}
+
+	gitProvider, err := a.gitProviderFactory(change.gitProviderToken)
+	if err != nil {
+		return err
+	}
+
+	if !change.autoMerge {
+		return a.createPullRequest(gitProvider, repoUrl, app.Spec.Branch, fmt.Sprintf("wego-%s-%s", change.command, app.Name), files,
+			fmt.Sprintf("wego app %s %s %s %s", change.command, app.Spec.URL, app.Spec.Path, app.Name),
+			fmt.Sprintf("wego app %s %s", change.command, app.Name), change.description)
+	}
+
+	addParams := AddParams{
+		Name:              app.Name,
+		AutoMerge:         change.autoMerge,
+		CommitAuthorName:  change.commitAuthorName,
+		CommitAuthorEmail: change.commitAuthorEmail,
+		InMemoryClone:     change.inMemoryClone,
+	}
+
+	remover, err := a.cloneRepo(repoUrl, app.Spec.Branch, addParams)
+	if err != nil {
+		return fmt.Errorf("failed to clone configuration repo: %w", err)
+	}
+	defer remover()
+
+	a.logger.Actionf("Writing manifests to disk")
+
+	for _, file := range files {
+		if err := a.git.Write(*file.Path, []byte(*file.Content)); err != nil {
+			return fmt.Errorf("failed writing manifests to disk: %w", err)
+		}
+	}
+
+	return a.commitAndPush(addParams)
+}
+
+func (a *App) writeTargetNotifications(targets []targetAutomation) error {
+	for _, target := range targets {
+		if len(target.notifications) == 0 {
+			continue
+		}
+
+		if err := a.git.Write(target.info.appNotificationsPath(), target.notifications); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func notifySeverity(severity string) string {
+	if severity == "" {
+		return DefaultNotifySeverity
+	}
+
+	return severity
+}
+
+// notificationName is the name of the provider and alert of one of the app's notifications
+func (a *AppResourceInfo) notificationName(providerType string) string {
+	return fmt.Sprintf("%s-%s", a.Name, providerType)
+}
+
+// appNotificationsPath is the file holding the app's notifications, next to its automation so the target dir kustomization applies it
+func (a *AppResourceInfo) appNotificationsPath() string {
+	return filepath.Join(a.appAutomationDir(), fmt.Sprintf("%s-notifications.yaml", a.Name))
+}
@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Notifications", func() {
+	var _ = BeforeEach(func() {
+		fluxClient.CreateAlertProviderStub = func(name, providerType, channel, address, secretRef, namespace string) ([]byte, error) {
+			return []byte(fmt.Sprintf("provider %s %s %s %s %s\n", name, providerType, channel, address, secretRef)), nil
+		}
+		fluxClient.CreateAlertStub = func(name, providerRef string, eventSources []string, eventSeverity, namespace string) ([]byte, error) {
+			return []byte(fmt.Sprintf("alert %s %s %s %s\n", name, providerRef, strings.Join(eventSources, ","), eventSeverity)), nil
+		}
+	})
+
+	table.DescribeTable("parses notifications",
+		func(notify []string, secretRef string, expected []notification, expectedErr string) {
+			result, err := parseNotifications(notify, secretRef)
+			if expectedErr != "" {
+				Expect(err).To(MatchError(expectedErr))
+				return
+			}
+
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result).To(Equal(expected))
+		},
+		table.Entry("generic webhook", []string{"generic:http://receiver.default:8080"}, "",
+			[]notification{{providerType: "generic", address: "http://receiver.default:8080"}}, ""),
+		table.Entry("slack channel", []string{"slack:deploys"}, "slack-webhook",
+			[]notification{{providerType: "slack", channel: "deploys"}}, ""),
+		table.Entry("slack without a secret", []string{"slack:deploys"}, "",
+			nil, "slack notifications need the webhook address in a secret, set --notify-secret-ref"),
+		table.Entry("unknown provider", []string{"pager:oncall"}, "",
+			nil, `unsupported notification provider "pager", expected generic, slack, discord or rocket`),
+		table.Entry("missing channel", []string{"slack"}, "slack-webhook",
+			nil, `invalid notification "slack", expected <provider>:<channel> or generic:<address>`),
+		table.Entry("repeated provider", []string{"generic:http://a", "generic:http://b"}, "",
+			nil, "generic notifications are specified more than once"),
+	)
+
+	Context("adding an app", func() {
+		var _ = BeforeEach(func() {
+			addParams = AddParams{
+				Url:             "git@github.com:foo/bar",
+				Path:            "./kustomize",
+				Branch:          "main",
+				Dir:             ".",
+				DeploymentType:  "kustomize",
+				Namespace:       "wego-system",
+				AppConfigUrl:    "NONE",
+				AutoMerge:       true,
+				Notify:          []string{"slack:deploys", "generic:http://receiver.default:8080"},
+				NotifySecretRef: "slack-webhook",
+			}
+		})
+
+		It("applies the providers and alerts with the rest of the app", func() {
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(kubeClient.ApplyCallCount()).To(Equal(5))
+
+			manifest, namespace := kubeClient.ApplyArgsForCall(4)
+			Expect(string(manifest)).To(Equal(
+				"provider bar-slack slack deploys  slack-webhook\n" +
+					"alert bar-slack bar-slack GitRepository/bar,Kustomization/bar info\n" +
+					"provider bar-generic generic  http://receiver.default:8080 \n" +
+					"alert bar-generic bar-generic GitRepository/bar,Kustomization/bar info\n"))
+			Expect(namespace).To(Equal("wego-system"))
+		})
+
+		It("writes the notifications next to the automation of each target", func() {
+			addParams.AppConfigUrl = "git@github.com:foo/config"
+			addParams.Targets = []string{"test-cluster", "prod"}
+			addParams.SecretEncryption = string(wego.SecretEncryptionSops)
+			addParams.SopsAgeRecipients = []string{"age1abc"}
+			addParams.Notify = []string{"generic:http://receiver.default:8080"}
+			addParams.NotifySeverity = "error"
+
+			err := appSrv.Add(addParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(gitClient.WriteCallCount()).To(Equal(5))
+
+			path, content := gitClient.WriteArgsForCall(3)
+			Expect(path).To(Equal("targets/test-cluster/bar/bar-notifications.yaml"))
+			Expect(string(content)).To(HaveSuffix("alert bar-generic bar-generic GitRepository/bar,Kustomization/bar error\n"))
+
+			path, _ = gitClient.WriteArgsForCall(4)
+			Expect(path).To(Equal("targets/prod/bar/bar-notifications.yaml"))
+		})
+
+		It("fails before changing anything for an invalid notification", func() {
+			addParams.NotifySecretRef = ""
+
+			err := appSrv.Add(addParams)
+			Expect(err).To(MatchError("could not set notifications: slack notifications need the webhook address in a secret, set --notify-secret-ref"))
+			Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("notifying for an existing app", func() {
+		var (
+			application *wego.Application
+			params      NotifyParams
+		)
+
+		var _ = BeforeEach(func() {
+			application = &wego.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "wego-system"},
+				Spec: wego.ApplicationSpec{
+					URL:            "ssh://git@github.com/foo/podinfo.git",
+					ConfigURL:      "ssh://git@github.com/foo/config.git",
+					Branch:         "main",
+					SourceType:     wego.SourceTypeHelm,
+					DeploymentType: wego.DeploymentTypeHelm,
+				},
+			}
+
+			params = NotifyParams{
+				Name:      "podinfo",
+				Namespace: "wego-system",
+				Notify:    []string{"generic:http://receiver.default:8080"},
+				AutoMerge: true,
+			}
+
+			kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+				return application, nil
+			}
+		})
+
+		It("writes the notifications to the config repository and pushes them", func() {
+			err := appSrv.Notify(params)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, _, url, branch, _ := gitClient.CloneWithOptionsArgsForCall(0)
+			Expect(url).To(Equal("ssh://git@github.com/foo/config.git"))
+			Expect(branch).To(Equal("main"))
+
+			Expect(gitClient.WriteCallCount()).To(Equal(1))
+
+			path, content := gitClient.WriteArgsForCall(0)
+			Expect(path).To(Equal("targets/test-cluster/podinfo/podinfo-notifications.yaml"))
+			Expect(string(content)).To(Equal(
+				"provider podinfo-generic generic  http://receiver.default:8080 \n" +
+					"alert podinfo-generic podinfo-generic HelmRepository/podinfo,HelmRelease/podinfo info\n"))
+
+			Expect(gitClient.CommitCallCount()).To(Equal(1))
+			commit, _ := gitClient.CommitArgsForCall(0)
+			Expect(commit.Author).To(Equal(git.Author{Name: DefaultCommitAuthorName, Email: DefaultCommitAuthorEmail}))
+			Expect(gitClient.PushCallCount()).To(Equal(1))
+		})
+
+		It("opens a pull request with the notifications", func() {
+			params.AutoMerge = false
+			gitProviders.CreatePullRequestToUserRepoReturns(nil, fmt.Errorf("stop after the pull request"))
+
+			err := appSrv.Notify(params)
+			Expect(err).To(MatchError("unable to create pull request: stop after the pull request"))
+
+			_, branch, newBranch, files, _, title, _ := gitProviders.CreatePullRequestToUserRepoArgsForCall(0)
+			Expect(branch).To(Equal("main"))
+			Expect(newBranch).To(Equal("wego-notify-podinfo"))
+			Expect(title).To(Equal("wego app notify podinfo"))
+			Expect(files).To(HaveLen(1))
+			Expect(*files[0].Path).To(Equal("targets/test-cluster/podinfo/podinfo-notifications.yaml"))
+
+			Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(0))
+		})
+
+		It("applies the notifications when the automation is only in the cluster", func() {
+			application.Spec.ConfigURL = "NONE"
+
+			err := appSrv.Notify(params)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(0))
+			Expect(kubeClient.ApplyCallCount()).To(Equal(1))
+
+			manifest, namespace := kubeClient.ApplyArgsForCall(0)
+			Expect(string(manifest)).To(ContainSubstring("provider podinfo-generic generic"))
+			Expect(namespace).To(Equal("wego-system"))
+		})
+
+		It("fails without notifications", func() {
+			params.Notify = nil
+
+			err := appSrv.Notify(params)
+			Expect(err).To(MatchError("no notifications were given, use --notify <provider>:<channel> or --notify generic:<address>"))
+		})
+	})
+})
@@ -0,0 +1,375 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fluxcd/go-git-providers/gitprovider"
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	extensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type PurgeParams struct {
+	Namespace        string
+	GitProviderToken string
+	// GitAuth is the auth the automation is read with, in the format of the automation repository urls
+	GitAuth GitAuthType
+	// RemoveAutomation opens a pull request removing the automation of each app from the repository holding it
+	RemoveAutomation bool
+	// CommitMessage is the message of the commits of the pull requests removing the automation
+	CommitMessage string
+	DryRun        bool
+}
+
+// resourceAPIVersions are the API versions of the kinds of the cluster resources of an app
+var resourceAPIVersions = map[string]string{
+	"GitRepository":  sourcev1.GroupVersion.String(),
+	"HelmRepository": sourcev1.GroupVersion.String(),
+	"Bucket":         sourcev1.GroupVersion.String(),
+	"Kustomization":  kustomizev1.GroupVersion.String(),
+	"HelmRelease":    helmv2.GroupVersion.String(),
+	"Application":    wego.GroupVersion.String(),
+	"Secret":         "v1",
+
+	"Provider":              notificationGroupVersion.String(),
+	"Alert":                 notificationGroupVersion.String(),
+	"ImageRepository":       imageGroupVersion.String(),
+	"ImagePolicy":           imageGroupVersion.String(),
+	"ImageUpdateAutomation": imageGroupVersion.String(),
+}
+
+var (
+	notificationGroupVersion = schema.GroupVersion{Group: "notification.toolkit.fluxcd.io", Version: "v1beta1"}
+	imageGroupVersion        = schema.GroupVersion{Group: "image.toolkit.fluxcd.io", Version: "v1alpha2"}
+)
+
+// automationObjectKinds are the kinds of the objects wego app notify and image-policy add to the automation of an
+// app, by the name of their CRD. Their names are not recorded in the app, so they are listed from the cluster. The
+// image kinds are only there when the image automation controllers are installed.
+var automationObjectKinds = []struct {
+	crd string
+	gvk schema.GroupVersionKind
+}{
+	{crd: "providers.notification.toolkit.fluxcd.io", gvk: notificationGroupVersion.WithKind("Provider")},
+	{crd: "alerts.notification.toolkit.fluxcd.io", gvk: notificationGroupVersion.WithKind("Alert")},
+	{crd: "imagerepositories.image.toolkit.fluxcd.io", gvk: imageGroupVersion.WithKind("ImageRepository")},
+	{crd: "imagepolicies.image.toolkit.fluxcd.io", gvk: imageGroupVersion.WithKind("ImagePolicy")},
+	{crd: "imageupdateautomations.image.toolkit.fluxcd.io", gvk: imageGroupVersion.WithKind("ImageUpdateAutomation")},
+}
+
+// Purge removes every app of a namespace: the objects generated for them in the cluster, which prunes what they
+// deployed, and the weave gitops deploy keys of their repositories. With RemoveAutomation, a pull request removing
+// the automation of each app is opened on the repository holding it.
+func (a *App) Purge(params PurgeParams) error {
+	ctx := context.Background()
+
+	clusterName, err := a.kube.GetClusterName(ctx)
+	if err != nil {
+		return err
+	}
+
+	apps, err := a.kube.GetApplications(ctx, params.Namespace)
+	if err != nil {
+		return fmt.Errorf("could not get applications: %w", err)
+	}
+
+	if len(apps) == 0 {
+		a.logger.Successf("No applications to remove in %s", params.Namespace)
+		return nil
+	}
+
+	gitProvider, err := a.gitProviderFactory(params.GitProviderToken)
+	if err != nil {
+		return err
+	}
+
+	repoUrls := []string{}
+	seen := map[string]bool{}
+
+	for _, app := range apps {
+		info := getAppResourceInfo(app, clusterName)
+
+		automationObjects, err := a.automationObjects(ctx, info)
+		if err != nil {
+			return fmt.Errorf("could not remove app %s: %w", app.Name, err)
+		}
+
+		if err := a.removeClusterResources(info, automationObjects, params.DryRun); err != nil {
+			return fmt.Errorf("could not remove app %s: %w", app.Name, err)
+		}
+
+		if params.RemoveAutomation && strings.ToUpper(app.Spec.ConfigURL) != string(ConfigTypeNone) {
+			if err := a.removeAutomation(info, gitProvider, params); err != nil {
+				return fmt.Errorf("could not remove the automation of app %s: %w", app.Name, err)
+			}
+		}
+
+		for _, repoUrl := range appRepoUrls(app) {
+			if !seen[repoUrl] {
+				seen[repoUrl] = true
+				repoUrls = append(repoUrls, repoUrl)
+			}
+		}
+	}
+
+	for _, repoUrl := range repoUrls {
+		if err := a.deleteDeployKeys(repoUrl, clusterName, gitProvider, params.DryRun); err != nil {
+			return fmt.Errorf("could not delete the deploy keys of repo %s: %w", repoUrl, err)
+		}
+	}
+
+	for _, repoUrl := range appGitRepoUrls(apps) {
+		if err := a.deleteWriteDeployKey(repoUrl, clusterName, gitProvider, params.DryRun); err != nil {
+			return fmt.Errorf("could not delete the write-enabled deploy key of repo %s: %w", repoUrl, err)
+		}
+	}
+
+	return nil
+}
+
+// removeClusterResources deletes the objects generated for an app, the automation syncing them first so that
+// nothing recreates them
+func (a *App) removeClusterResources(info *AppResourceInfo, automationObjects []ResourceRef, dryRun bool) error {
+	resources := append(info.clusterResources(), automationObjects...)
+
+	for i := len(resources) - 1; i >= 0; i-- {
+		resource := resources[i]
+
+		if dryRun {
+			a.logger.Actionf("Deleting %s %s", resource.kind, resource.name)
+			continue
+		}
+
+		// The application is in the wego namespace, next to the flux objects when they are not in a namespace of their own
+		namespace := info.GetFluxNamespace()
+		if resource.kind == "Application" {
+			namespace = info.Namespace
+		}
+
+		manifest := fmt.Sprintf("apiVersion: %s\nkind: %s\nmetadata:\n  name: %s\n  namespace: %s\n",
+			resourceAPIVersions[resource.kind], resource.kind, resource.name, namespace)
+
+		if out, err := a.kube.Delete([]byte(manifest), namespace); err != nil {
+			// Resources are shared by apps syncing from the same repository, and may already be gone
+			if strings.Contains(string(out), "NotFound") {
+				continue
+			}
+
+			return fmt.Errorf("could not delete %s %s: %s: %w", resource.kind, resource.name, string(out), err)
+		}
+
+		a.logger.Actionf("Deleted %s %s", resource.kind, resource.name)
+	}
+
+	return nil
+}
+
+// automationObjects returns the notifications and image updates of an app found in its flux namespace. They are
+// deleted explicitly, as nothing prunes them when flux is managed outside of wego.
+func (a *App) automationObjects(ctx context.Context, info *AppResourceInfo) ([]ResourceRef, error) {
+	objects := map[string][]unstructured.Unstructured{}
+
+	for _, kind := range automationObjectKinds {
+		crd := &extensionsv1.CustomResourceDefinition{}
+		if err := a.kube.GetResource(ctx, types.NamespacedName{Name: kind.crd}, crd); err != nil {
+			return nil, fmt.Errorf("could not get CRD %s: %w", kind.crd, err)
+		}
+
+		if crd.Name == "" {
+			continue
+		}
+
+		items, err := a.kube.ListResources(ctx, kind.gvk, info.GetFluxNamespace(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not list the %s objects of app %s: %w", kind.gvk.Kind, info.Name, err)
+		}
+
+		objects[kind.gvk.Kind] = items
+	}
+
+	notificationNames := map[string]bool{info.notificationName("commit-status"): true, info.notificationName(NotificationProviderGeneric): true}
+	for providerType := range notificationChannelProviders {
+		notificationNames[info.notificationName(providerType)] = true
+	}
+
+	resources := []ResourceRef{}
+
+	for _, kind := range []string{"Provider", "Alert"} {
+		for _, object := range objects[kind] {
+			if notificationNames[object.GetName()] {
+				resources = append(resources, ResourceRef{kind: kind, name: object.GetName()})
+			}
+		}
+	}
+
+	// The repository and policy of an image share its name, prefixed with the name of the app
+	for _, policy := range objects["ImagePolicy"] {
+		repository, _, _ := unstructured.NestedString(policy.Object, "spec", "imageRepositoryRef", "name")
+		if !strings.HasPrefix(policy.GetName(), info.Name+"-") || repository != policy.GetName() {
+			continue
+		}
+
+		resources = append(resources,
+			ResourceRef{kind: "ImageRepository", name: repository},
+			ResourceRef{kind: "ImagePolicy", name: policy.GetName()})
+	}
+
+	for _, automation := range objects["ImageUpdateAutomation"] {
+		if automation.GetName() == info.imageUpdateName() {
+			resources = append(resources,
+				ResourceRef{kind: "GitRepository", name: info.imageUpdateSourceName()},
+				ResourceRef{kind: "ImageUpdateAutomation", name: automation.GetName()})
+		}
+	}
+
+	return resources, nil
+}
+
+// removeAutomation opens a pull request deleting the app and target directories of an app
+func (a *App) removeAutomation(info *AppResourceInfo, gitProvider gitproviders.GitProvider, params PurgeParams) error {
+	repoUrl := info.automationRepoUrl()
+
+	dirs := []string{info.appYamlDir()}
+	for _, target := range info.targetInfos() {
+		dirs = append(dirs, target.appAutomationDir())
+	}
+
+	if params.DryRun {
+		a.logger.Actionf("Opening a pull request removing %s from %s", strings.Join(dirs, ", "), repoUrl)
+		return nil
+	}
+
+	repoDir, err := ioutil.TempDir("", "automation-repo-")
+	if err != nil {
+		return fmt.Errorf("failed creating temp. directory to clone repo: %w", err)
+	}
+	defer os.RemoveAll(repoDir)
+
+	if _, err := a.git.CloneWithOptions(context.Background(), repoDir, NormalizeRepoUrl(repoUrl, params.GitAuth), info.Spec.Branch, git.CloneOptions{Depth: 1}); err != nil {
+		return fmt.Errorf("failed cloning repo: %s: %w", repoUrl, err)
+	}
+
+	files := []gitprovider.CommitFile{}
+
+	for _, dir := range dirs {
+		err := filepath.WalkDir(filepath.Join(repoDir, dir), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+
+				return err
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			relPath, err := filepath.Rel(repoDir, path)
+			if err != nil {
+				return err
+			}
+
+			// A file without content is deleted by the commit
+			files = append(files, gitprovider.CommitFile{Path: &relPath})
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed listing the automation files in %s: %w", dir, err)
+		}
+	}
+
+	if len(files) == 0 {
+		a.logger.Successf("The automation of %s is not in %s", info.Name, repoUrl)
+		return nil
+	}
+
+	return a.createPullRequest(gitProvider, repoUrl, info.Spec.Branch, fmt.Sprintf("wego-remove-%s", info.Name), files, params.CommitMessage,
+		fmt.Sprintf("wego remove %s", info.Name), fmt.Sprintf("Removed the automation of %s", info.Name))
+}
+
+// deleteDeployKeys deletes the deploy keys a cluster uses for a repository
+func (a *App) deleteDeployKeys(repoUrl string, clusterName string, gitProvider gitproviders.GitProvider, dryRun bool) error {
+	repoUrl = sanitizeRepoUrl(repoUrl)
+
+	owner, err := getOwnerFromUrl(repoUrl)
+	if err != nil {
+		return err
+	}
+
+	repoName := urlToRepoName(repoUrl)
+
+	keys, err := gitProvider.ListDeployKeys(owner, repoName, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed listing deploy keys: %w", err)
+	}
+
+	for _, key := range keys {
+		a.logger.Actionf("Deleting deploy key %s of repo %s", key, repoUrl)
+
+		if dryRun {
+			continue
+		}
+
+		if err := gitProvider.DeleteDeployKey(owner, repoName, key); err != nil {
+			return fmt.Errorf("failed deleting deploy key %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteWriteDeployKey deletes the write-enabled deploy key a cluster pushes the image updates of the apps of a
+// repository with. Repositories without image updates have none, which is not an error.
+func (a *App) deleteWriteDeployKey(repoUrl string, clusterName string, gitProvider gitproviders.GitProvider, dryRun bool) error {
+	repoUrl = sanitizeRepoUrl(repoUrl)
+
+	owner, err := getOwnerFromUrl(repoUrl)
+	if err != nil {
+		return err
+	}
+
+	keyName := gitproviders.WriteDeployKeyName(clusterName)
+
+	a.logger.Actionf("Deleting deploy key %s of repo %s", keyName, repoUrl)
+
+	if dryRun {
+		return nil
+	}
+
+	if err := gitProvider.DeleteDeployKey(owner, urlToRepoName(repoUrl), keyName); err != nil {
+		return fmt.Errorf("failed deleting deploy key %s: %w", keyName, err)
+	}
+
+	return nil
+}
+
+// appGitRepoUrls returns the repositories the apps with a git source sync from, each once
+func appGitRepoUrls(apps []wego.Application) []string {
+	repoUrls := []string{}
+	seen := map[string]bool{}
+
+	for _, app := range apps {
+		if app.Spec.SourceType == wego.SourceTypeGit && !seen[app.Spec.URL] {
+			seen[app.Spec.URL] = true
+			repoUrls = append(repoUrls, app.Spec.URL)
+		}
+	}
+
+	return repoUrls
+}
@@ -0,0 +1,322 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	extensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var purgeParams PurgeParams
+
+var _ = Describe("Purge", func() {
+	var apps []wego.Application
+
+	deleted := func() []string {
+		manifests := []string{}
+		for i := 0; i < kubeClient.DeleteCallCount(); i++ {
+			manifest, _ := kubeClient.DeleteArgsForCall(i)
+			manifests = append(manifests, string(manifest))
+		}
+
+		return manifests
+	}
+
+	var _ = BeforeEach(func() {
+		purgeParams = PurgeParams{
+			Namespace: "wego-system",
+		}
+
+		apps = []wego.Application{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "wego-system"},
+				Spec: wego.ApplicationSpec{
+					URL:            "ssh://git@github.com/foo/bar.git",
+					ConfigURL:      "ssh://git@github.com/foo/config.git",
+					Branch:         "main",
+					SourceType:     wego.SourceTypeGit,
+					DeploymentType: wego.DeploymentTypeKustomize,
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "other-app", Namespace: "wego-system"},
+				Spec: wego.ApplicationSpec{
+					URL:            "ssh://git@github.com/foo/other.git",
+					ConfigURL:      "NONE",
+					Branch:         "main",
+					SourceType:     wego.SourceTypeGit,
+					DeploymentType: wego.DeploymentTypeHelm,
+				},
+			},
+		}
+
+		kubeClient.GetApplicationsStub = func(ctx context.Context, namespace string) ([]wego.Application, error) {
+			return apps, nil
+		}
+
+		gitProviders.ListDeployKeysStub = func(owner, repoName, clusterName string) ([]string, error) {
+			return []string{"weave-gitops-" + clusterName + "-deploy-key"}, nil
+		}
+	})
+
+	It("deletes the cluster resources of every app, automation first", func() {
+		err := appSrv.Purge(purgeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		manifests := deleted()
+		Expect(manifests).To(HaveLen(14))
+
+		Expect(manifests[0]).To(Equal("apiVersion: source.toolkit.fluxcd.io/v1beta1\nkind: GitRepository\nmetadata:\n  name: config\n  namespace: wego-system\n"))
+		Expect(manifests[2]).To(ContainSubstring("kind: Kustomization\nmetadata:\n  name: test-cluster-my-app\n"))
+		Expect(manifests[3]).To(ContainSubstring("kind: Kustomization\nmetadata:\n  name: my-app-apps-dir\n"))
+		Expect(manifests[4]).To(ContainSubstring("kind: Secret\nmetadata:\n  name: weave-gitops-test-cluster-bar-write\n"))
+		Expect(manifests[6]).To(ContainSubstring("apiVersion: wego.weave.works/v1alpha1\nkind: Application\nmetadata:\n  name: my-app\n"))
+		Expect(manifests[8]).To(ContainSubstring("kind: GitRepository\nmetadata:\n  name: my-app\n"))
+
+		Expect(manifests[10]).To(ContainSubstring("kind: Secret\nmetadata:\n  name: weave-gitops-test-cluster-other\n"))
+		Expect(manifests[12]).To(ContainSubstring("apiVersion: helm.toolkit.fluxcd.io/v2beta1\nkind: HelmRelease\nmetadata:\n  name: other-app\n"))
+	})
+
+	It("deletes the flux objects in the flux namespace of the app", func() {
+		apps[1].Spec.FluxNamespace = "flux-system"
+
+		err := appSrv.Purge(purgeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		manifests := deleted()
+		Expect(manifests[10]).To(ContainSubstring("kind: Secret\nmetadata:\n  name: weave-gitops-test-cluster-other\n  namespace: flux-system\n"))
+		Expect(manifests[11]).To(ContainSubstring("kind: Application\nmetadata:\n  name: other-app\n  namespace: wego-system\n"))
+		Expect(manifests[12]).To(ContainSubstring("kind: HelmRelease\nmetadata:\n  name: other-app\n  namespace: flux-system\n"))
+	})
+
+	It("deletes the notifications and image updates of the apps", func() {
+		kubeClient.GetResourceStub = func(ctx context.Context, name types.NamespacedName, r kube.Resource) error {
+			r.(*extensionsv1.CustomResourceDefinition).Name = name.Name
+			return nil
+		}
+
+		object := func(name string, spec map[string]interface{}) unstructured.Unstructured {
+			return unstructured.Unstructured{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": name}, "spec": spec}}
+		}
+
+		kubeClient.ListResourcesStub = func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+			switch gvk.Kind {
+			case "Provider", "Alert":
+				return []unstructured.Unstructured{object("my-app-slack", nil), object("my-app-commit-status", nil), object("user-alerts", nil)}, nil
+			case "ImagePolicy":
+				return []unstructured.Unstructured{
+					object("my-app-podinfo", map[string]interface{}{"imageRepositoryRef": map[string]interface{}{"name": "my-app-podinfo"}}),
+					object("my-app-user", map[string]interface{}{"imageRepositoryRef": map[string]interface{}{"name": "user"}}),
+				}, nil
+			case "ImageUpdateAutomation":
+				return []unstructured.Unstructured{object("my-app", nil)}, nil
+			}
+
+			return nil, nil
+		}
+
+		apps = apps[:1]
+
+		err := appSrv.Purge(purgeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.ListResourcesCallCount()).To(Equal(5))
+		_, gvk, namespace, _ := kubeClient.ListResourcesArgsForCall(0)
+		Expect(gvk.Kind).To(Equal("Provider"))
+		Expect(namespace).To(Equal("wego-system"))
+
+		manifests := deleted()
+		Expect(manifests[:8]).To(Equal([]string{
+			"apiVersion: image.toolkit.fluxcd.io/v1alpha2\nkind: ImageUpdateAutomation\nmetadata:\n  name: my-app\n  namespace: wego-system\n",
+			"apiVersion: source.toolkit.fluxcd.io/v1beta1\nkind: GitRepository\nmetadata:\n  name: my-app-image-update\n  namespace: wego-system\n",
+			"apiVersion: image.toolkit.fluxcd.io/v1alpha2\nkind: ImagePolicy\nmetadata:\n  name: my-app-podinfo\n  namespace: wego-system\n",
+			"apiVersion: image.toolkit.fluxcd.io/v1alpha2\nkind: ImageRepository\nmetadata:\n  name: my-app-podinfo\n  namespace: wego-system\n",
+			"apiVersion: notification.toolkit.fluxcd.io/v1beta1\nkind: Alert\nmetadata:\n  name: my-app-commit-status\n  namespace: wego-system\n",
+			"apiVersion: notification.toolkit.fluxcd.io/v1beta1\nkind: Alert\nmetadata:\n  name: my-app-slack\n  namespace: wego-system\n",
+			"apiVersion: notification.toolkit.fluxcd.io/v1beta1\nkind: Provider\nmetadata:\n  name: my-app-commit-status\n  namespace: wego-system\n",
+			"apiVersion: notification.toolkit.fluxcd.io/v1beta1\nkind: Provider\nmetadata:\n  name: my-app-slack\n  namespace: wego-system\n",
+		}))
+		Expect(manifests[8]).To(ContainSubstring("kind: GitRepository\nmetadata:\n  name: config\n"))
+	})
+
+	It("skips the notifications and image updates whose CRDs are not installed", func() {
+		err := appSrv.Purge(purgeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.ListResourcesCallCount()).To(Equal(0))
+	})
+
+	It("ignores resources already deleted", func() {
+		kubeClient.DeleteReturns([]byte(`Error from server (NotFound): secrets "weave-gitops-test-cluster-config" not found`), errors.New("exit status 1"))
+
+		err := appSrv.Purge(purgeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("fails when a resource can't be deleted", func() {
+		kubeClient.DeleteReturns([]byte("forbidden"), errors.New("exit status 1"))
+
+		err := appSrv.Purge(purgeParams)
+		Expect(err).To(MatchError("could not remove app my-app: could not delete GitRepository config: forbidden: exit status 1"))
+	})
+
+	It("deletes the deploy keys of every repository once", func() {
+		err := appSrv.Purge(purgeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(5))
+
+		repos := []string{}
+		for i := 0; i < 3; i++ {
+			owner, repoName, keyName := gitProviders.DeleteDeployKeyArgsForCall(i)
+			Expect(owner).To(Equal("foo"))
+			Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key"))
+			repos = append(repos, repoName)
+		}
+
+		Expect(repos).To(Equal([]string{"bar", "config", "other"}))
+
+		// The write-enabled keys of image updates, of the app repositories only
+		for i, repo := range []string{"bar", "other"} {
+			_, repoName, keyName := gitProviders.DeleteDeployKeyArgsForCall(3 + i)
+			Expect(repoName).To(Equal(repo))
+			Expect(keyName).To(Equal("weave-gitops-test-cluster-write-key"))
+		}
+	})
+
+	It("only deletes the deploy keys of the current cluster", func() {
+		repoKeys := []string{
+			"weave-gitops-test-cluster-deploy-key",
+			"weave-gitops-test-cluster-deploy-key-2",
+			"weave-gitops-staging-deploy-key",
+			"weave-gitops-test-cluster-eu-deploy-key",
+			"my-deploy-key",
+		}
+
+		gitProviders.ListDeployKeysStub = func(owner, repoName, clusterName string) ([]string, error) {
+			keys := []string{}
+			for _, key := range repoKeys {
+				if gitproviders.IsDeployKeyName(key, clusterName) {
+					keys = append(keys, key)
+				}
+			}
+
+			return keys, nil
+		}
+
+		err := appSrv.Purge(purgeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(gitProviders.ListDeployKeysCallCount()).To(Equal(3))
+		for i := 0; i < 3; i++ {
+			_, _, clusterName := gitProviders.ListDeployKeysArgsForCall(i)
+			Expect(clusterName).To(Equal("test-cluster"))
+		}
+
+		keys := map[string]bool{}
+		for i := 0; i < gitProviders.DeleteDeployKeyCallCount(); i++ {
+			_, _, keyName := gitProviders.DeleteDeployKeyArgsForCall(i)
+			keys[keyName] = true
+		}
+
+		Expect(keys).To(Equal(map[string]bool{
+			"weave-gitops-test-cluster-deploy-key":   true,
+			"weave-gitops-test-cluster-deploy-key-2": true,
+			"weave-gitops-test-cluster-write-key":    true,
+		}))
+	})
+
+	It("deletes the legacy key of installs predating per-cluster keys", func() {
+		gitProviders.ListDeployKeysStub = func(owner, repoName, clusterName string) ([]string, error) {
+			if gitproviders.IsDeployKeyName(gitproviders.LegacyDeployKeyName, clusterName) {
+				return []string{gitproviders.LegacyDeployKeyName}, nil
+			}
+
+			return []string{}, nil
+		}
+
+		err := appSrv.Purge(purgeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		for i := 0; i < 3; i++ {
+			_, _, keyName := gitProviders.DeleteDeployKeyArgsForCall(i)
+			Expect(keyName).To(Equal("weave-gitops-deploy-key"))
+		}
+	})
+
+	It("opens a pull request removing the automation of apps stored in git", func() {
+		purgeParams.RemoveAutomation = true
+		purgeParams.CommitMessage = "wego gitops uninstall --purge in wego-system"
+		purgeParams.GitAuth = GitAuthHTTPS
+
+		gitClient.CloneWithOptionsStub = func(ctx context.Context, path, url, branch string, opts git.CloneOptions) (bool, error) {
+			for _, file := range []string{"apps/my-app/app.yaml", "targets/test-cluster/my-app/my-app-gitops-runtime.yaml", "targets/test-cluster/other/other.yaml"} {
+				Expect(os.MkdirAll(filepath.Dir(filepath.Join(path, file)), 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(path, file), []byte("---"), 0644)).To(Succeed())
+			}
+
+			return true, nil
+		}
+
+		gitProviders.GetAccountTypeReturns(gitproviders.AccountTypeUser, nil)
+		gitProviders.CreatePullRequestToUserRepoReturns(nil, errors.New("stop after the pull request"))
+
+		err := appSrv.Purge(purgeParams)
+		Expect(err).To(MatchError("could not remove the automation of app my-app: unable to create pull request: stop after the pull request"))
+
+		Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(1))
+		_, _, url, branch, _ := gitClient.CloneWithOptionsArgsForCall(0)
+		Expect(url).To(Equal("https://github.com/foo/config.git"))
+		Expect(branch).To(Equal("main"))
+
+		Expect(gitProviders.CreatePullRequestToUserRepoCallCount()).To(Equal(1))
+		_, targetBranch, newBranch, files, commitMessage, title, _ := gitProviders.CreatePullRequestToUserRepoArgsForCall(0)
+		Expect(targetBranch).To(Equal("main"))
+		Expect(newBranch).To(Equal("wego-remove-my-app"))
+		Expect(title).To(Equal("wego remove my-app"))
+		Expect(commitMessage).To(Equal("wego gitops uninstall --purge in wego-system"))
+
+		paths := []string{}
+		for _, file := range files {
+			Expect(file.Content).To(BeNil())
+			paths = append(paths, *file.Path)
+		}
+
+		Expect(paths).To(Equal([]string{"apps/my-app/app.yaml", "targets/test-cluster/my-app/my-app-gitops-runtime.yaml"}))
+	})
+
+	It("only reports what it would remove on a dry run", func() {
+		purgeParams.RemoveAutomation = true
+		purgeParams.DryRun = true
+
+		err := appSrv.Purge(purgeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.DeleteCallCount()).To(Equal(0))
+		Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(0))
+		Expect(gitProviders.CreatePullRequestToUserRepoCallCount()).To(Equal(0))
+		Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(0))
+	})
+
+	It("does nothing without apps", func() {
+		apps = nil
+
+		err := appSrv.Purge(purgeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.DeleteCallCount()).To(Equal(0))
+	})
+})
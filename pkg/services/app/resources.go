@@ -0,0 +1,236 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// The kustomize controller labels the objects it applies with the name and namespace of their kustomization
+	kustomizeNameLabel      = "kustomize.toolkit.fluxcd.io/name"
+	kustomizeNamespaceLabel = "kustomize.toolkit.fluxcd.io/namespace"
+
+	// helmReleaseKey is the key of the helm storage secrets holding the gzipped release
+	helmReleaseKey = "release"
+)
+
+// Health of an object, the kstatus status or NotFound for objects missing from the cluster
+const (
+	HealthCurrent     = "Current"
+	HealthInProgress  = "InProgress"
+	HealthFailed      = "Failed"
+	HealthTerminating = "Terminating"
+	HealthNotFound    = "NotFound"
+	HealthUnknown     = "Unknown"
+)
+
+type ResourcesParams struct {
+	Name      string
+	Namespace string
+}
+
+// AppResource is a Kubernetes object deployed by an app
+type AppResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Health    string
+	// Message explains why the object is not current
+	Message string
+}
+
+// Resources lists the objects deployed by an app, with their health
+func (a *App) Resources(params ResourcesParams) ([]AppResource, error) {
+	ctx := context.Background()
+
+	app, err := a.kube.GetApplication(ctx, types.NamespacedName{Name: params.Name, Namespace: params.Namespace})
+	if err != nil {
+		return nil, fmt.Errorf("could not get application: %w", err)
+	}
+
+	return GetAppResources(ctx, a.kube, *app)
+}
+
+// GetAppResources lists the objects in the inventory of an app's kustomization, or in the manifest of its helm release
+func GetAppResources(ctx context.Context, kubeClient kube.Kube, app wego.Application) ([]AppResource, error) {
+	var (
+		objects []*unstructured.Unstructured
+		err     error
+	)
+
+	if app.Spec.DeploymentType == wego.DeploymentTypeHelm {
+		objects, err = helmReleaseObjects(ctx, kubeClient, app)
+	} else {
+		objects, err = kustomizationObjects(ctx, kubeClient, app)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []AppResource{}
+
+	for _, object := range objects {
+		health, message := objectHealth(object)
+
+		resources = append(resources, AppResource{
+			Kind:      object.GetKind(),
+			Namespace: object.GetNamespace(),
+			Name:      object.GetName(),
+			Health:    health,
+			Message:   message,
+		})
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		return fmt.Sprintf("%s/%s/%s", resources[i].Kind, resources[i].Namespace, resources[i].Name) <
+			fmt.Sprintf("%s/%s/%s", resources[j].Kind, resources[j].Namespace, resources[j].Name)
+	})
+
+	return resources, nil
+}
+
+// kustomizationObjects lists the objects of the kinds in the kustomization snapshot that carry its labels
+func kustomizationObjects(ctx context.Context, kubeClient kube.Kube, app wego.Application) ([]*unstructured.Unstructured, error) {
+	kustomization := &kustomizev1.Kustomization{}
+	if err := kubeClient.GetResource(ctx, types.NamespacedName{Name: app.Name, Namespace: app.GetFluxNamespace()}, kustomization); err != nil {
+		return nil, fmt.Errorf("could not get kustomization for app %s: %w", app.Name, err)
+	}
+
+	objects := []*unstructured.Unstructured{}
+
+	snapshot := kustomization.Status.Snapshot
+	if snapshot == nil {
+		return objects, nil
+	}
+
+	labels := map[string]string{
+		kustomizeNameLabel:      kustomization.Name,
+		kustomizeNamespaceLabel: kustomization.Namespace,
+	}
+
+	kinds := map[string][]schema.GroupVersionKind{"": snapshot.NonNamespacedKinds()}
+	for namespace, namespacedKinds := range snapshot.NamespacedKinds() {
+		kinds[namespace] = namespacedKinds
+	}
+
+	for namespace, namespaceKinds := range kinds {
+		for _, gvk := range namespaceKinds {
+			items, err := kubeClient.ListResources(ctx, gvk, namespace, labels)
+			if err != nil {
+				return nil, fmt.Errorf("could not list the objects of app %s: %w", app.Name, err)
+			}
+
+			for i := range items {
+				objects = append(objects, &items[i])
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+// helmReleaseObjects reads the objects from the manifest of the last release of an app's helm release
+func helmReleaseObjects(ctx context.Context, kubeClient kube.Kube, app wego.Application) ([]*unstructured.Unstructured, error) {
+	helmRelease := &helmv2.HelmRelease{}
+	if err := kubeClient.GetResource(ctx, types.NamespacedName{Name: app.Name, Namespace: app.GetFluxNamespace()}, helmRelease); err != nil {
+		return nil, fmt.Errorf("could not get helm release for app %s: %w", app.Name, err)
+	}
+
+	objects := []*unstructured.Unstructured{}
+
+	// The release was not installed yet
+	if helmRelease.Status.LastReleaseRevision == 0 {
+		return objects, nil
+	}
+
+	secretName := fmt.Sprintf("sh.helm.release.v1.%s.v%d", helmRelease.GetReleaseName(), helmRelease.Status.LastReleaseRevision)
+
+	secret := &corev1.Secret{}
+	if err := kubeClient.GetResource(ctx, types.NamespacedName{Name: secretName, Namespace: helmRelease.GetStorageNamespace()}, secret); err != nil {
+		return nil, fmt.Errorf("could not get helm release %s: %w", secretName, err)
+	}
+
+	if _, ok := secret.Data[helmReleaseKey]; !ok {
+		return nil, fmt.Errorf("helm release %s not found", secretName)
+	}
+
+	manifest, err := decodeHelmReleaseManifest(secret.Data[helmReleaseKey])
+	if err != nil {
+		return nil, fmt.Errorf("could not decode helm release %s: %w", secretName, err)
+	}
+
+	rendered, err := decodeObjects([][]byte{[]byte(manifest)})
+	if err != nil {
+		return nil, fmt.Errorf("could not decode the manifest of helm release %s: %w", secretName, err)
+	}
+
+	for _, object := range rendered {
+		namespace := object.GetNamespace()
+		if namespace == "" {
+			namespace = helmRelease.GetReleaseNamespace()
+		}
+
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(object.GroupVersionKind())
+
+		if err := kubeClient.GetResource(ctx, types.NamespacedName{Name: object.GetName(), Namespace: namespace}, live); err != nil {
+			return nil, fmt.Errorf("could not get %s %s: %w", object.GetKind(), object.GetName(), err)
+		}
+
+		// Objects that were not found are reported from the manifest, without a UID
+		if live.GetUID() == "" {
+			object.SetNamespace(namespace)
+			live = object
+		}
+
+		objects = append(objects, live)
+	}
+
+	return objects, nil
+}
+
+// decodeHelmReleaseManifest decodes the manifest of a helm release, stored base64 encoded and gzipped
+func decodeHelmReleaseManifest(data []byte) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return "", err
+	}
+
+	if bytes.HasPrefix(decoded, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+
+		if decoded, err = ioutil.ReadAll(reader); err != nil {
+			return "", err
+		}
+	}
+
+	release := struct {
+		Manifest string `json:"manifest"`
+	}{}
+
+	if err := json.Unmarshal(decoded, &release); err != nil {
+		return "", err
+	}
+
+	return release.Manifest, nil
+}
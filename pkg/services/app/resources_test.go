@@ -0,0 +1,191 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
+	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+func unstructuredObject(manifest string) unstructured.Unstructured {
+	data, err := yaml.YAMLToJSON([]byte(manifest))
+	Expect(err).ShouldNot(HaveOccurred())
+
+	// Decode numbers as int64, like the objects returned by the API server
+	object := unstructured.Unstructured{}
+	Expect(object.UnmarshalJSON(data)).To(Succeed())
+
+	return object
+}
+
+func encodeHelmRelease(manifest string) []byte {
+	release, err := json.Marshal(map[string]string{"manifest": manifest})
+	Expect(err).ShouldNot(HaveOccurred())
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err = writer.Write(release)
+	Expect(err).ShouldNot(HaveOccurred())
+	Expect(writer.Close()).To(Succeed())
+
+	return []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+var _ = Describe("Resources", func() {
+	var (
+		application *wego.Application
+		params      ResourcesParams
+	)
+
+	var _ = BeforeEach(func() {
+		application = &wego.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "wego-system"},
+			Spec:       wego.ApplicationSpec{DeploymentType: wego.DeploymentTypeKustomize},
+		}
+
+		params = ResourcesParams{Name: "podinfo", Namespace: "wego-system"}
+
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return application, nil
+		}
+	})
+
+	It("lists the objects in the kustomization snapshot", func() {
+		kubeClient.GetResourceStub = func(ctx context.Context, name types.NamespacedName, r kube.Resource) error {
+			kustomization, ok := r.(*kustomizev1.Kustomization)
+			Expect(ok).To(BeTrue())
+
+			kustomization.Name = name.Name
+			kustomization.Namespace = name.Namespace
+			kustomization.Status.Snapshot = &kustomizev1.Snapshot{
+				Entries: []kustomizev1.SnapshotEntry{
+					{Namespace: "", Kinds: map[string]string{"/v1, Kind=Namespace": "Namespace"}},
+					{Namespace: "apps", Kinds: map[string]string{"apps/v1, Kind=Deployment": "Deployment"}},
+				},
+			}
+
+			return nil
+		}
+
+		kubeClient.ListResourcesStub = func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+			Expect(labels).To(Equal(map[string]string{
+				"kustomize.toolkit.fluxcd.io/name":      "podinfo",
+				"kustomize.toolkit.fluxcd.io/namespace": "wego-system",
+			}))
+
+			switch gvk.Kind {
+			case "Namespace":
+				Expect(namespace).To(BeEmpty())
+				return []unstructured.Unstructured{unstructuredObject("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: apps\n  uid: a\n")}, nil
+			case "Deployment":
+				Expect(namespace).To(Equal("apps"))
+				return []unstructured.Unstructured{unstructuredObject(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+  namespace: apps
+  uid: b
+spec:
+  replicas: 2
+status:
+  replicas: 2
+  updatedReplicas: 2
+  readyReplicas: 1
+  availableReplicas: 1
+`)}, nil
+			}
+
+			return nil, nil
+		}
+
+		resources, err := appSrv.Resources(params)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(resources).To(Equal([]AppResource{
+			{Kind: "Deployment", Namespace: "apps", Name: "podinfo", Health: HealthInProgress, Message: "Available: 1/2"},
+			{Kind: "Namespace", Name: "apps", Health: HealthCurrent},
+		}))
+	})
+
+	It("lists nothing before the kustomization was applied", func() {
+		resources, err := appSrv.Resources(params)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(resources).To(BeEmpty())
+		Expect(kubeClient.ListResourcesCallCount()).To(Equal(0))
+	})
+
+	It("lists the objects in the manifest of the helm release", func() {
+		application.Spec.DeploymentType = wego.DeploymentTypeHelm
+
+		kubeClient.GetResourceStub = func(ctx context.Context, name types.NamespacedName, r kube.Resource) error {
+			switch obj := r.(type) {
+			case *helmv2.HelmRelease:
+				obj.Name = name.Name
+				obj.Namespace = name.Namespace
+				obj.Spec.TargetNamespace = "apps"
+				obj.Status.LastReleaseRevision = 3
+			case *corev1.Secret:
+				Expect(name).To(Equal(types.NamespacedName{Name: "sh.helm.release.v1.apps-podinfo.v3", Namespace: "wego-system"}))
+				obj.Data = map[string][]byte{"release": encodeHelmRelease("---\napiVersion: v1\nkind: Service\nmetadata:\n  name: podinfo\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n")}
+			case *unstructured.Unstructured:
+				Expect(name.Namespace).To(Equal("apps"))
+
+				if obj.GetKind() == "Service" {
+					obj.SetName(name.Name)
+					obj.SetNamespace(name.Namespace)
+					obj.SetUID("c")
+				}
+			}
+
+			return nil
+		}
+
+		resources, err := appSrv.Resources(params)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(resources).To(Equal([]AppResource{
+			{Kind: "ConfigMap", Namespace: "apps", Name: "config", Health: HealthNotFound, Message: "not found in the cluster"},
+			{Kind: "Service", Namespace: "apps", Name: "podinfo", Health: HealthCurrent},
+		}))
+	})
+
+	table.DescribeTable("computes the health of objects",
+		func(manifest string, health string) {
+			object := unstructuredObject(manifest)
+			object.SetUID("uid")
+
+			result, _ := objectHealth(&object)
+			Expect(result).To(Equal(health))
+		},
+		table.Entry("ready deployment", "apiVersion: apps/v1\nkind: Deployment\nstatus:\n  replicas: 1\n  updatedReplicas: 1\n  readyReplicas: 1\n  availableReplicas: 1\n  conditions:\n  - type: Available\n    status: \"True\"\n", HealthCurrent),
+		table.Entry("rolling out deployment", "apiVersion: apps/v1\nkind: Deployment\nstatus:\n  replicas: 2\n  updatedReplicas: 1\n  readyReplicas: 1\n  availableReplicas: 1\n", HealthInProgress),
+		table.Entry("stuck deployment", "apiVersion: apps/v1\nkind: Deployment\nstatus:\n  conditions:\n  - type: Progressing\n    status: \"False\"\n    reason: ProgressDeadlineExceeded\n", HealthFailed),
+		table.Entry("unobserved generation", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  generation: 2\nstatus:\n  observedGeneration: 1\n", HealthInProgress),
+		table.Entry("stalled object", "apiVersion: example.com/v1\nkind: Widget\nstatus:\n  conditions:\n  - type: Stalled\n    status: \"True\"\n", HealthFailed),
+		table.Entry("flux object that is not ready", "apiVersion: source.toolkit.fluxcd.io/v1beta1\nkind: GitRepository\nstatus:\n  conditions:\n  - type: Ready\n    status: \"False\"\n", HealthInProgress),
+		table.Entry("running pod", "apiVersion: v1\nkind: Pod\nstatus:\n  phase: Running\n  conditions:\n  - type: Ready\n    status: \"True\"\n", HealthCurrent),
+		table.Entry("pending pod", "apiVersion: v1\nkind: Pod\nstatus:\n  phase: Pending\n", HealthInProgress),
+		table.Entry("pending load balancer", "apiVersion: v1\nkind: Service\nspec:\n  type: LoadBalancer\n", HealthInProgress),
+		table.Entry("unbound claim", "apiVersion: v1\nkind: PersistentVolumeClaim\nstatus:\n  phase: Pending\n", HealthInProgress),
+		table.Entry("failed job", "apiVersion: batch/v1\nkind: Job\nstatus:\n  conditions:\n  - type: Failed\n    status: \"True\"\n", HealthFailed),
+		table.Entry("config map", "apiVersion: v1\nkind: ConfigMap\n", HealthCurrent),
+		table.Entry("reconciling object", "apiVersion: example.com/v1\nkind: Widget\nstatus:\n  conditions:\n  - type: Reconciling\n    status: \"True\"\n", HealthInProgress),
+		table.Entry("reconciling flux object", "apiVersion: kustomize.toolkit.fluxcd.io/v1beta1\nkind: Kustomization\nstatus:\n  conditions:\n  - type: Ready\n    status: \"Unknown\"\n", HealthInProgress),
+		table.Entry("crash looping pod", "apiVersion: v1\nkind: Pod\nstatus:\n  phase: Running\n  containerStatuses:\n  - name: app\n    state:\n      waiting:\n        reason: CrashLoopBackOff\n", HealthFailed),
+		table.Entry("terminating object", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  deletionTimestamp: \"2021-07-01T00:00:00Z\"\n", HealthTerminating),
+		table.Entry("status that can not be computed", "apiVersion: v1\nkind: Pod\nstatus:\n  phase: Exploded\n", HealthUnknown),
+	)
+})
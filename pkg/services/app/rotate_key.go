@@ -0,0 +1,339 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+type RotateKeyParams struct {
+	// Name is the app whose deploy keys are rotated
+	Name string
+	// Url is the repository whose deploy key is rotated, used instead of Name
+	Url              string
+	Namespace        string
+	GitProviderToken string
+	// GitAuth is the auth the encrypted secrets are pushed with, in the format of the automation repository urls
+	GitAuth           GitAuthType
+	CommitAuthorName  string
+	CommitAuthorEmail string
+	// SopsAgeRecipients, SopsPGPFingerprints and SealedSecretsCert re-encrypt the new secret of the apps that
+	// commit their secrets encrypted, see AddParams
+	SopsAgeRecipients   []string
+	SopsPGPFingerprints []string
+	SealedSecretsCert   string
+}
+
+// RotateKey replaces the deploy keys this cluster uses for an app, or for every app using a repository. The new key
+// is uploaded next to the old one, and the old one is only deleted once the sources have reconciled with the new key.
+// Apps committing their secrets encrypted get the new secret committed in place of the old one, which their
+// kustomizations would otherwise restore.
+func (a *App) RotateKey(params RotateKeyParams) error {
+	ctx := context.Background()
+
+	clusterName, err := a.kube.GetClusterName(ctx)
+	if err != nil {
+		return err
+	}
+
+	repoUrls := []string{params.Url}
+
+	if params.Name != "" {
+		app, err := a.kube.GetApplication(ctx, types.NamespacedName{Name: params.Name, Namespace: params.Namespace})
+		if err != nil {
+			return fmt.Errorf("could not get application: %w", err)
+		}
+
+		repoUrls = appRepoUrls(*app)
+		if len(repoUrls) == 0 {
+			return fmt.Errorf("app %s does not use any deploy keys", params.Name)
+		}
+	}
+
+	apps, err := a.kube.GetApplications(ctx, params.Namespace)
+	if err != nil {
+		return fmt.Errorf("could not get applications: %w", err)
+	}
+
+	gitProvider, err := a.gitProviderFactory(params.GitProviderToken)
+	if err != nil {
+		return err
+	}
+
+	for _, repoUrl := range repoUrls {
+		info := getAppResourceInfo(wego.Application{}, clusterName)
+		info.Namespace = params.Namespace
+
+		if info.Spec.FluxNamespace, err = repoFluxNamespace(apps, repoUrl); err != nil {
+			return err
+		}
+
+		if err := a.rotateRepoKey(info, repoUrl, apps, params, gitProvider); err != nil {
+			return fmt.Errorf("could not rotate deploy key for repo %s: %w", repoUrl, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *App) rotateRepoKey(info *AppResourceInfo, repoUrl string, apps []wego.Application, params RotateKeyParams, gitProvider gitproviders.GitProvider) error {
+	secretRefName := info.appSecretName(repoUrl)
+	sourceNames := repoSourceNames(apps, repoUrl)
+
+	secretApps, err := encryptedSecretApps(info.clusterName, repoUrl, apps, params)
+	if err != nil {
+		return err
+	}
+
+	repoUrl = sanitizeRepoUrl(repoUrl)
+
+	owner, err := getOwnerFromUrl(repoUrl)
+	if err != nil {
+		return err
+	}
+
+	repoName := urlToRepoName(repoUrl)
+
+	// Only the keys of this cluster are replaced, the other clusters syncing from the repository keep theirs
+	oldKeys, err := gitProvider.ListDeployKeys(owner, repoName, info.targetName)
+	if err != nil {
+		return fmt.Errorf("failed listing deploy keys: %w", err)
+	}
+
+	a.logger.Generatef("Generating deploy key for repo %s", repoUrl)
+
+	secret, err := a.flux.CreateSecretGit(secretRefName, repoUrl, info.GetFluxNamespace())
+	if err != nil {
+		return fmt.Errorf("could not create git secret: %w", err)
+	}
+
+	var secretData corev1.Secret
+	if err := yaml.Unmarshal(secret, &secretData); err != nil {
+		return fmt.Errorf("failed to unmarshal created secret: %w", err)
+	}
+
+	keyName := gitproviders.NextDeployKeyName(info.targetName, oldKeys)
+	if err := gitProvider.UploadDeployKey(owner, repoName, keyName, []byte(secretData.StringData["identity.pub"])); err != nil {
+		return fmt.Errorf("error uploading deploy key: %w", err)
+	}
+
+	if out, err := a.kube.Apply(secret, info.GetFluxNamespace()); err != nil {
+		return fmt.Errorf("could not apply manifest: %s: %w", string(out), err)
+	}
+
+	if err := a.commitRotatedSecret(secretApps, secretRefName, secret, params); err != nil {
+		return fmt.Errorf("could not commit the new secret, the previous keys were kept: %w", err)
+	}
+
+	for _, sourceName := range sourceNames {
+		a.logger.Waitingf("Waiting for source %s to reconcile with the new deploy key", sourceName)
+
+		if out, err := a.flux.ReconcileSource(string(SourceTypeGit), sourceName, info.GetFluxNamespace()); err != nil {
+			return fmt.Errorf("source %s did not reconcile with deploy key %s, the previous keys were kept: %s: %w", sourceName, keyName, string(out), err)
+		}
+	}
+
+	for _, oldKey := range oldKeys {
+		a.logger.Actionf("Deleting deploy key %s", oldKey)
+
+		if err := gitProvider.DeleteDeployKey(owner, repoName, oldKey); err != nil {
+			return fmt.Errorf("failed deleting deploy key %s: %w", oldKey, err)
+		}
+	}
+
+	a.logger.Successf("Rotated deploy key for repo %s", repoUrl)
+
+	return nil
+}
+
+// encryptedSecretApps returns the apps of this cluster that commit the secret of a repository encrypted, after
+// checking that the new secret can be encrypted for them
+func encryptedSecretApps(clusterName string, repoUrl string, apps []wego.Application, params RotateKeyParams) ([]*AppResourceInfo, error) {
+	infos := []*AppResourceInfo{}
+
+	for _, app := range apps {
+		info := getAppResourceInfo(app, clusterName)
+		if app.Spec.SecretEncryption == "" || info.currentTarget() == nil || !usesRepo(app, repoUrl) {
+			continue
+		}
+
+		switch app.Spec.SecretEncryption {
+		case wego.SecretEncryptionSops:
+			if len(params.SopsAgeRecipients) == 0 && len(params.SopsPGPFingerprints) == 0 {
+				return nil, fmt.Errorf("app %s commits its secrets encrypted with sops, set --sops-age-recipients or --sops-pgp-fingerprints to encrypt the new secret", app.Name)
+			}
+		case wego.SecretEncryptionSealedSecrets:
+			if params.SealedSecretsCert == "" {
+				return nil, fmt.Errorf("app %s commits its secrets sealed, set --sealed-secrets-cert to seal the new secret", app.Name)
+			}
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// commitRotatedSecret commits the new secret of a repository, encrypted, in place of the old one next to the
+// automation of each app. It is pushed rather than proposed in a pull request, as the old key stops working
+// once the sources have reconciled.
+func (a *App) commitRotatedSecret(infos []*AppResourceInfo, secretName string, secret []byte, params RotateKeyParams) error {
+	repoUrls := []string{}
+	branches := map[string]string{}
+	files := map[string][]secretFile{}
+
+	for _, info := range infos {
+		a.logger.Generatef("Encrypting secret %s of app %s with %s", secretName, info.Name, info.Spec.SecretEncryption)
+
+		encrypted, err := a.encryptSecret(info.Spec.SecretEncryption, secret, params.SopsAgeRecipients, params.SopsPGPFingerprints, params.SealedSecretsCert)
+		if err != nil {
+			return err
+		}
+
+		repoUrl := info.automationRepoUrl()
+		if _, ok := files[repoUrl]; !ok {
+			repoUrls = append(repoUrls, repoUrl)
+			branches[repoUrl] = info.Spec.Branch
+		}
+
+		files[repoUrl] = append(files[repoUrl], secretFile{path: info.currentTarget().secretPath(secretName), manifest: encrypted})
+	}
+
+	for _, repoUrl := range repoUrls {
+		if err := a.pushSecretFiles(NormalizeRepoUrl(repoUrl, params.GitAuth), branches[repoUrl], secretName, files[repoUrl], params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *App) pushSecretFiles(repoUrl string, branch string, secretName string, files []secretFile, params RotateKeyParams) error {
+	repoDir, err := ioutil.TempDir("", "automation-repo-")
+	if err != nil {
+		return fmt.Errorf("failed creating temp. directory to clone repo: %w", err)
+	}
+	defer os.RemoveAll(repoDir)
+
+	if _, err := a.git.CloneWithOptions(context.Background(), repoDir, repoUrl, branch, git.CloneOptions{Depth: 1}); err != nil {
+		return fmt.Errorf("failed cloning repo: %s: %w", repoUrl, err)
+	}
+
+	if err := a.writeSecretFiles(files); err != nil {
+		return fmt.Errorf("failed writing secret %s: %w", secretName, err)
+	}
+
+	a.logger.Actionf("Committing and pushing secret %s to %s", secretName, repoUrl)
+
+	_, err = a.git.Commit(git.Commit{
+		Author:  CommitAuthor(params.CommitAuthorName, params.CommitAuthorEmail),
+		Message: fmt.Sprintf("Rotate secret %s", secretName),
+	})
+	if err != nil {
+		if err == git.ErrNoStagedFiles {
+			return nil
+		}
+
+		return fmt.Errorf("failed to commit secret %s: %w", secretName, err)
+	}
+
+	if err := a.git.Push(context.Background()); err != nil {
+		return fmt.Errorf("failed to push secret %s: %w", secretName, err)
+	}
+
+	return nil
+}
+
+// repoFluxNamespace returns the namespace of the flux objects of the apps synced from a repository, which share the
+// secret of the repository
+func repoFluxNamespace(apps []wego.Application, repoUrl string) (string, error) {
+	namespace := ""
+
+	for _, app := range apps {
+		if !usesRepo(app, repoUrl) {
+			continue
+		}
+
+		if namespace != "" && namespace != app.GetFluxNamespace() {
+			return "", fmt.Errorf("the apps using repo %s have their flux objects in different namespaces, %s and %s", repoUrl, namespace, app.GetFluxNamespace())
+		}
+
+		namespace = app.GetFluxNamespace()
+	}
+
+	return namespace, nil
+}
+
+// usesRepo reports whether an app is synced from a repository with a deploy key
+func usesRepo(app wego.Application, repoUrl string) bool {
+	for _, appRepoUrl := range appRepoUrls(app) {
+		if sanitizeRepoUrl(appRepoUrl) == sanitizeRepoUrl(repoUrl) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appRepoUrls returns the repositories an app is synced from with a deploy key: its git repository
+// and its external config repository. Repositories using token auth don't have a deploy key.
+func appRepoUrls(app wego.Application) []string {
+	urls := []string{}
+
+	if app.Spec.SourceType == wego.SourceTypeGit && !isHTTPSRepoUrl(app.Spec.URL) {
+		urls = append(urls, app.Spec.URL)
+	}
+
+	if isExternalConfigUrl(app) && !isHTTPSRepoUrl(app.Spec.ConfigURL) {
+		urls = append(urls, app.Spec.ConfigURL)
+	}
+
+	return urls
+}
+
+// repoSourceNames returns the names of the git sources that are synced from a repository with its deploy key
+func repoSourceNames(apps []wego.Application, repoUrl string) []string {
+	names := []string{}
+	seen := map[string]bool{}
+
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, app := range apps {
+		for _, appRepoUrl := range appRepoUrls(app) {
+			if sanitizeRepoUrl(appRepoUrl) != sanitizeRepoUrl(repoUrl) {
+				continue
+			}
+
+			if appRepoUrl == app.Spec.ConfigURL && isExternalConfigUrl(app) {
+				add(generateResourceName(app.Spec.ConfigURL))
+			} else {
+				add(app.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+func isExternalConfigUrl(app wego.Application) bool {
+	switch strings.ToUpper(app.Spec.ConfigURL) {
+	case string(ConfigTypeNone), string(ConfigTypeUserRepo):
+		return false
+	}
+
+	return app.Spec.ConfigURL != app.Spec.URL
+}
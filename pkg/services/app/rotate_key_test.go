@@ -0,0 +1,286 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var rotateKeyParams RotateKeyParams
+
+var _ = Describe("RotateKey", func() {
+	var apps []wego.Application
+
+	var _ = BeforeEach(func() {
+		rotateKeyParams = RotateKeyParams{
+			Name:      "my-app",
+			Namespace: "wego-system",
+		}
+
+		apps = []wego.Application{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: "wego-system"},
+				Spec: wego.ApplicationSpec{
+					URL:        "ssh://git@github.com/foo/bar.git",
+					ConfigURL:  "ssh://git@github.com/foo/config.git",
+					SourceType: wego.SourceTypeGit,
+				},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "other-app", Namespace: "wego-system"},
+				Spec: wego.ApplicationSpec{
+					URL:        "git@github.com:foo/other.git",
+					ConfigURL:  "ssh://git@github.com/foo/config.git",
+					SourceType: wego.SourceTypeGit,
+				},
+			},
+		}
+
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return &apps[0], nil
+		}
+
+		kubeClient.GetApplicationsStub = func(ctx context.Context, namespace string) ([]wego.Application, error) {
+			return apps, nil
+		}
+
+		gitProviders.ListDeployKeysStub = func(owner, repoName, clusterName string) ([]string, error) {
+			return []string{"weave-gitops-" + clusterName + "-deploy-key"}, nil
+		}
+
+		fluxClient.CreateSecretGitStub = func(name, url, namespace string) ([]byte, error) {
+			return []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: ` + name + `
+stringData:
+  identity.pub: new-public-key
+`), nil
+		}
+	})
+
+	It("rotates the keys of the app repo and the config repo", func() {
+		err := appSrv.RotateKey(rotateKeyParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(2))
+
+		name, url, namespace := fluxClient.CreateSecretGitArgsForCall(0)
+		Expect(name).To(Equal("weave-gitops-test-cluster-bar"))
+		Expect(url).To(Equal("ssh://git@github.com/foo/bar.git"))
+		Expect(namespace).To(Equal("wego-system"))
+
+		name, url, _ = fluxClient.CreateSecretGitArgsForCall(1)
+		Expect(name).To(Equal("weave-gitops-test-cluster-config"))
+		Expect(url).To(Equal("ssh://git@github.com/foo/config.git"))
+
+		Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(2))
+
+		owner, repoName, keyName, key := gitProviders.UploadDeployKeyArgsForCall(0)
+		Expect(owner).To(Equal("foo"))
+		Expect(repoName).To(Equal("bar"))
+		Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key-2"))
+		Expect(string(key)).To(Equal("new-public-key"))
+
+		Expect(kubeClient.ApplyCallCount()).To(Equal(2))
+
+		Expect(fluxClient.ReconcileSourceCallCount()).To(Equal(2))
+
+		sourceType, sourceName, _ := fluxClient.ReconcileSourceArgsForCall(0)
+		Expect(sourceType).To(Equal("git"))
+		Expect(sourceName).To(Equal("my-app"))
+
+		_, sourceName, _ = fluxClient.ReconcileSourceArgsForCall(1)
+		Expect(sourceName).To(Equal("config"))
+
+		Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(2))
+
+		owner, repoName, keyName = gitProviders.DeleteDeployKeyArgsForCall(0)
+		Expect(owner).To(Equal("foo"))
+		Expect(repoName).To(Equal("bar"))
+		Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key"))
+	})
+
+	It("only replaces the keys of this cluster", func() {
+		err := appSrv.RotateKey(rotateKeyParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(gitProviders.ListDeployKeysCallCount()).To(Equal(2))
+
+		for i := 0; i < gitProviders.ListDeployKeysCallCount(); i++ {
+			_, _, clusterName := gitProviders.ListDeployKeysArgsForCall(i)
+			Expect(clusterName).To(Equal("test-cluster"))
+		}
+	})
+
+	It("rotates the key of a repository for every app using it", func() {
+		rotateKeyParams.Name = ""
+		rotateKeyParams.Url = "https://github.com/foo/config"
+
+		err := appSrv.RotateKey(rotateKeyParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.GetApplicationCallCount()).To(Equal(0))
+		Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(1))
+
+		_, url, _ := fluxClient.CreateSecretGitArgsForCall(0)
+		Expect(url).To(Equal("ssh://git@github.com/foo/config.git"))
+
+		Expect(fluxClient.ReconcileSourceCallCount()).To(Equal(1))
+
+		_, sourceName, _ := fluxClient.ReconcileSourceArgsForCall(0)
+		Expect(sourceName).To(Equal("config"))
+
+		Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(1))
+	})
+
+	It("uses the next version after the latest existing key", func() {
+		gitProviders.ListDeployKeysReturns([]string{"weave-gitops-test-cluster-deploy-key-2", "weave-gitops-test-cluster-deploy-key-3"}, nil)
+
+		err := appSrv.RotateKey(rotateKeyParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, _, keyName, _ := gitProviders.UploadDeployKeyArgsForCall(0)
+		Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key-4"))
+
+		Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(4))
+	})
+
+	It("replaces the legacy key of an install predating per-cluster keys", func() {
+		repoKeys := []string{"weave-gitops-deploy-key", "my-deploy-key"}
+
+		gitProviders.ListDeployKeysStub = func(owner, repoName, clusterName string) ([]string, error) {
+			keys := []string{}
+			for _, key := range repoKeys {
+				if gitproviders.IsDeployKeyName(key, clusterName) {
+					keys = append(keys, key)
+				}
+			}
+
+			return keys, nil
+		}
+
+		err := appSrv.RotateKey(rotateKeyParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, _, keyName, _ := gitProviders.UploadDeployKeyArgsForCall(0)
+		Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key-2"))
+
+		Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(2))
+
+		_, repoName, keyName := gitProviders.DeleteDeployKeyArgsForCall(0)
+		Expect(repoName).To(Equal("bar"))
+		Expect(keyName).To(Equal("weave-gitops-deploy-key"))
+	})
+
+	It("keeps the old key when the source does not reconcile", func() {
+		fluxClient.ReconcileSourceStub = func(sourceType, name, namespace string) ([]byte, error) {
+			return []byte("authentication required"), errors.New("exit status 1")
+		}
+
+		err := appSrv.RotateKey(rotateKeyParams)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("the previous keys were kept"))
+
+		Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(1))
+		Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(0))
+	})
+
+	It("skips repositories that use token auth", func() {
+		apps[0].Spec.URL = "https://github.com/foo/bar.git"
+		apps[0].Spec.ConfigURL = "https://github.com/foo/config.git"
+
+		err := appSrv.RotateKey(rotateKeyParams)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(Equal("app my-app does not use any deploy keys"))
+
+		Expect(fluxClient.CreateSecretGitCallCount()).To(Equal(0))
+	})
+
+	Context("when the app commits its secrets encrypted", func() {
+		BeforeEach(func() {
+			apps[0].Spec.Branch = "main"
+			apps[0].Spec.SecretEncryption = wego.SecretEncryptionSops
+			rotateKeyParams.SopsAgeRecipients = []string{"age1abc"}
+
+			encryptor.EncryptSopsStub = func(manifest []byte, ageRecipients, pgpFingerprints []string) ([]byte, error) {
+				return append([]byte("sops "), manifest...), nil
+			}
+		})
+
+		It("commits the new secret in place of the old one", func() {
+			err := appSrv.RotateKey(rotateKeyParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(encryptor.EncryptSopsCallCount()).To(Equal(2))
+
+			_, ageRecipients, _ := encryptor.EncryptSopsArgsForCall(0)
+			Expect(ageRecipients).To(Equal([]string{"age1abc"}))
+
+			Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(2))
+
+			_, _, url, branch, _ := gitClient.CloneWithOptionsArgsForCall(0)
+			Expect(url).To(Equal("ssh://git@github.com/foo/config.git"))
+			Expect(branch).To(Equal("main"))
+
+			Expect(gitClient.WriteCallCount()).To(Equal(2))
+
+			path, content := gitClient.WriteArgsForCall(0)
+			Expect(path).To(Equal("targets/test-cluster/my-app/weave-gitops-test-cluster-bar.yaml"))
+			Expect(string(content)).To(HavePrefix("sops apiVersion: v1"))
+
+			path, _ = gitClient.WriteArgsForCall(1)
+			Expect(path).To(Equal("targets/test-cluster/my-app/weave-gitops-test-cluster-config.yaml"))
+
+			Expect(gitClient.CommitCallCount()).To(Equal(2))
+			Expect(gitClient.PushCallCount()).To(Equal(2))
+		})
+
+		It("clones the automation repository in the format of the git auth, on any provider", func() {
+			rotateKeyParams.GitAuth = GitAuthHTTPS
+			apps[0].Spec.ConfigURL = "ssh://git@gitlab.example.com/foo/config.git"
+
+			err := appSrv.RotateKey(rotateKeyParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, _, url, _, _ := gitClient.CloneWithOptionsArgsForCall(0)
+			Expect(url).To(Equal("https://gitlab.example.com/foo/config.git"))
+		})
+
+		It("keeps the old key when the new secret can not be pushed", func() {
+			gitClient.PushReturns(errors.New("permission denied"))
+
+			err := appSrv.RotateKey(rotateKeyParams)
+			Expect(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not commit the new secret, the previous keys were kept"))
+
+			Expect(fluxClient.ReconcileSourceCallCount()).To(Equal(0))
+			Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(0))
+		})
+
+		It("does not commit the secrets of apps this cluster does not sync", func() {
+			apps[0].Spec.Targets = []wego.ApplicationTarget{{Name: "staging"}}
+
+			err := appSrv.RotateKey(rotateKeyParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(encryptor.EncryptSopsCallCount()).To(Equal(0))
+			Expect(gitClient.WriteCallCount()).To(Equal(0))
+		})
+
+		It("fails before uploading a key without sops recipients", func() {
+			rotateKeyParams.SopsAgeRecipients = nil
+
+			err := appSrv.RotateKey(rotateKeyParams)
+			Expect(err).To(MatchError("could not rotate deploy key for repo ssh://git@github.com/foo/bar.git: app my-app commits its secrets encrypted with sops, set --sops-age-recipients or --sops-pgp-fingerprints to encrypt the new secret"))
+
+			Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const DefaultDecryptionSecret = "sops-keys"
+
+// targetSecret is a secret generated for one of the app's targets
+type targetSecret struct {
+	target   *AppResourceInfo
+	manifest []byte
+}
+
+// secretFile is a generated secret, encrypted so that it can be committed to the config repository
+type secretFile struct {
+	path     string
+	manifest []byte
+}
+
+// setSecretEncryption stores the secret encryption passed to `wego app add` in the application spec
+func setSecretEncryption(app *wego.Application, params AddParams) error {
+	if params.SecretEncryption == "" {
+		return nil
+	}
+
+	if strings.ToUpper(app.Spec.ConfigURL) == string(ConfigTypeNone) {
+		return fmt.Errorf("encrypted secrets are committed to the config repository and can not be used with --app-config-url=NONE")
+	}
+
+	switch wego.SecretEncryptionType(params.SecretEncryption) {
+	case wego.SecretEncryptionSops:
+		if len(params.SopsAgeRecipients) == 0 && len(params.SopsPGPFingerprints) == 0 {
+			return fmt.Errorf("--sops-age-recipients or --sops-pgp-fingerprints must be set to encrypt secrets with sops")
+		}
+
+		app.Spec.DecryptionSecret = params.DecryptionSecret
+		if app.Spec.DecryptionSecret == "" {
+			app.Spec.DecryptionSecret = DefaultDecryptionSecret
+		}
+	case wego.SecretEncryptionSealedSecrets:
+		if params.SealedSecretsCert == "" {
+			return fmt.Errorf("--sealed-secrets-cert must be set to encrypt secrets with sealed secrets")
+		}
+	default:
+		return fmt.Errorf("invalid secret encryption %q, expected sops or sealed-secrets", params.SecretEncryption)
+	}
+
+	app.Spec.SecretEncryption = wego.SecretEncryptionType(params.SecretEncryption)
+
+	return nil
+}
+
+// decryptionSecret returns the secret flux uses to decrypt the app's manifests, if they are encrypted with sops
+func (a *AppResourceInfo) decryptionSecret() string {
+	if a.Spec.SecretEncryption != wego.SecretEncryptionSops {
+		return ""
+	}
+
+	return a.Spec.DecryptionSecret
+}
+
+// encryptSecrets encrypts the secrets generated while adding the app, so they can be stored next to the automation
+// of their target, where its cluster syncs them from and restores them from when it is rebuilt
+func (a *App) encryptSecrets(info *AppResourceInfo, params AddParams, secrets []targetSecret) ([]secretFile, error) {
+	if info.Spec.SecretEncryption == "" {
+		return nil, nil
+	}
+
+	files := []secretFile{}
+
+	for _, targetSecret := range secrets {
+		secret := targetSecret.manifest
+
+		var secretData corev1.Secret
+		if err := yaml.Unmarshal(secret, &secretData); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal secret: %w", err)
+		}
+
+		a.logger.Generatef("Encrypting secret %s with %s", secretData.Name, info.Spec.SecretEncryption)
+
+		encrypted, err := a.encryptSecret(info.Spec.SecretEncryption, secret, params.SopsAgeRecipients, params.SopsPGPFingerprints, params.SealedSecretsCert)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, secretFile{
+			path:     targetSecret.target.secretPath(secretData.Name),
+			manifest: encrypted,
+		})
+	}
+
+	return files, nil
+}
+
+// encryptSecret encrypts a secret for the sops recipients or the sealed secrets certificate
+func (a *App) encryptSecret(encryption wego.SecretEncryptionType, secret []byte, ageRecipients []string, pgpFingerprints []string, sealedSecretsCert string) ([]byte, error) {
+	switch encryption {
+	case wego.SecretEncryptionSops:
+		return a.encryptor.EncryptSops(secret, ageRecipients, pgpFingerprints)
+	case wego.SecretEncryptionSealedSecrets:
+		return a.encryptor.Seal(secret, sealedSecretsCert)
+	}
+
+	return nil, fmt.Errorf("invalid secret encryption %q, expected sops or sealed-secrets", encryption)
+}
+
+// secretPath returns the file a secret of the target is committed to
+func (a *AppResourceInfo) secretPath(name string) string {
+	return filepath.Join(a.appAutomationDir(), fmt.Sprintf("%s.yaml", name))
+}
+
+// secretPaths returns the files the app's secrets are committed to when they are encrypted: the secrets each
+// target reads the app repository and verifies its commits with, and the secret the current cluster reads an
+// external config repository with
+func (a *AppResourceInfo) secretPaths() []string {
+	if a.Spec.SecretEncryption == "" {
+		return nil
+	}
+
+	paths := []string{}
+
+	for _, target := range a.targetInfos() {
+		if a.Spec.SourceType == wego.SourceTypeGit {
+			paths = append(paths, target.secretPath(target.appSecretName(a.Spec.URL)))
+		}
+
+		if a.Spec.VerificationSecret != "" {
+			paths = append(paths, target.secretPath(a.Spec.VerificationSecret))
+		}
+	}
+
+	if current := a.currentTarget(); current != nil && isExternalConfigUrl(a.Application) {
+		paths = append(paths, current.secretPath(current.appSecretName(a.Spec.ConfigURL)))
+	}
+
+	return paths
+}
+
+func (a *App) writeSecretFiles(secrets []secretFile) error {
+	for _, secret := range secrets {
+		if err := a.git.Write(secret.path, secret.manifest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	"github.com/go-git/go-git/v5/plumbing"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+var commitShaRegexp = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// commitSearchDepth is how many of the latest commits of a branch are fetched to find the commit an app is pinned to
+const commitSearchDepth = 1000
+
+// setSourceRef stores the tag, semver range or commit the app's git source tracks instead of its branch
+func setSourceRef(app *wego.Application, params AddParams) error {
+	refs := 0
+
+	for _, ref := range []string{params.Tag, params.SemVer, params.Commit} {
+		if ref != "" {
+			refs++
+		}
+	}
+
+	if refs == 0 {
+		return nil
+	}
+
+	if refs > 1 {
+		return fmt.Errorf("only one of --tag, --semver and --commit can be set")
+	}
+
+	if app.Spec.SourceType != wego.SourceTypeGit {
+		return fmt.Errorf("--tag, --semver and --commit can only be used with git sources")
+	}
+
+	// The automation committed to the app repository goes to the branch, and would not be synced from a tag or commit
+	if strings.ToUpper(app.Spec.ConfigURL) == string(ConfigTypeUserRepo) {
+		return fmt.Errorf("--tag, --semver and --commit require --app-config-url to be set to NONE or an external repository")
+	}
+
+	if params.SemVer != "" {
+		if _, err := semver.NewConstraint(params.SemVer); err != nil {
+			return fmt.Errorf("invalid semver range %q: %w", params.SemVer, err)
+		}
+	}
+
+	if params.Commit != "" && !commitShaRegexp.MatchString(params.Commit) {
+		return fmt.Errorf("invalid commit %q, expected a full commit SHA", params.Commit)
+	}
+
+	app.Spec.Tag = params.Tag
+	app.Spec.SemVer = params.SemVer
+	app.Spec.Commit = params.Commit
+
+	return nil
+}
+
+// validateSourceRef checks that the tag, semver range or commit tracked by the app exists in its repository
+func (a *App) validateSourceRef(ctx context.Context, info *AppResourceInfo) error {
+	if info.Spec.Tag == "" && info.Spec.SemVer == "" && info.Spec.Commit == "" {
+		return nil
+	}
+
+	a.logger.Waitingf("Checking ref in %s", info.Spec.URL)
+
+	refs, err := a.git.ListRemoteRefs(ctx, info.Spec.URL)
+	if err != nil {
+		return fmt.Errorf("could not validate source ref: %w", err)
+	}
+
+	switch {
+	case info.Spec.Tag != "":
+		if !hasRef(refs, plumbing.NewTagReferenceName(info.Spec.Tag)) {
+			return fmt.Errorf("tag %s not found in %s", info.Spec.Tag, info.Spec.URL)
+		}
+
+		a.logger.Successf("Found tag %s", info.Spec.Tag)
+	case info.Spec.SemVer != "":
+		constraint, err := semver.NewConstraint(info.Spec.SemVer)
+		if err != nil {
+			return fmt.Errorf("invalid semver range %s: %w", info.Spec.SemVer, err)
+		}
+
+		latest := latestMatchingTag(refs, constraint)
+		if latest == "" {
+			return fmt.Errorf("no tag in %s matches semver range %s", info.Spec.URL, info.Spec.SemVer)
+		}
+
+		a.logger.Successf("Tag %s is the latest matching semver range %s", latest, info.Spec.SemVer)
+	case info.Spec.Commit != "":
+		if !hasRef(refs, plumbing.NewBranchReferenceName(info.Spec.Branch)) {
+			return fmt.Errorf("branch %s of commit %s not found in %s", info.Spec.Branch, info.Spec.Commit, info.Spec.URL)
+		}
+
+		// Only the tips of refs are advertised, so the latest commits of the branch are fetched to find the commit
+		found, err := a.git.BranchHasCommit(ctx, info.Spec.URL, info.Spec.Branch, info.Spec.Commit, commitSearchDepth)
+		if err != nil {
+			return fmt.Errorf("could not validate source ref: %w", err)
+		}
+
+		if !found {
+			return fmt.Errorf("commit %s not found in the last %d commits of branch %s of %s", info.Spec.Commit, commitSearchDepth, info.Spec.Branch, info.Spec.URL)
+		}
+
+		a.logger.Successf("Found commit %s in branch %s", info.Spec.Commit, info.Spec.Branch)
+	}
+
+	return nil
+}
+
+// addSourceCommit pins a generated GitRepository to the commit stored in the application spec,
+// as the flux CLI can only track branches and tags
+func addSourceCommit(manifest []byte, spec wego.ApplicationSpec) ([]byte, error) {
+	if spec.Commit == "" {
+		return manifest, nil
+	}
+
+	gitRepository := sourcev1.GitRepository{}
+	if err := yaml.Unmarshal(manifest, &gitRepository); err != nil {
+		return nil, fmt.Errorf("could not unmarshal git repository: %w", err)
+	}
+
+	if gitRepository.Spec.Reference == nil {
+		gitRepository.Spec.Reference = &sourcev1.GitRepositoryRef{}
+	}
+
+	// The branch is kept, as the commit is checked out from it
+	gitRepository.Spec.Reference.Branch = spec.Branch
+	gitRepository.Spec.Reference.Commit = spec.Commit
+
+	out, err := yaml.Marshal(&gitRepository)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal git repository: %w", err)
+	}
+
+	return sanitizeK8sYaml(out), nil
+}
+
+func hasRef(refs []*plumbing.Reference, name plumbing.ReferenceName) bool {
+	for _, ref := range refs {
+		if ref.Name() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// latestMatchingTag returns the highest tag matching a semver range. Pre-releases only match ranges that include
+// one, like in the source controller, which checks ranges with the same library.
+func latestMatchingTag(refs []*plumbing.Reference, constraint *semver.Constraints) string {
+	var (
+		latestTag     string
+		latestVersion *semver.Version
+	)
+
+	for _, ref := range refs {
+		if !ref.Name().IsTag() {
+			continue
+		}
+
+		tag := ref.Name().Short()
+
+		v, err := semver.NewVersion(tag)
+		if err != nil {
+			continue
+		}
+
+		if constraint.Check(v) && (latestVersion == nil || latestVersion.LessThan(v)) {
+			latestTag = tag
+			latestVersion = v
+		}
+	}
+
+	return latestTag
+}
@@ -0,0 +1,210 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/plumbing"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+const testCommit = "3f2b5d4e1c0a9b8f7e6d5c4b3a2f1e0d9c8b7a6f"
+
+var _ = Describe("Source refs", func() {
+	var _ = BeforeEach(func() {
+		addParams = AddParams{
+			Url:            "git@github.com:foo/bar",
+			Path:           "./kustomize",
+			Branch:         "main",
+			DeploymentType: "kustomize",
+			Namespace:      "wego-system",
+			AppConfigUrl:   "NONE",
+			AutoMerge:      true,
+		}
+
+		gitClient.ListRemoteRefsStub = func(ctx context.Context, url string) ([]*plumbing.Reference, error) {
+			hash := plumbing.NewHash(testCommit)
+
+			return []*plumbing.Reference{
+				plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), hash),
+				plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.0.0"), hash),
+				plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.2.0"), hash),
+				plumbing.NewHashReference(plumbing.NewTagReferenceName("v2.0.0-rc.1"), hash),
+			}, nil
+		}
+
+		gitClient.BranchHasCommitReturns(true, nil)
+
+		fluxClient.CreateSourceGitStub = func(name, url, branch, tag, semver, secretRef, namespace string) ([]byte, error) {
+			return []byte("apiVersion: source.toolkit.fluxcd.io/v1beta1\nkind: GitRepository\nmetadata:\n  name: " + name + "\n  namespace: " + namespace + "\nspec:\n  interval: 30s\n  ref:\n    branch: " + branch + "\n  url: " + url + "\n"), nil
+		}
+	})
+
+	It("creates a source tracking a tag", func() {
+		addParams.Tag = "v1.0.0"
+
+		err := appSrv.Add(addParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, url := gitClient.ListRemoteRefsArgsForCall(0)
+		Expect(url).To(Equal("ssh://git@github.com/foo/bar.git"))
+
+		_, _, branch, tag, semver, _, _ := fluxClient.CreateSourceGitArgsForCall(0)
+		Expect(branch).To(Equal("main"))
+		Expect(tag).To(Equal("v1.0.0"))
+		Expect(semver).To(BeEmpty())
+	})
+
+	It("creates a source tracking a semver range", func() {
+		addParams.SemVer = ">=1.0.0 <2.0.0"
+
+		err := appSrv.Add(addParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, _, _, tag, semver, _, _ := fluxClient.CreateSourceGitArgsForCall(0)
+		Expect(tag).To(BeEmpty())
+		Expect(semver).To(Equal(">=1.0.0 <2.0.0"))
+	})
+
+	It("pins the source to a commit on the branch", func() {
+		addParams.Commit = testCommit
+
+		err := appSrv.Add(addParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		source, _ := kubeClient.ApplyArgsForCall(1)
+		Expect(string(source)).To(ContainSubstring("  ref:\n    branch: main\n    commit: " + testCommit + "\n"))
+	})
+
+	It("stores the ref in the app spec", func() {
+		addParams.Tag = "v1.0.0"
+
+		err := appSrv.Add(addParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		appSpec, _ := kubeClient.ApplyArgsForCall(kubeClient.ApplyCallCount() - 1)
+		Expect(string(appSpec)).To(ContainSubstring("  tag: v1.0.0\n"))
+	})
+
+	It("fails when the tag does not exist", func() {
+		addParams.Tag = "v3.0.0"
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError("tag v3.0.0 not found in ssh://git@github.com/foo/bar.git"))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+	})
+
+	It("fails when no tag matches the semver range", func() {
+		addParams.SemVer = "^2.0.0"
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError("no tag in ssh://git@github.com/foo/bar.git matches semver range ^2.0.0"))
+	})
+
+	It("accepts wildcard semver ranges", func() {
+		addParams.SemVer = "1.x"
+
+		err := appSrv.Add(addParams)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("fails for an invalid semver range", func() {
+		addParams.SemVer = ">=1.0.0 <"
+
+		err := appSrv.Add(addParams)
+		Expect(err).Should(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix(`could not set source ref: invalid semver range ">=1.0.0 <"`))
+		Expect(gitClient.ListRemoteRefsCallCount()).To(Equal(0))
+	})
+
+	It("checks the commit is in the history of the branch", func() {
+		addParams.Commit = testCommit
+
+		err := appSrv.Add(addParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(gitClient.BranchHasCommitCallCount()).To(Equal(1))
+		_, url, branch, commit, depth := gitClient.BranchHasCommitArgsForCall(0)
+		Expect(url).To(Equal("ssh://git@github.com/foo/bar.git"))
+		Expect(branch).To(Equal("main"))
+		Expect(commit).To(Equal(testCommit))
+		Expect(depth).To(Equal(commitSearchDepth))
+
+		// The repository the automation is written to is not cloned to find the commit
+		Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(0))
+	})
+
+	It("fails when the commit is not in the branch", func() {
+		addParams.Commit = testCommit
+		gitClient.BranchHasCommitReturns(false, nil)
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError("commit " + testCommit + " not found in the last 1000 commits of branch main of ssh://git@github.com/foo/bar.git"))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+	})
+
+	It("fails when the branch of the commit does not exist", func() {
+		addParams.Commit = testCommit
+		addParams.Branch = "release"
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError("branch release of commit " + testCommit + " not found in ssh://git@github.com/foo/bar.git"))
+	})
+
+	It("fails when the remote can't be listed", func() {
+		addParams.Tag = "v1.0.0"
+		gitClient.ListRemoteRefsReturns(nil, errors.New("permission denied"))
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError("could not validate source ref: permission denied"))
+	})
+
+	It("fails for more than one ref", func() {
+		addParams.Tag = "v1.0.0"
+		addParams.Commit = testCommit
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError("could not set source ref: only one of --tag, --semver and --commit can be set"))
+	})
+
+	It("fails for abbreviated commits", func() {
+		addParams.Commit = "3f2b5d4"
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError(`could not set source ref: invalid commit "3f2b5d4", expected a full commit SHA`))
+	})
+
+	It("fails when the automation is stored in the app repository", func() {
+		addParams.Tag = "v1.0.0"
+		addParams.AppConfigUrl = ""
+
+		err := appSrv.Add(addParams)
+		Expect(err).To(MatchError("could not set source ref: --tag, --semver and --commit require --app-config-url to be set to NONE or an external repository"))
+	})
+
+	table.DescribeTable("finds the latest tag matching a semver range",
+		func(constraint string, expected string) {
+			c, err := semver.NewConstraint(constraint)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			hash := plumbing.NewHash(testCommit)
+			refs := []*plumbing.Reference{
+				plumbing.NewHashReference(plumbing.NewBranchReferenceName("v9.0.0"), hash),
+				plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.2.3"), hash),
+				plumbing.NewHashReference(plumbing.NewTagReferenceName("1.3.0"), hash),
+				plumbing.NewHashReference(plumbing.NewTagReferenceName("v2.0.0-rc.1"), hash),
+				plumbing.NewHashReference(plumbing.NewTagReferenceName("latest"), hash),
+			}
+
+			Expect(latestMatchingTag(refs, c)).To(Equal(expected))
+		},
+		table.Entry("tilde allows patch updates", "~1.2.0", "v1.2.3"),
+		table.Entry("caret allows minor updates", "^1.2.0", "1.3.0"),
+		table.Entry("skips pre-releases", ">=1.0.0", "1.3.0"),
+		table.Entry("matches pre-releases of a pre-release range", ">=2.0.0-rc.0", "v2.0.0-rc.1"),
+		table.Entry("ignores branches", ">=9.0.0", ""),
+	)
+})
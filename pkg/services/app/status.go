@@ -18,37 +18,49 @@ type StatusParams struct {
 }
 
 func (a *App) Status(params StatusParams) (string, string, error) {
-	fluxOutput, err := a.flux.GetAllResourcesStatus(params.Name, params.Namespace)
+	ctx := context.Background()
+
+	app, err := a.kube.GetApplication(ctx, types.NamespacedName{Name: params.Name, Namespace: params.Namespace})
 	if err != nil {
-		return "", "", fmt.Errorf("failed getting app status: %w", err)
+		return "", "", fmt.Errorf("failed getting application: %w", err)
 	}
 
-	ctx := context.Background()
-	deploymentType, err := a.getDeploymentType(ctx, params.Name, params.Namespace)
+	fluxOutput, err := a.flux.GetAllResourcesStatus(params.Name, app.GetFluxNamespace())
 	if err != nil {
-		return "", "", fmt.Errorf("failed getting app deployment type: %w", err)
+		return "", "", fmt.Errorf("failed getting app status: %w", err)
 	}
 
-	lastRecon, err := a.getLastSuccessfulReconciliation(ctx, deploymentType, params)
+	lastRecon, err := a.getLastSuccessfulReconciliation(ctx, app)
 	if err != nil {
 		return "", "", fmt.Errorf("failed getting last successful reconciliation: %w", err)
 	}
 
+	verificationFailure, err := a.getVerificationFailure(ctx, app)
+	if err != nil {
+		return "", "", fmt.Errorf("failed getting source verification status: %w", err)
+	}
+
+	if verificationFailure != "" {
+		return fmt.Sprintf("Commit signature verification failed: %s\n\n%s", verificationFailure, fluxOutput), lastRecon, nil
+	}
+
 	return string(fluxOutput), lastRecon, nil
 }
 
-func (a *App) getLastSuccessfulReconciliation(ctx context.Context, deploymentType wego.DeploymentType, params StatusParams) (string, error) {
+func (a *App) getLastSuccessfulReconciliation(ctx context.Context, app *wego.Application) (string, error) {
+	name := types.NamespacedName{Name: app.Name, Namespace: app.GetFluxNamespace()}
+
 	conditions := []metav1.Condition{}
-	switch deploymentType {
+	switch app.Spec.DeploymentType {
 	case wego.DeploymentTypeKustomize:
 		kust := &kustomizev1.Kustomization{}
-		if err := a.kube.GetResource(ctx, types.NamespacedName{Name: params.Name, Namespace: params.Namespace}, kust); err != nil {
+		if err := a.kube.GetResource(ctx, name, kust); err != nil {
 			return "", fmt.Errorf("failed getting resource: %w", err)
 		}
 		conditions = kust.Status.Conditions
 	case wego.DeploymentTypeHelm:
 		helm := &helmv2.HelmRelease{}
-		if err := a.kube.GetResource(ctx, types.NamespacedName{Name: params.Name, Namespace: params.Namespace}, helm); err != nil {
+		if err := a.kube.GetResource(ctx, name, helm); err != nil {
 			return "", fmt.Errorf("failed getting resource: %w", err)
 		}
 		conditions = helm.Status.Conditions
@@ -7,6 +7,7 @@ import (
 	helmv2 "github.com/fluxcd/helm-controller/api/v2beta1"
 	kustomizev1 "github.com/fluxcd/kustomize-controller/api/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
@@ -29,7 +30,8 @@ var _ = Describe("Status", func() {
 
 		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
 			return &wego.Application{
-				Spec: wego.ApplicationSpec{DeploymentType: wego.DeploymentTypeKustomize},
+				ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace},
+				Spec:       wego.ApplicationSpec{DeploymentType: wego.DeploymentTypeKustomize},
 			}, nil
 		}
 	})
@@ -74,7 +76,8 @@ var _ = Describe("Status", func() {
 		It("returns when using helm", func() {
 			kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
 				return &wego.Application{
-					Spec: wego.ApplicationSpec{DeploymentType: wego.DeploymentTypeHelm},
+					ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace},
+					Spec:       wego.ApplicationSpec{DeploymentType: wego.DeploymentTypeHelm},
 				}, nil
 			}
 
@@ -109,4 +112,53 @@ var _ = Describe("Status", func() {
 			Expect(lastRecon).To(Equal("No succesfull reconciliation"))
 		})
 	})
+
+	It("reads the app's flux objects in its flux namespace", func() {
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return &wego.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace},
+				Spec:       wego.ApplicationSpec{DeploymentType: wego.DeploymentTypeKustomize, FluxNamespace: "flux-system"},
+			}, nil
+		}
+
+		_, _, err := appSrv.Status(statusParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		name, namespace := fluxClient.GetAllResourcesStatusArgsForCall(0)
+		Expect(name).To(Equal(statusParams.Name))
+		Expect(namespace).To(Equal("flux-system"))
+
+		_, kustomization, _ := kubeClient.GetResourceArgsForCall(0)
+		Expect(kustomization).To(Equal(types.NamespacedName{Name: statusParams.Name, Namespace: "flux-system"}))
+	})
+
+	It("shows a failed signature verification", func() {
+		kubeClient.GetApplicationStub = func(ctx context.Context, name types.NamespacedName) (*wego.Application, error) {
+			return &wego.Application{
+				ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace},
+				Spec: wego.ApplicationSpec{
+					DeploymentType:     wego.DeploymentTypeKustomize,
+					SourceType:         wego.SourceTypeGit,
+					VerificationSecret: "weave-gitops-my-app-trusted-keys",
+				},
+			}, nil
+		}
+
+		kubeClient.GetResourceStub = func(c context.Context, nn types.NamespacedName, r kube.Resource) error {
+			if repo, ok := r.(*sourcev1.GitRepository); ok {
+				repo.Status.Conditions = []metav1.Condition{{
+					Type:    meta.ReadyCondition,
+					Status:  metav1.ConditionFalse,
+					Reason:  sourcev1.VerificationFailedReason,
+					Message: "PGP signature of commit 'abc' could not be verified",
+				}}
+			}
+
+			return nil
+		}
+
+		fluxOutput, _, err := appSrv.Status(statusParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fluxOutput).To(Equal("Commit signature verification failed: PGP signature of commit 'abc' could not be verified\n\nstatus"))
+	})
 })
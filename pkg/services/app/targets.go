@@ -0,0 +1,166 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+)
+
+// parseTargets parses targets in the form name[:key=value,...], where key is one of branch, path or namespace,
+// e.g. prod:branch=release,path=./overlays/prod,namespace=podinfo
+func parseTargets(targets []string) ([]wego.ApplicationTarget, error) {
+	result := []wego.ApplicationTarget{}
+	seen := map[string]bool{}
+
+	for _, target := range targets {
+		parts := strings.SplitN(target, ":", 2)
+
+		appTarget := wego.ApplicationTarget{Name: parts[0]}
+		if appTarget.Name == "" {
+			return nil, fmt.Errorf("invalid target %q, expected name[:key=value,...]", target)
+		}
+
+		if seen[appTarget.Name] {
+			return nil, fmt.Errorf("target %q is specified more than once", appTarget.Name)
+		}
+
+		seen[appTarget.Name] = true
+
+		if len(parts) == 2 {
+			for _, setting := range strings.Split(parts[1], ",") {
+				keyValue := strings.SplitN(setting, "=", 2)
+				if len(keyValue) != 2 {
+					return nil, fmt.Errorf("invalid setting %q for target %q, expected key=value", setting, appTarget.Name)
+				}
+
+				switch keyValue[0] {
+				case "branch":
+					appTarget.Branch = keyValue[1]
+				case "path":
+					appTarget.Path = keyValue[1]
+				case "namespace":
+					appTarget.Namespace = keyValue[1]
+				default:
+					return nil, fmt.Errorf("unknown setting %q for target %q, expected branch, path or namespace", keyValue[0], appTarget.Name)
+				}
+			}
+		}
+
+		result = append(result, appTarget)
+	}
+
+	return result, nil
+}
+
+// setTargets stores the targets passed to `wego app add` in the application spec
+func setTargets(app *wego.Application, params AddParams) error {
+	if len(params.Targets) == 0 {
+		return nil
+	}
+
+	if strings.ToUpper(app.Spec.ConfigURL) == string(ConfigTypeNone) {
+		return fmt.Errorf("targets are written to the config repository and can not be used with --app-config-url=NONE")
+	}
+
+	targets, err := parseTargets(params.Targets)
+	if err != nil {
+		return err
+	}
+
+	// The automation in the app repository is synced from the app branch, so targets can't move to another one
+	if app.Spec.ConfigURL == string(ConfigTypeUserRepo) || app.Spec.ConfigURL == app.Spec.URL {
+		for _, target := range targets {
+			if target.Branch != "" {
+				return fmt.Errorf("target %q sets a branch, which requires an external config repository", target.Name)
+			}
+		}
+	}
+
+	app.Spec.Targets = targets
+
+	return nil
+}
+
+// targetInfos returns the resource info for each of the app's targets, with the target's settings applied.
+// An app without targets has a single target: the cluster it was added from.
+func (a *AppResourceInfo) targetInfos() []*AppResourceInfo {
+	if len(a.Spec.Targets) == 0 {
+		return []*AppResourceInfo{a}
+	}
+
+	infos := []*AppResourceInfo{}
+
+	for _, target := range a.Spec.Targets {
+		info := &AppResourceInfo{
+			Application:     *a.Application.DeepCopy(),
+			clusterName:     target.Name,
+			targetName:      target.Name,
+			targetNamespace: a.targetNamespace,
+		}
+
+		if target.Branch != "" {
+			info.Spec.Branch = target.Branch
+		}
+
+		if target.Path != "" {
+			info.Spec.Path = target.Path
+		}
+
+		if target.Namespace != "" {
+			info.targetNamespace = target.Namespace
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+// isTarget reports whether the cluster the app is being added from is one of its targets
+func (a *AppResourceInfo) isTarget() bool {
+	if len(a.Spec.Targets) == 0 {
+		return true
+	}
+
+	for _, target := range a.Spec.Targets {
+		if target.Name == a.clusterName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// currentTarget returns the target of the cluster the app is being added from, or nil when it is not one of them
+func (a *AppResourceInfo) currentTarget() *AppResourceInfo {
+	for _, target := range a.targetInfos() {
+		if target.clusterName == a.clusterName {
+			return target
+		}
+	}
+
+	return nil
+}
+
+// validateTargetClusters checks that the targets other than the current cluster can reach their automation. Their
+// clusters deploy it with the sync of the config repository they are installed from, which covers their target
+// directory, so it must be an external one, and the secrets they read the app with must be committed to it
+// encrypted.
+func validateTargetClusters(info *AppResourceInfo) error {
+	for _, target := range info.targetInfos() {
+		if target.clusterName == info.clusterName {
+			continue
+		}
+
+		if info.automationRoot() != "." {
+			return fmt.Errorf("target %q is not the current cluster, which requires an external config repository for its cluster to sync from", target.targetName)
+		}
+
+		if info.Spec.SourceType == wego.SourceTypeGit && info.Spec.SecretEncryption == "" {
+			return fmt.Errorf("target %q is not the current cluster, set --encrypt-secrets to commit the secrets of its cluster to the config repository", target.targetName)
+		}
+	}
+
+	return nil
+}
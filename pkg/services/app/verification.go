@@ -0,0 +1,136 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1beta1"
+	wego "github.com/weaveworks/weave-gitops/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// verificationKeyringKey is the secret key holding the armored public keys; the source controller reads every key of the secret
+	verificationKeyringKey = "keyring.asc"
+
+	// verificationModeHead verifies the signature of the commit the branch points to
+	verificationModeHead = "head"
+)
+
+// setSignatureVerification stores the secret holding the trusted public keys in the application spec
+func setSignatureVerification(app *wego.Application, params AddParams) error {
+	if !params.VerifySignatures {
+		return nil
+	}
+
+	if app.Spec.SourceType != wego.SourceTypeGit {
+		return fmt.Errorf("commit signatures can only be verified for git sources")
+	}
+
+	if params.VerificationKeyring == "" {
+		return fmt.Errorf("--verify-keyring must be set to verify commit signatures")
+	}
+
+	app.Spec.VerificationSecret = fmt.Sprintf("weave-gitops-%s-trusted-keys", app.Name)
+
+	return nil
+}
+
+// createVerificationSecret creates the secret holding the public keys trusted to sign the app's commits
+func (a *App) createVerificationSecret(info *AppResourceInfo, params AddParams) ([]byte, error) {
+	keyring, err := ioutil.ReadFile(params.VerificationKeyring)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring %s: %w", params.VerificationKeyring, err)
+	}
+
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(keyring))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read armored keyring %s: %w", params.VerificationKeyring, err)
+	}
+
+	if len(entities) == 0 {
+		return nil, fmt.Errorf("keyring %s does not contain any public keys", params.VerificationKeyring)
+	}
+
+	if params.DryRun {
+		return nil, nil
+	}
+
+	a.logger.Generatef("Generating secret %s with %d trusted public keys", info.Spec.VerificationSecret, len(entities))
+
+	secret := corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      info.Spec.VerificationSecret,
+			Namespace: info.GetFluxNamespace(),
+		},
+		StringData: map[string]string{
+			verificationKeyringKey: string(keyring),
+		},
+	}
+
+	manifest, err := yaml.Marshal(&secret)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal verification secret: %w", err)
+	}
+
+	if out, err := a.kube.Apply(manifest, info.GetFluxNamespace()); err != nil {
+		return nil, fmt.Errorf("could not apply manifest: %s: %w", string(out), err)
+	}
+
+	return manifest, nil
+}
+
+// addSourceVerification makes a generated GitRepository verify the signature of the commit it syncs
+func addSourceVerification(manifest []byte, spec wego.ApplicationSpec) ([]byte, error) {
+	if spec.VerificationSecret == "" {
+		return manifest, nil
+	}
+
+	gitRepository := sourcev1.GitRepository{}
+	if err := yaml.Unmarshal(manifest, &gitRepository); err != nil {
+		return nil, fmt.Errorf("could not unmarshal git repository: %w", err)
+	}
+
+	gitRepository.Spec.Verification = &sourcev1.GitRepositoryVerification{
+		Mode:      verificationModeHead,
+		SecretRef: meta.LocalObjectReference{Name: spec.VerificationSecret},
+	}
+
+	out, err := yaml.Marshal(&gitRepository)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal git repository: %w", err)
+	}
+
+	return sanitizeK8sYaml(out), nil
+}
+
+// getVerificationFailure returns why the app's source failed to verify the synced commit, if it did
+func (a *App) getVerificationFailure(ctx context.Context, app *wego.Application) (string, error) {
+	if app.Spec.VerificationSecret == "" {
+		return "", nil
+	}
+
+	gitRepository := &sourcev1.GitRepository{}
+	if err := a.kube.GetResource(ctx, types.NamespacedName{Name: app.Name, Namespace: app.GetFluxNamespace()}, gitRepository); err != nil {
+		return "", fmt.Errorf("failed getting resource: %w", err)
+	}
+
+	for _, c := range gitRepository.Status.Conditions {
+		if c.Type == meta.ReadyCondition && c.Status == metav1.ConditionFalse && c.Reason == sourcev1.VerificationFailedReason {
+			return c.Message, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,259 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/weaveworks/weave-gitops/manifests"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	componentsFileName = "wego-components.yaml"
+	syncFileName       = "wego-sync.yaml"
+)
+
+// bootstrap commits the flux components, the App CRD and the sync of the config repo to targets/<cluster>/<namespace>
+// in the config repo, and installs them from there. The cluster then reconciles its own install from git, so it is
+// upgraded by committing new components, e.g. by running the bootstrap again with a newer wego. The sync covers all
+// of targets/<cluster>, so the cluster also deploys the apps targeting it, including the ones added from other
+// clusters, and decrypts their encrypted secrets.
+func (g *Gitops) bootstrap(params InstallParams) ([]byte, error) {
+	ctx := context.Background()
+
+	clusterName, err := g.kube.GetClusterName(ctx)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	g.logger.Generatef("Generating the install manifests")
+
+	components, err := g.flux.Install(params.Namespace, params.Registry, params.ImagePullSecret, true)
+	if err != nil {
+		return []byte{}, fmt.Errorf("error on flux install %s", err)
+	}
+
+	components = append(components, manifests.AppCRD...)
+
+	wegoComponents, err := renderComponents(params.Components, params.Namespace, params.Registry, params.ImagePullSecret)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	components = append(components, wegoComponents...)
+
+	secretName := configRepoSecretName(clusterName, params.ConfigRepo)
+
+	source, err := g.flux.CreateSourceGit(params.Namespace, params.ConfigRepo, params.Branch, "", "", secretName, params.Namespace)
+	if err != nil {
+		return []byte{}, fmt.Errorf("could not generate the config repo source: %w", err)
+	}
+
+	kustomization, err := g.flux.CreateKustomization(params.Namespace, params.Namespace, "./"+app.TargetDir(clusterName), "", "", params.DecryptionSecret, params.Namespace)
+	if err != nil {
+		return []byte{}, fmt.Errorf("could not generate the config repo kustomization: %w", err)
+	}
+
+	sync := append(source, kustomization...)
+
+	if params.DryRun {
+		return append(components, sync...), nil
+	}
+
+	if err := g.commitInstall(params, clusterName, components, sync); err != nil {
+		return []byte{}, err
+	}
+
+	g.logger.Actionf("Installing the flux components, the App CRD and the wego components")
+
+	if out, err := g.kube.Apply(components, params.Namespace); err != nil {
+		return []byte{}, errors.Wrapf(err, "failed to apply the install manifests: %s", string(out))
+	}
+
+	if err := g.createConfigRepoSecret(params, clusterName, secretName); err != nil {
+		return []byte{}, err
+	}
+
+	if err := g.createDecryptionSecret(params); err != nil {
+		return []byte{}, err
+	}
+
+	g.logger.Actionf("Syncing the cluster from %s", params.ConfigRepo)
+
+	if out, err := g.kube.Apply(sync, params.Namespace); err != nil {
+		return []byte{}, errors.Wrapf(err, "failed to apply the config repo sync: %s", string(out))
+	}
+
+	g.logger.Successf("Weave GitOps is installed from %s", params.ConfigRepo)
+
+	return []byte{}, nil
+}
+
+// commitInstall pushes the install manifests to the head of the config repo branch
+func (g *Gitops) commitInstall(params InstallParams, clusterName string, components []byte, sync []byte) error {
+	repoDir, err := ioutil.TempDir("", "config-repo-")
+	if err != nil {
+		return fmt.Errorf("failed creating temp. directory to clone repo: %w", err)
+	}
+	defer os.RemoveAll(repoDir)
+
+	g.logger.Actionf("Cloning %s", params.ConfigRepo)
+
+	if _, err := g.git.CloneWithOptions(context.Background(), repoDir, params.ConfigRepo, params.Branch, git.CloneOptions{Depth: 1}); err != nil {
+		return fmt.Errorf("failed cloning config repo: %s: %w", params.ConfigRepo, err)
+	}
+
+	installDir := filepath.Join(app.TargetDir(clusterName), params.Namespace)
+
+	if err := g.git.Write(filepath.Join(installDir, componentsFileName), components); err != nil {
+		return fmt.Errorf("failed writing the install manifests to disk: %w", err)
+	}
+
+	if err := g.git.Write(filepath.Join(installDir, syncFileName), sync); err != nil {
+		return fmt.Errorf("failed writing the sync manifests to disk: %w", err)
+	}
+
+	_, err = g.git.Commit(git.Commit{
+		Author:  app.CommitAuthor(params.CommitAuthorName, params.CommitAuthorEmail),
+		Message: fmt.Sprintf("Install Weave GitOps on %s", clusterName),
+	})
+	if err == git.ErrNoStagedFiles {
+		g.logger.Successf("Install manifests are up to date")
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to commit the install manifests: %w", err)
+	}
+
+	g.logger.Actionf("Pushing the install manifests to %s", params.ConfigRepo)
+
+	if err := g.git.Push(context.Background()); err != nil {
+		return fmt.Errorf("failed to push the install manifests: %w", err)
+	}
+
+	return nil
+}
+
+// createConfigRepoSecret creates the secret flux reads the config repo with: a deploy key for ssh URLs, or the
+// git provider token for https URLs. An existing deploy key is kept.
+func (g *Gitops) createConfigRepoSecret(params InstallParams, clusterName string, secretName string) error {
+	ctx := context.Background()
+
+	if strings.HasPrefix(params.ConfigRepo, "https://") {
+		secret, err := app.GitCredentialsSecret(secretName, params.Namespace, params.GitProviderToken)
+		if err != nil {
+			return err
+		}
+
+		if out, err := g.kube.Apply(secret, params.Namespace); err != nil {
+			return fmt.Errorf("could not apply manifest: %s: %w", string(out), err)
+		}
+
+		return nil
+	}
+
+	repo, err := gitproviders.ParseRepoURL(params.ConfigRepo)
+	if err != nil {
+		return err
+	}
+
+	owner, repoName := repo.Owner, repo.Name
+
+	deployKeys, err := g.gitProvider.ListDeployKeys(owner, repoName, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed check for existing deploy key: %w", err)
+	}
+
+	secretPresent, err := g.kube.SecretPresent(ctx, secretName, params.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed check for existing secret: %w", err)
+	}
+
+	if len(deployKeys) > 0 && secretPresent {
+		return nil
+	}
+
+	g.logger.Generatef("Generating deploy key for repo %s", params.ConfigRepo)
+
+	secret, err := g.flux.CreateSecretGit(secretName, params.ConfigRepo, params.Namespace)
+	if err != nil {
+		return fmt.Errorf("could not create git secret: %w", err)
+	}
+
+	var secretData corev1.Secret
+	if err := yaml.Unmarshal(secret, &secretData); err != nil {
+		return fmt.Errorf("failed to unmarshal created secret: %w", err)
+	}
+
+	// The keys of a lost secret are replaced, nothing can use them anymore
+	keyName := gitproviders.NextDeployKeyName(clusterName, deployKeys)
+	if err := g.gitProvider.UploadDeployKey(owner, repoName, keyName, []byte(secretData.StringData["identity.pub"])); err != nil {
+		return fmt.Errorf("error uploading deploy key: %w", err)
+	}
+
+	for _, oldKey := range deployKeys {
+		g.logger.Actionf("Deleting deploy key %s", oldKey)
+
+		if err := g.gitProvider.DeleteDeployKey(owner, repoName, oldKey); err != nil {
+			return fmt.Errorf("failed deleting deploy key %s: %w", oldKey, err)
+		}
+	}
+
+	if out, err := g.kube.Apply(secret, params.Namespace); err != nil {
+		return fmt.Errorf("could not apply manifest: %s: %w", string(out), err)
+	}
+
+	return nil
+}
+
+// createDecryptionSecret creates the secret the config repo sync decrypts with, empty, when it is missing. The sync
+// then reconciles until an encrypted secret is committed, whose sops keys are added to the secret. Existing keys
+// are kept.
+func (g *Gitops) createDecryptionSecret(params InstallParams) error {
+	if params.DecryptionSecret == "" {
+		return nil
+	}
+
+	present, err := g.kube.SecretPresent(context.Background(), params.DecryptionSecret, params.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed check for existing secret: %w", err)
+	}
+
+	if present {
+		return nil
+	}
+
+	secret, err := yaml.Marshal(&corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: params.DecryptionSecret, Namespace: params.Namespace},
+	})
+	if err != nil {
+		return fmt.Errorf("could not marshal decryption secret: %w", err)
+	}
+
+	g.logger.Actionf("Creating the decryption secret %s, add the sops keys of the encrypted secrets to it", params.DecryptionSecret)
+
+	if out, err := g.kube.Apply(secret, params.Namespace); err != nil {
+		return fmt.Errorf("could not apply manifest: %s: %w", string(out), err)
+	}
+
+	return nil
+}
+
+// configRepoSecretName returns the name of the secret flux reads the config repo with, the same one the apps
+// stored in the config repo use
+func configRepoSecretName(clusterName string, repoUrl string) string {
+	return fmt.Sprintf("weave-gitops-%s-%s", clusterName, strings.TrimSuffix(path.Base(repoUrl), ".git"))
+}
@@ -0,0 +1,260 @@
+package gitops_test
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/flux/fluxfakes"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/git/gitfakes"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders/gitprovidersfakes"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/services/gitops"
+	"github.com/weaveworks/weave-gitops/pkg/version"
+)
+
+var _ = Describe("Install from a config repository", func() {
+	var (
+		gitClient    *gitfakes.FakeGit
+		gitProviders *gitprovidersfakes.FakeGitProvider
+	)
+
+	BeforeEach(func() {
+		fluxClient = &fluxfakes.FakeFlux{
+			InstallStub: func(namespace, registry, imagePullSecret string, export bool) ([]byte, error) {
+				return []byte("---\nkind: Deployment\n"), nil
+			},
+			CreateSourceGitStub: func(name, url, branch, tag, semver, secretRef, namespace string) ([]byte, error) {
+				return []byte("---\nkind: GitRepository\n"), nil
+			},
+			CreateKustomizationStub: func(name, source, path, targetNamespace, serviceAccount, decryptionSecret, namespace string) ([]byte, error) {
+				return []byte("---\nkind: Kustomization\n"), nil
+			},
+			CreateSecretGitStub: func(name, url, namespace string) ([]byte, error) {
+				return []byte("kind: Secret\nstringData:\n  identity.pub: ssh-ed25519 key\n"), nil
+			},
+		}
+		kubeClient = &kubefakes.FakeKube{
+			GetClusterStatusStub: func(c context.Context) kube.ClusterStatus {
+				return kube.Unmodified
+			},
+			GetClusterNameStub: func(ctx context.Context) (string, error) {
+				return "test-cluster", nil
+			},
+		}
+		gitClient = &gitfakes.FakeGit{}
+		gitProviders = &gitprovidersfakes.FakeGitProvider{}
+
+		gitopsSrv = gitops.New(logger.New(os.Stderr), fluxClient, kubeClient).WithGit(gitClient, gitProviders)
+
+		installParams = gitops.InstallParams{
+			Namespace:  "wego-system",
+			ConfigRepo: "ssh://git@github.com/foo/config.git",
+			Branch:     "main",
+		}
+	})
+
+	It("commits the install to the cluster's target directory", func() {
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, _, url, branch, opts := gitClient.CloneWithOptionsArgsForCall(0)
+		Expect(url).To(Equal("ssh://git@github.com/foo/config.git"))
+		Expect(branch).To(Equal("main"))
+		Expect(opts).To(Equal(git.CloneOptions{Depth: 1}))
+
+		Expect(gitClient.WriteCallCount()).To(Equal(2))
+
+		path, components := gitClient.WriteArgsForCall(0)
+		Expect(path).To(Equal("targets/test-cluster/wego-system/wego-components.yaml"))
+		Expect(string(components)).To(HavePrefix("---\nkind: Deployment\n"))
+		Expect(string(components)).To(ContainSubstring("kind: CustomResourceDefinition"))
+
+		path, sync := gitClient.WriteArgsForCall(1)
+		Expect(path).To(Equal("targets/test-cluster/wego-system/wego-sync.yaml"))
+		Expect(string(sync)).To(Equal("---\nkind: GitRepository\n---\nkind: Kustomization\n"))
+
+		name, url, branch, _, _, secretRef, namespace := fluxClient.CreateSourceGitArgsForCall(0)
+		Expect(name).To(Equal("wego-system"))
+		Expect(url).To(Equal("ssh://git@github.com/foo/config.git"))
+		Expect(branch).To(Equal("main"))
+		Expect(secretRef).To(Equal("weave-gitops-test-cluster-config"))
+		Expect(namespace).To(Equal("wego-system"))
+
+		name, source, kustomizationPath, _, _, _, _ := fluxClient.CreateKustomizationArgsForCall(0)
+		Expect(name).To(Equal("wego-system"))
+		Expect(source).To(Equal("wego-system"))
+		Expect(kustomizationPath).To(Equal("./targets/test-cluster"))
+
+		Expect(gitClient.CommitCallCount()).To(Equal(1))
+		Expect(gitClient.PushCallCount()).To(Equal(1))
+	})
+
+	It("commits the install with the given author", func() {
+		installParams.CommitAuthorName = "Jane Doe"
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		commit, _ := gitClient.CommitArgsForCall(0)
+		Expect(commit.Author).To(Equal(git.Author{Name: "Jane Doe", Email: "weave-gitops@weave.works"}))
+	})
+
+	It("syncs the apps targeting the cluster, decrypting their secrets", func() {
+		installParams.DecryptionSecret = "sops-keys"
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, _, kustomizationPath, _, _, decryptionSecret, _ := fluxClient.CreateKustomizationArgsForCall(0)
+		Expect(kustomizationPath).To(Equal("./targets/test-cluster"))
+		Expect(decryptionSecret).To(Equal("sops-keys"))
+
+		_, name, namespace := kubeClient.SecretPresentArgsForCall(1)
+		Expect(name).To(Equal("sops-keys"))
+		Expect(namespace).To(Equal("wego-system"))
+
+		Expect(kubeClient.ApplyCallCount()).To(Equal(4))
+		secret, namespace := kubeClient.ApplyArgsForCall(2)
+		Expect(string(secret)).To(ContainSubstring("kind: Secret\nmetadata:\n  creationTimestamp: null\n  name: sops-keys\n  namespace: wego-system\n"))
+		Expect(namespace).To(Equal("wego-system"))
+	})
+
+	It("keeps the sops keys of an existing decryption secret", func() {
+		installParams.DecryptionSecret = "sops-keys"
+		kubeClient.SecretPresentStub = func(ctx context.Context, name, namespace string) (bool, error) {
+			return name == "sops-keys", nil
+		}
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.ApplyCallCount()).To(Equal(3))
+	})
+
+	It("commits the selected wego components with the flux ones", func() {
+		version.WegoImageTag = "0.2.0"
+		installParams.Components = []string{gitops.ComponentController}
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, components := gitClient.WriteArgsForCall(0)
+		Expect(string(components)).To(ContainSubstring("kind: Deployment\nmetadata:\n  name: wego-controller\n  namespace: wego-system\n"))
+		Expect(string(components)).To(ContainSubstring("image: ghcr.io/weaveworks/wego-controller:0.2.0"))
+		Expect(string(components)).NotTo(ContainSubstring("wego-ui"))
+	})
+
+	It("installs the components, the deploy key and the sync", func() {
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		owner, repoName, keyName, deployKey := gitProviders.UploadDeployKeyArgsForCall(0)
+		Expect(owner).To(Equal("foo"))
+		Expect(repoName).To(Equal("config"))
+		Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key"))
+		Expect(string(deployKey)).To(Equal("ssh-ed25519 key"))
+
+		Expect(kubeClient.ApplyCallCount()).To(Equal(3))
+
+		components, _ := kubeClient.ApplyArgsForCall(0)
+		Expect(string(components)).To(ContainSubstring("kind: CustomResourceDefinition"))
+
+		secret, _ := kubeClient.ApplyArgsForCall(1)
+		Expect(string(secret)).To(HavePrefix("kind: Secret"))
+
+		sync, _ := kubeClient.ApplyArgsForCall(2)
+		Expect(string(sync)).To(ContainSubstring("kind: Kustomization"))
+
+		Expect(fluxClient.InstallCallCount()).To(Equal(1))
+		_, _, _, export := fluxClient.InstallArgsForCall(0)
+		Expect(export).To(BeTrue())
+	})
+
+	It("uploads the deploy key to the owner of scp-like and nested group urls", func() {
+		installParams.ConfigRepo = "git@gitlab.com:foo/team/config.git"
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		owner, repoName, _, _ := gitProviders.UploadDeployKeyArgsForCall(0)
+		Expect(owner).To(Equal("foo/team"))
+		Expect(repoName).To(Equal("config"))
+	})
+
+	It("keeps an existing deploy key", func() {
+		gitProviders.ListDeployKeysReturns([]string{"weave-gitops-test-cluster-deploy-key"}, nil)
+		kubeClient.SecretPresentReturns(true, nil)
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(2))
+	})
+
+	It("replaces the deploy key of a lost secret", func() {
+		gitProviders.ListDeployKeysReturns([]string{gitproviders.LegacyDeployKeyName}, nil)
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, _, keyName, _ := gitProviders.UploadDeployKeyArgsForCall(0)
+		Expect(keyName).To(Equal("weave-gitops-test-cluster-deploy-key-2"))
+
+		Expect(gitProviders.DeleteDeployKeyCallCount()).To(Equal(1))
+		_, _, keyName = gitProviders.DeleteDeployKeyArgsForCall(0)
+		Expect(keyName).To(Equal(gitproviders.LegacyDeployKeyName))
+	})
+
+	It("uses the token for https repositories", func() {
+		installParams.ConfigRepo = "https://github.com/foo/config.git"
+		installParams.GitProviderToken = "token"
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(gitProviders.UploadDeployKeyCallCount()).To(Equal(0))
+
+		secret, _ := kubeClient.ApplyArgsForCall(1)
+		Expect(string(secret)).To(ContainSubstring("name: weave-gitops-test-cluster-config"))
+		Expect(string(secret)).To(ContainSubstring("password: token"))
+	})
+
+	It("applies the install when the manifests are already committed", func() {
+		gitClient.CommitReturns("", git.ErrNoStagedFiles)
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(gitClient.PushCallCount()).To(Equal(0))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(3))
+	})
+
+	It("returns the manifests without committing them on a dry run", func() {
+		installParams.DryRun = true
+
+		out, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(string(out)).To(ContainSubstring("kind: CustomResourceDefinition"))
+		Expect(string(out)).To(HaveSuffix("---\nkind: GitRepository\n---\nkind: Kustomization\n"))
+
+		Expect(gitClient.CloneWithOptionsCallCount()).To(Equal(0))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+	})
+
+	It("fails on a cluster that is using flux", func() {
+		kubeClient.GetClusterStatusStub = func(c context.Context) kube.ClusterStatus {
+			return kube.FluxInstalled
+		}
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).To(MatchError("Weave GitOps can not be installed from a config repository onto a cluster that is using Flux"))
+	})
+})
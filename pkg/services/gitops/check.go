@@ -0,0 +1,308 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"golang.org/x/mod/semver"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	extensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// MinimumKubernetesVersion is the oldest Kubernetes the embedded flux supports
+const MinimumKubernetesVersion = "v1.16.0"
+
+// gitHostTimeout bounds the connection to each git host
+const gitHostTimeout = 5 * time.Second
+
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// CheckResult is the outcome of one of the checks of 'wego gitops check'
+type CheckResult struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+}
+
+type CheckParams struct {
+	Namespace string
+	// FluxNamespace is where flux is looked for when it isn't installed in the wego namespace
+	FluxNamespace string
+	// GitHosts are the git hosts the cluster and the CLI talk to, as host or host:port. Without a port,
+	// both ssh and https are checked.
+	GitHosts []string
+}
+
+// requiredPermission is an action wego performs with the credentials of the user
+type requiredPermission struct {
+	verb     string
+	group    string
+	resource string
+	// clusterScoped permissions are checked outside of the wego namespace
+	clusterScoped bool
+}
+
+var requiredPermissions = []requiredPermission{
+	{verb: "create", group: "apiextensions.k8s.io", resource: "customresourcedefinitions", clusterScoped: true},
+	{verb: "create", resource: "namespaces", clusterScoped: true},
+	{verb: "create", group: "wego.weave.works", resource: "apps"},
+	{verb: "list", group: "wego.weave.works", resource: "apps"},
+	{verb: "create", resource: "secrets"},
+	{verb: "create", group: "source.toolkit.fluxcd.io", resource: "gitrepositories"},
+	{verb: "create", group: "kustomize.toolkit.fluxcd.io", resource: "kustomizations"},
+	{verb: "create", group: "helm.toolkit.fluxcd.io", resource: "helmreleases"},
+}
+
+// requiredCRD is a custom resource definition, and the version of it wego generates objects for
+type requiredCRD struct {
+	name    string
+	version string
+	// optional CRDs are only used by some commands, and are warned about
+	optional bool
+}
+
+var requiredCRDs = []requiredCRD{
+	{name: kube.WeGOCRDName, version: "v1alpha1"},
+	{name: "gitrepositories.source.toolkit.fluxcd.io", version: "v1beta1"},
+	{name: "helmrepositories.source.toolkit.fluxcd.io", version: "v1beta1"},
+	{name: "buckets.source.toolkit.fluxcd.io", version: "v1beta1"},
+	{name: "kustomizations.kustomize.toolkit.fluxcd.io", version: "v1beta1"},
+	{name: "helmreleases.helm.toolkit.fluxcd.io", version: "v2beta1"},
+	{name: "providers.notification.toolkit.fluxcd.io", version: "v1beta1"},
+	{name: "alerts.notification.toolkit.fluxcd.io", version: "v1beta1"},
+	{name: "imagerepositories.image.toolkit.fluxcd.io", version: "v1alpha2", optional: true},
+	{name: "imagepolicies.image.toolkit.fluxcd.io", version: "v1alpha2", optional: true},
+	{name: "imageupdateautomations.image.toolkit.fluxcd.io", version: "v1alpha2", optional: true},
+}
+
+// Check runs the preflight checks of a cluster, printing each result. The CRD and controller checks are skipped
+// when wego isn't installed yet.
+func (g *Gitops) Check(params CheckParams) []CheckResult {
+	ctx := context.Background()
+	results := []CheckResult{}
+
+	report := func(result CheckResult) {
+		switch result.Status {
+		case CheckPass:
+			g.logger.Successf("%s: %s", result.Name, result.Message)
+		case CheckWarn:
+			g.logger.Warningf("%s: %s", result.Name, result.Message)
+		default:
+			g.logger.Failuref("%s: %s", result.Name, result.Message)
+		}
+
+		results = append(results, result)
+	}
+
+	g.logger.Waitingf("Checking the cluster")
+
+	version, err := g.kube.GetServerVersion(ctx)
+	if err != nil {
+		report(CheckResult{Name: "kubernetes", Status: CheckFail, Message: fmt.Sprintf("could not reach the cluster: %s", err)})
+		return results
+	}
+
+	report(checkKubernetesVersion(version))
+
+	for _, result := range g.checkPermissions(ctx, params) {
+		report(result)
+	}
+
+	crd := &extensionsv1.CustomResourceDefinition{}
+	if err := g.kube.GetResource(ctx, types.NamespacedName{Name: kube.WeGOCRDName}, crd); err != nil {
+		report(CheckResult{Name: "crds", Status: CheckFail, Message: err.Error()})
+	} else if crd.Name == "" {
+		report(CheckResult{Name: "crds", Status: CheckWarn, Message: "Weave GitOps is not installed, skipping the CRD and controller checks"})
+	} else {
+		for _, result := range g.checkCRDs(ctx) {
+			report(result)
+		}
+
+		for _, result := range g.checkControllers(ctx, params) {
+			report(result)
+		}
+	}
+
+	g.logger.Waitingf("Checking the git hosts")
+
+	for _, host := range params.GitHosts {
+		report(checkGitHost(host))
+	}
+
+	return results
+}
+
+func checkKubernetesVersion(version string) CheckResult {
+	result := CheckResult{Name: "kubernetes", Status: CheckPass, Message: fmt.Sprintf("%s >= %s", version, MinimumKubernetesVersion)}
+
+	switch {
+	case !semver.IsValid(version):
+		result.Status = CheckWarn
+		result.Message = fmt.Sprintf("could not parse the version %q", version)
+	case semver.Compare(version, MinimumKubernetesVersion) < 0:
+		result.Status = CheckFail
+		result.Message = fmt.Sprintf("%s < %s", version, MinimumKubernetesVersion)
+	}
+
+	return result
+}
+
+func (g *Gitops) checkPermissions(ctx context.Context, params CheckParams) []CheckResult {
+	results := []CheckResult{}
+
+	for _, permission := range requiredPermissions {
+		attributes := authorizationv1.ResourceAttributes{
+			Verb:     permission.verb,
+			Group:    permission.group,
+			Resource: permission.resource,
+		}
+
+		scope := "the cluster"
+		if !permission.clusterScoped {
+			attributes.Namespace = params.Namespace
+			scope = params.Namespace
+		}
+
+		name := "rbac " + permission.verb + " " + permission.resource
+		if permission.group != "" {
+			name += "." + permission.group
+		}
+
+		allowed, err := g.kube.CanI(ctx, attributes)
+
+		switch {
+		case err != nil:
+			results = append(results, CheckResult{Name: name, Status: CheckFail, Message: err.Error()})
+		case !allowed:
+			results = append(results, CheckResult{Name: name, Status: CheckFail, Message: fmt.Sprintf("not allowed in %s", scope)})
+		default:
+			results = append(results, CheckResult{Name: name, Status: CheckPass, Message: fmt.Sprintf("allowed in %s", scope)})
+		}
+	}
+
+	return results
+}
+
+func (g *Gitops) checkCRDs(ctx context.Context) []CheckResult {
+	results := []CheckResult{}
+
+	for _, required := range requiredCRDs {
+		result := CheckResult{Name: required.name, Status: CheckPass, Message: fmt.Sprintf("serves %s", required.version)}
+
+		crd := &extensionsv1.CustomResourceDefinition{}
+		err := g.kube.GetResource(ctx, types.NamespacedName{Name: required.name}, crd)
+
+		switch {
+		case err != nil:
+			result.Status = CheckFail
+			result.Message = err.Error()
+		case crd.Name == "":
+			result.Status = failOrWarn(required.optional)
+			result.Message = "not installed"
+		case !servesVersion(crd, required.version):
+			result.Status = failOrWarn(required.optional)
+			result.Message = fmt.Sprintf("does not serve %s", required.version)
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func servesVersion(crd *extensionsv1.CustomResourceDefinition, version string) bool {
+	for _, v := range crd.Spec.Versions {
+		if v.Name == version && v.Served {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Gitops) checkControllers(ctx context.Context, params CheckParams) []CheckResult {
+	namespace := params.Namespace
+
+	controllers, err := kube.GetFluxControllers(ctx, g.kube, namespace)
+	if err == nil && len(controllers) == 0 && params.FluxNamespace != "" {
+		namespace = params.FluxNamespace
+		controllers, err = kube.GetFluxControllers(ctx, g.kube, namespace)
+	}
+
+	if err != nil {
+		return []CheckResult{{Name: "controllers", Status: CheckFail, Message: err.Error()}}
+	}
+
+	results := []CheckResult{}
+
+	check := func(name string, optional bool) {
+		controller, ok := findFluxController(controllers, name)
+
+		switch {
+		case !ok:
+			results = append(results, CheckResult{Name: name, Status: failOrWarn(optional), Message: fmt.Sprintf("not installed in %s", namespace)})
+		case !controller.Ready:
+			results = append(results, CheckResult{Name: name, Status: CheckFail, Message: fmt.Sprintf("%s is not ready in %s", controller.Version, namespace)})
+		default:
+			results = append(results, CheckResult{Name: name, Status: CheckPass, Message: fmt.Sprintf("%s is ready in %s", controller.Version, namespace)})
+		}
+	}
+
+	for _, name := range requiredFluxControllers {
+		check(name, false)
+	}
+
+	for _, name := range optionalFluxControllers {
+		check(name, true)
+	}
+
+	return results
+}
+
+// checkGitHost checks a git host accepts connections, on ssh and https when no port is given
+func checkGitHost(host string) CheckResult {
+	addresses := []string{host}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		addresses = []string{net.JoinHostPort(host, "22"), net.JoinHostPort(host, "443")}
+	}
+
+	unreachable := []string{}
+
+	for _, address := range addresses {
+		conn, err := net.DialTimeout("tcp", address, gitHostTimeout)
+		if err != nil {
+			unreachable = append(unreachable, address)
+			continue
+		}
+
+		conn.Close()
+	}
+
+	switch {
+	case len(unreachable) == len(addresses):
+		return CheckResult{Name: host, Status: CheckFail, Message: fmt.Sprintf("could not connect to %s", strings.Join(unreachable, ", "))}
+	case len(unreachable) > 0:
+		return CheckResult{Name: host, Status: CheckWarn, Message: fmt.Sprintf("could not connect to %s", strings.Join(unreachable, ", "))}
+	default:
+		return CheckResult{Name: host, Status: CheckPass, Message: "reachable"}
+	}
+}
+
+func failOrWarn(optional bool) CheckStatus {
+	if optional {
+		return CheckWarn
+	}
+
+	return CheckFail
+}
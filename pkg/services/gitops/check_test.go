@@ -0,0 +1,207 @@
+package gitops_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/flux/fluxfakes"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/services/gitops"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	extensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Check", func() {
+	var (
+		checkParams   gitops.CheckParams
+		installedCRDs map[string]string
+		controllers   []unstructured.Unstructured
+		listener      net.Listener
+	)
+
+	readyDeployment := func(name string) unstructured.Unstructured {
+		deployment := fluxDeployment(name, "ghcr.io/fluxcd/"+name, "v0.16.0")
+		Expect(unstructured.SetNestedField(deployment.Object, int64(1), "status", "readyReplicas")).To(Succeed())
+
+		return deployment
+	}
+
+	results := func(status gitops.CheckStatus) map[string]string {
+		found := map[string]string{}
+
+		for _, result := range gitopsSrv.Check(checkParams) {
+			if result.Status == status {
+				found[result.Name] = result.Message
+			}
+		}
+
+		return found
+	}
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ShouldNot(HaveOccurred())
+
+		installedCRDs = map[string]string{
+			"apps.wego.weave.works":                          "v1alpha1",
+			"gitrepositories.source.toolkit.fluxcd.io":       "v1beta1",
+			"helmrepositories.source.toolkit.fluxcd.io":      "v1beta1",
+			"buckets.source.toolkit.fluxcd.io":               "v1beta1",
+			"kustomizations.kustomize.toolkit.fluxcd.io":     "v1beta1",
+			"helmreleases.helm.toolkit.fluxcd.io":            "v2beta1",
+			"providers.notification.toolkit.fluxcd.io":       "v1beta1",
+			"alerts.notification.toolkit.fluxcd.io":          "v1beta1",
+			"imagerepositories.image.toolkit.fluxcd.io":      "v1alpha2",
+			"imagepolicies.image.toolkit.fluxcd.io":          "v1alpha2",
+			"imageupdateautomations.image.toolkit.fluxcd.io": "v1alpha2",
+		}
+
+		controllers = []unstructured.Unstructured{}
+		for _, name := range []string{"source-controller", "kustomize-controller", "helm-controller", "notification-controller",
+			"image-reflector-controller", "image-automation-controller"} {
+			controllers = append(controllers, readyDeployment(name))
+		}
+
+		fluxClient = &fluxfakes.FakeFlux{}
+		kubeClient = &kubefakes.FakeKube{
+			GetServerVersionStub: func(ctx context.Context) (string, error) {
+				return "v1.21.1", nil
+			},
+			CanIStub: func(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+				return true, nil
+			},
+			GetResourceStub: func(ctx context.Context, name types.NamespacedName, resource kube.Resource) error {
+				if version, ok := installedCRDs[name.Name]; ok {
+					crd := resource.(*extensionsv1.CustomResourceDefinition)
+					crd.Name = name.Name
+					crd.Spec.Versions = []extensionsv1.CustomResourceDefinitionVersion{{Name: version, Served: true}}
+				}
+
+				return nil
+			},
+			ListResourcesStub: func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+				if namespace == "wego-system" {
+					return controllers, nil
+				}
+
+				return nil, nil
+			},
+		}
+		gitopsSrv = gitops.New(logger.New(os.Stderr), fluxClient, kubeClient)
+
+		checkParams = gitops.CheckParams{
+			Namespace:     "wego-system",
+			FluxNamespace: "flux-system",
+			GitHosts:      []string{listener.Addr().String()},
+		}
+	})
+
+	AfterEach(func() {
+		listener.Close()
+	})
+
+	It("passes on a cluster running wego", func() {
+		Expect(results(gitops.CheckFail)).To(BeEmpty())
+		Expect(results(gitops.CheckWarn)).To(BeEmpty())
+
+		passed := results(gitops.CheckPass)
+		Expect(passed).To(HaveKeyWithValue("kubernetes", "v1.21.1 >= v1.16.0"))
+		Expect(passed).To(HaveKeyWithValue("rbac create secrets", "allowed in wego-system"))
+		Expect(passed).To(HaveKeyWithValue("rbac create customresourcedefinitions.apiextensions.k8s.io", "allowed in the cluster"))
+		Expect(passed).To(HaveKeyWithValue("kustomizations.kustomize.toolkit.fluxcd.io", "serves v1beta1"))
+		Expect(passed).To(HaveKeyWithValue("source-controller", "v0.16.0 is ready in wego-system"))
+		Expect(passed).To(HaveKeyWithValue(listener.Addr().String(), "reachable"))
+	})
+
+	It("reviews the access of the user to the wego namespace", func() {
+		results(gitops.CheckPass)
+
+		attributes := []authorizationv1.ResourceAttributes{}
+		for i := 0; i < kubeClient.CanICallCount(); i++ {
+			_, a := kubeClient.CanIArgsForCall(i)
+			attributes = append(attributes, a)
+		}
+
+		Expect(attributes).To(ContainElement(authorizationv1.ResourceAttributes{Verb: "create", Resource: "secrets", Namespace: "wego-system"}))
+		Expect(attributes).To(ContainElement(authorizationv1.ResourceAttributes{Verb: "create", Resource: "namespaces"}))
+	})
+
+	It("fails when the cluster can't be reached", func() {
+		kubeClient.GetServerVersionReturns("", errors.New("connection refused"))
+
+		Expect(results(gitops.CheckFail)).To(Equal(map[string]string{"kubernetes": "could not reach the cluster: connection refused"}))
+		Expect(kubeClient.CanICallCount()).To(Equal(0))
+	})
+
+	It("fails on an old kubernetes", func() {
+		kubeClient.GetServerVersionReturns("v1.15.12", nil)
+
+		Expect(results(gitops.CheckFail)).To(HaveKeyWithValue("kubernetes", "v1.15.12 < v1.16.0"))
+	})
+
+	It("fails without the permissions wego needs", func() {
+		kubeClient.CanIStub = func(ctx context.Context, attributes authorizationv1.ResourceAttributes) (bool, error) {
+			return attributes.Resource != "secrets", nil
+		}
+
+		Expect(results(gitops.CheckFail)).To(Equal(map[string]string{"rbac create secrets": "not allowed in wego-system"}))
+	})
+
+	It("skips the crd and controller checks before wego is installed", func() {
+		delete(installedCRDs, "apps.wego.weave.works")
+
+		Expect(results(gitops.CheckFail)).To(BeEmpty())
+		Expect(results(gitops.CheckWarn)).To(Equal(map[string]string{"crds": "Weave GitOps is not installed, skipping the CRD and controller checks"}))
+		Expect(kubeClient.ListResourcesCallCount()).To(Equal(0))
+	})
+
+	It("fails on missing crds and versions, and warns about the image ones", func() {
+		delete(installedCRDs, "buckets.source.toolkit.fluxcd.io")
+		installedCRDs["helmreleases.helm.toolkit.fluxcd.io"] = "v2beta2"
+		delete(installedCRDs, "imagepolicies.image.toolkit.fluxcd.io")
+
+		Expect(results(gitops.CheckFail)).To(Equal(map[string]string{
+			"buckets.source.toolkit.fluxcd.io":    "not installed",
+			"helmreleases.helm.toolkit.fluxcd.io": "does not serve v2beta1",
+		}))
+		Expect(results(gitops.CheckWarn)).To(Equal(map[string]string{"imagepolicies.image.toolkit.fluxcd.io": "not installed"}))
+	})
+
+	It("fails on controllers that aren't ready", func() {
+		controllers[1] = fluxDeployment("kustomize-controller", "ghcr.io/fluxcd/kustomize-controller", "v0.16.0")
+		controllers = controllers[:5]
+
+		Expect(results(gitops.CheckFail)).To(Equal(map[string]string{"kustomize-controller": "v0.16.0 is not ready in wego-system"}))
+		Expect(results(gitops.CheckWarn)).To(Equal(map[string]string{"image-automation-controller": "not installed in wego-system"}))
+	})
+
+	It("checks the controllers of the flux wego was installed alongside", func() {
+		kubeClient.ListResourcesStub = func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+			if namespace == "flux-system" {
+				return controllers, nil
+			}
+
+			return nil, nil
+		}
+
+		Expect(results(gitops.CheckFail)).To(BeEmpty())
+		Expect(results(gitops.CheckPass)).To(HaveKeyWithValue("helm-controller", "v0.16.0 is ready in flux-system"))
+	})
+
+	It("fails on git hosts that can't be reached", func() {
+		address := listener.Addr().String()
+		listener.Close()
+
+		Expect(results(gitops.CheckFail)).To(HaveKeyWithValue(address, "could not connect to "+address))
+	})
+})
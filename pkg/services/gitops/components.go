@@ -0,0 +1,208 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/weaveworks/weave-gitops/manifests"
+	"github.com/weaveworks/weave-gitops/pkg/version"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	ComponentController = "controller"
+	ComponentServer     = "server"
+	ComponentUI         = "ui"
+)
+
+// Components are the wego components installed next to flux, in the order they are applied
+var Components = []string{ComponentController, ComponentServer, ComponentUI}
+
+// DefaultImageRegistry is the registry the wego images are published to
+const DefaultImageRegistry = "ghcr.io/weaveworks"
+
+const (
+	// componentLabel is set on the objects of each component, partOfLabel on the objects of every component
+	componentLabel = "app.kubernetes.io/component"
+	partOfLabel    = "app.kubernetes.io/part-of"
+	partOfValue    = "weave-gitops"
+
+	// uiServiceName and uiPort are the service the UI is served from in the cluster
+	uiServiceName = "wego-ui"
+	uiPort        = 9001
+)
+
+var deploymentGVK = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+type component struct {
+	manifest []byte
+	// image is the name of the image of the component, published under the image registry
+	image string
+}
+
+var components = map[string]component{
+	ComponentController: {manifest: manifests.WegoController, image: "wego-controller"},
+	ComponentServer:     {manifest: manifests.WegoServer, image: "wego-server"},
+	ComponentUI:         {manifest: manifests.WegoUI, image: "wego-ui"},
+}
+
+// componentValues are the values the component manifests are rendered with
+type componentValues struct {
+	Namespace       string
+	Image           string
+	ImagePullSecret string
+}
+
+func validateComponents(names []string) error {
+	if len(names) > 0 && !componentImagesPublished() {
+		return fmt.Errorf("the wego components are only published for releases, install them with a released wego")
+	}
+
+	for _, name := range names {
+		if _, ok := components[name]; !ok {
+			return fmt.Errorf("unknown component %q, the components are %s", name, strings.Join(Components, ", "))
+		}
+	}
+
+	return nil
+}
+
+// componentImagesPublished reports whether this wego was built by a release, which publishes the images of the
+// components with its version
+func componentImagesPublished() bool {
+	return version.WegoImageTag != "undefined"
+}
+
+// componentImage returns the image of a component, pulled from a registry mirroring the default one when given
+func componentImage(name string, registry string) string {
+	if registry == "" {
+		registry = DefaultImageRegistry
+	}
+
+	return fmt.Sprintf("%s/%s:%s", registry, components[name].image, version.WegoImageTag)
+}
+
+// renderComponents renders the manifests of the selected components, in the order of Components
+func renderComponents(names []string, namespace string, registry string, imagePullSecret string) ([]byte, error) {
+	selected := map[string]bool{}
+	for _, name := range names {
+		selected[name] = true
+	}
+
+	out := []byte{}
+
+	for _, name := range Components {
+		if !selected[name] {
+			continue
+		}
+
+		tmpl, err := template.New(name).Parse(string(components[name].manifest))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse the %s manifests: %w", name, err)
+		}
+
+		buf := &bytes.Buffer{}
+
+		err = tmpl.Execute(buf, componentValues{
+			Namespace:       namespace,
+			Image:           componentImage(name, registry),
+			ImagePullSecret: imagePullSecret,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not render the %s manifests: %w", name, err)
+		}
+
+		out = append(out, buf.Bytes()...)
+	}
+
+	return out, nil
+}
+
+// installComponents applies the selected components, or returns their manifests on a dry run
+func (g *Gitops) installComponents(params InstallParams) ([]byte, error) {
+	if len(params.Components) == 0 {
+		return []byte{}, nil
+	}
+
+	componentManifests, err := renderComponents(params.Components, params.Namespace, params.Registry, params.ImagePullSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.DryRun {
+		return componentManifests, nil
+	}
+
+	g.logger.Actionf("Installing the wego %s", strings.Join(params.Components, ", "))
+
+	if out, err := g.kube.Apply(componentManifests, params.Namespace); err != nil {
+		return nil, fmt.Errorf("failed to apply the wego components: %s: %w", string(out), err)
+	}
+
+	return []byte{}, nil
+}
+
+// installedComponents lists the components installed in a namespace, from the labels of their deployments
+func (g *Gitops) installedComponents(ctx context.Context, namespace string) ([]string, error) {
+	images, err := g.installedComponentImages(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+
+	for _, name := range Components {
+		if _, ok := images[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// installedComponentImages returns the image of each component installed in a namespace
+func (g *Gitops) installedComponentImages(ctx context.Context, namespace string) (map[string]string, error) {
+	deployments, err := g.kube.ListResources(ctx, deploymentGVK, namespace, map[string]string{partOfLabel: partOfValue})
+	if err != nil {
+		return nil, fmt.Errorf("could not list the wego components: %w", err)
+	}
+
+	images := map[string]string{}
+
+	for i := range deployments {
+		if name := deployments[i].GetLabels()[componentLabel]; name != "" {
+			images[name] = deploymentImage(&deployments[i])
+		}
+	}
+
+	return images, nil
+}
+
+// uninstallComponents deletes the components installed in a namespace, their cluster roles included
+func (g *Gitops) uninstallComponents(ctx context.Context, params UinstallParams) error {
+	names, err := g.installedComponents(ctx, params.Namespace)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if params.DryRun {
+			g.logger.Actionf("Deleting the wego %s", name)
+			continue
+		}
+
+		componentManifests, err := renderComponents([]string{name}, params.Namespace, "", "")
+		if err != nil {
+			return err
+		}
+
+		if out, err := g.kube.Delete(componentManifests, params.Namespace); err != nil {
+			return fmt.Errorf("failed to delete the wego %s: %s: %w", name, string(out), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package gitops_test
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/flux/fluxfakes"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/services/gitops"
+	"github.com/weaveworks/weave-gitops/pkg/version"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Components", func() {
+	componentDeployment := func(name, component string) unstructured.Unstructured {
+		deployment := unstructured.Unstructured{}
+		deployment.SetName(name)
+		deployment.SetLabels(map[string]string{
+			"app.kubernetes.io/part-of":   "weave-gitops",
+			"app.kubernetes.io/component": component,
+		})
+
+		return deployment
+	}
+
+	BeforeEach(func() {
+		version.WegoImageTag = "0.2.0"
+
+		fluxClient = &fluxfakes.FakeFlux{}
+		kubeClient = &kubefakes.FakeKube{
+			GetClusterStatusStub: func(c context.Context) kube.ClusterStatus {
+				return kube.Unmodified
+			},
+		}
+		gitopsSrv = gitops.New(logger.New(os.Stderr), fluxClient, kubeClient)
+
+		installParams = gitops.InstallParams{
+			Namespace:  "wego-system",
+			Components: gitops.Components,
+		}
+	})
+
+	It("applies the selected components after the App CRD", func() {
+		installParams.Components = []string{gitops.ComponentUI}
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.ApplyCallCount()).To(Equal(2))
+
+		components, namespace := kubeClient.ApplyArgsForCall(1)
+		Expect(namespace).To(Equal("wego-system"))
+		Expect(string(components)).To(ContainSubstring("kind: Deployment\nmetadata:\n  name: wego-ui\n  namespace: wego-system\n"))
+		Expect(string(components)).To(ContainSubstring("image: ghcr.io/weaveworks/wego-ui:0.2.0"))
+		Expect(string(components)).To(ContainSubstring("kind: ClusterRoleBinding\nmetadata:\n  name: wego-ui-wego-system\n"))
+		Expect(string(components)).NotTo(ContainSubstring("wego-controller"))
+		Expect(string(components)).NotTo(ContainSubstring("imagePullSecrets"))
+	})
+
+	It("renders every component with the registry and image pull secret on a dry run", func() {
+		installParams.DryRun = true
+		installParams.Registry = "registry.example.com/weaveworks"
+		installParams.ImagePullSecret = "regcred"
+
+		out, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(string(out)).To(ContainSubstring("image: registry.example.com/weaveworks/wego-controller:0.2.0"))
+		Expect(string(out)).To(ContainSubstring("image: registry.example.com/weaveworks/wego-server:0.2.0"))
+		Expect(string(out)).To(ContainSubstring("image: registry.example.com/weaveworks/wego-ui:0.2.0"))
+		Expect(string(out)).To(ContainSubstring("      imagePullSecrets:\n      - name: regcred\n"))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+	})
+
+	It("installs no component when none is selected", func() {
+		installParams.Components = nil
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.ApplyCallCount()).To(Equal(1))
+	})
+
+	It("fails to install components with a build that isn't a release", func() {
+		version.WegoImageTag = "undefined"
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).To(MatchError("the wego components are only published for releases, install them with a released wego"))
+
+		Expect(fluxClient.InstallCallCount()).To(Equal(0))
+	})
+
+	It("fails on unknown components", func() {
+		installParams.Components = []string{"dashboard"}
+
+		_, err := gitopsSrv.Install(installParams)
+		Expect(err).To(MatchError(`unknown component "dashboard", the components are controller, server, ui`))
+
+		Expect(fluxClient.InstallCallCount()).To(Equal(0))
+	})
+
+	It("deletes the installed components on uninstall", func() {
+		kubeClient.GetClusterStatusStub = func(c context.Context) kube.ClusterStatus {
+			return kube.WeGOInstalled
+		}
+		kubeClient.ListResourcesStub = func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+			if labels["app.kubernetes.io/part-of"] == "weave-gitops" {
+				return []unstructured.Unstructured{componentDeployment("wego-ui", "ui"), componentDeployment("wego-controller", "controller")}, nil
+			}
+
+			return []unstructured.Unstructured{fluxDeployment("source-controller", "ghcr.io/fluxcd/source-controller:v0.15.3", "v0.16.0")}, nil
+		}
+
+		err := gitopsSrv.Uninstall(gitops.UinstallParams{Namespace: "wego-system"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.DeleteCallCount()).To(Equal(3))
+
+		controller, _ := kubeClient.DeleteArgsForCall(0)
+		Expect(string(controller)).To(ContainSubstring("name: wego-controller-wego-system"))
+
+		ui, _ := kubeClient.DeleteArgsForCall(1)
+		Expect(string(ui)).To(ContainSubstring("name: wego-ui-wego-system"))
+
+		appCRD, _ := kubeClient.DeleteArgsForCall(2)
+		Expect(string(appCRD)).To(ContainSubstring("kind: App"))
+	})
+})
@@ -2,19 +2,34 @@ package gitops
 
 import (
 	"github.com/weaveworks/weave-gitops/pkg/flux"
+	"github.com/weaveworks/weave-gitops/pkg/git"
+	"github.com/weaveworks/weave-gitops/pkg/gitproviders"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
 	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
 )
 
 type GitopsService interface {
 	Install(params InstallParams) ([]byte, error)
 	Uninstall(params UinstallParams) error
+	Upgrade(params UpgradeParams) (UpgradeSummary, []byte, error)
+	Check(params CheckParams) []CheckResult
+	Images(params ImagesParams) ([]string, error)
+	UI(params UIParams) error
+}
+
+// AppPurger removes the apps of a namespace when wego is uninstalled with --purge
+type AppPurger interface {
+	Purge(params app.PurgeParams) error
 }
 
 type Gitops struct {
-	flux   flux.Flux
-	kube   kube.Kube
-	logger logger.Logger
+	flux        flux.Flux
+	kube        kube.Kube
+	logger      logger.Logger
+	git         git.Git
+	gitProvider gitproviders.GitProvider
+	apps        AppPurger
 }
 
 func New(logger logger.Logger, flux flux.Flux, kube kube.Kube) *Gitops {
@@ -25,5 +40,20 @@ func New(logger logger.Logger, flux flux.Flux, kube kube.Kube) *Gitops {
 	}
 }
 
+// WithGit sets the clients used to commit the install to a config repository
+func (g *Gitops) WithGit(gitClient git.Git, gitProvider gitproviders.GitProvider) *Gitops {
+	g.git = gitClient
+	g.gitProvider = gitProvider
+
+	return g
+}
+
+// WithApps sets the service removing the apps on uninstall
+func (g *Gitops) WithApps(apps AppPurger) *Gitops {
+	g.apps = apps
+
+	return g
+}
+
 // Make sure App implements all the required methods.
 var _ GitopsService = &Gitops{}
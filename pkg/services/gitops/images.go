@@ -0,0 +1,71 @@
+package gitops
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/weaveworks/weave-gitops/manifests"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type ImagesParams struct {
+	Namespace string
+}
+
+// Images lists the images an install of wego pulls, to mirror them to the registry of an air-gapped cluster.
+// The cluster isn't needed, the images are read from the manifests wego installs.
+func (g *Gitops) Images(params ImagesParams) ([]string, error) {
+	fluxManifests, err := g.flux.Install(params.Namespace, "", "", true)
+	if err != nil {
+		return nil, fmt.Errorf("error exporting the flux manifests: %w", err)
+	}
+
+	componentManifests, err := renderComponents(Components, params.Namespace, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := workloadImages(append(append(fluxManifests, manifests.AppCRD...), componentManifests...))
+	if err != nil {
+		return nil, fmt.Errorf("could not read the install manifests: %w", err)
+	}
+
+	return images, nil
+}
+
+// workloadImages returns the sorted images of every container of the deployments in multi-document manifests
+func workloadImages(manifests []byte) ([]string, error) {
+	objects, err := kube.DecodeObjects(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{}
+
+	for _, object := range objects {
+		if object.GetKind() != "Deployment" {
+			continue
+		}
+
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, _, _ := unstructured.NestedSlice(object.Object, "spec", "template", "spec", field)
+
+			for _, c := range containers {
+				container, _ := c.(map[string]interface{})
+				if image, _, _ := unstructured.NestedString(container, "image"); image != "" {
+					found[image] = true
+				}
+			}
+		}
+	}
+
+	images := []string{}
+	for image := range found {
+		images = append(images, image)
+	}
+
+	sort.Strings(images)
+
+	return images, nil
+}
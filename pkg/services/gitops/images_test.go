@@ -0,0 +1,92 @@
+package gitops_test
+
+import (
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/flux/fluxfakes"
+	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/services/gitops"
+	"github.com/weaveworks/weave-gitops/pkg/version"
+)
+
+var _ = Describe("Images", func() {
+	fluxManifests := `---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: wego-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: source-controller
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: ghcr.io/fluxcd/source-controller:v0.15.3
+      containers:
+      - name: manager
+        image: ghcr.io/fluxcd/source-controller:v0.15.3
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: helm-controller
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        image: ghcr.io/fluxcd/helm-controller:v0.11.1
+`
+
+	BeforeEach(func() {
+		version.WegoImageTag = "0.2.0"
+
+		fluxClient = &fluxfakes.FakeFlux{
+			InstallStub: func(namespace, registry, imagePullSecret string, export bool) ([]byte, error) {
+				return []byte(fluxManifests), nil
+			},
+		}
+		kubeClient = &kubefakes.FakeKube{}
+		gitopsSrv = gitops.New(logger.New(os.Stderr), fluxClient, kubeClient)
+	})
+
+	It("lists the images of the install, once each", func() {
+		images, err := gitopsSrv.Images(gitops.ImagesParams{Namespace: "wego-system"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(images).To(Equal([]string{
+			"ghcr.io/fluxcd/helm-controller:v0.11.1",
+			"ghcr.io/fluxcd/source-controller:v0.15.3",
+			"ghcr.io/weaveworks/wego-controller:0.2.0",
+			"ghcr.io/weaveworks/wego-server:0.2.0",
+			"ghcr.io/weaveworks/wego-ui:0.2.0",
+		}))
+
+		namespace, registry, _, export := fluxClient.InstallArgsForCall(0)
+		Expect(namespace).To(Equal("wego-system"))
+		Expect(registry).To(BeEmpty())
+		Expect(export).To(BeTrue())
+	})
+
+	It("doesn't need the cluster", func() {
+		_, err := gitopsSrv.Images(gitops.ImagesParams{Namespace: "wego-system"})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(kubeClient.Invocations()).To(BeEmpty())
+	})
+
+	It("fails when the flux manifests can't be exported", func() {
+		fluxClient.InstallReturns(nil, errors.New("no flux binary"))
+
+		_, err := gitopsSrv.Images(gitops.ImagesParams{Namespace: "wego-system"})
+		Expect(err).To(MatchError("error exporting the flux manifests: no flux binary"))
+	})
+})
@@ -3,28 +3,89 @@ package gitops
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/weaveworks/weave-gitops/manifests"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"golang.org/x/mod/semver"
 )
 
+// MinimumFluxVersion is the oldest flux wego can be installed alongside, the first one with the
+// image update automation API wego generates
+const MinimumFluxVersion = "v0.13.0"
+
+// requiredFluxControllers are the controllers reconciling the objects wego generates for apps
+var requiredFluxControllers = []string{"source-controller", "kustomize-controller", "helm-controller", "notification-controller"}
+
+// optionalFluxControllers are the controllers only 'wego app image-policy' needs
+var optionalFluxControllers = []string{"image-reflector-controller", "image-automation-controller"}
+
 type InstallParams struct {
 	Namespace string
-	DryRun    bool
+	// FluxNamespace is the namespace of an existing flux install to adopt instead of installing flux
+	FluxNamespace string
+	// ConfigRepo is the url of a repository to commit the install to and sync the cluster from
+	ConfigRepo       string
+	Branch           string
+	GitProviderToken string
+	// DecryptionSecret is the secret of the namespace holding the sops keys the config repo sync decrypts the
+	// secrets of the apps with. It is created empty when missing.
+	DecryptionSecret string
+	// CommitAuthorName and CommitAuthorEmail are the author of the commit of the install to the config repo
+	CommitAuthorName  string
+	CommitAuthorEmail string
+	// Registry is a registry mirroring the flux images, for clusters without access to ghcr.io
+	Registry string
+	// ImagePullSecret is a secret of the namespace holding the credentials of the registry
+	ImagePullSecret string
+	// Components are the wego components to install next to flux, see Components
+	Components []string
+	DryRun     bool
 }
 
 func (g *Gitops) Install(params InstallParams) ([]byte, error) {
-	status := g.kube.GetClusterStatus(context.Background())
+	ctx := context.Background()
+	status := g.kube.GetClusterStatus(ctx)
 
-	switch status {
-	case kube.FluxInstalled:
-		return []byte{}, errors.New("Weave GitOps does not yet support installation onto a cluster that is using Flux.\nPlease uninstall flux before proceeding:\n  $ flux uninstall")
-	case kube.Unknown:
+	if status == kube.Unknown {
 		return []byte{}, errors.New("Weave GitOps cannot talk to the cluster")
 	}
 
-	fluxManifests, err := g.flux.Install(params.Namespace, params.DryRun)
+	if err := validateComponents(params.Components); err != nil {
+		return []byte{}, err
+	}
+
+	if params.ImagePullSecret != "" && !params.DryRun {
+		if err := g.checkImagePullSecret(ctx, params.Namespace, params.ImagePullSecret); err != nil {
+			return []byte{}, err
+		}
+	}
+
+	if params.ConfigRepo != "" {
+		if status == kube.FluxInstalled {
+			return []byte{}, errors.New("Weave GitOps can not be installed from a config repository onto a cluster that is using Flux")
+		}
+
+		return g.bootstrap(params)
+	}
+
+	if status != kube.WeGOInstalled && params.FluxNamespace != "" && params.FluxNamespace != params.Namespace {
+		controllers, err := kube.GetFluxControllers(ctx, g.kube, params.FluxNamespace)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		if len(controllers) > 0 {
+			return g.installAlongsideFlux(params, controllers)
+		}
+	}
+
+	if status == kube.FluxInstalled {
+		return []byte{}, fmt.Errorf("Weave GitOps could not find the flux controllers in %s.\nSet the namespace flux is installed in with --flux-namespace", params.FluxNamespace)
+	}
+
+	fluxManifests, err := g.flux.Install(params.Namespace, params.Registry, params.ImagePullSecret, params.DryRun)
 	if err != nil {
 		return fluxManifests, fmt.Errorf("error on flux install %s", err)
 	}
@@ -37,5 +98,105 @@ func (g *Gitops) Install(params InstallParams) ([]byte, error) {
 		}
 	}
 
-	return fluxManifests, nil
+	componentManifests, err := g.installComponents(params)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return append(fluxManifests, componentManifests...), nil
+}
+
+// installAlongsideFlux installs the App CRD and the wego namespace next to a flux wego doesn't manage
+func (g *Gitops) installAlongsideFlux(params InstallParams, controllers []kube.FluxController) ([]byte, error) {
+	if err := checkFluxCompatibility(params, controllers); err != nil {
+		return []byte{}, err
+	}
+
+	g.logger.Successf("Using the flux %s installed in %s", controllers[0].Version, params.FluxNamespace)
+
+	if params.Registry != "" {
+		g.logger.Warningf("Flux is not installed by wego, ignoring the registry %s", params.Registry)
+	}
+
+	for _, name := range optionalFluxControllers {
+		if !hasFluxController(controllers, name) {
+			g.logger.Warningf("The flux installed in %s has no %s, 'wego app image-policy' needs it", params.FluxNamespace, name)
+		}
+	}
+
+	namespace := []byte(fmt.Sprintf("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: %s\n", params.Namespace))
+	wegoManifests := append(append(namespace, []byte("---\n")...), manifests.AppCRD...)
+
+	if !params.DryRun {
+		if out, err := g.kube.Apply(wegoManifests, params.Namespace); err != nil {
+			return []byte{}, errors.Wrapf(err, "failed to apply App spec CR: %s", string(out))
+		}
+
+		wegoManifests = []byte{}
+	}
+
+	componentManifests, err := g.installComponents(params)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return append(wegoManifests, componentManifests...), nil
+}
+
+// checkFluxCompatibility checks that an existing flux has the controllers and API versions wego uses, and reconciles
+// the objects of the wego namespace
+func checkFluxCompatibility(params InstallParams, controllers []kube.FluxController) error {
+	missing := []string{}
+
+	for _, name := range requiredFluxControllers {
+		controller, ok := findFluxController(controllers, name)
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+
+		if !semver.IsValid(controller.Version) || semver.Compare(controller.Version, MinimumFluxVersion) < 0 {
+			return fmt.Errorf("the flux installed in %s is version %q, Weave GitOps needs flux %s or later", params.FluxNamespace, controller.Version, MinimumFluxVersion)
+		}
+
+		if !controller.WatchAllNamespaces {
+			return fmt.Errorf("the %s installed in %s only reconciles its own namespace, Weave GitOps needs flux to watch all namespaces to reconcile the apps in %s",
+				name, params.FluxNamespace, params.Namespace)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("the flux installed in %s is missing the %s, which Weave GitOps needs", params.FluxNamespace, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// checkImagePullSecret checks the secret flux pulls its images with exists, as flux doesn't create it
+func (g *Gitops) checkImagePullSecret(ctx context.Context, namespace string, name string) error {
+	present, err := g.kube.SecretPresent(ctx, name, namespace)
+	if err != nil {
+		return fmt.Errorf("could not check the image pull secret: %w", err)
+	}
+
+	if !present {
+		return fmt.Errorf("the image pull secret %s was not found in %s, create the namespace and the secret before installing", name, namespace)
+	}
+
+	return nil
+}
+
+func findFluxController(controllers []kube.FluxController, name string) (kube.FluxController, bool) {
+	for _, controller := range controllers {
+		if controller.Name == name {
+			return controller, true
+		}
+	}
+
+	return kube.FluxController{}, false
+}
+
+func hasFluxController(controllers []kube.FluxController, name string) bool {
+	_, ok := findFluxController(controllers, name)
+	return ok
 }
@@ -11,6 +11,8 @@ import (
 	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
 	"github.com/weaveworks/weave-gitops/pkg/logger"
 	"github.com/weaveworks/weave-gitops/pkg/services/gitops"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var installParams gitops.InstallParams
@@ -26,8 +28,9 @@ var _ = Describe("Install", func() {
 		gitopsSrv = gitops.New(logger.New(os.Stderr), fluxClient, kubeClient)
 
 		installParams = gitops.InstallParams{
-			Namespace: "wego-system",
-			DryRun:    false,
+			Namespace:     "wego-system",
+			FluxNamespace: "flux-system",
+			DryRun:        false,
 		}
 	})
 
@@ -36,7 +39,7 @@ var _ = Describe("Install", func() {
 			return kube.FluxInstalled
 		}
 		_, err := gitopsSrv.Install(installParams)
-		Expect(err).Should(MatchError("Weave GitOps does not yet support installation onto a cluster that is using Flux.\nPlease uninstall flux before proceeding:\n  $ flux uninstall"))
+		Expect(err).Should(MatchError("Weave GitOps could not find the flux controllers in flux-system.\nSet the namespace flux is installed in with --flux-namespace"))
 
 		kubeClient.GetClusterStatusStub = func(c context.Context) kube.ClusterStatus {
 			return kube.Unknown
@@ -51,7 +54,7 @@ var _ = Describe("Install", func() {
 
 		Expect(fluxClient.InstallCallCount()).To(Equal(1))
 
-		namespace, dryRun := fluxClient.InstallArgsForCall(0)
+		namespace, _, _, dryRun := fluxClient.InstallArgsForCall(0)
 		Expect(namespace).To(Equal("wego-system"))
 		Expect(dryRun).To(Equal(false))
 	})
@@ -67,10 +70,49 @@ var _ = Describe("Install", func() {
 		Expect(namespace).To(Equal("wego-system"))
 	})
 
+	Context("when installing from a registry", func() {
+		BeforeEach(func() {
+			installParams.Registry = "registry.example.com/fluxcd"
+			installParams.ImagePullSecret = "regcred"
+			kubeClient.SecretPresentReturns(true, nil)
+		})
+
+		It("pulls the flux images from the registry", func() {
+			_, err := gitopsSrv.Install(installParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, registry, imagePullSecret, _ := fluxClient.InstallArgsForCall(0)
+			Expect(registry).To(Equal("registry.example.com/fluxcd"))
+			Expect(imagePullSecret).To(Equal("regcred"))
+
+			_, secretName, namespace := kubeClient.SecretPresentArgsForCall(0)
+			Expect(secretName).To(Equal("regcred"))
+			Expect(namespace).To(Equal("wego-system"))
+		})
+
+		It("fails without the image pull secret", func() {
+			kubeClient.SecretPresentReturns(false, nil)
+
+			_, err := gitopsSrv.Install(installParams)
+			Expect(err).To(MatchError("the image pull secret regcred was not found in wego-system, create the namespace and the secret before installing"))
+
+			Expect(fluxClient.InstallCallCount()).To(Equal(0))
+		})
+
+		It("doesn't look for the image pull secret on a dry run", func() {
+			installParams.DryRun = true
+
+			_, err := gitopsSrv.Install(installParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(kubeClient.SecretPresentCallCount()).To(Equal(0))
+		})
+	})
+
 	Context("when dry-run", func() {
 		BeforeEach(func() {
 			installParams.DryRun = true
-			fluxClient.InstallStub = func(s string, b bool) ([]byte, error) {
+			fluxClient.InstallStub = func(s, r, p string, b bool) ([]byte, error) {
 				return []byte("manifests"), nil
 			}
 		})
@@ -82,7 +124,7 @@ var _ = Describe("Install", func() {
 
 			Expect(fluxClient.InstallCallCount()).To(Equal(1))
 
-			namespace, dryRun := fluxClient.InstallArgsForCall(0)
+			namespace, _, _, dryRun := fluxClient.InstallArgsForCall(0)
 			Expect(namespace).To(Equal("wego-system"))
 			Expect(dryRun).To(Equal(true))
 		})
@@ -101,4 +143,64 @@ var _ = Describe("Install", func() {
 			Expect(kubeClient.ApplyCallCount()).To(Equal(0))
 		})
 	})
+
+	Context("when flux is already installed", func() {
+		var controllers []unstructured.Unstructured
+
+		BeforeEach(func() {
+			kubeClient.GetClusterStatusStub = func(c context.Context) kube.ClusterStatus {
+				return kube.FluxInstalled
+			}
+
+			controllers = []unstructured.Unstructured{}
+			for _, name := range []string{"source-controller", "kustomize-controller", "helm-controller", "notification-controller"} {
+				controllers = append(controllers, fluxDeployment(name, "ghcr.io/fluxcd/"+name, "v0.16.0"))
+			}
+
+			kubeClient.ListResourcesStub = func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+				return controllers, nil
+			}
+		})
+
+		It("installs the wego namespace and the app crd alongside it", func() {
+			_, err := gitopsSrv.Install(installParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, _, namespace, labels := kubeClient.ListResourcesArgsForCall(0)
+			Expect(namespace).To(Equal("flux-system"))
+			Expect(labels).To(Equal(map[string]string{"app.kubernetes.io/instance": "flux-system"}))
+
+			Expect(fluxClient.InstallCallCount()).To(Equal(0))
+			Expect(kubeClient.ApplyCallCount()).To(Equal(1))
+
+			manifests, _ := kubeClient.ApplyArgsForCall(0)
+			Expect(string(manifests)).To(HavePrefix("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: wego-system\n---\n"))
+			Expect(string(manifests)).To(ContainSubstring("kind: App"))
+		})
+
+		It("fails for an old flux", func() {
+			controllers[0].SetLabels(map[string]string{"app.kubernetes.io/version": "v0.12.3"})
+
+			_, err := gitopsSrv.Install(installParams)
+			Expect(err).To(MatchError(`the flux installed in flux-system is version "v0.12.3", Weave GitOps needs flux v0.13.0 or later`))
+			Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+		})
+
+		It("fails without the controllers wego uses", func() {
+			controllers = controllers[:2]
+
+			_, err := gitopsSrv.Install(installParams)
+			Expect(err).To(MatchError("the flux installed in flux-system is missing the helm-controller, notification-controller, which Weave GitOps needs"))
+		})
+
+		It("fails when flux only watches its own namespace", func() {
+			Expect(unstructured.SetNestedSlice(controllers[1].Object, []interface{}{map[string]interface{}{
+				"image": "ghcr.io/fluxcd/kustomize-controller",
+				"args":  []interface{}{"--watch-all-namespaces=false"},
+			}}, "spec", "template", "spec", "containers")).To(Succeed())
+
+			_, err := gitopsSrv.Install(installParams)
+			Expect(err).To(MatchError("the kustomize-controller installed in flux-system only reconciles its own namespace, Weave GitOps needs flux to watch all namespaces to reconcile the apps in wego-system"))
+		})
+	})
 })
@@ -0,0 +1,33 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type UIParams struct {
+	Namespace string
+	// Port is the local port the UI is served on
+	Port int
+}
+
+// UI serves the UI installed in the cluster on a local port, until interrupted
+func (g *Gitops) UI(params UIParams) error {
+	ctx := context.Background()
+
+	service := &corev1.Service{}
+	if err := g.kube.GetResource(ctx, types.NamespacedName{Name: uiServiceName, Namespace: params.Namespace}, service); err != nil {
+		return fmt.Errorf("could not get the UI service: %w", err)
+	}
+
+	if service.Name == "" {
+		return fmt.Errorf("the UI is not installed in %s, install it with:\n  $ wego gitops install --components ui", params.Namespace)
+	}
+
+	g.logger.Successf("Serving the UI on http://localhost:%d, press Ctrl-C to stop", params.Port)
+
+	return g.kube.PortForward(ctx, params.Namespace, uiServiceName, params.Port, uiPort)
+}
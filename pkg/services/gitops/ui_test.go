@@ -0,0 +1,56 @@
+package gitops_test
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/pkg/flux/fluxfakes"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/services/gitops"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("UI", func() {
+	uiParams := gitops.UIParams{Namespace: "wego-system", Port: 9000}
+
+	BeforeEach(func() {
+		fluxClient = &fluxfakes.FakeFlux{}
+		kubeClient = &kubefakes.FakeKube{
+			GetResourceStub: func(ctx context.Context, name types.NamespacedName, resource kube.Resource) error {
+				service := resource.(*corev1.Service)
+				service.Name = name.Name
+
+				return nil
+			},
+		}
+		gitopsSrv = gitops.New(logger.New(os.Stderr), fluxClient, kubeClient)
+	})
+
+	It("forwards the local port to the ui service", func() {
+		err := gitopsSrv.UI(uiParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		_, name, _ := kubeClient.GetResourceArgsForCall(0)
+		Expect(name).To(Equal(types.NamespacedName{Name: "wego-ui", Namespace: "wego-system"}))
+
+		_, namespace, service, localPort, remotePort := kubeClient.PortForwardArgsForCall(0)
+		Expect(namespace).To(Equal("wego-system"))
+		Expect(service).To(Equal("wego-ui"))
+		Expect(localPort).To(Equal(9000))
+		Expect(remotePort).To(Equal(9001))
+	})
+
+	It("fails when the ui isn't installed", func() {
+		kubeClient.GetResourceReturns(nil)
+
+		err := gitopsSrv.UI(uiParams)
+		Expect(err).To(MatchError("the UI is not installed in wego-system, install it with:\n  $ wego gitops install --components ui"))
+
+		Expect(kubeClient.PortForwardCallCount()).To(Equal(0))
+	})
+})
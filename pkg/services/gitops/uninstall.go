@@ -7,21 +7,60 @@ import (
 	"github.com/pkg/errors"
 	"github.com/weaveworks/weave-gitops/manifests"
 	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
 )
 
 type UinstallParams struct {
 	Namespace string
-	DryRun    bool
+	// Purge removes the apps, their deploy keys and, with RemoveAutomation, their automation before flux
+	Purge            bool
+	RemoveAutomation bool
+	GitProviderToken string
+	// GitAuth is the auth the automation is read with, see app.PurgeParams
+	GitAuth app.GitAuthType
+	DryRun  bool
 }
 
 func (g *Gitops) Uninstall(params UinstallParams) error {
-	if g.kube.GetClusterStatus(context.Background()) != kube.WeGOInstalled {
+	ctx := context.Background()
+
+	if g.kube.GetClusterStatus(ctx) != kube.WeGOInstalled {
 		return fmt.Errorf("Wego is not installed... exiting")
 	}
 
-	err := g.flux.Uninstall(params.Namespace, params.DryRun)
+	if params.Purge {
+		g.logger.Actionf("Removing the applications in %s", params.Namespace)
+
+		err := g.apps.Purge(app.PurgeParams{
+			Namespace:        params.Namespace,
+			GitProviderToken: params.GitProviderToken,
+			GitAuth:          params.GitAuth,
+			RemoveAutomation: params.RemoveAutomation,
+			CommitMessage:    fmt.Sprintf("wego gitops uninstall --purge in %s", params.Namespace),
+			DryRun:           params.DryRun,
+		})
+		if err != nil {
+			return fmt.Errorf("could not remove the applications: %w", err)
+		}
+	}
+
+	if err := g.uninstallComponents(ctx, params); err != nil {
+		return err
+	}
+
+	controllers, err := kube.GetFluxControllers(ctx, g.kube, params.Namespace)
 	if err != nil {
-		return fmt.Errorf("error on flux install %s", err)
+		return err
+	}
+
+	// A flux wego was installed alongside belongs to the user
+	if len(controllers) == 0 {
+		g.logger.Actionf("Leaving flux as is, it is managed outside of wego")
+	} else {
+		err := g.flux.Uninstall(params.Namespace, params.DryRun)
+		if err != nil {
+			return fmt.Errorf("error on flux install %s", err)
+		}
 	}
 
 	if params.DryRun {
@@ -2,6 +2,7 @@ package gitops_test
 
 import (
 	"context"
+	"errors"
 	"os"
 
 	. "github.com/onsi/ginkgo"
@@ -10,11 +11,29 @@ import (
 	"github.com/weaveworks/weave-gitops/pkg/kube"
 	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
 	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/services/app"
 	"github.com/weaveworks/weave-gitops/pkg/services/gitops"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var uninstallParams gitops.UinstallParams
 
+type fakePurger struct {
+	params []app.PurgeParams
+	err    error
+	// fluxUninstalled records whether flux was already uninstalled when the apps were purged
+	fluxUninstalled bool
+	flux            *fluxfakes.FakeFlux
+}
+
+func (p *fakePurger) Purge(params app.PurgeParams) error {
+	p.params = append(p.params, params)
+	p.fluxUninstalled = p.flux.UninstallCallCount() > 0
+
+	return p.err
+}
+
 var _ = Describe("Uninstall", func() {
 	BeforeEach(func() {
 		fluxClient = &fluxfakes.FakeFlux{}
@@ -22,6 +41,9 @@ var _ = Describe("Uninstall", func() {
 			GetClusterStatusStub: func(ctx context.Context) kube.ClusterStatus {
 				return kube.WeGOInstalled
 			},
+			ListResourcesStub: func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+				return []unstructured.Unstructured{fluxDeployment("source-controller", "ghcr.io/fluxcd/source-controller:v0.15.3", "v0.16.0")}, nil
+			},
 		}
 		gitopsSrv = gitops.New(logger.New(os.Stderr), fluxClient, kubeClient)
 
@@ -57,6 +79,16 @@ var _ = Describe("Uninstall", func() {
 		Expect(dryRun).To(Equal(false))
 	})
 
+	It("leaves a flux wego was installed alongside", func() {
+		kubeClient.ListResourcesReturns(nil, nil)
+
+		err := gitopsSrv.Uninstall(uninstallParams)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(fluxClient.UninstallCallCount()).To(Equal(0))
+		Expect(kubeClient.DeleteCallCount()).To(Equal(1))
+	})
+
 	It("deletes app crd", func() {
 		err := gitopsSrv.Uninstall(uninstallParams)
 		Expect(err).ShouldNot(HaveOccurred())
@@ -68,6 +100,52 @@ var _ = Describe("Uninstall", func() {
 		Expect(namespace).To(Equal("wego-system"))
 	})
 
+	Context("when purging", func() {
+		var purger *fakePurger
+
+		BeforeEach(func() {
+			purger = &fakePurger{flux: fluxClient}
+			gitopsSrv = gitops.New(logger.New(os.Stderr), fluxClient, kubeClient).WithApps(purger)
+
+			uninstallParams.Purge = true
+			uninstallParams.RemoveAutomation = true
+			uninstallParams.GitProviderToken = "token"
+		})
+
+		It("removes the applications before flux", func() {
+			err := gitopsSrv.Uninstall(uninstallParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(purger.params).To(Equal([]app.PurgeParams{{
+				Namespace:        "wego-system",
+				GitProviderToken: "token",
+				RemoveAutomation: true,
+				CommitMessage:    "wego gitops uninstall --purge in wego-system",
+			}}))
+			Expect(purger.fluxUninstalled).To(BeFalse())
+			Expect(fluxClient.UninstallCallCount()).To(Equal(1))
+		})
+
+		It("stops when the applications can't be removed", func() {
+			purger.err = errors.New("forbidden")
+
+			err := gitopsSrv.Uninstall(uninstallParams)
+			Expect(err).To(MatchError("could not remove the applications: forbidden"))
+
+			Expect(fluxClient.UninstallCallCount()).To(Equal(0))
+			Expect(kubeClient.DeleteCallCount()).To(Equal(0))
+		})
+
+		It("doesn't remove the applications without purge", func() {
+			uninstallParams.Purge = false
+
+			err := gitopsSrv.Uninstall(uninstallParams)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(purger.params).To(BeEmpty())
+		})
+	})
+
 	Context("when dry-run", func() {
 		BeforeEach(func() {
 			uninstallParams.DryRun = true
@@ -0,0 +1,314 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/weaveworks/weave-gitops/manifests"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/version"
+	extensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+type UpgradeParams struct {
+	Namespace string
+	// Registry and ImagePullSecret are the ones of an air-gapped install, see InstallParams
+	Registry        string
+	ImagePullSecret string
+	DryRun          bool
+}
+
+// ComponentChange is a flux controller or wego component whose image differs between the cluster and this wego.
+// Images are empty for controllers missing from the cluster.
+type ComponentChange struct {
+	Name         string
+	CurrentImage string
+	NewImage     string
+}
+
+// UpgradeSummary lists what an upgrade changes in the cluster
+type UpgradeSummary struct {
+	CurrentFluxVersion string
+	NewFluxVersion     string
+	Components         []ComponentChange
+	// WegoComponents are the installed wego components whose image differs from the one of this wego. Components
+	// that aren't installed are left out, install them with wego gitops install.
+	WegoComponents []ComponentChange
+	// CRDChanged reports whether the App CRD in the cluster differs from the embedded one
+	CRDChanged bool
+	// ExternalFlux is set when wego was installed alongside a flux it doesn't manage, which is left as is
+	ExternalFlux bool
+}
+
+// UpToDate reports whether the upgrade has nothing to change
+func (s UpgradeSummary) UpToDate() bool {
+	return s.CurrentFluxVersion == s.NewFluxVersion && len(s.Components) == 0 && len(s.WegoComponents) == 0 && !s.CRDChanged
+}
+
+// Upgrade re-installs the embedded flux version, the App CRD and the installed wego components over an existing
+// wego install. They are applied in place, so the apps and their automation are left untouched. A flux wego was
+// installed alongside is not upgraded.
+func (g *Gitops) Upgrade(params UpgradeParams) (UpgradeSummary, []byte, error) {
+	ctx := context.Background()
+
+	if g.kube.GetClusterStatus(ctx) != kube.WeGOInstalled {
+		return UpgradeSummary{}, nil, fmt.Errorf("Weave GitOps is not installed, install it with:\n  $ wego gitops install")
+	}
+
+	controllers, err := kube.GetFluxControllers(ctx, g.kube, params.Namespace)
+	if err != nil {
+		return UpgradeSummary{}, nil, err
+	}
+
+	fluxManifests := []byte{}
+	summary := UpgradeSummary{ExternalFlux: len(controllers) == 0}
+
+	if !summary.ExternalFlux {
+		if fluxManifests, err = g.flux.Install(params.Namespace, params.Registry, params.ImagePullSecret, true); err != nil {
+			return UpgradeSummary{}, nil, fmt.Errorf("error exporting the flux manifests: %w", err)
+		}
+
+		if summary, err = fluxUpgradeSummary(controllers, fluxManifests); err != nil {
+			return UpgradeSummary{}, nil, err
+		}
+	}
+
+	if summary.CRDChanged, err = g.appCRDChanged(ctx); err != nil {
+		return UpgradeSummary{}, nil, err
+	}
+
+	if summary.WegoComponents, err = g.wegoComponentChanges(ctx, params); err != nil {
+		return UpgradeSummary{}, nil, err
+	}
+
+	componentManifests, err := renderComponents(changedComponents(summary.WegoComponents), params.Namespace, params.Registry, params.ImagePullSecret)
+	if err != nil {
+		return UpgradeSummary{}, nil, err
+	}
+
+	g.printUpgradeSummary(summary)
+
+	if summary.UpToDate() {
+		return summary, nil, nil
+	}
+
+	if params.DryRun {
+		return summary, append(append(fluxManifests, manifests.AppCRD...), componentManifests...), nil
+	}
+
+	if !summary.ExternalFlux {
+		g.logger.Actionf("Upgrading flux to %s", summary.NewFluxVersion)
+
+		if _, err := g.flux.Install(params.Namespace, params.Registry, params.ImagePullSecret, false); err != nil {
+			return summary, nil, fmt.Errorf("error on flux install: %w", err)
+		}
+	}
+
+	g.logger.Actionf("Upgrading the App CRD")
+
+	if out, err := g.kube.Apply(manifests.AppCRD, params.Namespace); err != nil {
+		return summary, nil, fmt.Errorf("failed to apply App CRD: %s: %w", string(out), err)
+	}
+
+	if len(summary.WegoComponents) > 0 {
+		g.logger.Actionf("Upgrading the wego %s", strings.Join(changedComponents(summary.WegoComponents), ", "))
+
+		if out, err := g.kube.Apply(componentManifests, params.Namespace); err != nil {
+			return summary, nil, fmt.Errorf("failed to apply the wego components: %s: %w", string(out), err)
+		}
+	}
+
+	return summary, nil, nil
+}
+
+func (g *Gitops) printUpgradeSummary(summary UpgradeSummary) {
+	if summary.UpToDate() {
+		g.logger.Successf("Weave GitOps is up to date")
+		return
+	}
+
+	if summary.ExternalFlux {
+		g.logger.Println("Flux: managed outside of wego, not upgraded")
+	} else {
+		g.printFluxUpgradeSummary(summary)
+	}
+
+	if summary.CRDChanged {
+		g.logger.Println("App CRD: updated")
+	} else {
+		g.logger.Println("App CRD: up to date")
+	}
+
+	for _, component := range summary.WegoComponents {
+		g.logger.Println("Wego %s: %s -> %s", component.Name, component.CurrentImage, component.NewImage)
+	}
+}
+
+func (g *Gitops) printFluxUpgradeSummary(summary UpgradeSummary) {
+	current := summary.CurrentFluxVersion
+	if current == "" {
+		current = "unknown"
+	}
+
+	g.logger.Println("Flux: %s -> %s", current, summary.NewFluxVersion)
+
+	for _, component := range summary.Components {
+		currentImage := component.CurrentImage
+		if currentImage == "" {
+			currentImage = "not installed"
+		}
+
+		g.logger.Println("  %s: %s -> %s", component.Name, currentImage, component.NewImage)
+	}
+}
+
+// fluxUpgradeSummary compares the controllers installed by wego with the ones in the embedded flux manifests
+func fluxUpgradeSummary(controllers []kube.FluxController, fluxManifests []byte) (UpgradeSummary, error) {
+	summary := UpgradeSummary{NewFluxVersion: normalizeFluxVersion(version.FluxVersion)}
+
+	currentImages := map[string]string{}
+
+	for _, controller := range controllers {
+		currentImages[controller.Name] = controller.Image
+
+		if controller.Version != "" {
+			summary.CurrentFluxVersion = normalizeFluxVersion(controller.Version)
+		}
+	}
+
+	newImages, err := deploymentImages(fluxManifests)
+	if err != nil {
+		return summary, fmt.Errorf("could not read the flux manifests: %w", err)
+	}
+
+	names := []string{}
+	for name := range newImages {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if currentImages[name] != newImages[name] {
+			summary.Components = append(summary.Components, ComponentChange{Name: name, CurrentImage: currentImages[name], NewImage: newImages[name]})
+		}
+	}
+
+	return summary, nil
+}
+
+// wegoComponentChanges compares the images of the installed wego components with the ones of this wego. Builds
+// other than releases have no published images to upgrade to, and leave the components as they are.
+func (g *Gitops) wegoComponentChanges(ctx context.Context, params UpgradeParams) ([]ComponentChange, error) {
+	currentImages, err := g.installedComponentImages(ctx, params.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(currentImages) > 0 && !componentImagesPublished() {
+		g.logger.Warningf("The wego components are only published for releases, upgrade them with a released wego")
+		return nil, nil
+	}
+
+	var changes []ComponentChange
+
+	for _, name := range Components {
+		currentImage, ok := currentImages[name]
+		if !ok {
+			continue
+		}
+
+		if newImage := componentImage(name, params.Registry); currentImage != newImage {
+			changes = append(changes, ComponentChange{Name: name, CurrentImage: currentImage, NewImage: newImage})
+		}
+	}
+
+	return changes, nil
+}
+
+// changedComponents returns the names of changed components
+func changedComponents(changes []ComponentChange) []string {
+	names := []string{}
+	for _, change := range changes {
+		names = append(names, change.Name)
+	}
+
+	return names
+}
+
+// normalizeFluxVersion prefixes a flux version with v, as in the version label of the controllers. Flux versions
+// are embedded at build time without it.
+func normalizeFluxVersion(fluxVersion string) string {
+	return "v" + strings.TrimPrefix(fluxVersion, "v")
+}
+
+// appCRDChanged compares the spec of the App CRD in the cluster with the embedded one, the metadata and status
+// being set by the cluster. Upgrades can't drop a version apps are still stored in.
+func (g *Gitops) appCRDChanged(ctx context.Context) (bool, error) {
+	current := &extensionsv1.CustomResourceDefinition{}
+	if err := g.kube.GetResource(ctx, types.NamespacedName{Name: kube.WeGOCRDName}, current); err != nil {
+		return false, fmt.Errorf("could not get the App CRD: %w", err)
+	}
+
+	embedded := &extensionsv1.CustomResourceDefinition{}
+	if err := yaml.Unmarshal(manifests.AppCRD, embedded); err != nil {
+		return false, fmt.Errorf("could not read the embedded App CRD: %w", err)
+	}
+
+	served := map[string]bool{}
+	for _, v := range embedded.Spec.Versions {
+		served[v.Name] = true
+	}
+
+	for _, stored := range current.Status.StoredVersions {
+		if !served[stored] {
+			return false, fmt.Errorf("the App CRD no longer has version %s, which apps in the cluster are stored in", stored)
+		}
+	}
+
+	// The API server defaults the conversion of CRDs without one
+	if embedded.Spec.Conversion == nil {
+		embedded.Spec.Conversion = &extensionsv1.CustomResourceConversion{Strategy: extensionsv1.NoneConverter}
+	}
+
+	return !equality.Semantic.DeepEqual(current.Spec, embedded.Spec), nil
+}
+
+// deploymentImages returns the image of the first container of each deployment in multi-document manifests
+func deploymentImages(manifests []byte) (map[string]string, error) {
+	images := map[string]string{}
+
+	objects, err := kube.DecodeObjects(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, object := range objects {
+		if object.GetKind() != "Deployment" {
+			continue
+		}
+
+		images[object.GetName()] = deploymentImage(object)
+	}
+
+	return images, nil
+}
+
+// deploymentImage returns the image of the first container of a deployment
+func deploymentImage(deployment *unstructured.Unstructured) string {
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	if len(containers) == 0 {
+		return ""
+	}
+
+	container, _ := containers[0].(map[string]interface{})
+	image, _, _ := unstructured.NestedString(container, "image")
+
+	return image
+}
@@ -0,0 +1,270 @@
+package gitops_test
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/weaveworks/weave-gitops/manifests"
+	"github.com/weaveworks/weave-gitops/pkg/flux/fluxfakes"
+	"github.com/weaveworks/weave-gitops/pkg/kube"
+	"github.com/weaveworks/weave-gitops/pkg/kube/kubefakes"
+	"github.com/weaveworks/weave-gitops/pkg/logger"
+	"github.com/weaveworks/weave-gitops/pkg/services/gitops"
+	"github.com/weaveworks/weave-gitops/pkg/version"
+	extensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+const exportedFlux = `---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: wego-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: source-controller
+spec:
+  template:
+    spec:
+      containers:
+      - image: ghcr.io/fluxcd/source-controller:v0.15.3
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: image-reflector-controller
+spec:
+  template:
+    spec:
+      containers:
+      - image: ghcr.io/fluxcd/image-reflector-controller:v0.11.0
+`
+
+func fluxDeployment(name, image, fluxVersion string) unstructured.Unstructured {
+	deployment := unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":   name,
+			"labels": map[string]interface{}{"app.kubernetes.io/instance": "wego-system", "app.kubernetes.io/version": fluxVersion},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{"image": image}},
+				},
+			},
+		},
+	}}
+
+	return deployment
+}
+
+var _ = Describe("Upgrade", func() {
+	var (
+		upgradeParams gitops.UpgradeParams
+		installedCRD  *extensionsv1.CustomResourceDefinition
+		deployments   []unstructured.Unstructured
+	)
+
+	BeforeEach(func() {
+		// Flux versions are embedded without the v of the version label of the controllers
+		version.FluxVersion = "0.16.0"
+		version.WegoImageTag = "0.2.0"
+
+		// The embedded CRD as the cluster returns it, with its metadata, status and defaulted fields
+		installedCRD = &extensionsv1.CustomResourceDefinition{}
+		Expect(yaml.Unmarshal(manifests.AppCRD, installedCRD)).To(Succeed())
+		installedCRD.ResourceVersion = "1234"
+		installedCRD.Annotations = map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"}
+		installedCRD.Spec.Conversion = &extensionsv1.CustomResourceConversion{Strategy: extensionsv1.NoneConverter}
+		installedCRD.Status.StoredVersions = []string{"v1alpha1"}
+		installedCRD.Status.AcceptedNames = installedCRD.Spec.Names
+
+		deployments = []unstructured.Unstructured{
+			fluxDeployment("source-controller", "ghcr.io/fluxcd/source-controller:v0.15.3", "v0.16.0"),
+			fluxDeployment("image-reflector-controller", "ghcr.io/fluxcd/image-reflector-controller:v0.11.0", "v0.16.0"),
+		}
+
+		fluxClient = &fluxfakes.FakeFlux{
+			InstallStub: func(namespace, registry, imagePullSecret string, export bool) ([]byte, error) {
+				if export {
+					return []byte(exportedFlux), nil
+				}
+
+				return nil, nil
+			},
+		}
+		kubeClient = &kubefakes.FakeKube{
+			GetClusterStatusStub: func(c context.Context) kube.ClusterStatus {
+				return kube.WeGOInstalled
+			},
+			ListResourcesStub: func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+				return deployments, nil
+			},
+			GetResourceStub: func(ctx context.Context, name types.NamespacedName, r kube.Resource) error {
+				installedCRD.DeepCopyInto(r.(*extensionsv1.CustomResourceDefinition))
+				return nil
+			},
+		}
+		gitopsSrv = gitops.New(logger.New(os.Stderr), fluxClient, kubeClient)
+
+		upgradeParams = gitops.UpgradeParams{Namespace: "wego-system"}
+	})
+
+	It("fails when wego is not installed", func() {
+		kubeClient.GetClusterStatusStub = func(c context.Context) kube.ClusterStatus {
+			return kube.FluxInstalled
+		}
+
+		_, _, err := gitopsSrv.Upgrade(upgradeParams)
+		Expect(err).To(MatchError("Weave GitOps is not installed, install it with:\n  $ wego gitops install"))
+	})
+
+	It("does nothing when the cluster is up to date", func() {
+		summary, _, err := gitopsSrv.Upgrade(upgradeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(summary.UpToDate()).To(BeTrue())
+
+		Expect(fluxClient.InstallCallCount()).To(Equal(1))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+
+		_, gvk, namespace, labels := kubeClient.ListResourcesArgsForCall(0)
+		Expect(gvk.Kind).To(Equal("Deployment"))
+		Expect(namespace).To(Equal("wego-system"))
+		Expect(labels).To(Equal(map[string]string{"app.kubernetes.io/instance": "wego-system"}))
+
+		_, name, _ := kubeClient.GetResourceArgsForCall(0)
+		Expect(name.Name).To(Equal("apps.wego.weave.works"))
+	})
+
+	It("upgrades the changed and missing controllers and the CRD in place", func() {
+		deployments = []unstructured.Unstructured{
+			fluxDeployment("source-controller", "ghcr.io/fluxcd/source-controller:v0.15.2", "v0.15.0"),
+		}
+		installedCRD.Spec.Versions[0].Schema = nil
+
+		summary, out, err := gitopsSrv.Upgrade(upgradeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).To(BeNil())
+
+		Expect(summary).To(Equal(gitops.UpgradeSummary{
+			CurrentFluxVersion: "v0.15.0",
+			NewFluxVersion:     "v0.16.0",
+			Components: []gitops.ComponentChange{
+				{Name: "image-reflector-controller", NewImage: "ghcr.io/fluxcd/image-reflector-controller:v0.11.0"},
+				{Name: "source-controller", CurrentImage: "ghcr.io/fluxcd/source-controller:v0.15.2", NewImage: "ghcr.io/fluxcd/source-controller:v0.15.3"},
+			},
+			CRDChanged: true,
+		}))
+
+		Expect(fluxClient.InstallCallCount()).To(Equal(2))
+		namespace, _, _, export := fluxClient.InstallArgsForCall(1)
+		Expect(namespace).To(Equal("wego-system"))
+		Expect(export).To(BeFalse())
+
+		Expect(kubeClient.ApplyCallCount()).To(Equal(1))
+		crd, _ := kubeClient.ApplyArgsForCall(0)
+		Expect(crd).To(Equal(manifests.AppCRD))
+	})
+
+	It("upgrades the installed wego components", func() {
+		wegoDeployment := func(name, component, image string) unstructured.Unstructured {
+			deployment := fluxDeployment(name, image, "")
+			deployment.SetLabels(map[string]string{"app.kubernetes.io/part-of": "weave-gitops", "app.kubernetes.io/component": component})
+
+			return deployment
+		}
+
+		kubeClient.ListResourcesStub = func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+			if labels["app.kubernetes.io/part-of"] == "weave-gitops" {
+				return []unstructured.Unstructured{
+					wegoDeployment("wego-controller", "controller", "ghcr.io/weaveworks/wego-controller:0.1.0"),
+					wegoDeployment("wego-ui", "ui", "ghcr.io/weaveworks/wego-ui:0.2.0"),
+				}, nil
+			}
+
+			return deployments, nil
+		}
+
+		summary, _, err := gitopsSrv.Upgrade(upgradeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(summary.WegoComponents).To(Equal([]gitops.ComponentChange{
+			{Name: "controller", CurrentImage: "ghcr.io/weaveworks/wego-controller:0.1.0", NewImage: "ghcr.io/weaveworks/wego-controller:0.2.0"},
+		}))
+
+		Expect(fluxClient.InstallCallCount()).To(Equal(2))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(2))
+
+		components, namespace := kubeClient.ApplyArgsForCall(1)
+		Expect(namespace).To(Equal("wego-system"))
+		Expect(string(components)).To(ContainSubstring("image: ghcr.io/weaveworks/wego-controller:0.2.0"))
+		Expect(string(components)).NotTo(ContainSubstring("wego-ui"))
+	})
+
+	It("leaves the wego components as they are with a build that isn't a release", func() {
+		version.WegoImageTag = "undefined"
+
+		kubeClient.ListResourcesStub = func(ctx context.Context, gvk schema.GroupVersionKind, namespace string, labels map[string]string) ([]unstructured.Unstructured, error) {
+			if labels["app.kubernetes.io/part-of"] == "weave-gitops" {
+				deployment := fluxDeployment("wego-controller", "ghcr.io/weaveworks/wego-controller:0.1.0", "")
+				deployment.SetLabels(map[string]string{"app.kubernetes.io/part-of": "weave-gitops", "app.kubernetes.io/component": "controller"})
+
+				return []unstructured.Unstructured{deployment}, nil
+			}
+
+			return deployments, nil
+		}
+
+		summary, _, err := gitopsSrv.Upgrade(upgradeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(summary.UpToDate()).To(BeTrue())
+		Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+	})
+
+	It("upgrades a CRD whose names changed", func() {
+		installedCRD.Spec.Names.ShortNames = []string{"wapp"}
+
+		summary, _, err := gitopsSrv.Upgrade(upgradeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(summary.CRDChanged).To(BeTrue())
+	})
+
+	It("only upgrades the CRD when wego was installed alongside an existing flux", func() {
+		deployments = nil
+		installedCRD.Spec.Versions[0].Schema = nil
+
+		summary, _, err := gitopsSrv.Upgrade(upgradeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(summary).To(Equal(gitops.UpgradeSummary{ExternalFlux: true, CRDChanged: true}))
+
+		Expect(fluxClient.InstallCallCount()).To(Equal(0))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(1))
+	})
+
+	It("returns the manifests without applying them when dry-run", func() {
+		upgradeParams.DryRun = true
+		deployments = deployments[:1]
+
+		_, out, err := gitopsSrv.Upgrade(upgradeParams)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("image-reflector-controller:v0.11.0"))
+		Expect(string(out)).To(ContainSubstring("kind: CustomResourceDefinition"))
+
+		Expect(fluxClient.InstallCallCount()).To(Equal(1))
+		Expect(kubeClient.ApplyCallCount()).To(Equal(0))
+	})
+
+	It("refuses to drop a version apps are stored in", func() {
+		installedCRD.Status.StoredVersions = []string{"v1alpha0", "v1alpha1"}
+
+		_, _, err := gitopsSrv.Upgrade(upgradeParams)
+		Expect(err).To(MatchError("the App CRD no longer has version v1alpha0, which apps in the cluster are stored in"))
+		Expect(fluxClient.InstallCallCount()).To(Equal(1))
+	})
+})
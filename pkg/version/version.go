@@ -5,4 +5,8 @@ var (
 	// Examples: -X version.FluxVersion=xxxxx
 
 	FluxVersion = "undefined"
+
+	// WegoImageTag is the tag of the wego controller, server and UI images installed by wego, the version of the
+	// release they are published with
+	WegoImageTag = "undefined"
 )
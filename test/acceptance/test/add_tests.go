@@ -1159,6 +1159,41 @@ var _ = Describe("Weave GitOps Add Tests", func() {
 		})
 	})
 
+	It("Verify that wego can deploy an app from a MinIO bucket with app-config-url set to NONE", func() {
+		tip := generateTestInputs()
+		appName := MINIO_BUCKET_NAME
+
+		addCommand := "app add --url=minio.minio.svc:9000 --bucket=" + MINIO_BUCKET_NAME + " --bucket-secret-ref=minio-credentials --bucket-insecure --path=./ --app-config-url=NONE"
+
+		defer deleteMinio()
+		defer deleteWorkload(tip.workloadName, tip.workloadNamespace)
+
+		By("And application workload is not already deployed to cluster", func() {
+			deleteWorkload(tip.workloadName, tip.workloadNamespace)
+		})
+
+		By("And I install wego to my active cluster", func() {
+			installAndVerifyWego(WEGO_DEFAULT_NAMESPACE)
+		})
+
+		By("And I have my app workload in a MinIO bucket", func() {
+			setupMinioBucket(tip.appManifestFilePath, WEGO_DEFAULT_NAMESPACE)
+		})
+
+		By("And I have my default ssh key on path "+DEFAULT_SSH_KEY_PATH, func() {
+			setupSSHKey(DEFAULT_SSH_KEY_PATH)
+		})
+
+		By("And I run wego add command", func() {
+			runWegoAddCommand(".", addCommand, WEGO_DEFAULT_NAMESPACE)
+		})
+
+		By("Then I should see my workload deployed to the cluster", func() {
+			Expect(waitForResource("Buckets", appName, WEGO_DEFAULT_NAMESPACE, INSTALL_PODS_READY_TIMEOUT)).To(Succeed())
+			verifyWorkloadIsDeployed(tip.workloadName, tip.workloadNamespace)
+		})
+	})
+
 	It("Verify that a PR is raised against a user repo when skipping auto-merge", func() {
 		var repoAbsolutePath string
 		tip := generateTestInputs()
@@ -43,7 +43,7 @@ var _ = Describe("Weave GitOps Install Tests", func() {
 
 		By("Then I should see wego help text displayed for 'uninstall' command", func() {
 			Eventually(string(sessionOutput.Wait().Out.Contents())).Should(MatchRegexp(
-				`The uninstall command removes Wego components from the cluster.\n*Usage:\n\s*wego gitops uninstall \[flags]\n*Examples:\n\s*# Uninstall wego in the wego-system namespace\n\s*wego uninstall\n*Flags:\n\s*-h, --help\s*help for uninstall\n*Global Flags:\n\s*--dry-run\s*outputs all the manifests that would be installed\n\s*-n, --namespace string \s*the namespace scope for this operation \(default "wego-system"\)\n\s*-v, --verbose\s*Enable verbose output`))
+				`The uninstall command removes Wego components from the cluster.\nWith --purge, the applications are removed first[^\n]*\n[^\n]*\n[^\n]*\n*Usage:\n\s*wego gitops uninstall \[flags]\n*Examples:\n\s*# Uninstall wego in the wego-system namespace\n\s*wego uninstall\n*\s*# Uninstall wego, removing the applications and their automation\n\s*wego gitops uninstall --purge --remove-automation\n*Flags:\n\s*-h, --help\s*help for uninstall\n\s*--purge\s*Remove the applications and their deploy keys before uninstalling\n\s*--remove-automation\s*With --purge, open a pull request removing the automation of each application\n*Global Flags:\n\s*--dry-run\s*outputs all the manifests that would be installed\n\s*-n, --namespace string \s*the namespace scope for this operation \(default "wego-system"\)\n\s*-v, --verbose\s*Enable verbose output`))
 		})
 	})
 
@@ -69,7 +69,7 @@ var _ = Describe("Weave GitOps Install Tests", func() {
 
 		By("Then I should see a quitting message", func() {
 			Eventually(errOutput).Should(MatchRegexp(
-				`Error: Weave GitOps does not yet support installation onto a cluster that is using Flux.\nPlease uninstall flux before proceeding:\n\s*. flux uninstall`))
+				`Error: Weave GitOps could not find the flux controllers in flux-system.\nSet the namespace flux is installed in with --flux-namespace`))
 		})
 	})
 
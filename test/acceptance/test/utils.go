@@ -27,6 +27,7 @@ const INSTALL_RESET_TIMEOUT time.Duration = 300 * time.Second
 const NAMESPACE_TERMINATE_TIMEOUT time.Duration = 600 * time.Second
 const INSTALL_PODS_READY_TIMEOUT time.Duration = 180 * time.Second
 const WEGO_DEFAULT_NAMESPACE = "wego-system"
+const MINIO_BUCKET_NAME = "wego-test-bucket"
 
 var DEFAULT_SSH_KEY_PATH string
 var GITHUB_ORG string
@@ -473,6 +474,26 @@ func verifyWorkloadIsDeployed(workloadName string, workloadNamespace string) {
 	Eventually(session, INSTALL_PODS_READY_TIMEOUT).Should(gexec.Exit())
 }
 
+// Deploys MinIO to the cluster and uploads the manifest to a bucket, along with the credentials flux needs to read it
+func setupMinioBucket(appManifestFilePath string, wegoNamespace string) {
+	Expect(runCommandPassThrough([]string{}, "kubectl", "apply", "-f", "data/minio.yaml")).To(Succeed())
+	Expect(runCommandPassThrough([]string{}, "kubectl", "wait", "--for=condition=Available", "--timeout=120s", "-n", "minio", "deploy/minio")).To(Succeed())
+
+	Expect(runCommandPassThrough([]string{}, "kubectl", "create", "configmap", "minio-manifests", "-n", "minio", "--from-file="+appManifestFilePath)).To(Succeed())
+	Expect(runCommandPassThrough([]string{}, "kubectl", "apply", "-f", "data/minio-upload.yaml")).To(Succeed())
+	Expect(runCommandPassThrough([]string{}, "kubectl", "wait", "--for=condition=Complete", "--timeout=120s", "-n", "minio", "job/minio-upload")).To(Succeed())
+
+	Expect(runCommandPassThrough([]string{}, "kubectl", "create", "secret", "generic", "minio-credentials", "-n", wegoNamespace,
+		"--from-literal=accesskey=wego-test", "--from-literal=secretkey=wego-test-secret")).To(Succeed())
+}
+
+func deleteMinio() {
+	log.Infof("Deleting MinIO")
+	_ = runCommandPassThrough([]string{}, "kubectl", "delete", "secret", "minio-credentials", "-n", WEGO_DEFAULT_NAMESPACE)
+	deleteNamespace("minio")
+	_ = waitForNamespaceToTerminate("minio", INSTALL_RESET_TIMEOUT)
+}
+
 func createGitRepoBranch(repoAbsolutePath string, branchName string) string {
 	command := exec.Command("sh", "-c", fmt.Sprintf("cd %s && git checkout -b %s && git push --set-upstream origin %s", repoAbsolutePath, branchName, branchName))
 	session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
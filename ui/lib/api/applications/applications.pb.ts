@@ -38,6 +38,41 @@ export type GetApplicationResponse = {
   application?: Application
 }
 
+export type ApplicationResource = {
+  kind?: string
+  namespace?: string
+  name?: string
+  health?: string
+  message?: string
+}
+
+export type GetApplicationResourcesRequest = {
+  name?: string
+  namespace?: string
+}
+
+export type GetApplicationResourcesResponse = {
+  resources?: ApplicationResource[]
+}
+
+export type LogEntry = {
+  timestamp?: number
+  source?: string
+  level?: string
+  object?: string
+  message?: string
+}
+
+export type GetApplicationLogsRequest = {
+  name?: string
+  namespace?: string
+  sinceSeconds?: string
+}
+
+export type GetApplicationLogsResponse = {
+  entries?: LogEntry[]
+}
+
 export class Applications {
   static ListApplications(req: ListApplicationsRequest, initReq?: fm.InitReq): Promise<ListApplicationsResponse> {
     return fm.fetchReq<ListApplicationsRequest, ListApplicationsResponse>(`/v1/applications?${fm.renderURLSearchParams(req, [])}`, {...initReq, method: "GET"})
@@ -45,4 +80,10 @@ export class Applications {
   static GetApplication(req: GetApplicationRequest, initReq?: fm.InitReq): Promise<GetApplicationResponse> {
     return fm.fetchReq<GetApplicationRequest, GetApplicationResponse>(`/v1/applications/${req["name"]}?${fm.renderURLSearchParams(req, ["name"])}`, {...initReq, method: "GET"})
   }
+  static GetApplicationResources(req: GetApplicationResourcesRequest, initReq?: fm.InitReq): Promise<GetApplicationResourcesResponse> {
+    return fm.fetchReq<GetApplicationResourcesRequest, GetApplicationResourcesResponse>(`/v1/applications/${req["name"]}/resources?${fm.renderURLSearchParams(req, ["name"])}`, {...initReq, method: "GET"})
+  }
+  static GetApplicationLogs(req: GetApplicationLogsRequest, initReq?: fm.InitReq): Promise<GetApplicationLogsResponse> {
+    return fm.fetchReq<GetApplicationLogsRequest, GetApplicationLogsResponse>(`/v1/applications/${req["name"]}/logs?${fm.renderURLSearchParams(req, ["name"])}`, {...initReq, method: "GET"})
+  }
 }
\ No newline at end of file